@@ -0,0 +1,193 @@
+package jsonpath
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// WithStrictNumeric makes Sum, Min, Max and Avg fail with ErrTypeMismatch on
+// a match that is not a number. By default such matches are skipped.
+func WithStrictNumeric() Option {
+	return func(e *engine) {
+		e.strictNumeric = true
+	}
+}
+
+// Sum returns the sum of the numeric matches of path, or 0 if there are
+// none. Other matches are skipped unless WithStrictNumeric is given.
+//
+// Example:
+//
+//	total, err := jsonpath.Sum(data, "$..price")
+func Sum(data []byte, path string, opts ...Option) (float64, error) {
+	sum := 0.0
+	_, err := aggregate(data, path, opts, func(f float64) { sum += f })
+	if err != nil {
+		return 0, err
+	}
+	return sum, nil
+}
+
+// Min returns the smallest numeric match of path. It fails with ErrNoMatch
+// when there are no numeric matches; see Sum for non-numeric ones.
+func Min(data []byte, path string, opts ...Option) (float64, error) {
+	min := math.Inf(1)
+	return extreme(data, path, opts, &min, func(f float64) {
+		if f < min {
+			min = f
+		}
+	})
+}
+
+// Max returns the largest numeric match of path. It fails with ErrNoMatch
+// when there are no numeric matches; see Sum for non-numeric ones.
+func Max(data []byte, path string, opts ...Option) (float64, error) {
+	max := math.Inf(-1)
+	return extreme(data, path, opts, &max, func(f float64) {
+		if f > max {
+			max = f
+		}
+	})
+}
+
+// Avg returns the mean of the numeric matches of path. It fails with
+// ErrNoMatch when there are no numeric matches; see Sum for non-numeric ones.
+func Avg(data []byte, path string, opts ...Option) (float64, error) {
+	sum := 0.0
+	n, err := aggregate(data, path, opts, func(f float64) { sum += f })
+	if err != nil {
+		return 0, err
+	}
+	if n == 0 {
+		return 0, noNumbers(path)
+	}
+	return sum / float64(n), nil
+}
+
+func extreme(data []byte, path string, opts []Option, result *float64, fn func(float64)) (float64, error) {
+	n, err := aggregate(data, path, opts, fn)
+	if err != nil {
+		return 0, err
+	}
+	if n == 0 {
+		return 0, noNumbers(path)
+	}
+	return *result, nil
+}
+
+func noNumbers(path string) error {
+	return &Error{Code: ErrNoMatch, Message: fmt.Sprintf("path %s matched no numbers", path)}
+}
+
+// aggregate passes every numeric match of path to fn in one pass and returns
+// how many there were.
+func aggregate(data []byte, path string, opts []Option, fn func(float64)) (int, error) {
+	strict := newEngine(context.Background(), opts).strictNumeric
+	n := 0
+	err := QueryFunc(context.Background(), data, path, func(r Result) error {
+		f, ok := numericValue(r.Value)
+		if !ok {
+			if strict {
+				return &Error{Code: ErrTypeMismatch, Message: fmt.Sprintf("%s is not a number", r.Path)}
+			}
+			return nil
+		}
+		fn(f)
+		n++
+		return nil
+	}, opts...)
+	return n, err
+}
+
+// numericValue is toFloat64 that also reads json.RawMessage numbers, as
+// produced by WithRawValues.
+func numericValue(v interface{}) (float64, bool) {
+	if raw, ok := v.(json.RawMessage); ok {
+		if len(raw) == 0 || (raw[0] != '-' && (raw[0] < '0' || raw[0] > '9')) {
+			return 0, false
+		}
+		f, err := strconv.ParseFloat(string(raw), 64)
+		return f, err == nil
+	}
+	return toFloat64(v)
+}
+
+// GroupBy runs itemsPath and buckets the matches by the value keyPath, a
+// relative path such as "@.category", selects in each of them. The document
+// is parsed once and each key path only looks inside its item. Keys are the
+// string form of the first value keyPath selects: strings as they are, other
+// values as JSON. Items where keyPath selects nothing are left out; within a
+// group, items keep document order.
+//
+// Example:
+//
+//	byCategory, err := jsonpath.GroupBy(data, "$.store.book[*]", "@.category")
+//	for category, books := range byCategory {
+//	    fmt.Println(category, len(books))
+//	}
+func GroupBy(data []byte, itemsPath, keyPath string, opts ...Option) (map[string][]Result, error) {
+	if !strings.HasPrefix(keyPath, "@") {
+		return nil, &Error{Code: ErrInvalidPath, Message: fmt.Sprintf("GroupBy key path must be relative to the item and start with '@': %s", keyPath)}
+	}
+	e := newEngine(context.Background(), opts)
+	items, err := e.compile(itemsPath)
+	if err != nil {
+		return nil, err
+	}
+	key, err := e.compile("$" + keyPath[1:])
+	if err != nil {
+		return nil, err
+	}
+	doc, err := e.parse(data)
+	if err != nil {
+		return nil, err
+	}
+
+	groups := map[string][]Result{}
+	e.attach(doc)
+	keys := e.operandEngine()
+	err = e.stream(doc.root, items.tokens, func(r Result) error {
+		item := r.Value
+		if _, ok := item.(json.RawMessage); ok {
+			// WithRawValues: the key path runs against the item's node
+			var err error
+			if item, err = nodeAt(doc.root, r.Path); err != nil {
+				return err
+			}
+		}
+		var k interface{}
+		found := false
+		err := keys.evaluate(item, key.tokens, "$", func(kr Result) error {
+			k, found = kr.Value, true
+			return ErrStop
+		})
+		if err != nil && err != ErrStop {
+			return err
+		}
+		if found {
+			s := groupKey(k)
+			groups[s] = append(groups[s], r)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return groups, nil
+}
+
+// groupKey returns the map key for a GroupBy key value.
+func groupKey(v interface{}) string {
+	if s, ok := v.(string); ok {
+		return s
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(b)
+}
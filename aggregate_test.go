@@ -0,0 +1,107 @@
+package jsonpath_test
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+
+	"github.com/njchilds90/go-jsonpath"
+)
+
+func TestAggregates(t *testing.T) {
+	data := []byte(`{"items": [{"price": 10}, {"price": 2.5}, {"price": "n/a"}, {"price": null}, {"price": -4}]}`)
+	tests := []struct {
+		name string
+		fn   func([]byte, string, ...jsonpath.Option) (float64, error)
+		want float64
+	}{
+		{"Sum", jsonpath.Sum, 8.5},
+		{"Min", jsonpath.Min, -4},
+		{"Max", jsonpath.Max, 10},
+		{"Avg", jsonpath.Avg, 8.5 / 3},
+	}
+	for _, tt := range tests {
+		got, err := tt.fn(data, "$.items[*].price")
+		if err != nil || math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("%s: expected %v, got %v %v", tt.name, tt.want, got, err)
+		}
+		got, err = tt.fn(data, "$.items[*].price", jsonpath.WithRawValues())
+		if err != nil || math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("%s with raw values: expected %v, got %v %v", tt.name, tt.want, got, err)
+		}
+		if _, err := tt.fn(data, "$.items[*].price", jsonpath.WithStrictNumeric()); !isTypeMismatch(err) {
+			t.Errorf("%s: expected type mismatch in strict mode, got %v", tt.name, err)
+		}
+		if _, err := tt.fn(data, "$.items["); !jsonpath.IsPathError(err) {
+			t.Errorf("%s: expected path error, got %v", tt.name, err)
+		}
+	}
+
+	if total, err := jsonpath.Sum(sampleJSON, "$..price"); err != nil || math.Abs(total-73.87) > 1e-9 {
+		t.Errorf("expected 73.87, got %v %v", total, err)
+	}
+}
+
+func TestAggregatesNoNumbers(t *testing.T) {
+	if sum, err := jsonpath.Sum(sampleJSON, "$..missing"); err != nil || sum != 0 {
+		t.Errorf("expected 0, got %v %v", sum, err)
+	}
+	for name, fn := range map[string]func([]byte, string, ...jsonpath.Option) (float64, error){
+		"Min": jsonpath.Min, "Max": jsonpath.Max, "Avg": jsonpath.Avg,
+	} {
+		if _, err := fn(sampleJSON, "$..author"); !jsonpath.IsNoMatch(err) {
+			t.Errorf("%s: expected no match, got %v", name, err)
+		}
+	}
+}
+
+func TestGroupBy(t *testing.T) {
+	groups, err := jsonpath.GroupBy(sampleJSON, "$.store.book[*]", "@.category")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(groups) != 2 || len(groups["reference"]) != 1 || len(groups["fiction"]) != 3 {
+		t.Fatalf("unexpected groups: %v", groups)
+	}
+	if groups["fiction"][2].Path != "$.store.book[3]" {
+		t.Errorf("expected document order within a group, got %v", groups["fiction"])
+	}
+
+	data := []byte(`{"orders": [{"id": 1, "qty": 2}, {"id": 2, "qty": 1}, {"id": 3, "qty": 2}, {"id": 4}, {"id": 5, "qty": null}]}`)
+	groups, err = jsonpath.GroupBy(data, "$.orders[*]", "@.qty")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(groups["2"]) != 2 || len(groups["1"]) != 1 || len(groups["null"]) != 1 || len(groups) != 3 {
+		t.Errorf("unexpected groups: %v", groups)
+	}
+
+	groups, err = jsonpath.GroupBy(sampleJSON, "$..book[*].category", "@")
+	if err != nil || len(groups["fiction"]) != 3 {
+		t.Errorf("unexpected groups by the item itself: %v %v", groups, err)
+	}
+
+	data = []byte(`{"b": [{"c": "x", "n": 1}, {"c": "y"}, {"c": "x"}]}`)
+	groups, err = jsonpath.GroupBy(data, "$.b[*]", "@.c", jsonpath.WithRawValues())
+	if err != nil || len(groups) != 2 || len(groups["x"]) != 2 {
+		t.Fatalf("unexpected groups of raw values: %v %v", groups, err)
+	}
+	if raw, ok := groups["x"][0].Value.(json.RawMessage); !ok || string(raw) != `{"c": "x", "n": 1}` {
+		t.Errorf("expected raw values in the groups, got %v", groups["x"][0].Value)
+	}
+}
+
+func TestGroupByErrors(t *testing.T) {
+	if _, err := jsonpath.GroupBy(sampleJSON, "$.store.book[*]", "$.category"); !jsonpath.IsPathError(err) {
+		t.Errorf("expected path error for an absolute key path, got %v", err)
+	}
+	if _, err := jsonpath.GroupBy(sampleJSON, "$.store.book[", "@.category"); !jsonpath.IsPathError(err) {
+		t.Errorf("expected path error, got %v", err)
+	}
+	if _, err := jsonpath.GroupBy(sampleJSON, "$.store.book[*]", "@.["); !jsonpath.IsPathError(err) {
+		t.Errorf("expected path error, got %v", err)
+	}
+	if _, err := jsonpath.GroupBy([]byte("{"), "$[*]", "@.a"); !jsonpath.IsJSONError(err) {
+		t.Errorf("expected JSON error, got %v", err)
+	}
+}
@@ -0,0 +1,104 @@
+package jsonpath
+
+import (
+	"fmt"
+	"strings"
+)
+
+// WithAllAlternatives makes a path of alternatives, such as
+// "$.email | $.contact.email", return the matches of every alternative in
+// turn. By default only the first alternative that matches contributes.
+func WithAllAlternatives() Option {
+	return func(e *engine) {
+		e.allAlts = true
+	}
+}
+
+// CompileAny compiles paths into one path that returns the matches of the
+// first of them that matches anything, as if they were joined with '|'. It
+// suits fallback chains over documents whose schema varies.
+//
+// Example:
+//
+//	email := jsonpath.MustCompileAny("$.primaryEmail", "$.contact.email", "$.emails[0]")
+//	r, err := email.First(data)
+func CompileAny(paths ...string) (*CompiledPath, error) {
+	if len(paths) == 0 {
+		return nil, &Error{Code: ErrInvalidInput, Message: "CompileAny needs at least one path"}
+	}
+	return Compile(strings.Join(paths, " | "))
+}
+
+// MustCompileAny is CompileAny that panics on error.
+func MustCompileAny(paths ...string) *CompiledPath {
+	cp, err := CompileAny(paths...)
+	if err != nil {
+		panic(fmt.Sprintf("jsonpath.MustCompileAny: %v", err))
+	}
+	return cp
+}
+
+// splitAlternatives splits path at each '|' that is outside brackets,
+// parentheses and quoted strings, so the || of a filter does not split it.
+func splitAlternatives(path string) []string {
+	var parts []string
+	depth, start := 0, 0
+	var quote byte
+	for i := 0; i < len(path); i++ {
+		c := path[i]
+		switch {
+		case quote != 0:
+			if c == '\\' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"':
+			quote = c
+		case c == '[' || c == '(':
+			depth++
+		case c == ']' || c == ')':
+			depth--
+		case c == '|' && depth == 0:
+			parts = append(parts, path[start:i])
+			start = i + 1
+		}
+	}
+	return append(parts, path[start:])
+}
+
+// evalAlternatives evaluates each alternative against node, stopping
+// after the first that matches unless WithAllAlternatives is set. A missing
+// key or index reported under WithAllowMissingKeys(true) moves on to the next
+// alternative; only the last one's is returned.
+func (e *engine) evalAlternatives(node interface{}, alts [][]token, currentPath string, emit emitFunc) error {
+	for i, alt := range alts {
+		matched := false
+		mark := len(e.st.violations)
+		err := e.evaluate(node, alt[1:], currentPath, func(r Result) error {
+			matched = true
+			return emit(r)
+		})
+		if err != nil && (matched || e.allAlts || i == len(alts)-1 || !isLookupError(err)) {
+			return err
+		}
+		if matched && !e.allAlts {
+			return nil
+		}
+		if !e.allAlts && i < len(alts)-1 {
+			if e.logger != nil {
+				e.debug("jsonpath: alternative matched nothing", "path", currentPath, "alternative", formatTokens(alt), "error", err)
+			}
+			// violations recorded under WithAllErrors fall through like errors
+			e.st.violations = e.st.violations[:mark]
+		}
+	}
+	return nil
+}
+
+func isLookupError(err error) bool {
+	if e, ok := err.(*Error); ok {
+		return e.Code == ErrKeyNotFound || e.Code == ErrIndexOutOfBounds || e.Code == ErrTypeMismatch
+	}
+	return false
+}
@@ -0,0 +1,97 @@
+package jsonpath_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/njchilds90/go-jsonpath"
+)
+
+var contactJSON = []byte(`{
+	"contact": {"email": "c@example.com"},
+	"emails": ["e0@example.com", "e1@example.com"],
+	"note": "a | b"
+}`)
+
+func TestAlternativesFirstMatch(t *testing.T) {
+	tests := []struct{ path, want string }{
+		{"$.primaryEmail | $.contact.email | $.emails[0]", "c@example.com"},
+		{"$.primaryEmail|$.emails[0]", "e0@example.com"},
+		{"$.emails[?(@ == 'x' || @ == 'e1@example.com')] | $.note", "e1@example.com"},
+		{"$['a | b'] | $.note", "a | b"},
+	}
+	for _, tt := range tests {
+		results, err := jsonpath.Query(contactJSON, tt.path)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.path, err)
+		}
+		if len(results) != 1 || results[0].Value != tt.want {
+			t.Errorf("%s: expected %q, got %v", tt.path, tt.want, results)
+		}
+	}
+
+	results, err := jsonpath.Query(contactJSON, "$.missing | $.emails[*]")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 2 || results[1].Path != "$.emails[1]" {
+		t.Errorf("expected every match of the first matching alternative, got %v", results)
+	}
+
+	results, err = jsonpath.Query(contactJSON, "$.a | $.b")
+	if err != nil || len(results) != 0 {
+		t.Errorf("expected no results and no error, got %v, %v", results, err)
+	}
+}
+
+func TestAlternativesAll(t *testing.T) {
+	results, err := jsonpath.Query(contactJSON, "$.emails[0] | $.missing | $.contact.email", jsonpath.WithAllAlternatives())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 2 || results[0].Value != "e0@example.com" || results[1].Value != "c@example.com" {
+		t.Errorf("unexpected results: %v", results)
+	}
+}
+
+func TestAlternativesStrict(t *testing.T) {
+	r, err := jsonpath.QueryOne(contactJSON, "$.primaryEmail | $.contact.email", jsonpath.WithAllowMissingKeys(true))
+	if err != nil || r.Value != "c@example.com" {
+		t.Errorf("expected a missing key to fall through, got %v, %v", r, err)
+	}
+	_, err = jsonpath.Query(contactJSON, "$.a | $.b", jsonpath.WithAllowMissingKeys(true))
+	if !jsonpath.IsNotFound(err) {
+		t.Errorf("expected the last alternative's error, got %v", err)
+	}
+}
+
+func TestCompileAny(t *testing.T) {
+	cp := jsonpath.MustCompileAny("$.primaryEmail", "$..email")
+	if cp.String() != "$.primaryEmail | $..email" {
+		t.Errorf("unexpected string: %s", cp)
+	}
+	r, err := cp.First(contactJSON)
+	if err != nil || r == nil || r.Value != "c@example.com" {
+		t.Errorf("unexpected first result: %v, %v", r, err)
+	}
+	plan := cp.Explain()
+	if !plan.Descendant || plan.Cost != jsonpath.CostFullScan || plan.Steps[1].Kind != "alternatives" {
+		t.Errorf("unexpected plan: %+v", plan)
+	}
+	ast, err := cp.MarshalAST()
+	if err != nil || !strings.Contains(string(ast), `"alternatives":[[{"kind":"root"},{"kind":"child","key":"primaryEmail"}]`) {
+		t.Errorf("unexpected AST: %s, %v", ast, err)
+	}
+
+	if _, err := jsonpath.CompileAny(); !isInputError(err) {
+		t.Errorf("expected ErrInvalidInput, got %v", err)
+	}
+	for _, path := range []string{"$.a |", "| $.a", "$.a | b", "$.a | $.b["} {
+		if _, err := jsonpath.Compile(path); !jsonpath.IsPathError(err) {
+			t.Errorf("%s: expected ErrInvalidPath, got %v", path, err)
+		}
+	}
+	if _, err := jsonpath.Transpile("$.a | $.b", jsonpath.TargetJQ); err == nil {
+		t.Error("expected transpiling alternatives to fail")
+	}
+}
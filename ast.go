@@ -0,0 +1,167 @@
+package jsonpath
+
+import (
+	"bytes"
+	"encoding/json"
+)
+
+// astVersion is the version of the MarshalAST format. It changes only when
+// existing fields change meaning; new fields may be added within a version.
+const astVersion = 1
+
+// MarshalAST returns a JSON representation of the parsed path, so tools such
+// as linters, editors and policy engines can inspect user-supplied paths
+// without parsing them again. The format is stable:
+//
+//	{"version": 1, "path": "$.a[?(@.b > 1)]", "selectors": [...]}
+//
+// Each selector has a "kind" — root, child, descendant, wildcard, index,
+// slice, union, filter, alternatives, function, parent, names, pattern or
+// default — and the fields of that kind: "key" for child; "index" for index;
+// "start", "end" and "step" for slice, omitted when absent; "indices" or
+// "keys" for union; "expr" for filter; "alternatives", a list of selector
+// lists, for a path such as $.a | $.b; "function", the name, for a path
+// ending in length(); "pattern", the glob such as cpu_* or the regular
+// expression such as /^feature_/i, for pattern; and "path", a selector list,
+// and "default", a literal operand, for a path such as $.a ?? 30.
+//
+// Filter expressions are trees of nodes whose "kind" is one of:
+//
+//	logical     "op" ("&&" or "||"), "left", "right"
+//	comparison  "op" ("==", "!=", "<", "<=", ">", ">="), "left", "right"
+//	regex       "operand", "pattern", "flags"
+//	exists      "operand"
+//
+// and whose operands are {"kind": "path", "path", "selectors"},
+// {"kind": "literal", "value"}, {"kind": "call", "name", "args"} or
+// {"kind": "parameter", "name"} for an unbound placeholder such as :id.
+//
+// Example:
+//
+//	cp, err := jsonpath.Compile(userPath)
+//	if err != nil {
+//	    return err
+//	}
+//	ast, _ := cp.MarshalAST()
+//	policy.Evaluate(ast)
+func (cp *CompiledPath) MarshalAST() ([]byte, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false) // keep operators such as && and < readable
+	if err := enc.Encode(astPath{Version: astVersion, Path: cp.raw, Selectors: astSelectors(cp.tokens)}); err != nil {
+		return nil, err
+	}
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), nil
+}
+
+type astPath struct {
+	Version   int           `json:"version"`
+	Path      string        `json:"path"`
+	Selectors []astSelector `json:"selectors"`
+}
+
+type astSelector struct {
+	Kind         string          `json:"kind"`
+	Key          *string         `json:"key,omitempty"`
+	Index        *int            `json:"index,omitempty"`
+	Start        *int            `json:"start,omitempty"`
+	End          *int            `json:"end,omitempty"`
+	Step         *int            `json:"step,omitempty"`
+	Indices      []int           `json:"indices,omitempty"`
+	Keys         []string        `json:"keys,omitempty"`
+	Function     string          `json:"function,omitempty"`
+	Pattern      string          `json:"pattern,omitempty"`
+	Expr         *astNode        `json:"expr,omitempty"`
+	Alternatives [][]astSelector `json:"alternatives,omitempty"`
+	Path         []astSelector   `json:"path,omitempty"`
+	Default      *astNode        `json:"default,omitempty"`
+}
+
+// astNode is a filter expression node or operand.
+type astNode struct {
+	Kind      string        `json:"kind"`
+	Op        string        `json:"op,omitempty"`
+	Left      *astNode      `json:"left,omitempty"`
+	Right     *astNode      `json:"right,omitempty"`
+	Operand   *astNode      `json:"operand,omitempty"`
+	Pattern   *string       `json:"pattern,omitempty"`
+	Flags     *string       `json:"flags,omitempty"`
+	Path      string        `json:"path,omitempty"`
+	Selectors []astSelector `json:"selectors,omitempty"`
+	Value     *astValue     `json:"value,omitempty"`
+	Name      string        `json:"name,omitempty"`
+	Args      *[]*astNode   `json:"args,omitempty"`
+}
+
+// astValue holds a literal, so that a null literal is still written.
+type astValue struct {
+	v interface{}
+}
+
+func (v *astValue) MarshalJSON() ([]byte, error) {
+	return json.Marshal(v.v)
+}
+
+func astSelectors(tokens []token) []astSelector {
+	sels := make([]astSelector, len(tokens))
+	for i, tok := range tokens {
+		tok := tok
+		sel := astSelector{Kind: tok.kind.String()}
+		switch tok.kind {
+		case tokenChild:
+			sel.Key = &tok.key
+		case tokenIndex:
+			sel.Index = &tok.index
+		case tokenSlice:
+			sel.Start, sel.End, sel.Step = tok.slice[0], tok.slice[1], tok.slice[2]
+		case tokenUnion:
+			sel.Indices, sel.Keys = tok.indices, tok.keys
+		case tokenFilter:
+			sel.Expr = astFilter(tok.expr)
+		case tokenFunction:
+			sel.Function = tok.key
+		case tokenPattern:
+			sel.Pattern = tok.key
+		case tokenAlternatives:
+			for _, alt := range tok.alts {
+				sel.Alternatives = append(sel.Alternatives, astSelectors(alt))
+			}
+		case tokenDefault:
+			sel.Path, sel.Default = astSelectors(tok.alts[0]), astOperand(tok.literal)
+		}
+		sels[i] = sel
+	}
+	return sels
+}
+
+func astFilter(expr filterExpr) *astNode {
+	switch x := expr.(type) {
+	case *logicalExpr:
+		return &astNode{Kind: "logical", Op: x.op, Left: astFilter(x.left), Right: astFilter(x.right)}
+	case *compareExpr:
+		return &astNode{Kind: "comparison", Op: x.op, Left: astOperand(x.left), Right: astOperand(x.right)}
+	case *regexExpr:
+		return &astNode{Kind: "regex", Operand: astOperand(x.left), Pattern: &x.pattern, Flags: &x.flags}
+	case *existsExpr:
+		return &astNode{Kind: "exists", Operand: astOperand(x.operand)}
+	}
+	return nil
+}
+
+func astOperand(op operand) *astNode {
+	switch x := op.(type) {
+	case *pathOperand:
+		return &astNode{Kind: "path", Path: x.raw, Selectors: astSelectors(x.tokens)}
+	case *literalOperand:
+		return &astNode{Kind: "literal", Value: &astValue{x.value}}
+	case *callOperand:
+		args := make([]*astNode, len(x.args))
+		for i, a := range x.args {
+			args[i] = astOperand(a)
+		}
+		return &astNode{Kind: "call", Name: x.name, Args: &args}
+	case *paramOperand:
+		return &astNode{Kind: "parameter", Name: x.name}
+	}
+	return nil
+}
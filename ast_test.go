@@ -0,0 +1,47 @@
+package jsonpath_test
+
+import (
+	"testing"
+
+	"github.com/njchilds90/go-jsonpath"
+)
+
+func TestMarshalAST(t *testing.T) {
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"$", `{"version":1,"path":"$","selectors":[{"kind":"root"}]}`},
+		{"$.store.book[0]", `{"version":1,"path":"$.store.book[0]","selectors":[{"kind":"root"},{"kind":"child","key":"store"},{"kind":"child","key":"book"},{"kind":"index","index":0}]}`},
+		{"$..*", `{"version":1,"path":"$..*","selectors":[{"kind":"root"},{"kind":"descendant"},{"kind":"wildcard"}]}`},
+		{"$[1:]", `{"version":1,"path":"$[1:]","selectors":[{"kind":"root"},{"kind":"slice","start":1}]}`},
+		{"$[0,2]", `{"version":1,"path":"$[0,2]","selectors":[{"kind":"root"},{"kind":"union","indices":[0,2]}]}`},
+		{"$['a','b']", `{"version":1,"path":"$['a','b']","selectors":[{"kind":"root"},{"kind":"union","keys":["a","b"]}]}`},
+		{
+			"$[?(@.price < 10 && @.tag == null)]",
+			`{"version":1,"path":"$[?(@.price < 10 && @.tag == null)]","selectors":[{"kind":"root"},{"kind":"filter","expr":` +
+				`{"kind":"logical","op":"&&",` +
+				`"left":{"kind":"comparison","op":"<","left":{"kind":"path","path":"@.price","selectors":[{"kind":"root"},{"kind":"child","key":"price"}]},"right":{"kind":"literal","value":10}},` +
+				`"right":{"kind":"comparison","op":"==","left":{"kind":"path","path":"@.tag","selectors":[{"kind":"root"},{"kind":"child","key":"tag"}]},"right":{"kind":"literal","value":null}}}}]}`,
+		},
+		{
+			"$[?(@.name =~ /^a/i)]",
+			`{"version":1,"path":"$[?(@.name =~ /^a/i)]","selectors":[{"kind":"root"},{"kind":"filter","expr":` +
+				`{"kind":"regex","operand":{"kind":"path","path":"@.name","selectors":[{"kind":"root"},{"kind":"child","key":"name"}]},"pattern":"^a","flags":"i"}}]}`,
+		},
+		{
+			"$[?(ready())]",
+			`{"version":1,"path":"$[?(ready())]","selectors":[{"kind":"root"},{"kind":"filter","expr":{"kind":"exists","operand":{"kind":"call","name":"ready","args":[]}}}]}`,
+		},
+	}
+	for _, tt := range tests {
+		got, err := jsonpath.MustCompile(tt.path).MarshalAST()
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", tt.path, err)
+			continue
+		}
+		if string(got) != tt.want {
+			t.Errorf("%s:\n  expected %s\n       got %s", tt.path, tt.want, got)
+		}
+	}
+}
@@ -0,0 +1,241 @@
+package jsonpath
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// paramOperand is a placeholder such as `:id`, replaced by Bind.
+type paramOperand struct {
+	name string
+}
+
+func (*paramOperand) isOperand() {}
+
+// Bind returns a copy of cp with the placeholders of its filter expressions,
+// such as :id in $.users[?(@.id == :id)], replaced by the values in params.
+// Values are inserted as literals, never as path text, so a value cannot
+// change the shape of the path. They must be strings, numbers, booleans or
+// nil. Every placeholder must be bound, and every entry of params used, or
+// Bind fails with ErrInvalidInput. Querying a path with unbound placeholders
+// fails with ErrInvalidFilter.
+//
+// Example:
+//
+//	roles := jsonpath.MustCompile("$.users[?(@.id == :id)].roles")
+//	cp, err := roles.Bind(map[string]interface{}{"id": userID})
+//	results, err := cp.Query(data)
+func (cp *CompiledPath) Bind(params map[string]interface{}) (*CompiledPath, error) {
+	b := &binder{params: params, used: map[string]bool{}}
+	tokens, err := b.tokens(cp.tokens)
+	if err != nil {
+		return nil, err
+	}
+	if len(b.missing) > 0 {
+		return nil, &Error{Code: ErrInvalidInput, Message: fmt.Sprintf("no value bound to %s", strings.Join(b.missing, ", "))}
+	}
+	var unused []string
+	for name := range params {
+		if !b.used[name] {
+			unused = append(unused, name)
+		}
+	}
+	if len(unused) > 0 {
+		sort.Strings(unused)
+		return nil, &Error{Code: ErrInvalidInput, Message: fmt.Sprintf("path %s has no placeholder %s", cp.raw, strings.Join(unused, ", "))}
+	}
+	return &CompiledPath{raw: formatTokens(tokens), tokens: tokens}, nil
+}
+
+// MustBind is Bind that panics on error.
+func (cp *CompiledPath) MustBind(params map[string]interface{}) *CompiledPath {
+	bound, err := cp.Bind(params)
+	if err != nil {
+		panic(fmt.Sprintf("jsonpath.MustBind: %v", err))
+	}
+	return bound
+}
+
+// binder copies tokens, replacing placeholders with literals.
+type binder struct {
+	params  map[string]interface{}
+	used    map[string]bool
+	missing []string
+}
+
+func (b *binder) tokens(tokens []token) ([]token, error) {
+	out := make([]token, len(tokens))
+	for i, tok := range tokens {
+		switch tok.kind {
+		case tokenFilter:
+			expr, err := b.filter(tok.expr)
+			if err != nil {
+				return nil, err
+			}
+			tok.expr, tok.filter = expr, formatFilter(expr)
+		case tokenAlternatives, tokenDefault:
+			alts := make([][]token, len(tok.alts))
+			for j, alt := range tok.alts {
+				bound, err := b.tokens(alt)
+				if err != nil {
+					return nil, err
+				}
+				alts[j] = bound
+			}
+			tok.alts = alts
+		}
+		out[i] = tok
+	}
+	return out, nil
+}
+
+func (b *binder) filter(expr filterExpr) (filterExpr, error) {
+	switch x := expr.(type) {
+	case *logicalExpr:
+		left, err := b.filter(x.left)
+		if err != nil {
+			return nil, err
+		}
+		right, err := b.filter(x.right)
+		if err != nil {
+			return nil, err
+		}
+		return &logicalExpr{op: x.op, left: left, right: right}, nil
+	case *compareExpr:
+		left, err := b.operand(x.left)
+		if err != nil {
+			return nil, err
+		}
+		right, err := b.operand(x.right)
+		if err != nil {
+			return nil, err
+		}
+		return &compareExpr{op: x.op, left: left, right: right}, nil
+	case *regexExpr:
+		left, err := b.operand(x.left)
+		if err != nil {
+			return nil, err
+		}
+		return &regexExpr{left: left, pattern: x.pattern, flags: x.flags, re: x.re}, nil
+	case *existsExpr:
+		op, err := b.operand(x.operand)
+		if err != nil {
+			return nil, err
+		}
+		return &existsExpr{operand: op}, nil
+	}
+	return expr, nil
+}
+
+func (b *binder) operand(op operand) (operand, error) {
+	switch x := op.(type) {
+	case *paramOperand:
+		v, ok := b.params[x.name]
+		if !ok {
+			b.missing = append(b.missing, ":"+x.name)
+			return x, nil
+		}
+		b.used[x.name] = true
+		lit, err := bindValue(x.name, v)
+		if err != nil {
+			return nil, err
+		}
+		return lit, nil
+	case *pathOperand:
+		tokens, err := b.tokens(x.tokens)
+		if err != nil {
+			return nil, err
+		}
+		return &pathOperand{raw: "@" + formatTokens(tokens)[1:], tokens: tokens}, nil
+	case *callOperand:
+		call := &callOperand{name: x.name, args: make([]operand, len(x.args))}
+		for i, a := range x.args {
+			arg, err := b.operand(a)
+			if err != nil {
+				return nil, err
+			}
+			call.args[i] = arg
+		}
+		return call, nil
+	}
+	return op, nil
+}
+
+// bindValue returns the literal for a bound value, with numbers as float64
+// like the numbers of a filter expression.
+func bindValue(name string, v interface{}) (*literalOperand, error) {
+	switch v.(type) {
+	case nil, bool, string:
+		return &literalOperand{value: v}, nil
+	}
+	if f, ok := toFloat64(v); ok {
+		lit := &literalOperand{value: f}
+		switch rv := reflect.ValueOf(v); rv.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			lit.number = strconv.FormatInt(rv.Int(), 10)
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+			lit.number = strconv.FormatUint(rv.Uint(), 10)
+		case reflect.String: // json.Number
+			lit.number = rv.String()
+		}
+		return lit, nil
+	}
+	return nil, &Error{Code: ErrInvalidInput, Message: fmt.Sprintf("cannot bind %T to :%s: values must be strings, numbers, booleans or nil", v, name)}
+}
+
+// formatFilter returns expr in filter syntax.
+func formatFilter(expr filterExpr) string {
+	switch x := expr.(type) {
+	case *logicalExpr:
+		return formatLogicalSide(x.op, x.left) + " " + x.op + " " + formatLogicalSide(x.op, x.right)
+	case *compareExpr:
+		return formatOperand(x.left) + " " + x.op + " " + formatOperand(x.right)
+	case *regexExpr:
+		return formatOperand(x.left) + " =~ /" + x.pattern + "/" + x.flags
+	case *existsExpr:
+		return formatOperand(x.operand)
+	}
+	return ""
+}
+
+// formatLogicalSide parenthesizes a side of op that is a different logical
+// operator, keeping the grouping explicit.
+func formatLogicalSide(op string, side filterExpr) string {
+	if l, ok := side.(*logicalExpr); ok && l.op != op {
+		return "(" + formatFilter(side) + ")"
+	}
+	return formatFilter(side)
+}
+
+func formatOperand(op operand) string {
+	switch x := op.(type) {
+	case *pathOperand:
+		return x.raw
+	case *paramOperand:
+		return ":" + x.name
+	case *callOperand:
+		args := make([]string, len(x.args))
+		for i, a := range x.args {
+			args[i] = formatOperand(a)
+		}
+		return x.name + "(" + strings.Join(args, ", ") + ")"
+	case *literalOperand:
+		switch v := x.value.(type) {
+		case nil:
+			return "null"
+		case bool:
+			return strconv.FormatBool(v)
+		case string:
+			return quoteKey(v)
+		case float64:
+			if x.number != "" {
+				return x.number
+			}
+			return strconv.FormatFloat(v, 'g', -1, 64)
+		}
+	}
+	return ""
+}
@@ -0,0 +1,85 @@
+package jsonpath_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/njchilds90/go-jsonpath"
+)
+
+var usersJSON = []byte(`{"users": [
+	{"id": 41, "name": "ann", "roles": ["admin"]},
+	{"id": 42, "name": "o'brien", "roles": ["dev", "ops"]}
+]}`)
+
+func TestBind(t *testing.T) {
+	roles := jsonpath.MustCompile("$.users[?(@.id == :id)].roles[*]")
+	cp, err := roles.Bind(map[string]interface{}{"id": 42})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cp.String() != "$.users[?(@.id == 42)].roles[*]" {
+		t.Errorf("unexpected string: %s", cp)
+	}
+	values, err := cp.Values(usersJSON)
+	if err != nil || len(values) != 2 || values[0] != "dev" {
+		t.Errorf("unexpected values: %v, %v", values, err)
+	}
+	if roles.String() != "$.users[?(@.id == :id)].roles[*]" {
+		t.Errorf("Bind must not change the template, got %s", roles)
+	}
+}
+
+func TestBindValuesAreLiterals(t *testing.T) {
+	byName := jsonpath.MustCompile("$.users[?(@.name == :name || hasRole(@, :role))].id")
+	cp := byName.MustBind(map[string]interface{}{"name": "o'brien", "role": "') || true || ('"})
+	if !strings.Contains(cp.String(), `'o\'brien'`) {
+		t.Errorf("expected the quote to be escaped, got %s", cp)
+	}
+	hasRole := jsonpath.WithFunction("hasRole", func(_ context.Context, args []interface{}) (interface{}, error) {
+		return false, nil
+	})
+	values, err := cp.Values(usersJSON, hasRole)
+	if err != nil || len(values) != 1 || values[0] != float64(42) {
+		t.Errorf("unexpected values: %v, %v", values, err)
+	}
+	again, err := jsonpath.Compile(cp.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again.String() != cp.String() {
+		t.Errorf("expected the bound path to round-trip, got %s", again)
+	}
+}
+
+func TestBindNested(t *testing.T) {
+	cp := jsonpath.MustCompile("$.missing | $.users[?(@.id > :min)].name").
+		MustBind(map[string]interface{}{"min": int64(41)})
+	values, err := cp.Values(usersJSON)
+	if err != nil || len(values) != 1 || values[0] != "o'brien" {
+		t.Errorf("unexpected values: %v, %v", values, err)
+	}
+}
+
+func TestBindErrors(t *testing.T) {
+	cp := jsonpath.MustCompile("$.users[?(@.id == :id)]")
+	if _, err := cp.Bind(nil); !isInputError(err) || !strings.Contains(err.Error(), ":id") {
+		t.Errorf("expected a missing parameter error, got %v", err)
+	}
+	if _, err := cp.Bind(map[string]interface{}{"id": 1, "idd": 2}); !isInputError(err) || !strings.Contains(err.Error(), "idd") {
+		t.Errorf("expected an unused parameter error, got %v", err)
+	}
+	if _, err := cp.Bind(map[string]interface{}{"id": []int{1}}); !isInputError(err) {
+		t.Errorf("expected a type error, got %v", err)
+	}
+	if _, err := cp.Query(usersJSON); !jsonpath.IsFilterError(err) {
+		t.Errorf("expected querying an unbound path to fail, got %v", err)
+	}
+	if _, err := jsonpath.Compile("$[?(@.id == :)]"); !jsonpath.IsFilterError(err) {
+		t.Errorf("expected a parse error, got %v", err)
+	}
+	if _, err := jsonpath.Transpile("$[?(@.id == :id)]", jsonpath.TargetPostgres); !jsonpath.IsUnsupported(err) {
+		t.Errorf("expected ErrUnsupported, got %v", err)
+	}
+}
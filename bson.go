@@ -0,0 +1,280 @@
+package jsonpath
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ParseBSON decodes a raw BSON document, such as a bson.Raw or the bytes of
+// a MongoDB reply, into a Document.
+//
+// Documents decode to map[string]interface{} and arrays to []interface{}.
+// Strings, doubles and booleans decode as in JSON; int32 and int64 are
+// int64, datetimes are time.Time, ObjectIds are their hex string, binary
+// data is []byte, Decimal128 is a json.Number, or the string "NaN",
+// "Infinity" or "-Infinity" for the special values JSON cannot hold, and
+// regular expressions are objects with "pattern" and "options" members.
+// Queries that must follow the stored member order can run QueryValue over
+// a bson.D instead.
+//
+// A document whose length prefix disagrees with its content, a missing NUL
+// terminator, a deprecated element type such as DBPointer, or bytes after
+// the document fail with ErrInvalidJSON: BSON decode failures reuse that
+// code rather than having their own, and the error's Cause describes the
+// problem.
+//
+// Example:
+//
+//	raw, err := coll.FindOne(ctx, filter).Raw()
+//	if err != nil {
+//	    return err
+//	}
+//	results, err := jsonpath.QueryBSON(raw, "$.items[?(@.qty > 10)].sku")
+func ParseBSON(data []byte) (*Document, error) {
+	d := bsonDecoder{data: data}
+	root, err := d.document(false, 0)
+	if err == nil && d.pos != len(data) {
+		err = fmt.Errorf("%d bytes after document", len(data)-d.pos)
+	}
+	if err != nil {
+		return nil, &Error{Code: ErrInvalidJSON, Message: "failed to parse BSON", Cause: err}
+	}
+	return &Document{root: root}, nil
+}
+
+// QueryBSON executes a JSONPath expression against a raw BSON document.
+// See ParseBSON for how BSON values are represented in results.
+func QueryBSON(data []byte, path string, opts ...Option) ([]Result, error) {
+	doc, err := ParseBSON(data)
+	if err != nil {
+		return nil, err
+	}
+	return doc.Query(path, opts...)
+}
+
+type bsonDecoder struct {
+	data []byte
+	pos  int
+}
+
+func (d *bsonDecoder) next(n int) ([]byte, error) {
+	if n < 0 || len(d.data)-d.pos < n {
+		return nil, io.ErrUnexpectedEOF
+	}
+	b := d.data[d.pos : d.pos+n]
+	d.pos += n
+	return b, nil
+}
+
+func (d *bsonDecoder) int32() (int32, error) {
+	b, err := d.next(4)
+	if err != nil {
+		return 0, err
+	}
+	return int32(binary.LittleEndian.Uint32(b)), nil
+}
+
+func (d *bsonDecoder) int64() (int64, error) {
+	b, err := d.next(8)
+	if err != nil {
+		return 0, err
+	}
+	return int64(binary.LittleEndian.Uint64(b)), nil
+}
+
+func (d *bsonDecoder) cstring() (string, error) {
+	i := bytes.IndexByte(d.data[d.pos:], 0)
+	if i < 0 {
+		return "", io.ErrUnexpectedEOF
+	}
+	s := string(d.data[d.pos : d.pos+i])
+	d.pos += i + 1
+	return s, nil
+}
+
+func (d *bsonDecoder) string() (string, error) {
+	n, err := d.int32()
+	if err != nil {
+		return "", err
+	}
+	b, err := d.next(int(n))
+	if err != nil {
+		return "", err
+	}
+	if n < 1 || b[n-1] != 0 {
+		return "", errors.New("string is not NUL-terminated")
+	}
+	return string(b[:n-1]), nil
+}
+
+// document reads an embedded document, or an array when isArray is set.
+func (d *bsonDecoder) document(isArray bool, depth int) (interface{}, error) {
+	if depth > maxDecodeDepth {
+		return nil, errors.New("maximum nesting depth exceeded")
+	}
+	start := d.pos
+	size, err := d.int32()
+	if err != nil {
+		return nil, err
+	}
+	if size < 5 || int(size) > len(d.data)-start {
+		return nil, fmt.Errorf("invalid document length %d", size)
+	}
+	end := start + int(size)
+
+	obj := map[string]interface{}{}
+	arr := []interface{}{}
+	for {
+		if d.pos >= end {
+			return nil, errors.New("document is not NUL-terminated")
+		}
+		typ := d.data[d.pos]
+		d.pos++
+		if typ == 0 {
+			break
+		}
+		name, err := d.cstring()
+		if err != nil {
+			return nil, err
+		}
+		v, err := d.value(typ, depth)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		if isArray {
+			arr = append(arr, v)
+		} else {
+			obj[name] = v
+		}
+	}
+	if d.pos != end {
+		return nil, fmt.Errorf("document length %d does not match its content", size)
+	}
+	if isArray {
+		return arr, nil
+	}
+	return obj, nil
+}
+
+func (d *bsonDecoder) value(typ byte, depth int) (interface{}, error) {
+	switch typ {
+	case 0x01:
+		n, err := d.int64()
+		return math.Float64frombits(uint64(n)), err
+	case 0x02, 0x0d, 0x0e: // string, JavaScript code, symbol
+		return d.string()
+	case 0x03:
+		return d.document(false, depth+1)
+	case 0x04:
+		return d.document(true, depth+1)
+	case 0x05:
+		n, err := d.int32()
+		if err != nil {
+			return nil, err
+		}
+		if _, err := d.next(1); err != nil { // subtype
+			return nil, err
+		}
+		b, err := d.next(int(n))
+		if err != nil {
+			return nil, err
+		}
+		return append([]byte(nil), b...), nil
+	case 0x06, 0x0a: // undefined, null
+		return nil, nil
+	case 0x07:
+		b, err := d.next(12)
+		if err != nil {
+			return nil, err
+		}
+		return hex.EncodeToString(b), nil
+	case 0x08:
+		b, err := d.next(1)
+		if err != nil {
+			return nil, err
+		}
+		return b[0] != 0, nil
+	case 0x09:
+		ms, err := d.int64()
+		return time.UnixMilli(ms).UTC(), err
+	case 0x0b:
+		pattern, err := d.cstring()
+		if err != nil {
+			return nil, err
+		}
+		options, err := d.cstring()
+		if err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{"pattern": pattern, "options": options}, nil
+	case 0x10:
+		n, err := d.int32()
+		return int64(n), err
+	case 0x11:
+		n, err := d.int64()
+		return map[string]interface{}{"t": int64(uint64(n) >> 32), "i": int64(uint32(n))}, err
+	case 0x12:
+		return d.int64()
+	case 0x13:
+		b, err := d.next(16)
+		if err != nil {
+			return nil, err
+		}
+		return decimal128(binary.LittleEndian.Uint64(b[8:]), binary.LittleEndian.Uint64(b[:8])), nil
+	}
+	return nil, fmt.Errorf("unsupported BSON type 0x%02x", typ)
+}
+
+// decimal128 formats an IEEE 754-2008 decimal128 value. NaN and the
+// infinities are strings, since neither json.Number nor encoding/json can
+// represent them.
+func decimal128(high, low uint64) interface{} {
+	neg := high>>63 == 1
+	var exp int
+	coeff := new(big.Int)
+	switch {
+	case (high>>58)&0x1f == 0x1f:
+		return "NaN"
+	case (high>>58)&0x1f == 0x1e:
+		if neg {
+			return "-Infinity"
+		}
+		return "Infinity"
+	case (high>>61)&3 == 3:
+		// coefficients in this form exceed the maximum and are zero
+		exp = int((high >> 47) & 0x3fff)
+	default:
+		exp = int((high >> 49) & 0x3fff)
+		coeff.SetUint64(high & (1<<49 - 1))
+		coeff.Lsh(coeff, 64)
+		coeff.Or(coeff, new(big.Int).SetUint64(low))
+	}
+	exp -= 6176
+
+	digits := coeff.String()
+	var s string
+	switch {
+	case exp == 0:
+		s = digits
+	case exp < 0 && -exp < len(digits):
+		s = digits[:len(digits)+exp] + "." + digits[len(digits)+exp:]
+	case exp < 0 && -exp-len(digits) < 6:
+		s = "0." + strings.Repeat("0", -exp-len(digits)) + digits
+	default:
+		s = digits + "E" + strconv.Itoa(exp)
+	}
+	if neg {
+		s = "-" + s
+	}
+	return json.Number(s)
+}
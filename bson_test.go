@@ -0,0 +1,167 @@
+package jsonpath_test
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/njchilds90/go-jsonpath"
+)
+
+// Local stand-ins with the shapes of the MongoDB driver's bson.D, bson.E,
+// bson.M and bson.A.
+type (
+	bsonE struct {
+		Key   string
+		Value interface{}
+	}
+	bsonD []bsonE
+	bsonM map[string]interface{}
+	bsonA []interface{}
+)
+
+func TestQueryValueBSONTypes(t *testing.T) {
+	doc := bsonD{
+		{"zeta", 1},
+		{"alpha", bsonM{"name": "x", "tags": bsonA{"a", "b"}}},
+		{"items", bsonA{bsonD{{"sku", "s1"}, {"qty", int32(5)}}, bsonD{{"sku", "s2"}, {"qty", int32(20)}}}},
+	}
+
+	tests := []struct {
+		path  string
+		paths []string
+	}{
+		// bson.D members keep their stored order
+		{"$.*", []string{"$.zeta", "$.alpha", "$.items"}},
+		{"$.alpha.tags[1]", []string{"$.alpha.tags[1]"}},
+		{"$.items[?(@.qty > 10)].sku", []string{"$.items[1].sku"}},
+		{"$..sku", []string{"$.items[0].sku", "$.items[1].sku"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			results, err := jsonpath.QueryValue(doc, tt.path)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			var got []string
+			for _, r := range results {
+				got = append(got, r.Path)
+			}
+			if !reflect.DeepEqual(got, tt.paths) {
+				t.Errorf("expected %v, got %v", tt.paths, got)
+			}
+		})
+	}
+}
+
+// bsonDoc encodes elements as a BSON document.
+func bsonDoc(elems ...[]byte) []byte {
+	var body []byte
+	for _, e := range elems {
+		body = append(body, e...)
+	}
+	out := binary.LittleEndian.AppendUint32(nil, uint32(len(body)+5))
+	out = append(out, body...)
+	return append(out, 0)
+}
+
+func bsonElem(typ byte, name string, value []byte) []byte {
+	out := append([]byte{typ}, name...)
+	out = append(out, 0)
+	return append(out, value...)
+}
+
+func bsonStr(s string) []byte {
+	out := binary.LittleEndian.AppendUint32(nil, uint32(len(s)+1))
+	out = append(out, s...)
+	return append(out, 0)
+}
+
+func TestQueryBSON(t *testing.T) {
+	decimal := binary.LittleEndian.AppendUint64(nil, 15)
+	decimal = binary.LittleEndian.AppendUint64(decimal, uint64(6176-1)<<49) // 1.5
+
+	data := bsonDoc(
+		bsonElem(0x07, "_id", []byte{0x65, 0x0a, 0, 0, 0, 0, 0, 0, 0, 0, 0, 1}),
+		bsonElem(0x02, "name", bsonStr("widget")),
+		bsonElem(0x10, "qty", binary.LittleEndian.AppendUint32(nil, 12)),
+		bsonElem(0x04, "tags", bsonDoc(bsonElem(0x02, "0", bsonStr("a")), bsonElem(0x02, "1", bsonStr("b")))),
+		bsonElem(0x09, "at", binary.LittleEndian.AppendUint64(nil, 1700000000000)),
+		bsonElem(0x13, "price", decimal),
+		bsonElem(0x0a, "note", nil),
+	)
+
+	tests := []struct {
+		path     string
+		expected []interface{}
+	}{
+		{"$._id", []interface{}{"650a00000000000000000001"}},
+		{"$.name", []interface{}{"widget"}},
+		{"$.qty", []interface{}{int64(12)}},
+		{"$.tags[*]", []interface{}{"a", "b"}},
+		{"$.at", []interface{}{time.UnixMilli(1700000000000).UTC()}},
+		{"$.price", []interface{}{json.Number("1.5")}},
+		{"$.note", []interface{}{nil}},
+		{"$[?(@ == 1.5)]", []interface{}{json.Number("1.5")}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			results, err := jsonpath.QueryBSON(data, tt.path)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			got := make([]interface{}, len(results))
+			for i, r := range results {
+				got[i] = r.Value
+			}
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("expected %#v, got %#v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestQueryBSONDecimalSpecials(t *testing.T) {
+	special := func(high uint64) []byte {
+		return binary.LittleEndian.AppendUint64(binary.LittleEndian.AppendUint64(nil, 0), high)
+	}
+	data := bsonDoc(
+		bsonElem(0x13, "nan", special(0x7c00000000000000)),
+		bsonElem(0x13, "inf", special(0x7800000000000000)),
+		bsonElem(0x13, "ninf", special(0xf800000000000000)),
+	)
+	results, err := jsonpath.QueryBSON(data, "$.*")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	out, err := json.Marshal(results)
+	if err != nil {
+		t.Fatalf("expected the results to encode, got %v", err)
+	}
+	if want := `[{"path":"$.inf","value":"Infinity"},{"path":"$.nan","value":"NaN"},{"path":"$.ninf","value":"-Infinity"}]`; string(out) != want {
+		t.Errorf("expected %s, got %s", want, out)
+	}
+	if r, err := jsonpath.QueryBSON(data, "$[?(@ == 'NaN')]"); err != nil || len(r) != 1 || r[0].Path != "$.nan" {
+		t.Errorf("expected NaN to compare as a string, got %v %v", r, err)
+	}
+}
+
+func TestParseBSONInvalid(t *testing.T) {
+	valid := bsonDoc(bsonElem(0x02, "a", bsonStr("b")))
+	inputs := map[string][]byte{
+		"empty":     {},
+		"truncated": valid[:len(valid)-2],
+		"trailing":  append(append([]byte(nil), valid...), 0),
+		"length":    {0xff, 0, 0, 0, 0},
+		"type":      bsonDoc(bsonElem(0x0c, "a", nil)),
+	}
+	for name, data := range inputs {
+		t.Run(name, func(t *testing.T) {
+			if _, err := jsonpath.ParseBSON(data); !jsonpath.IsJSONError(err) {
+				t.Errorf("expected invalid input error, got: %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,149 @@
+package jsonpath
+
+import "fmt"
+
+// Builder assembles a path selector by selector. Member names are used as
+// given, never parsed, so keys from untrusted input cannot change the shape
+// of the path. The first error, such as a malformed filter, is kept and
+// returned by Build.
+//
+// Example:
+//
+//	cp, err := jsonpath.New().
+//	    Child("store").Child(userKey).
+//	    Filter("@.price < 10").
+//	    Child("title").
+//	    Build()
+type Builder struct {
+	tokens []token
+	err    error
+}
+
+// New returns a Builder holding the root selector $.
+func New() *Builder {
+	return &Builder{tokens: []token{{kind: tokenRoot}}}
+}
+
+func (b *Builder) add(t token) *Builder {
+	if b.err == nil {
+		b.tokens = append(b.tokens, t)
+	}
+	return b
+}
+
+// Child selects member key, quoting it as needed: Child("a b") is ['a b'].
+func (b *Builder) Child(key string) *Builder {
+	return b.add(token{kind: tokenChild, key: key})
+}
+
+// Index selects array element i; negative indices count from the end.
+func (b *Builder) Index(i int) *Builder {
+	return b.add(token{kind: tokenIndex, index: i})
+}
+
+// Wildcard selects every member or element: [*].
+func (b *Builder) Wildcard() *Builder {
+	return b.add(token{kind: tokenWildcard})
+}
+
+// Descendant applies the next selector at every depth: the .. operator.
+// It must be followed by another selector, not by Descendant.
+func (b *Builder) Descendant() *Builder {
+	if b.endsInDescendant() {
+		return b.fail("Descendant needs a selector after it, not another Descendant")
+	}
+	return b.add(token{kind: tokenRecursive})
+}
+
+func (b *Builder) endsInDescendant() bool {
+	return b.tokens[len(b.tokens)-1].kind == tokenRecursive
+}
+
+// Slice selects array elements start to end, exclusive: [start:end].
+func (b *Builder) Slice(start, end int) *Builder {
+	return b.add(token{kind: tokenSlice, slice: [3]*int{&start, &end}})
+}
+
+// SliceFrom selects array elements from start to the end: [start:].
+func (b *Builder) SliceFrom(start int) *Builder {
+	return b.add(token{kind: tokenSlice, slice: [3]*int{&start}})
+}
+
+// SliceStep selects every step-th array element from start to end,
+// exclusive: [start:end:step]. step must not be 0.
+func (b *Builder) SliceStep(start, end, step int) *Builder {
+	if step == 0 {
+		return b.fail("SliceStep needs a non-zero step")
+	}
+	return b.add(token{kind: tokenSlice, slice: [3]*int{&start, &end, &step}})
+}
+
+// Keys selects several members: ['a','b'].
+func (b *Builder) Keys(keys ...string) *Builder {
+	switch len(keys) {
+	case 0:
+		return b.fail("Keys needs at least one key")
+	case 1:
+		return b.Child(keys[0])
+	}
+	return b.add(token{kind: tokenUnion, keys: append([]string(nil), keys...)})
+}
+
+// Indices selects several array elements: [0,2,-1].
+func (b *Builder) Indices(indices ...int) *Builder {
+	switch len(indices) {
+	case 0:
+		return b.fail("Indices needs at least one index")
+	case 1:
+		return b.Index(indices[0])
+	}
+	return b.add(token{kind: tokenUnion, indices: append([]int(nil), indices...)})
+}
+
+// Filter selects the members or elements for which expr holds, as in
+// [?(expr)]. expr is parsed here; unlike member names it is code, so it must
+// not be assembled from untrusted input.
+func (b *Builder) Filter(expr string) *Builder {
+	if b.err != nil {
+		return b
+	}
+	parsed, err := parseFilter(expr)
+	if err != nil {
+		b.err = err
+		return b
+	}
+	return b.add(token{kind: tokenFilter, filter: expr, expr: parsed})
+}
+
+func (b *Builder) fail(msg string) *Builder {
+	if b.err == nil {
+		b.err = &Error{Code: ErrInvalidPath, Message: msg}
+	}
+	return b
+}
+
+// String returns the path built so far.
+func (b *Builder) String() string {
+	return formatTokens(b.tokens)
+}
+
+// Build returns the compiled path, or the first error met while building.
+func (b *Builder) Build() (*CompiledPath, error) {
+	if b.err != nil {
+		return nil, b.err
+	}
+	if b.endsInDescendant() {
+		return nil, &Error{Code: ErrInvalidPath, Message: "Descendant needs a selector after it"}
+	}
+	tokens := append([]token(nil), b.tokens...)
+	return &CompiledPath{raw: b.String(), tokens: tokens}, nil
+}
+
+// MustBuild is Build that panics on error.
+func (b *Builder) MustBuild() *CompiledPath {
+	cp, err := b.Build()
+	if err != nil {
+		panic(fmt.Sprintf("jsonpath.MustBuild: %v", err))
+	}
+	return cp
+}
@@ -0,0 +1,81 @@
+package jsonpath_test
+
+import (
+	"testing"
+
+	"github.com/njchilds90/go-jsonpath"
+)
+
+func TestBuilder(t *testing.T) {
+	tests := []struct {
+		builder *jsonpath.Builder
+		want    string
+	}{
+		{jsonpath.New(), "$"},
+		{jsonpath.New().Child("store").Child("book").Filter("@.price < 10").Child("title"), "$.store.book[?(@.price < 10)].title"},
+		{jsonpath.New().Descendant().Child("price"), "$..price"},
+		{jsonpath.New().Descendant().Child("first name"), "$..['first name']"},
+		{jsonpath.New().Child("a.b").Index(-1).Wildcard(), "$['a.b'][-1][*]"},
+		{jsonpath.New().Child("book").Slice(0, 2), "$.book[0:2]"},
+		{jsonpath.New().Child("book").SliceFrom(1), "$.book[1:]"},
+		{jsonpath.New().Child("book").SliceStep(0, 4, 2), "$.book[0:4:2]"},
+		{jsonpath.New().Keys("author", "title"), "$['author','title']"},
+		{jsonpath.New().Keys("only"), "$.only"},
+		{jsonpath.New().Indices(0, -1), "$[0,-1]"},
+	}
+	for _, tt := range tests {
+		cp, err := tt.builder.Build()
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", tt.want, err)
+			continue
+		}
+		if cp.String() != tt.want {
+			t.Errorf("expected %s, got %s", tt.want, cp.String())
+		}
+		if _, err := jsonpath.Compile(cp.String()); err != nil {
+			t.Errorf("%s: built path does not compile: %v", tt.want, err)
+		}
+	}
+}
+
+func TestBuilderQuery(t *testing.T) {
+	cp := jsonpath.New().Child("store").Child("book").Filter("@.price < 10").Child("title").MustBuild()
+	results, err := cp.Query(sampleJSON)
+	if err != nil || len(results) != 2 || results[1].Value != "Moby Dick" {
+		t.Errorf("unexpected results: %v %v", results, err)
+	}
+
+	// keys are never parsed, so selector syntax in them stays literal
+	data := []byte(`{"a": {"b": 1}, "a.b": 2, "x']..*": 3}`)
+	for key, want := range map[string]float64{"a.b": 2, "x']..*": 3} {
+		results, err := jsonpath.New().Child(key).MustBuild().Query(data)
+		if err != nil || len(results) != 1 || results[0].Value != want {
+			t.Errorf("%s: expected %v, got %v %v", key, want, results, err)
+		}
+	}
+}
+
+func TestBuilderErrors(t *testing.T) {
+	if _, err := jsonpath.New().Child("a").Filter("@.b ==").Child("c").Build(); !jsonpath.IsFilterError(err) {
+		t.Errorf("expected filter error, got %v", err)
+	}
+	invalid := map[string]*jsonpath.Builder{
+		"no keys":             jsonpath.New().Keys(),
+		"no indices":          jsonpath.New().Indices(),
+		"zero step":           jsonpath.New().Child("a").SliceStep(0, 4, 0),
+		"double descendant":   jsonpath.New().Descendant().Descendant().Child("b"),
+		"trailing descendant": jsonpath.New().Child("a").Descendant(),
+	}
+	for name, b := range invalid {
+		if _, err := b.Build(); !jsonpath.IsPathError(err) {
+			t.Errorf("%s: expected path error, got %v", name, err)
+		}
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("expected MustBuild to panic")
+		}
+	}()
+	jsonpath.New().Filter("(").MustBuild()
+}
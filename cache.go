@@ -0,0 +1,81 @@
+package jsonpath
+
+import (
+	"container/list"
+	"sync"
+)
+
+// Cache memoizes compiled paths by their string form so that callers passing
+// the same path string repeatedly skip re-parsing it. Compiled paths are
+// immutable, so a cached entry can be shared by any number of goroutines.
+//
+// A Cache is safe for concurrent use. Pass it to string-path functions with
+// WithCache, or call Compile on it directly.
+type Cache struct {
+	mu    sync.Mutex
+	size  int
+	order *list.List // front is most recently used
+	items map[string]*list.Element
+}
+
+type cacheEntry struct {
+	path string
+	cp   *CompiledPath
+}
+
+// NewCache returns a least-recently-used cache holding up to size compiled
+// paths. A size below 1 is treated as 1.
+//
+// Example:
+//
+//	cache := jsonpath.NewCache(256)
+//	for _, doc := range docs {
+//	    results, err := jsonpath.Query(doc, "$.items[*].id", jsonpath.WithCache(cache))
+//	}
+func NewCache(size int) *Cache {
+	if size < 1 {
+		size = 1
+	}
+	return &Cache{size: size, order: list.New(), items: make(map[string]*list.Element)}
+}
+
+// Compile returns the cached compiled form of path, compiling and caching it
+// on first use. Invalid paths are not cached.
+func (c *Cache) Compile(path string) (*CompiledPath, error) {
+	c.mu.Lock()
+	if el, ok := c.items[path]; ok {
+		c.order.MoveToFront(el)
+		cp := el.Value.(*cacheEntry).cp
+		c.mu.Unlock()
+		return cp, nil
+	}
+	c.mu.Unlock()
+
+	// Compile outside the lock; a concurrent miss for the same path just
+	// compiles it twice and keeps one.
+	cp, err := Compile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if el, ok := c.items[path]; ok {
+		c.order.MoveToFront(el)
+		return el.Value.(*cacheEntry).cp, nil
+	}
+	c.items[path] = c.order.PushFront(&cacheEntry{path: path, cp: cp})
+	if c.order.Len() > c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.items, oldest.Value.(*cacheEntry).path)
+	}
+	return cp, nil
+}
+
+// Len returns the number of compiled paths currently cached.
+func (c *Cache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.order.Len()
+}
@@ -0,0 +1,87 @@
+package jsonpath_test
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/njchilds90/go-jsonpath"
+)
+
+func TestCacheReusesCompiledPaths(t *testing.T) {
+	c := jsonpath.NewCache(2)
+	a1, err := c.Compile("$.a")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	a2, _ := c.Compile("$.a")
+	if a1 != a2 {
+		t.Error("expected the cached compiled path to be returned")
+	}
+
+	if _, err := c.Compile("bad"); !jsonpath.IsPathError(err) {
+		t.Errorf("expected path error, got: %v", err)
+	}
+	if c.Len() != 1 {
+		t.Errorf("invalid paths must not be cached, len=%d", c.Len())
+	}
+}
+
+func TestCacheEvictsLeastRecentlyUsed(t *testing.T) {
+	c := jsonpath.NewCache(2)
+	a, _ := c.Compile("$.a")
+	c.Compile("$.b")
+	c.Compile("$.a") // touch a so b is the oldest
+	c.Compile("$.c")
+
+	if c.Len() != 2 {
+		t.Fatalf("expected 2 entries, got %d", c.Len())
+	}
+	if again, _ := c.Compile("$.a"); again != a {
+		t.Error("expected $.a to survive eviction")
+	}
+}
+
+func TestWithCache(t *testing.T) {
+	c := jsonpath.NewCache(8)
+	for i := 0; i < 3; i++ {
+		results, err := jsonpath.Query(sampleJSON, "$..author", jsonpath.WithCache(c))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(results) != 4 {
+			t.Fatalf("expected 4 results, got %d", len(results))
+		}
+	}
+	if c.Len() != 1 {
+		t.Errorf("expected 1 cached path, got %d", c.Len())
+	}
+}
+
+func TestCacheConcurrent(t *testing.T) {
+	c := jsonpath.NewCache(4)
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < 100; i++ {
+				if _, err := c.Compile(fmt.Sprintf("$.k%d", (g+i)%6)); err != nil {
+					t.Error(err)
+					return
+				}
+			}
+		}(g)
+	}
+	wg.Wait()
+	if c.Len() > 4 {
+		t.Errorf("cache grew past its size: %d", c.Len())
+	}
+}
+
+func BenchmarkQueryWithCache(b *testing.B) {
+	c := jsonpath.NewCache(16)
+	for i := 0; i < b.N; i++ {
+		_, _ = jsonpath.Query(sampleJSON, "$.store.book[?(@.price < 10)].title", jsonpath.WithCache(c))
+	}
+}
@@ -0,0 +1,293 @@
+package jsonpath
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"time"
+)
+
+// ParseCBOR decodes a CBOR (RFC 8949) document into a Document so it can be
+// queried with the same paths as JSON.
+//
+// Values decode to the types encoding/json produces, with these additions:
+// integers are int64 (uint64 when they do not fit), byte strings are []byte,
+// tags 0 and 1 are time.Time, and maps with any non-string key are
+// map[interface{}]interface{}, whose keys are matched by their string form,
+// so the key 1 is selected by $['1']. Other tags are transparent.
+//
+// Truncated items, reserved or misplaced indefinite lengths, unsupported
+// simple values and data after the top-level item are rejected. CBOR decode
+// failures reuse the ErrInvalidJSON code, as there is none for binary
+// formats; the error's Cause holds the specific problem.
+//
+// Example:
+//
+//	doc, err := jsonpath.ParseCBOR(payload)
+//	if err != nil {
+//	    return err
+//	}
+//	temps, err := doc.Query("$.readings[*].temp")
+func ParseCBOR(data []byte) (*Document, error) {
+	d := cborDecoder{data: data}
+	root, err := d.value(0)
+	if err == nil && d.pos != len(data) {
+		err = fmt.Errorf("%d bytes after top-level value", len(data)-d.pos)
+	}
+	if err != nil {
+		return nil, &Error{Code: ErrInvalidJSON, Message: "failed to parse CBOR", Cause: err}
+	}
+	return &Document{root: root}, nil
+}
+
+// QueryCBOR executes a JSONPath expression against CBOR-encoded data.
+// See ParseCBOR for how CBOR values are represented in results.
+func QueryCBOR(data []byte, path string, opts ...Option) ([]Result, error) {
+	doc, err := ParseCBOR(data)
+	if err != nil {
+		return nil, err
+	}
+	return doc.Query(path, opts...)
+}
+
+const cborBreak = 0xff
+
+type cborDecoder struct {
+	data []byte
+	pos  int
+}
+
+// head reads an initial byte and its argument. For indefinite lengths info
+// is 31 and arg is zero.
+func (d *cborDecoder) head() (major, info byte, arg uint64, err error) {
+	if d.pos >= len(d.data) {
+		return 0, 0, 0, io.ErrUnexpectedEOF
+	}
+	b := d.data[d.pos]
+	d.pos++
+	major, info = b>>5, b&0x1f
+	switch {
+	case info < 24:
+		arg = uint64(info)
+	case info <= 27:
+		n := 1 << (info - 24)
+		if len(d.data)-d.pos < n {
+			return 0, 0, 0, io.ErrUnexpectedEOF
+		}
+		for _, c := range d.data[d.pos : d.pos+n] {
+			arg = arg<<8 | uint64(c)
+		}
+		d.pos += n
+	case info == 31:
+		if major < 2 || major == 6 {
+			return 0, 0, 0, fmt.Errorf("indefinite length not allowed for major type %d", major)
+		}
+	default:
+		return 0, 0, 0, fmt.Errorf("reserved additional information %d", info)
+	}
+	return major, info, arg, nil
+}
+
+// atBreak consumes a break code if one is next.
+func (d *cborDecoder) atBreak() bool {
+	if d.pos < len(d.data) && d.data[d.pos] == cborBreak {
+		d.pos++
+		return true
+	}
+	return false
+}
+
+// length checks that n items of at least one byte each can follow.
+func (d *cborDecoder) length(n uint64) (int, error) {
+	if n > uint64(len(d.data)-d.pos) {
+		return 0, io.ErrUnexpectedEOF
+	}
+	return int(n), nil
+}
+
+func (d *cborDecoder) value(depth int) (interface{}, error) {
+	if depth > maxDecodeDepth {
+		return nil, errors.New("maximum nesting depth exceeded")
+	}
+	major, info, arg, err := d.head()
+	if err != nil {
+		return nil, err
+	}
+
+	switch major {
+	case 0:
+		if arg > math.MaxInt64 {
+			return arg, nil
+		}
+		return int64(arg), nil
+
+	case 1:
+		if arg > math.MaxInt64 {
+			return -1 - float64(arg), nil
+		}
+		return -1 - int64(arg), nil
+
+	case 2, 3:
+		b, err := d.chunks(major, info, arg)
+		if err != nil {
+			return nil, err
+		}
+		if major == 3 {
+			return string(b), nil
+		}
+		return append([]byte(nil), b...), nil
+
+	case 4:
+		arr := []interface{}{}
+		if info == 31 {
+			for !d.atBreak() {
+				v, err := d.value(depth + 1)
+				if err != nil {
+					return nil, err
+				}
+				arr = append(arr, v)
+			}
+			return arr, nil
+		}
+		n, err := d.length(arg)
+		if err != nil {
+			return nil, err
+		}
+		arr = make([]interface{}, 0, n)
+		for i := 0; i < n; i++ {
+			v, err := d.value(depth + 1)
+			if err != nil {
+				return nil, err
+			}
+			arr = append(arr, v)
+		}
+		return arr, nil
+
+	case 5:
+		return d.object(info, arg, depth)
+
+	case 6:
+		v, err := d.value(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+		switch arg {
+		case 0:
+			s, ok := v.(string)
+			if !ok {
+				return nil, errors.New("tag 0 requires a text string")
+			}
+			return time.Parse(time.RFC3339Nano, s)
+		case 1:
+			switch n := v.(type) {
+			case int64:
+				return time.Unix(n, 0).UTC(), nil
+			case float64:
+				sec, frac := math.Modf(n)
+				return time.Unix(int64(sec), int64(frac*1e9)).UTC(), nil
+			}
+			return nil, errors.New("tag 1 requires a number")
+		}
+		return v, nil
+
+	default: // 7
+		switch info {
+		case 20:
+			return false, nil
+		case 21:
+			return true, nil
+		case 22, 23:
+			return nil, nil
+		case 25:
+			return halfToFloat64(uint16(arg)), nil
+		case 26:
+			return float64(math.Float32frombits(uint32(arg))), nil
+		case 27:
+			return math.Float64frombits(arg), nil
+		case 31:
+			return nil, errors.New("unexpected break")
+		}
+		return nil, fmt.Errorf("unsupported simple value %d", arg)
+	}
+}
+
+// chunks reads a definite or indefinite byte or text string.
+func (d *cborDecoder) chunks(major, info byte, arg uint64) ([]byte, error) {
+	if info != 31 {
+		n, err := d.length(arg)
+		if err != nil {
+			return nil, err
+		}
+		b := d.data[d.pos : d.pos+n : d.pos+n]
+		d.pos += n
+		return b, nil
+	}
+	var buf []byte
+	for !d.atBreak() {
+		m, ci, n, err := d.head()
+		if err != nil {
+			return nil, err
+		}
+		if m != major || ci == 31 {
+			return nil, errors.New("invalid chunk in indefinite-length string")
+		}
+		chunk, err := d.chunks(m, ci, n)
+		if err != nil {
+			return nil, err
+		}
+		buf = append(buf, chunk...)
+	}
+	return buf, nil
+}
+
+func (d *cborDecoder) object(info byte, arg uint64, depth int) (interface{}, error) {
+	n := -1
+	if info != 31 {
+		var err error
+		if n, err = d.length(arg); err != nil {
+			return nil, err
+		}
+	}
+	b := newObjectBuilder(n)
+	for i := 0; n < 0 || i < n; i++ {
+		if n < 0 && d.atBreak() {
+			break
+		}
+		k, err := d.value(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+		v, err := d.value(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+		if err := b.set(k, v); err != nil {
+			return nil, err
+		}
+	}
+	return b.value(), nil
+}
+
+// halfToFloat64 converts an IEEE 754 half-precision value.
+func halfToFloat64(h uint16) float64 {
+	exp := int(h>>10) & 0x1f
+	frac := float64(h & 0x3ff)
+	var f float64
+	switch exp {
+	case 0:
+		f = math.Ldexp(frac, -24)
+	case 0x1f:
+		if frac == 0 {
+			f = math.Inf(1)
+		} else {
+			f = math.NaN()
+		}
+	default:
+		f = math.Ldexp(frac+1024, exp-25)
+	}
+	if h&0x8000 != 0 {
+		f = -f
+	}
+	return f
+}
@@ -0,0 +1,115 @@
+package jsonpath_test
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/njchilds90/go-jsonpath"
+)
+
+// cborSample encodes {"a": 1, "b": [true, null, 1.5, -1], 1: h'0102'}.
+var cborSample = []byte{
+	0xa3,
+	0x61, 'a', 0x01,
+	0x61, 'b', 0x84, 0xf5, 0xf6, 0xf9, 0x3e, 0x00, 0x20,
+	0x01, 0x42, 0x01, 0x02,
+}
+
+func TestQueryCBOR(t *testing.T) {
+	tests := []struct {
+		path     string
+		expected []interface{}
+	}{
+		{"$.a", []interface{}{int64(1)}},
+		{"$.b[*]", []interface{}{true, nil, 1.5, int64(-1)}},
+		{"$.b[?(@ < 0)]", []interface{}{int64(-1)}},
+		{"$['1']", []interface{}{[]byte{1, 2}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			values, err := jsonpath.QueryCBOR(cborSample, tt.path)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			got := make([]interface{}, len(values))
+			for i, r := range values {
+				got[i] = r.Value
+			}
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("expected %#v, got %#v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestParseCBORIntegerKeysSorted(t *testing.T) {
+	doc, err := jsonpath.ParseCBOR(cborSample)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	paths, _ := jsonpath.MustCompile("$.*").QueryDocument(doc)
+	var got []string
+	for _, r := range paths {
+		got = append(got, r.Path)
+	}
+	expected := []string{"$.1", "$.a", "$.b"}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected %v, got %v", expected, got)
+	}
+}
+
+func TestParseCBORIndefiniteAndTags(t *testing.T) {
+	data := []byte{
+		0xbf,                                        // indefinite map
+		0x61, 's', 0x7f, 0x61, 'h', 0x61, 'i', 0xff, // "s": "h" "i" (chunked)
+		0x61, 'l', 0x9f, 0x01, 0x02, 0xff, // "l": [1, 2]
+		0x61, 't', 0xc0, 0x74, // "t": 0("2013-03-21T20:04:00Z")
+	}
+	data = append(data, "2013-03-21T20:04:00Z"...)
+	data = append(data, 0xff)
+
+	doc, err := jsonpath.ParseCBOR(data)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := map[string]interface{}{
+		"s": "hi",
+		"l": []interface{}{int64(1), int64(2)},
+		"t": time.Date(2013, 3, 21, 20, 4, 0, 0, time.UTC),
+	}
+	if !reflect.DeepEqual(doc.Value(), expected) {
+		t.Errorf("expected %#v, got %#v", expected, doc.Value())
+	}
+}
+
+func TestParseCBORDoesNotAliasInput(t *testing.T) {
+	data := []byte{0x42, 0x01, 0x02}
+	doc, err := jsonpath.ParseCBOR(data)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	data[1] = 0xff
+	if !bytes.Equal(doc.Value().([]byte), []byte{1, 2}) {
+		t.Errorf("byte string changed with its input: %v", doc.Value())
+	}
+}
+
+func TestParseCBORInvalid(t *testing.T) {
+	inputs := map[string][]byte{
+		"empty":     {},
+		"truncated": {0x82, 0x01},
+		"trailing":  {0x01, 0x02},
+		"huge":      {0x9b, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff},
+		"break":     {0xff},
+		"array key": {0xa1, 0x80, 0x01},
+	}
+	for name, data := range inputs {
+		t.Run(name, func(t *testing.T) {
+			if _, err := jsonpath.ParseCBOR(data); !jsonpath.IsJSONError(err) {
+				t.Errorf("expected invalid input error, got: %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,388 @@
+// Command jsonpath-mcp is a Model Context Protocol server that lets LLM
+// agents run JSONPath queries as tools. It speaks JSON-RPC 2.0 over stdin and
+// stdout, one message per line, and needs no configuration:
+//
+//	{"mcpServers": {"jsonpath": {"command": "jsonpath-mcp"}}}
+//
+// Tools:
+//
+//	query   matches as {"path", "value"} pairs
+//	values  matched values only
+//	exists  whether the path matches anything
+//
+// Every tool takes a "path" and the document, either as a JSON value in
+// "document" or as JSON text in "document_json". query and values accept
+// "max_results", capped by the server limit; when matches are dropped the
+// result says "truncated": true.
+//
+// Every call runs under the server's limits, so a hostile or careless path
+// cannot pin the process:
+//
+//	-max-results N   most matches returned per call (default 100)
+//	-max-nodes N     most nodes visited per call (default 1000000)
+//	-max-depth N     deepest recursive descent (default: package default)
+//	-budget D        longest evaluation per call (default 1s)
+//	-max-bytes N     largest document accepted (default 10 MiB)
+//
+// Messages longer than -max-bytes plus 64 KiB are answered with a JSON-RPC
+// parse error and discarded as they are read.
+//
+// Failed calls return isError with a machine-readable body:
+//
+//	{"error": {"code": "invalid_path", "message": "jsonpath: ..."}}
+//
+// The codes are invalid_arguments and document_too_large for calls the server
+// rejects, the names of jsonpath.ErrorCode, such as invalid_path,
+// invalid_filter, invalid_json, invalid_input, max_depth_exceeded,
+// budget_exceeded, cancelled and limit_exceeded, for failed queries, and
+// internal for anything else.
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"time"
+
+	"github.com/njchilds90/go-jsonpath"
+)
+
+const (
+	serverName    = "jsonpath-mcp"
+	serverVersion = "1.0.0"
+
+	// latestProtocol is answered to clients asking for a version not in
+	// protocolVersions.
+	latestProtocol = "2025-06-18"
+)
+
+var protocolVersions = map[string]bool{
+	"2024-11-05": true,
+	"2025-03-26": true,
+	"2025-06-18": true,
+}
+
+// JSON-RPC error codes.
+const (
+	codeParseError     = -32700
+	codeInvalidRequest = -32600
+	codeMethodNotFound = -32601
+	codeInvalidParams  = -32602
+)
+
+// limits bound every tool call.
+type limits struct {
+	maxResults int
+	maxNodes   int
+	maxDepth   int
+	maxBytes   int
+	budget     time.Duration
+}
+
+func main() {
+	lim := limits{}
+	flag.IntVar(&lim.maxResults, "max-results", 100, "most matches returned per call")
+	flag.IntVar(&lim.maxNodes, "max-nodes", 1000000, "most nodes visited per call")
+	flag.IntVar(&lim.maxDepth, "max-depth", 0, "deepest recursive descent (0 uses the package default)")
+	flag.IntVar(&lim.maxBytes, "max-bytes", 10<<20, "largest document accepted, in bytes")
+	flag.DurationVar(&lim.budget, "budget", time.Second, "longest evaluation per call")
+	flag.Parse()
+
+	if err := serve(context.Background(), os.Stdin, os.Stdout, lim); err != nil {
+		fmt.Fprintln(os.Stderr, "jsonpath-mcp:", err)
+		os.Exit(1)
+	}
+}
+
+type request struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+type response struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  interface{}     `json:"result,omitempty"`
+	Error   *rpcError       `json:"error,omitempty"`
+}
+
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// messageOverhead is the room a line needs beyond its document for the
+// JSON-RPC envelope, the path and the other arguments.
+const messageOverhead = 64 << 10
+
+// serve answers requests read from in until it is exhausted. Lines longer
+// than the document limit allows are answered with a parse error without
+// being held in memory.
+func serve(ctx context.Context, in io.Reader, out io.Writer, lim limits) error {
+	maxLine := math.MaxInt
+	if lim.maxBytes > 0 {
+		maxLine = lim.maxBytes + messageOverhead
+	}
+	lines := &lineSplitter{max: maxLine}
+	sc := bufio.NewScanner(in)
+	sc.Buffer(nil, maxLine)
+	sc.Split(lines.split)
+	w := bufio.NewWriter(out)
+	enc := json.NewEncoder(w)
+	for sc.Scan() {
+		var resp *response
+		if lines.tooLong {
+			msg := fmt.Sprintf("message is longer than %d bytes; documents are limited to %d bytes", maxLine, lim.maxBytes)
+			resp = &response{JSONRPC: "2.0", ID: json.RawMessage("null"), Error: &rpcError{codeParseError, msg}}
+		} else if len(bytes.TrimSpace(sc.Bytes())) > 0 {
+			resp = handle(ctx, sc.Bytes(), lim)
+		}
+		if resp != nil {
+			if err := enc.Encode(resp); err != nil {
+				return err
+			}
+			if err := w.Flush(); err != nil {
+				return err
+			}
+		}
+	}
+	return sc.Err()
+}
+
+// lineSplitter splits input into lines for a bufio.Scanner. A line reaching
+// max bytes is discarded as it is read, and yields an empty token with
+// tooLong set once its end is found.
+type lineSplitter struct {
+	max      int
+	skipping bool
+	tooLong  bool
+}
+
+func (l *lineSplitter) split(data []byte, atEOF bool) (int, []byte, error) {
+	l.tooLong = false
+	if i := bytes.IndexByte(data, '\n'); i >= 0 {
+		return i + 1, l.token(data[:i]), nil
+	}
+	if atEOF && (len(data) > 0 || l.skipping) {
+		return len(data), l.token(data), nil
+	}
+	if len(data) >= l.max {
+		l.skipping = true
+		return len(data), nil, nil
+	}
+	return 0, nil, nil
+}
+
+// token ends the current line, returning line or, if the line was too long,
+// an empty token.
+func (l *lineSplitter) token(line []byte) []byte {
+	if l.skipping {
+		l.skipping, l.tooLong = false, true
+		return []byte{}
+	}
+	return line
+}
+
+// handle answers one message; notifications get no answer.
+func handle(ctx context.Context, msg []byte, lim limits) *response {
+	var req request
+	if err := json.Unmarshal(msg, &req); err != nil {
+		return &response{JSONRPC: "2.0", ID: json.RawMessage("null"), Error: &rpcError{codeParseError, err.Error()}}
+	}
+	if req.ID == nil {
+		return nil
+	}
+	resp := &response{JSONRPC: "2.0", ID: req.ID}
+	if req.JSONRPC != "2.0" || req.Method == "" {
+		resp.Error = &rpcError{codeInvalidRequest, "expected a JSON-RPC 2.0 request"}
+		return resp
+	}
+
+	switch req.Method {
+	case "initialize":
+		var p struct {
+			ProtocolVersion string `json:"protocolVersion"`
+		}
+		json.Unmarshal(req.Params, &p)
+		version := p.ProtocolVersion
+		if !protocolVersions[version] {
+			version = latestProtocol
+		}
+		resp.Result = map[string]interface{}{
+			"protocolVersion": version,
+			"capabilities":    map[string]interface{}{"tools": map[string]interface{}{}},
+			"serverInfo":      map[string]string{"name": serverName, "version": serverVersion},
+		}
+	case "ping":
+		resp.Result = struct{}{}
+	case "tools/list":
+		resp.Result = map[string]interface{}{"tools": tools}
+	case "tools/call":
+		var p struct {
+			Name      string          `json:"name"`
+			Arguments json.RawMessage `json:"arguments"`
+		}
+		if err := json.Unmarshal(req.Params, &p); err != nil {
+			resp.Error = &rpcError{codeInvalidParams, err.Error()}
+			return resp
+		}
+		if !toolNames[p.Name] {
+			resp.Error = &rpcError{codeInvalidParams, fmt.Sprintf("unknown tool %q", p.Name)}
+			return resp
+		}
+		resp.Result = callTool(ctx, p.Name, p.Arguments, lim)
+	default:
+		resp.Error = &rpcError{codeMethodNotFound, fmt.Sprintf("method %q not found", req.Method)}
+	}
+	return resp
+}
+
+// tool is a tools/list entry.
+type tool struct {
+	Name        string                 `json:"name"`
+	Description string                 `json:"description"`
+	InputSchema map[string]interface{} `json:"inputSchema"`
+}
+
+func inputSchema(withMax bool) map[string]interface{} {
+	props := map[string]interface{}{
+		"path":          map[string]string{"type": "string", "description": "JSONPath expression, e.g. $.store.book[?(@.price < 10)].title"},
+		"document":      map[string]string{"description": "the JSON document to query"},
+		"document_json": map[string]string{"type": "string", "description": "the JSON document as text; used when document is absent"},
+	}
+	if withMax {
+		props["max_results"] = map[string]interface{}{"type": "integer", "minimum": 1, "description": "most matches to return; capped by the server"}
+	}
+	return map[string]interface{}{"type": "object", "properties": props, "required": []string{"path"}}
+}
+
+var tools = []tool{
+	{"query", "Run a JSONPath expression against a JSON document and return each match with its normalized path.", inputSchema(true)},
+	{"values", "Run a JSONPath expression against a JSON document and return the matched values.", inputSchema(true)},
+	{"exists", "Report whether a JSONPath expression matches anything in a JSON document.", inputSchema(false)},
+}
+
+var toolNames = map[string]bool{"query": true, "values": true, "exists": true}
+
+type toolArgs struct {
+	Path         string          `json:"path"`
+	Document     json.RawMessage `json:"document"`
+	DocumentJSON *string         `json:"document_json"`
+	MaxResults   int             `json:"max_results"`
+}
+
+// toolResult is a tools/call result. The payload is sent both as structured
+// content and as JSON text for clients that only read text.
+type toolResult struct {
+	Content           []textContent `json:"content"`
+	StructuredContent interface{}   `json:"structuredContent"`
+	IsError           bool          `json:"isError"`
+}
+
+type textContent struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+func newToolResult(payload interface{}, isError bool) toolResult {
+	b, err := json.Marshal(payload)
+	if err != nil {
+		return toolError("internal", err.Error())
+	}
+	return toolResult{Content: []textContent{{"text", string(b)}}, StructuredContent: payload, IsError: isError}
+}
+
+func toolError(code, message string) toolResult {
+	return newToolResult(map[string]interface{}{"error": map[string]string{"code": code, "message": message}}, true)
+}
+
+func callTool(ctx context.Context, name string, raw json.RawMessage, lim limits) toolResult {
+	var args toolArgs
+	if len(raw) > 0 {
+		if err := json.Unmarshal(raw, &args); err != nil {
+			return toolError("invalid_arguments", err.Error())
+		}
+	}
+	if args.Path == "" {
+		return toolError("invalid_arguments", "path is required")
+	}
+	doc := []byte(args.Document)
+	if len(doc) == 0 {
+		if args.DocumentJSON == nil {
+			return toolError("invalid_arguments", "document or document_json is required")
+		}
+		doc = []byte(*args.DocumentJSON)
+	}
+	if lim.maxBytes > 0 && len(doc) > lim.maxBytes {
+		return toolError("document_too_large", fmt.Sprintf("document is %d bytes; the limit is %d", len(doc), lim.maxBytes))
+	}
+
+	max := lim.maxResults
+	if name == "exists" {
+		max = 1
+	} else if args.MaxResults > 0 && (max <= 0 || args.MaxResults < max) {
+		max = args.MaxResults
+	}
+
+	results := []jsonpath.Result{}
+	truncated := false
+	err := jsonpath.QueryFunc(ctx, doc, args.Path, func(r jsonpath.Result) error {
+		if max > 0 && len(results) == max {
+			truncated = true
+			return jsonpath.ErrStop
+		}
+		results = append(results, r)
+		if name == "exists" {
+			return jsonpath.ErrStop
+		}
+		return nil
+	}, lim.options()...)
+	if err != nil {
+		return toolError(errorCode(err), err.Error())
+	}
+
+	switch name {
+	case "exists":
+		return newToolResult(map[string]interface{}{"exists": len(results) > 0}, false)
+	case "values":
+		values := make([]interface{}, len(results))
+		for i, r := range results {
+			values[i] = r.Value
+		}
+		return newToolResult(map[string]interface{}{"values": values, "count": len(values), "truncated": truncated}, false)
+	}
+	return newToolResult(map[string]interface{}{"results": results, "count": len(results), "truncated": truncated}, false)
+}
+
+func (lim limits) options() []jsonpath.Option {
+	var opts []jsonpath.Option
+	if lim.maxDepth > 0 {
+		opts = append(opts, jsonpath.WithMaxDepth(lim.maxDepth))
+	}
+	if lim.maxNodes > 0 {
+		opts = append(opts, jsonpath.WithMaxNodes(lim.maxNodes))
+	}
+	if lim.budget > 0 {
+		opts = append(opts, jsonpath.WithBudget(lim.budget))
+	}
+	return opts
+}
+
+// errorCode returns the machine-readable code for a query error: the name of
+// its jsonpath.ErrorCode, or internal for any other failure.
+func errorCode(err error) string {
+	var e *jsonpath.Error
+	if errors.As(err, &e) {
+		return e.Code.String()
+	}
+	return "internal"
+}
@@ -0,0 +1,211 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/njchilds90/go-jsonpath"
+)
+
+var testLimits = limits{maxResults: 2, maxNodes: 1000, maxBytes: 1 << 10, budget: time.Second}
+
+type rpcResponse struct {
+	ID     int             `json:"id"`
+	Result json.RawMessage `json:"result"`
+	Error  *rpcError       `json:"error"`
+}
+
+// exchange sends the messages and returns the responses in order.
+func exchange(t *testing.T, lim limits, msgs ...string) []rpcResponse {
+	t.Helper()
+	var out bytes.Buffer
+	if err := serve(context.Background(), strings.NewReader(strings.Join(msgs, "\n")), &out, lim); err != nil {
+		t.Fatalf("serve: %v", err)
+	}
+	var resps []rpcResponse
+	dec := json.NewDecoder(&out)
+	for dec.More() {
+		var r rpcResponse
+		if err := dec.Decode(&r); err != nil {
+			t.Fatalf("invalid response: %v", err)
+		}
+		resps = append(resps, r)
+	}
+	return resps
+}
+
+// call runs one tools/call and returns the decoded tool result.
+func call(t *testing.T, lim limits, name, args string) (map[string]interface{}, bool) {
+	t.Helper()
+	resps := exchange(t, lim, `{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"`+name+`","arguments":`+args+`}}`)
+	if len(resps) != 1 || resps[0].Error != nil {
+		t.Fatalf("unexpected responses: %+v", resps)
+	}
+	var res struct {
+		Content []struct {
+			Type string `json:"type"`
+			Text string `json:"text"`
+		} `json:"content"`
+		StructuredContent map[string]interface{} `json:"structuredContent"`
+		IsError           bool                   `json:"isError"`
+	}
+	if err := json.Unmarshal(resps[0].Result, &res); err != nil {
+		t.Fatalf("invalid tool result: %v", err)
+	}
+	var text map[string]interface{}
+	if len(res.Content) != 1 || res.Content[0].Type != "text" || json.Unmarshal([]byte(res.Content[0].Text), &text) != nil {
+		t.Fatalf("expected one JSON text content, got %+v", res.Content)
+	}
+	return res.StructuredContent, res.IsError
+}
+
+func TestInitializeAndList(t *testing.T) {
+	resps := exchange(t, testLimits,
+		`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2025-03-26","capabilities":{}}}`,
+		`{"jsonrpc":"2.0","method":"notifications/initialized"}`,
+		`{"jsonrpc":"2.0","id":2,"method":"tools/list"}`,
+		`{"jsonrpc":"2.0","id":3,"method":"ping"}`,
+		`{"jsonrpc":"2.0","id":4,"method":"resources/list"}`,
+	)
+	if len(resps) != 4 {
+		t.Fatalf("expected 4 responses, got %d", len(resps))
+	}
+	if !strings.Contains(string(resps[0].Result), `"protocolVersion":"2025-03-26"`) {
+		t.Errorf("expected the requested version, got %s", resps[0].Result)
+	}
+	var list struct {
+		Tools []tool `json:"tools"`
+	}
+	json.Unmarshal(resps[1].Result, &list)
+	if len(list.Tools) != 3 || list.Tools[0].Name != "query" || list.Tools[0].InputSchema["type"] != "object" {
+		t.Errorf("unexpected tools: %+v", list.Tools)
+	}
+	if resps[2].ID != 3 || resps[2].Error != nil {
+		t.Errorf("unexpected ping response: %+v", resps[2])
+	}
+	if resps[3].Error == nil || resps[3].Error.Code != codeMethodNotFound {
+		t.Errorf("expected method not found, got %+v", resps[3])
+	}
+}
+
+func TestProtocolErrors(t *testing.T) {
+	resps := exchange(t, testLimits,
+		`{not json`,
+		`{"jsonrpc":"1.0","id":2,"method":"ping"}`,
+		`{"jsonrpc":"2.0","id":3,"method":"tools/call","params":{"name":"delete"}}`,
+	)
+	want := []int{codeParseError, codeInvalidRequest, codeInvalidParams}
+	if len(resps) != len(want) {
+		t.Fatalf("expected %d responses, got %d", len(want), len(resps))
+	}
+	for i, r := range resps {
+		if r.Error == nil || r.Error.Code != want[i] {
+			t.Errorf("response %d: expected error %d, got %+v", i, want[i], r)
+		}
+	}
+}
+
+func TestLongLines(t *testing.T) {
+	huge := `{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"exists","arguments":{"path":"$","document_json":"` + strings.Repeat("x", 2*messageOverhead) + `"}}}`
+	resps := exchange(t, limits{maxBytes: 8},
+		huge,
+		`{"jsonrpc":"2.0","id":2,"method":"ping"}`,
+		huge,
+	)
+	if len(resps) != 3 {
+		t.Fatalf("expected 3 responses, got %+v", resps)
+	}
+	for _, i := range []int{0, 2} {
+		if r := resps[i]; r.Error == nil || r.Error.Code != codeParseError || !strings.Contains(r.Error.Message, "documents are limited to 8 bytes") {
+			t.Errorf("response %d: expected a parse error for the long line, got %+v", i, r)
+		}
+	}
+	if resps[1].ID != 2 || resps[1].Error != nil {
+		t.Errorf("expected the line after the long one to be answered, got %+v", resps[1])
+	}
+}
+
+const books = `{"book": [{"title": "A", "price": 8}, {"title": "B", "price": 12}, {"title": "C", "price": 9}]}`
+
+func TestTools(t *testing.T) {
+	res, isErr := call(t, testLimits, "query", `{"path":"$.book[?(@.price < 10)].title","document":`+books+`}`)
+	if isErr || res["count"] != 2.0 || res["truncated"] != false {
+		t.Errorf("unexpected query result: %v", res)
+	}
+	if first := res["results"].([]interface{})[0].(map[string]interface{}); first["path"] != "$.book[0].title" || first["value"] != "A" {
+		t.Errorf("unexpected first result: %v", first)
+	}
+
+	res, isErr = call(t, testLimits, "values", `{"path":"$..price","document_json":`+mustQuote(books)+`,"max_results":1}`)
+	if isErr || res["count"] != 1.0 || res["truncated"] != true || res["values"].([]interface{})[0] != 8.0 {
+		t.Errorf("unexpected values result: %v", res)
+	}
+
+	res, _ = call(t, testLimits, "values", `{"path":"$..price","document":`+books+`,"max_results":50}`)
+	if res["count"] != 2.0 || res["truncated"] != true {
+		t.Errorf("expected the server limit to cap max_results, got %v", res)
+	}
+
+	res, _ = call(t, testLimits, "exists", `{"path":"$.book[?(@.price > 10)]","document":`+books+`}`)
+	if res["exists"] != true {
+		t.Errorf("expected exists, got %v", res)
+	}
+	res, _ = call(t, testLimits, "exists", `{"path":"$.book[?(@.price > 100)]","document":`+books+`}`)
+	if res["exists"] != false {
+		t.Errorf("expected no match, got %v", res)
+	}
+}
+
+func TestToolErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		lim  limits
+		args string
+		code string
+	}{
+		{"no path", testLimits, `{"document":{}}`, "invalid_arguments"},
+		{"no document", testLimits, `{"path":"$"}`, "invalid_arguments"},
+		{"bad arguments", testLimits, `{"path":1}`, "invalid_arguments"},
+		{"bad path", testLimits, `{"path":"book","document":{}}`, "invalid_path"},
+		{"bad filter", testLimits, `{"path":"$[?(@.a ==)]","document":{}}`, "invalid_filter"},
+		{"bad json", testLimits, `{"path":"$","document_json":"{"}`, "invalid_json"},
+		{"too large", limits{maxBytes: 8}, `{"path":"$","document":` + books + `}`, "document_too_large"},
+		{"nodes", limits{maxNodes: 3}, `{"path":"$..price","document":` + books + `}`, "budget_exceeded"},
+		{"depth", limits{maxDepth: 1}, `{"path":"$..price","document":` + books + `}`, "max_depth_exceeded"},
+	}
+	for _, tt := range tests {
+		res, isErr := call(t, tt.lim, "query", tt.args)
+		e, _ := res["error"].(map[string]interface{})
+		if !isErr || e == nil || e["code"] != tt.code || e["message"] == "" {
+			t.Errorf("%s: expected error %s, got %v", tt.name, tt.code, res)
+		}
+	}
+}
+
+func TestErrorCode(t *testing.T) {
+	tests := []struct {
+		err  error
+		code string
+	}{
+		{&jsonpath.Error{Code: jsonpath.ErrCancelled}, "cancelled"},
+		{&jsonpath.Error{Code: jsonpath.ErrLimitExceeded}, "limit_exceeded"},
+		{fmt.Errorf("query: %w", &jsonpath.Error{Code: jsonpath.ErrKeyNotFound}), "key_not_found"},
+		{errors.New("boom"), "internal"},
+	}
+	for _, tt := range tests {
+		if code := errorCode(tt.err); code != tt.code {
+			t.Errorf("%v: expected %s, got %s", tt.err, tt.code, code)
+		}
+	}
+}
+
+func mustQuote(s string) string {
+	b, _ := json.Marshal(s)
+	return string(b)
+}
@@ -0,0 +1,337 @@
+// Command jsonpath runs JSONPath expressions against JSON documents.
+//
+// Usage:
+//
+//	jsonpath [flags] PATH [FILE...]
+//	jsonpath [flags] -p PATH [-p PATH...] [FILE...]
+//
+// Documents are read from the named files, or from standard input when no
+// file or "-" is given. Each path is compiled once and run against every
+// document. With -ndjson every non-blank line of the input is a document.
+// Numbers are printed as written, so large integers keep every digit.
+//
+// Flags:
+//
+//	-p PATH     path to run; repeat for several paths
+//	-print WHAT values (default), paths, or results (file, line, query, path and value)
+//	-format F   jsonl (default, one match per line), json (a single array), or csv
+//	-r          print string values and paths without JSON quotes (jsonl only)
+//	-first      print only the first match of each path in each document
+//	-exists     print nothing; exit 0 if anything matched, 1 otherwise
+//	-ndjson     treat each input line as a separate document
+//	-lenient    accept comments, trailing commas and unquoted keys
+//
+// Exit status is 0 on success, 1 when -exists found no match, and 2 on usage,
+// input or query errors.
+//
+// Examples:
+//
+//	curl -s https://api.example.com/orders | jsonpath '$.items[?(@.qty > 10)].sku'
+//	jsonpath -print paths -format csv '$..price' catalog.json
+//	jsonpath -ndjson -r '$.request.path' access.log.jsonl
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/njchilds90/go-jsonpath"
+)
+
+const (
+	exitOK      = 0
+	exitNoMatch = 1
+	exitError   = 2
+)
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+// pathList collects repeated -p flags.
+type pathList []string
+
+func (p *pathList) String() string     { return strings.Join(*p, ", ") }
+func (p *pathList) Set(s string) error { *p = append(*p, s); return nil }
+
+// match is one result together with where it came from.
+type match struct {
+	File  string      `json:"file"`
+	Line  int         `json:"line,omitempty"`
+	Query string      `json:"query"`
+	Path  string      `json:"path"`
+	Value interface{} `json:"value"`
+}
+
+type config struct {
+	print, format string
+	raw, first    bool
+	exists        bool
+	ndjson        bool
+	opts          []jsonpath.Option
+}
+
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("jsonpath", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	var (
+		paths   pathList
+		cfg     config
+		lenient bool
+	)
+	fs.Var(&paths, "p", "path to run; repeat for several paths")
+	fs.StringVar(&cfg.print, "print", "values", "what to print: values, paths or results")
+	fs.StringVar(&cfg.format, "format", "jsonl", "output format: jsonl, json or csv")
+	fs.BoolVar(&cfg.raw, "r", false, "print strings without JSON quotes (jsonl only)")
+	fs.BoolVar(&cfg.first, "first", false, "print only the first match of each path in each document")
+	fs.BoolVar(&cfg.exists, "exists", false, "print nothing; exit 0 if anything matched, 1 otherwise")
+	fs.BoolVar(&cfg.ndjson, "ndjson", false, "treat each input line as a separate document")
+	fs.BoolVar(&lenient, "lenient", false, "accept comments, trailing commas and unquoted keys")
+	fs.Usage = func() {
+		fmt.Fprintln(stderr, "usage: jsonpath [flags] PATH [FILE...]")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return exitError
+	}
+
+	files := fs.Args()
+	if len(paths) == 0 {
+		if len(files) == 0 {
+			fs.Usage()
+			return exitError
+		}
+		paths, files = pathList{files[0]}, files[1:]
+	}
+	if len(files) == 0 {
+		files = []string{"-"}
+	}
+	switch cfg.print {
+	case "values", "paths", "results":
+	default:
+		fmt.Fprintf(stderr, "jsonpath: unknown -print %q\n", cfg.print)
+		return exitError
+	}
+	switch cfg.format {
+	case "jsonl", "json", "csv":
+	default:
+		fmt.Fprintf(stderr, "jsonpath: unknown -format %q\n", cfg.format)
+		return exitError
+	}
+	// numbers keep their digits, so large integers print as they were read
+	cfg.opts = append(cfg.opts, jsonpath.WithUseNumber())
+	if lenient {
+		cfg.opts = append(cfg.opts, jsonpath.WithLenientJSON())
+	}
+
+	compiled := make([]*jsonpath.CompiledPath, len(paths))
+	for i, p := range paths {
+		cp, err := jsonpath.Compile(p)
+		if err != nil {
+			fmt.Fprintln(stderr, err)
+			return exitError
+		}
+		compiled[i] = cp
+	}
+
+	out := newPrinter(stdout, cfg)
+	matched := false
+	for _, name := range files {
+		err := eachDocument(name, stdin, cfg.ndjson, func(line int, data []byte) error {
+			for _, cp := range compiled {
+				err := cp.QueryFunc(context.Background(), data, func(r jsonpath.Result) error {
+					matched = true
+					if cfg.exists {
+						return jsonpath.ErrStop
+					}
+					if err := out.print(match{File: name, Line: line, Query: cp.String(), Path: r.Path, Value: r.Value}); err != nil {
+						return err
+					}
+					if cfg.first {
+						return jsonpath.ErrStop
+					}
+					return nil
+				}, cfg.opts...)
+				if err != nil {
+					if line > 0 {
+						return fmt.Errorf("%s:%d: %w", name, line, err)
+					}
+					return fmt.Errorf("%s: %w", name, err)
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			// keep what earlier documents produced
+			out.flush()
+			fmt.Fprintln(stderr, err)
+			return exitError
+		}
+	}
+	if err := out.flush(); err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitError
+	}
+	if cfg.exists && !matched {
+		return exitNoMatch
+	}
+	return exitOK
+}
+
+// eachDocument calls fn with the content of the named file, or with each
+// non-blank line and its number when ndjson is set. "-" is stdin.
+func eachDocument(name string, stdin io.Reader, ndjson bool, fn func(line int, data []byte) error) error {
+	r := stdin
+	if name != "-" {
+		f, err := os.Open(name)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		r = f
+	}
+
+	if !ndjson {
+		data, err := io.ReadAll(r)
+		if err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+		return fn(0, data)
+	}
+	br := bufio.NewReader(r)
+	for line := 1; ; line++ {
+		text, err := br.ReadBytes('\n')
+		if err != nil && err != io.EOF {
+			return fmt.Errorf("%s:%d: %w", name, line, err)
+		}
+		if len(bytes.TrimSpace(text)) > 0 {
+			if err := fn(line, text); err != nil {
+				return err
+			}
+		}
+		if err == io.EOF {
+			return nil
+		}
+	}
+}
+
+// printer writes matches in the configured format.
+type printer struct {
+	w    *bufio.Writer
+	cfg  config
+	csv  *csv.Writer
+	all  []interface{}
+	rows int
+}
+
+func newPrinter(w io.Writer, cfg config) *printer {
+	p := &printer{w: bufio.NewWriter(w), cfg: cfg, all: []interface{}{}}
+	if cfg.format == "csv" {
+		p.csv = csv.NewWriter(p.w)
+	}
+	return p
+}
+
+// item returns what to print for m.
+func (p *printer) item(m match) interface{} {
+	switch p.cfg.print {
+	case "paths":
+		return m.Path
+	case "results":
+		return m
+	}
+	return m.Value
+}
+
+func (p *printer) print(m match) error {
+	switch p.cfg.format {
+	case "json":
+		p.all = append(p.all, p.item(m))
+		return nil
+	case "csv":
+		if p.rows == 0 {
+			if err := p.csv.Write(p.header()); err != nil {
+				return err
+			}
+		}
+		p.rows++
+		return p.csv.Write(p.record(m))
+	}
+
+	if s, ok := p.item(m).(string); ok && p.cfg.raw {
+		_, err := fmt.Fprintln(p.w, s)
+		return err
+	}
+	b, err := json.Marshal(p.item(m))
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(p.w, "%s\n", b)
+	return err
+}
+
+func (p *printer) header() []string {
+	switch p.cfg.print {
+	case "paths":
+		return []string{"path"}
+	case "results":
+		return []string{"file", "line", "query", "path", "value"}
+	}
+	return []string{"value"}
+}
+
+func (p *printer) record(m match) []string {
+	switch p.cfg.print {
+	case "paths":
+		return []string{m.Path}
+	case "results":
+		line := ""
+		if m.Line > 0 {
+			line = strconv.Itoa(m.Line)
+		}
+		return []string{m.File, line, m.Query, m.Path, csvValue(m.Value)}
+	}
+	return []string{csvValue(m.Value)}
+}
+
+// csvValue writes strings as they are and other values as JSON.
+func csvValue(v interface{}) string {
+	if s, ok := v.(string); ok {
+		return s
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(b)
+}
+
+func (p *printer) flush() error {
+	if p.cfg.format == "json" && !p.cfg.exists {
+		enc := json.NewEncoder(p.w)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(p.all); err != nil {
+			return err
+		}
+	}
+	if p.csv != nil {
+		p.csv.Flush()
+		if err := p.csv.Error(); err != nil {
+			return err
+		}
+	}
+	if err := p.w.Flush(); err != nil {
+		return errors.New("jsonpath: writing output: " + err.Error())
+	}
+	return nil
+}
@@ -0,0 +1,142 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const store = `{"store": {"book": [
+	{"title": "Sayings of the Century", "price": 8.95},
+	{"title": "Sword of Honour", "price": 12.99},
+	{"title": "Moby Dick", "price": 8.99}
+]}}`
+
+func runCLI(t *testing.T, stdin string, args ...string) (int, string, string) {
+	t.Helper()
+	var stdout, stderr bytes.Buffer
+	code := run(args, strings.NewReader(stdin), &stdout, &stderr)
+	return code, stdout.String(), stderr.String()
+}
+
+func TestRunOutput(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		want string
+	}{
+		{"values", []string{"$.store.book[?(@.price < 10)].title"}, "\"Sayings of the Century\"\n\"Moby Dick\"\n"},
+		{"raw", []string{"-r", "$.store.book[0].title"}, "Sayings of the Century\n"},
+		{"paths", []string{"-print", "paths", "-r", "$..price"}, "$.store.book[0].price\n$.store.book[1].price\n$.store.book[2].price\n"},
+		{"first", []string{"-first", "$..price"}, "8.95\n"},
+		{"several paths", []string{"-p", "$.store.book[0].price", "-p", "$.store.book[2].price"}, "8.95\n8.99\n"},
+		{"json", []string{"-format", "json", "$.store.book[1:].price"}, "[\n  12.99,\n  8.99\n]\n"},
+		{"json empty", []string{"-format", "json", "$.nope"}, "[]\n"},
+		{"csv", []string{"-format", "csv", "-print", "results", "$.store.book[:2].title"},
+			"file,line,query,path,value\n-,,$.store.book[:2].title,$.store.book[0].title,Sayings of the Century\n-,,$.store.book[:2].title,$.store.book[1].title,Sword of Honour\n"},
+	}
+	for _, tt := range tests {
+		code, out, errOut := runCLI(t, store, tt.args...)
+		if code != exitOK || out != tt.want {
+			t.Errorf("%s: expected exit 0 and %q, got %d %q (stderr %q)", tt.name, tt.want, code, out, errOut)
+		}
+	}
+}
+
+func TestRunResults(t *testing.T) {
+	code, out, _ := runCLI(t, store, "-print", "results", "$.store.book[2].price")
+	want := `{"file":"-","query":"$.store.book[2].price","path":"$.store.book[2].price","value":8.99}` + "\n"
+	if code != exitOK || out != want {
+		t.Errorf("expected %q, got %d %q", want, code, out)
+	}
+}
+
+func TestRunNDJSON(t *testing.T) {
+	input := "{\"msg\": \"up\"}\n\n{\"status\": 503}\n{\"msg\": \"still down\"}"
+	code, out, _ := runCLI(t, input, "-ndjson", "-print", "results", "-format", "csv", "$.msg")
+	want := "file,line,query,path,value\n-,1,$.msg,$.msg,up\n-,4,$.msg,$.msg,still down\n"
+	if code != exitOK || out != want {
+		t.Errorf("expected %q, got %d %q", want, code, out)
+	}
+
+	code, _, errOut := runCLI(t, "{}\n{bad\n", "-ndjson", "$.a")
+	if code != exitError || !strings.HasPrefix(errOut, "-:2: ") {
+		t.Errorf("expected a line-numbered error, got %d %q", code, errOut)
+	}
+}
+
+func TestRunExists(t *testing.T) {
+	if code, out, _ := runCLI(t, store, "-exists", "$.store.book[?(@.price > 10)]"); code != exitOK || out != "" {
+		t.Errorf("expected silent exit 0, got %d %q", code, out)
+	}
+	if code, out, _ := runCLI(t, store, "-exists", "$.store.book[?(@.price > 100)]"); code != exitNoMatch || out != "" {
+		t.Errorf("expected silent exit 1, got %d %q", code, out)
+	}
+}
+
+func TestRunFiles(t *testing.T) {
+	dir := t.TempDir()
+	a := filepath.Join(dir, "a.json")
+	b := filepath.Join(dir, "b.json")
+	if err := os.WriteFile(a, []byte(`{"id": 1}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(b, []byte(`{"id": 2}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	code, out, _ := runCLI(t, `{"id": 3}`, "$.id", a, "-", b)
+	if code != exitOK || out != "1\n3\n2\n" {
+		t.Errorf("expected 1, 3, 2, got %d %q", code, out)
+	}
+
+	code, _, errOut := runCLI(t, "", "$.id", filepath.Join(dir, "missing.json"))
+	if code != exitError || errOut == "" {
+		t.Errorf("expected an error for a missing file, got %d %q", code, errOut)
+	}
+
+	bad := filepath.Join(dir, "bad.json")
+	if err := os.WriteFile(bad, []byte(`{`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	code, out, errOut = runCLI(t, "", "$.id", a, bad)
+	if code != exitError || out != "1\n" || !strings.Contains(errOut, "bad.json") {
+		t.Errorf("expected the output of a.json before the error, got %d %q %q", code, out, errOut)
+	}
+}
+
+func TestRunLargeNumbers(t *testing.T) {
+	code, out, _ := runCLI(t, `{"id": 12345678901234567890, "n": [1.50, 2]}`, "-p", "$.id", "-p", "$.n[?(@ > 1)]")
+	if code != exitOK || out != "12345678901234567890\n1.50\n2\n" {
+		t.Errorf("expected numbers as written, got %d %q", code, out)
+	}
+}
+
+func TestRunErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		stdin string
+		args  []string
+	}{
+		{"no path", store, nil},
+		{"bad path", store, []string{"store"}},
+		{"bad json", "{", []string{"$.a"}},
+		{"bad print", store, []string{"-print", "keys", "$"}},
+		{"bad format", store, []string{"-format", "xml", "$"}},
+		{"bad flag", store, []string{"-nope", "$"}},
+	}
+	for _, tt := range tests {
+		code, out, errOut := runCLI(t, tt.stdin, tt.args...)
+		if code != exitError || out != "" || errOut == "" {
+			t.Errorf("%s: expected exit 2 with a message, got %d %q %q", tt.name, code, out, errOut)
+		}
+	}
+}
+
+func TestRunLenient(t *testing.T) {
+	code, out, _ := runCLI(t, "{a: [1, 2,], // note\n}", "-lenient", "$.a[-1]")
+	if code != exitOK || out != "2\n" {
+		t.Errorf("expected 2, got %d %q", code, out)
+	}
+}
@@ -0,0 +1,41 @@
+package jsonpath
+
+import "fmt"
+
+// Then returns the path that applies next to each match of cp, with next's
+// root standing for the match, so extraction stages can be composed from
+// reusable compiled pieces instead of by string concatenation. Result paths
+// are the normalized paths of the combined path, and its String is the
+// combined path, such as $.store.book[*].author for $.store.book[*] then
+// $.author. Paths with alternatives or a default, and paths ending in a
+// selector that must come last, such as length() or ~, cannot be chained.
+//
+// Example:
+//
+//	books := jsonpath.MustCompile("$.store.book[?(@.price < 10)]")
+//	titles, err := books.Then(jsonpath.MustCompile("$.title"))
+//	results, err := titles.Query(data)
+func (cp *CompiledPath) Then(next *CompiledPath) (*CompiledPath, error) {
+	for _, p := range []*CompiledPath{cp, next} {
+		if p == nil {
+			return nil, &Error{Code: ErrInvalidInput, Message: "Then needs two compiled paths"}
+		}
+		if len(p.tokens) > 1 && (p.tokens[1].kind == tokenAlternatives || p.tokens[1].kind == tokenDefault) {
+			return nil, &Error{Code: ErrInvalidInput, Message: fmt.Sprintf("cannot chain %s: it has alternatives or a default", p.raw)}
+		}
+	}
+	if last := cp.tokens[len(cp.tokens)-1]; len(next.tokens) > 1 && (last.kind == tokenFunction || last.kind == tokenNames) {
+		return nil, &Error{Code: ErrInvalidInput, Message: fmt.Sprintf("cannot chain after %s: %s must be the last selector", cp.raw, last)}
+	}
+	tokens := append(append([]token{}, cp.tokens...), next.tokens[1:]...)
+	return &CompiledPath{raw: formatTokens(tokens), tokens: tokens}, nil
+}
+
+// MustThen is Then that panics on error.
+func (cp *CompiledPath) MustThen(next *CompiledPath) *CompiledPath {
+	chained, err := cp.Then(next)
+	if err != nil {
+		panic(fmt.Sprintf("jsonpath.MustThen: %v", err))
+	}
+	return chained
+}
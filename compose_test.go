@@ -0,0 +1,70 @@
+package jsonpath_test
+
+import (
+	"testing"
+
+	"github.com/njchilds90/go-jsonpath"
+)
+
+func TestThen(t *testing.T) {
+	tests := []struct {
+		first, next string
+		want        string
+		paths       []string
+	}{
+		{"$.store.book[?(@.price < 10)]", "$.title", "$.store.book[?(@.price < 10)].title", []string{"$.store.book[0].title", "$.store.book[2].title"}},
+		{"$.store", "$..price", "$.store..price", []string{"$.store.bicycle.price", "$.store.book[0].price", "$.store.book[1].price", "$.store.book[2].price", "$.store.book[3].price"}},
+		{"$.store.book[*]", "$", "$.store.book[*]", []string{"$.store.book[0]", "$.store.book[1]", "$.store.book[2]", "$.store.book[3]"}},
+		{"$.store.book", "$[-1]['author']", "$.store.book[-1].author", []string{"$.store.book[3].author"}},
+		{"$", "$.nope", "$.nope", nil},
+	}
+	for _, tt := range tests {
+		cp, err := jsonpath.MustCompile(tt.first).Then(jsonpath.MustCompile(tt.next))
+		if err != nil {
+			t.Errorf("%s then %s: unexpected error: %v", tt.first, tt.next, err)
+			continue
+		}
+		if cp.String() != tt.want {
+			t.Errorf("%s then %s: expected %s, got %s", tt.first, tt.next, tt.want, cp.String())
+		}
+		if _, err := jsonpath.Compile(cp.String()); err != nil {
+			t.Errorf("expected %s to parse back, got %v", cp, err)
+		}
+		paths, err := cp.Paths(sampleJSON)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", cp, err)
+			continue
+		}
+		if len(paths) != len(tt.paths) {
+			t.Errorf("%s: expected %v, got %v", cp, tt.paths, paths)
+			continue
+		}
+		for i, p := range paths {
+			if p != tt.paths[i] {
+				t.Errorf("%s: expected %s, got %s", cp, tt.paths[i], p)
+			}
+		}
+	}
+
+	names := jsonpath.MustCompile("$.store.book[0]").MustThen(jsonpath.MustCompile("$~"))
+	if n, err := names.Count(sampleJSON); err != nil || n != 4 {
+		t.Errorf("expected 4 member names, got %d (%v)", n, err)
+	}
+}
+
+func TestThenErrors(t *testing.T) {
+	tests := []struct{ first, next string }{
+		{"$.a | $.b", "$.c"},
+		{"$.a", "$.b ?? 1"},
+		{"$.a.length()", "$.b"},
+		{"$.a~", "$[0]"},
+	}
+	for _, tt := range tests {
+		if _, err := jsonpath.MustCompile(tt.first).Then(jsonpath.MustCompile(tt.next)); !isInputError(err) {
+			t.Errorf("%s then %s: expected invalid input, got %v", tt.first, tt.next, err)
+		}
+	}
+	if _, err := jsonpath.MustCompile("$.a").Then(nil); !isInputError(err) {
+		t.Errorf("expected invalid input for nil, got %v", err)
+	}
+}
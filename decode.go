@@ -0,0 +1,141 @@
+package jsonpath
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// decodeField is a struct field tagged with a path.
+type decodeField struct {
+	index    []int
+	name     string
+	path     *CompiledPath
+	all      bool // collect every match into a slice field
+	required bool
+}
+
+// decodePlans caches the tagged fields of each struct type given to Decode.
+var decodePlans sync.Map // reflect.Type -> []decodeField
+
+// Decode fills the struct dst points to from data. Each field tagged
+// `jsonpath:"<path>"` receives the first match of its path, converted as
+// json.Unmarshal would; a slice field whose path can match several nodes
+// receives every match instead. Fields whose path matches nothing are left
+// unchanged unless the tag ends in ",required", which makes a missing match
+// an ErrNoMatch error. Untagged struct fields are filled the same way,
+// so tagged fields may be grouped in nested structs. data is decoded once
+// and each struct type's paths are compiled once.
+//
+// Example:
+//
+//	var user struct {
+//	    Name   string   `jsonpath:"$.user.profile.name,required"`
+//	    Emails []string `jsonpath:"$.user.contacts[?(@.type == 'email')].value"`
+//	    Admin  bool     `jsonpath:"$.user.roles.admin"`
+//	}
+//	err := jsonpath.Decode(data, &user)
+func Decode(data []byte, dst interface{}, opts ...Option) error {
+	rv := reflect.ValueOf(dst)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return &Error{Code: ErrInvalidInput, Message: fmt.Sprintf("Decode needs a non-nil pointer to a struct, got %T", dst)}
+	}
+	fields, err := decodePlan(rv.Elem().Type())
+	if err != nil {
+		return err
+	}
+	doc, err := Parse(data, opts...)
+	if err != nil {
+		return err
+	}
+
+	for _, f := range fields {
+		results, err := f.path.QueryDocument(doc, opts...)
+		if err != nil {
+			return err
+		}
+		if len(results) == 0 {
+			if f.required {
+				return &Error{Code: ErrNoMatch, Message: fmt.Sprintf("field %s: path %s matched nothing", f.name, f.path)}
+			}
+			continue
+		}
+		fv := rv.Elem().FieldByIndex(f.index)
+		if !f.all {
+			if err := setField(fv, results[0]); err != nil {
+				return &Error{Code: ErrTypeMismatch, Message: fmt.Sprintf("field %s: cannot decode %s into %s", f.name, results[0].Path, fv.Type()), Cause: err}
+			}
+			continue
+		}
+		slice := reflect.MakeSlice(fv.Type(), len(results), len(results))
+		for i, r := range results {
+			if err := setField(slice.Index(i), r); err != nil {
+				return &Error{Code: ErrTypeMismatch, Message: fmt.Sprintf("field %s: cannot decode %s into %s", f.name, r.Path, fv.Type().Elem()), Cause: err}
+			}
+		}
+		fv.Set(slice)
+	}
+	return nil
+}
+
+// setField stores r.Value in v, assigning it directly when the types allow.
+func setField(v reflect.Value, r Result) error {
+	if r.Value != nil {
+		if src := reflect.ValueOf(r.Value); src.Type().AssignableTo(v.Type()) {
+			v.Set(src)
+			return nil
+		}
+	}
+	return decodeValue(r.Value, v.Addr().Interface())
+}
+
+// decodePlan returns the tagged fields of t, compiling their paths on first use.
+func decodePlan(t reflect.Type) ([]decodeField, error) {
+	if plan, ok := decodePlans.Load(t); ok {
+		return plan.([]decodeField), nil
+	}
+	fields, err := collectFields(t, nil, "")
+	if err != nil {
+		return nil, err
+	}
+	decodePlans.Store(t, fields)
+	return fields, nil
+}
+
+func collectFields(t reflect.Type, index []int, prefix string) ([]decodeField, error) {
+	var fields []decodeField
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		idx := append(append([]int(nil), index...), i)
+		name := prefix + sf.Name
+
+		tag, ok := sf.Tag.Lookup("jsonpath")
+		if !ok || tag == "-" {
+			if !ok && sf.Type.Kind() == reflect.Struct && (sf.IsExported() || sf.Anonymous) {
+				nested, err := collectFields(sf.Type, idx, name+".")
+				if err != nil {
+					return nil, err
+				}
+				fields = append(fields, nested...)
+			}
+			continue
+		}
+		if !sf.IsExported() {
+			return nil, &Error{Code: ErrInvalidInput, Message: fmt.Sprintf("field %s has a jsonpath tag but is not exported", name)}
+		}
+
+		f := decodeField{index: idx, name: name}
+		if rest, found := strings.CutSuffix(tag, ",required"); found {
+			tag, f.required = rest, true
+		}
+		cp, err := Compile(tag)
+		if err != nil {
+			return nil, &Error{Code: ErrInvalidPath, Message: fmt.Sprintf("field %s: invalid jsonpath tag %q", name, tag), Cause: err}
+		}
+		f.path = cp
+		f.all = sf.Type.Kind() == reflect.Slice && sf.Type.Elem().Kind() != reflect.Uint8 && !cp.Explain().Singular
+		fields = append(fields, f)
+	}
+	return fields, nil
+}
@@ -0,0 +1,88 @@
+package jsonpath_test
+
+import (
+	"testing"
+
+	"github.com/njchilds90/go-jsonpath"
+)
+
+type bicycle struct {
+	Color string  `jsonpath:"$.store.bicycle.color"`
+	Price float64 `jsonpath:"$.store.bicycle.price"`
+}
+
+type storeSummary struct {
+	Titles    []string  `jsonpath:"$.store.book[*].title"`
+	Cheap     []book    `jsonpath:"$.store.book[?(@.price < 10)],required"`
+	First     string    `jsonpath:"$..author"`
+	FirstBook *book     `jsonpath:"$.store.book[0]"`
+	Prices    []float64 `jsonpath:"$..book[*].price"`
+	Tags      []string  `jsonpath:"$.store.tags"`
+	Missing   string    `jsonpath:"$.store.missing"`
+	Ignored   string    `jsonpath:"-"`
+	Bicycle   bicycle
+	untagged  int
+}
+
+func TestDecode(t *testing.T) {
+	s := storeSummary{Missing: "default", Ignored: "kept"}
+	if err := jsonpath.Decode(sampleJSON, &s); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(s.Titles) != 4 || s.Titles[3] != "The Lord of the Rings" {
+		t.Errorf("unexpected titles: %v", s.Titles)
+	}
+	if len(s.Cheap) != 2 || s.Cheap[1].Author != "Herman Melville" {
+		t.Errorf("unexpected cheap books: %+v", s.Cheap)
+	}
+	if s.First != "Nigel Rees" || s.FirstBook == nil || s.FirstBook.Title != "Sayings of the Century" {
+		t.Errorf("unexpected first matches: %q %+v", s.First, s.FirstBook)
+	}
+	if len(s.Prices) != 4 || s.Prices[1] != 12.99 {
+		t.Errorf("unexpected prices: %v", s.Prices)
+	}
+	if s.Tags != nil || s.Missing != "default" || s.Ignored != "kept" {
+		t.Errorf("expected unmatched fields to be left alone: %+v", s)
+	}
+	if s.Bicycle.Color != "red" || s.Bicycle.Price != 19.95 {
+		t.Errorf("unexpected nested struct: %+v", s.Bicycle)
+	}
+}
+
+func TestDecodeErrors(t *testing.T) {
+	var required struct {
+		Name string `jsonpath:"$.store.owner,required"`
+	}
+	if err := jsonpath.Decode(sampleJSON, &required); !jsonpath.IsNoMatch(err) {
+		t.Errorf("expected no match, got %v", err)
+	}
+
+	var mismatch struct {
+		Price int `jsonpath:"$.store.bicycle.color"`
+	}
+	if err := jsonpath.Decode(sampleJSON, &mismatch); !isTypeMismatch(err) {
+		t.Errorf("expected type mismatch, got %v", err)
+	}
+
+	var badTag struct {
+		Name string `jsonpath:"store"`
+	}
+	if err := jsonpath.Decode(sampleJSON, &badTag); !jsonpath.IsPathError(err) {
+		t.Errorf("expected path error, got %v", err)
+	}
+
+	var unexported struct {
+		name string `jsonpath:"$.a"`
+	}
+	if err := jsonpath.Decode(sampleJSON, &unexported); !isInputError(err) {
+		t.Errorf("expected input error, got %v", err)
+	}
+
+	var s storeSummary
+	if err := jsonpath.Decode(sampleJSON, s); !isInputError(err) {
+		t.Errorf("expected input error for a non-pointer, got %v", err)
+	}
+	if err := jsonpath.Decode([]byte(`{`), &s); !jsonpath.IsJSONError(err) {
+		t.Errorf("expected JSON error, got %v", err)
+	}
+}
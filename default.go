@@ -0,0 +1,105 @@
+package jsonpath
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// splitDefault splits path at the first "??" outside brackets, parentheses
+// and quoted strings, so that a path can carry the value to return when it
+// matches nothing, as in "$.settings.timeout ?? 30". ok is false when path
+// has no default.
+func splitDefault(path string) (left, right string, ok bool) {
+	depth := 0
+	var quote byte
+	for i := 0; i < len(path)-1; i++ {
+		c := path[i]
+		switch {
+		case quote != 0:
+			if c == '\\' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"':
+			quote = c
+		case c == '[' || c == '(':
+			depth++
+		case c == ']' || c == ')':
+			depth--
+		case c == '?' && path[i+1] == '?' && depth == 0:
+			return path[:i], path[i+2:], true
+		}
+	}
+	return path, "", false
+}
+
+// scanDefault tokenizes a path with a default value. The default is a
+// literal as written in filters: a number, a quoted string, true, false or
+// null.
+func (s *pathScanner) scanDefault(left, right string, base int) []token {
+	if strings.TrimSpace(left) == "" {
+		s.fail(base, "empty-path", &Error{Code: ErrInvalidPath, Message: "path before '??' must not be empty"}, "")
+		return nil
+	}
+	n := len(s.diags)
+	tokens := s.scan(left, base)
+	if len(s.diags) > n && !s.all {
+		return nil
+	}
+	offset := base + len(left) + 2
+	p := &filterParser{src: right}
+	op, err := p.parseOperand()
+	lit, isLiteral := op.(*literalOperand)
+	if err == nil {
+		p.skipSpace()
+		if !isLiteral || p.pos < len(p.src) {
+			err = fmt.Errorf("expected a number, string, true, false or null, got %q", strings.TrimSpace(right))
+		}
+	}
+	if err != nil {
+		msg := err.Error()
+		if e, ok := err.(*Error); ok {
+			msg = e.Message
+		}
+		s.fail(offset, "invalid-default", &Error{Code: ErrInvalidPath, Message: "invalid default value: " + msg}, "quote string defaults, as in ?? 'none'")
+		return nil
+	}
+	return []token{{kind: tokenRoot}, {kind: tokenDefault, alts: [][]token{tokens}, literal: lit}}
+}
+
+// evalDefault evaluates the path of a default, emitting the default value
+// when the path matches nothing. The default has no place in the document,
+// so its Result has an empty Path. A missing key or index reported under
+// WithAllowMissingKeys(true) also yields the default.
+func (e *engine) evalDefault(node interface{}, tok token, currentPath string, emit emitFunc) error {
+	matched := false
+	mark := len(e.st.violations)
+	err := e.evaluate(node, tok.alts[0][1:], currentPath, func(r Result) error {
+		matched = true
+		return emit(r)
+	})
+	if matched || (err != nil && !isLookupError(err)) {
+		return err
+	}
+	e.st.violations = e.st.violations[:mark]
+	if e.logger != nil {
+		e.debug("jsonpath: default used", "path", currentPath, "default", formatOperand(tok.literal), "error", err)
+	}
+	return emit(Result{Value: e.defaultValue(tok.literal)})
+}
+
+// defaultValue returns the value of lit with the number types the options
+// ask for.
+func (e *engine) defaultValue(lit *literalOperand) interface{} {
+	switch {
+	case lit.number == "":
+		return lit.value
+	case e.exactNumbers:
+		return decodeExactNumber(lit.number)
+	case e.useNumber:
+		return json.Number(lit.number)
+	}
+	return lit.value
+}
@@ -0,0 +1,76 @@
+package jsonpath_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/njchilds90/go-jsonpath"
+)
+
+func TestDefaultValue(t *testing.T) {
+	tests := []struct {
+		path string
+		want []interface{}
+	}{
+		{"$.expensive ?? 0", []interface{}{float64(10)}},
+		{"$.cheap ?? 5", []interface{}{float64(5)}},
+		{"$.store.bicycle.gears ?? 'none'", []interface{}{"none"}},
+		{`$.store.bicycle.gears ?? "a ?? b"`, []interface{}{"a ?? b"}},
+		{"$.store.bicycle.gears??true", []interface{}{true}},
+		{"$.store.bicycle.gears ?? null", []interface{}{nil}},
+		{"$.store.book[?(@.price > 100)].title ?? 'none'", []interface{}{"none"}},
+		{"$.store.book[?(@.price > 20)].price ?? 0", []interface{}{22.99}},
+		{"$.store.book[?@.isbn].isbn ?? ''", []interface{}{"0-553-21311-3", "0-395-19395-8"}},
+		{"$.nope | $.expensive ?? 0", []interface{}{float64(10)}},
+		{"$.nope | $.none ?? -1", []interface{}{float64(-1)}},
+	}
+	for _, tt := range tests {
+		results, err := jsonpath.Query(sampleJSON, tt.path)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", tt.path, err)
+			continue
+		}
+		if len(results) != len(tt.want) {
+			t.Errorf("%s: expected %v, got %v", tt.path, tt.want, results)
+			continue
+		}
+		for i, r := range results {
+			if r.Value != tt.want[i] {
+				t.Errorf("%s: expected %v, got %v", tt.path, tt.want[i], r.Value)
+			}
+		}
+	}
+
+	r, err := jsonpath.QueryOne(sampleJSON, "$.timeout ?? 30")
+	if err != nil || r.Path != "" || r.Value != float64(30) {
+		t.Errorf("expected the default with no path, got %+v (%v)", r, err)
+	}
+	r, err = jsonpath.QueryOne(sampleJSON, "$.timeout ?? 30", jsonpath.WithAllowMissingKeys(true))
+	if err != nil || r.Value != float64(30) {
+		t.Errorf("expected the default in strict mode, got %+v (%v)", r, err)
+	}
+	r, err = jsonpath.QueryOne(sampleJSON, "$.timeout ?? 30", jsonpath.WithUseNumber())
+	if err != nil || r.Value != json.Number("30") {
+		t.Errorf("expected json.Number 30, got %+v (%v)", r, err)
+	}
+	r, err = jsonpath.QueryOne(sampleJSON, "$.timeout ?? 30", jsonpath.WithExactNumbers())
+	if err != nil || r.Value != int64(30) {
+		t.Errorf("expected int64 30, got %+v (%v)", r, err)
+	}
+
+	cp := jsonpath.MustCompile("$.store.bicycle.gears ?? 'none'")
+	if got := cp.Explain().Steps[1].Selector; got != "$.store.bicycle.gears ?? 'none'" {
+		t.Errorf("expected the default in the plan, got %s", got)
+	}
+}
+
+func TestDefaultValueErrors(t *testing.T) {
+	for _, path := range []string{"$.a ??", "?? 1", "$.a ?? $.b", "$.a ?? 1 2", "$.a ?? none", "$.a ?? 1 ?? 2"} {
+		if _, err := jsonpath.Compile(path); !jsonpath.IsPathError(err) {
+			t.Errorf("%s: expected a path error, got %v", path, err)
+		}
+	}
+	if _, err := jsonpath.Transpile("$.a ?? 1", jsonpath.TargetJQ); !jsonpath.IsUnsupported(err) {
+		t.Errorf("expected unsupported, got %v", err)
+	}
+}
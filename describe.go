@@ -0,0 +1,176 @@
+package jsonpath
+
+import (
+	"context"
+	"strings"
+)
+
+const (
+	// maxShapeKeys bounds the member names a Shape lists.
+	maxShapeKeys = 50
+	// maxShapeExamples bounds the example values a Shape keeps.
+	maxShapeExamples = 3
+	// maxExampleLength bounds the characters of an example string.
+	maxExampleLength = 64
+)
+
+// Shape is a compact structural summary of a set of values, as returned by
+// DescribeMatches: the kinds seen, the members of objects and elements of
+// arrays, summarized in turn, array lengths and a few example scalars.
+type Shape struct {
+	// Count is the number of values summarized.
+	Count int `json:"count"`
+	// Kinds counts the values of each kind.
+	Kinds map[NodeKind]int `json:"kinds"`
+	// Keys summarizes, for each member name of the object values, the
+	// values of that member; a member's Count below Kinds[NodeObject]
+	// means some objects lack it.
+	Keys map[string]*Shape `json:"keys,omitempty"`
+	// MoreKeys reports whether member names beyond the first 50 were left
+	// out of Keys.
+	MoreKeys bool `json:"moreKeys,omitempty"`
+	// Items summarizes the elements of all the array values.
+	Items *Shape `json:"items,omitempty"`
+	// MinLength and MaxLength are the lengths of the shortest and longest
+	// array values.
+	MinLength int `json:"minLength,omitempty"`
+	MaxLength int `json:"maxLength,omitempty"`
+	// Examples holds the first three distinct null, boolean, number and
+	// string values, with long strings shortened.
+	Examples []interface{} `json:"examples,omitempty"`
+
+	order []string // member names in the order first seen
+}
+
+// DescribeMatches returns a Shape summarizing the values path matches, so
+// that agents and people can see what a query returns, and decide on the
+// next one, without dumping large values.
+//
+// Example:
+//
+//	shape, err := jsonpath.DescribeMatches(data, "$.store.book[*]")
+//	fmt.Println(shape)
+//	// {author: string, category: string, price: number, title: string, isbn?: string}
+func DescribeMatches(data []byte, path string, opts ...Option) (*Shape, error) {
+	shape := newShape()
+	err := QueryFunc(context.Background(), data, path, func(r Result) error {
+		shape.add(decodedValue(r.Value))
+		return nil
+	}, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return shape, nil
+}
+
+func newShape() *Shape {
+	return &Shape{Kinds: map[NodeKind]int{}}
+}
+
+// add adds v to the values s summarizes.
+func (s *Shape) add(v interface{}) {
+	s.Count++
+	kind := kindOf(v)
+	s.Kinds[kind]++
+	switch kind {
+	case NodeObject:
+		obj, _ := objectOf(v)
+		for _, name := range obj.Keys() {
+			child, ok := s.Keys[name]
+			if !ok {
+				if len(s.order) >= maxShapeKeys {
+					s.MoreKeys = true
+					continue
+				}
+				if s.Keys == nil {
+					s.Keys = map[string]*Shape{}
+				}
+				child = newShape()
+				s.Keys[name] = child
+				s.order = append(s.order, name)
+			}
+			value, _ := obj.Get(name)
+			child.add(value)
+		}
+	case NodeArray:
+		arr, _ := arrayOf(v)
+		n := arr.Len()
+		if s.Kinds[NodeArray] == 1 || n < s.MinLength {
+			s.MinLength = n
+		}
+		s.MaxLength = max(s.MaxLength, n)
+		if n > 0 && s.Items == nil {
+			s.Items = newShape()
+		}
+		for i := 0; i < n; i++ {
+			s.Items.add(arr.Index(i))
+		}
+	case NodeNull, NodeBool, NodeNumber, NodeString:
+		if str, ok := v.(string); ok {
+			if runes := []rune(str); len(runes) > maxExampleLength {
+				v = string(runes[:maxExampleLength-1]) + "…"
+			}
+		}
+		if len(s.Examples) < maxShapeExamples && !containsExample(s.Examples, v) {
+			s.Examples = append(s.Examples, v)
+		}
+	}
+}
+
+func containsExample(examples []interface{}, v interface{}) bool {
+	for _, x := range examples {
+		if x == v {
+			return true
+		}
+	}
+	return false
+}
+
+// String returns the shape in a compact type notation, with the kinds of
+// the values joined by |, objects written {name: type}, members some
+// objects lack marked ?, and arrays written type[]:
+//
+//	{author: string, isbn?: string, tags: string[]} | null
+func (s *Shape) String() string {
+	if s == nil || s.Count == 0 {
+		return "never"
+	}
+	var parts []string
+	for k := NodeNull; k <= NodeOther; k++ {
+		if s.Kinds[k] == 0 {
+			continue
+		}
+		switch k {
+		case NodeObject:
+			members := make([]string, len(s.order))
+			for i, name := range s.order {
+				child := s.Keys[name]
+				sep := ": "
+				if child.Count < s.Kinds[NodeObject] {
+					sep = "?: "
+				}
+				label := quoteKey(name)
+				if m := formatMember(name); m[0] == '.' {
+					label = m[1:]
+				}
+				members[i] = label + sep + child.String()
+			}
+			if s.MoreKeys {
+				members = append(members, "...")
+			}
+			parts = append(parts, "{"+strings.Join(members, ", ")+"}")
+		case NodeArray:
+			switch {
+			case s.Items == nil:
+				parts = append(parts, "[]")
+			case len(s.Items.Kinds) > 1:
+				parts = append(parts, "("+s.Items.String()+")[]")
+			default:
+				parts = append(parts, s.Items.String()+"[]")
+			}
+		default:
+			parts = append(parts, k.String())
+		}
+	}
+	return strings.Join(parts, " | ")
+}
@@ -0,0 +1,75 @@
+package jsonpath_test
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/njchilds90/go-jsonpath"
+)
+
+func TestDescribeMatches(t *testing.T) {
+	shape, err := jsonpath.DescribeMatches(sampleJSON, "$.store.book[*]")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "{author: string, category: string, price: number, title: string, isbn?: string}"; shape.String() != want {
+		t.Errorf("expected %s, got %s", want, shape)
+	}
+	if shape.Count != 4 || shape.Kinds[jsonpath.NodeObject] != 4 || shape.Keys["isbn"].Count != 2 {
+		t.Errorf("unexpected counts: %+v", shape)
+	}
+	if got := shape.Keys["category"].Examples; len(got) != 2 || got[0] != "reference" || got[1] != "fiction" {
+		t.Errorf("expected distinct examples, got %v", got)
+	}
+	if got := shape.Keys["price"].Examples; len(got) != 3 {
+		t.Errorf("expected 3 examples, got %v", got)
+	}
+
+	data := []byte(`{"a": [[1, "x"], [], [null, 2, 3]], "b": {"my key": true}, "s": "` + strings.Repeat("y", 100) + `"}`)
+	shape, err = jsonpath.DescribeMatches(data, "$")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "{a: (null | number | string)[][], b: {'my key': boolean}, s: string}"; shape.String() != want {
+		t.Errorf("expected %s, got %s", want, shape)
+	}
+	if a := shape.Keys["a"]; a.MinLength != 3 || a.MaxLength != 3 || a.Items.MinLength != 0 || a.Items.MaxLength != 3 {
+		t.Errorf("unexpected lengths: %+v", a)
+	}
+	if s := shape.Keys["s"].Examples[0].(string); len([]rune(s)) != 64 || !strings.HasSuffix(s, "…") {
+		t.Errorf("expected a shortened example, got %q", s)
+	}
+
+	out, err := json.Marshal(shape.Keys["b"])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `{"count":1,"kinds":{"object":1},"keys":{"my key":{"count":1,"kinds":{"boolean":1},"examples":[true]}}}`; string(out) != want {
+		t.Errorf("expected %s, got %s", want, out)
+	}
+
+	shape, err = jsonpath.DescribeMatches(sampleJSON, "$.nope")
+	if err != nil || shape.Count != 0 || shape.String() != "never" {
+		t.Errorf("expected an empty shape, got %v (%v)", shape, err)
+	}
+}
+
+func TestDescribeMatchesManyKeys(t *testing.T) {
+	var b strings.Builder
+	b.WriteString("{")
+	for i := 0; i < 60; i++ {
+		if i > 0 {
+			b.WriteString(",")
+		}
+		b.WriteString(`"k` + strings.Repeat("x", i) + `": 1`)
+	}
+	b.WriteString("}")
+	shape, err := jsonpath.DescribeMatches([]byte(b.String()), "$")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(shape.Keys) != 50 || !shape.MoreKeys || !strings.HasSuffix(shape.String(), ", ...}") {
+		t.Errorf("expected 50 keys and more, got %d, %v", len(shape.Keys), shape.MoreKeys)
+	}
+}
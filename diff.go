@@ -0,0 +1,211 @@
+package jsonpath
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strconv"
+)
+
+// ChangeKind classifies a Change.
+type ChangeKind int
+
+const (
+	// ChangeAdded is a value present only in the new document.
+	ChangeAdded ChangeKind = iota + 1
+	// ChangeRemoved is a value present only in the old document.
+	ChangeRemoved
+	// ChangeModified is a value that differs between the documents. A value
+	// whose type changes, such as an object that becomes an array, is
+	// modified as a whole.
+	ChangeModified
+)
+
+var changeKindNames = map[ChangeKind]string{
+	ChangeAdded:    "added",
+	ChangeRemoved:  "removed",
+	ChangeModified: "modified",
+}
+
+// String returns the name of the change kind.
+func (k ChangeKind) String() string {
+	if name, ok := changeKindNames[k]; ok {
+		return name
+	}
+	return "ChangeKind(" + strconv.Itoa(int(k)) + ")"
+}
+
+// MarshalText implements encoding.TextMarshaler so changes encode readably.
+func (k ChangeKind) MarshalText() ([]byte, error) {
+	return []byte(k.String()), nil
+}
+
+// Change is one difference between two documents.
+type Change struct {
+	// Path is the normalized path of the value that changed.
+	Path string `json:"path"`
+	// Kind says whether the value was added, removed or modified.
+	Kind ChangeKind `json:"kind"`
+	// Old is the value in the old document; nil when it was added.
+	Old interface{} `json:"old"`
+	// New is the value in the new document; nil when it was removed.
+	New interface{} `json:"new"`
+}
+
+// Diff compares two JSON documents and returns the changes that turn a into
+// b, ordered like the members wildcards visit. Objects are compared member
+// by member and arrays element by element, so an element inserted at the
+// front of an array modifies every element after it. Numbers compare by
+// value, so 1 and 1.0 are equal.
+//
+// Example:
+//
+//	changes, err := jsonpath.Diff(before, after)
+//	for _, c := range changes {
+//	    fmt.Println(c.Kind, c.Path, c.Old, "→", c.New)
+//	}
+func Diff(a, b []byte, opts ...Option) ([]Change, error) {
+	return DiffAt(a, b, "$", opts...)
+}
+
+// DiffAt is Diff scoped to the values path matches in each document. Values
+// matched in only one of them are added or removed as a whole.
+//
+// Example:
+//
+//	changes, err := jsonpath.DiffAt(before, after, "$.spec.containers[*]")
+func DiffAt(a, b []byte, path string, opts ...Option) ([]Change, error) {
+	e := newEngine(context.Background(), opts)
+	cp, err := e.compile(path)
+	if err != nil {
+		return nil, err
+	}
+	docA, err := e.parse(a)
+	if err != nil {
+		return nil, err
+	}
+	docB, err := e.parse(b)
+	if err != nil {
+		return nil, err
+	}
+	inA, err := e.collect(docA.root, cp.tokens)
+	if err != nil {
+		return nil, err
+	}
+	inB, err := e.collect(docB.root, cp.tokens)
+	if err != nil {
+		return nil, err
+	}
+
+	byPath := make(map[string]interface{}, len(inB))
+	for _, r := range inB {
+		byPath[r.Path] = r.Value
+	}
+	d := &differ{maxDepth: e.maxDepth}
+	for _, r := range inA {
+		v, ok := byPath[r.Path]
+		if !ok {
+			d.add(r.Path, ChangeRemoved, r.Value, nil)
+			continue
+		}
+		delete(byPath, r.Path)
+		if err := d.diff(r.Value, v, r.Path, 0); err != nil {
+			return nil, err
+		}
+	}
+	for _, r := range inB {
+		if _, ok := byPath[r.Path]; ok {
+			d.add(r.Path, ChangeAdded, nil, r.Value)
+		}
+	}
+	return d.changes, nil
+}
+
+type differ struct {
+	maxDepth int
+	changes  []Change
+}
+
+func (d *differ) add(path string, kind ChangeKind, before, after interface{}) {
+	d.changes = append(d.changes, Change{Path: path, Kind: kind, Old: before, New: after})
+}
+
+// diff records the changes between a and b, both at path.
+func (d *differ) diff(a, b interface{}, path string, depth int) error {
+	if d.maxDepth > 0 && depth > d.maxDepth {
+		return &Error{Code: ErrMaxDepthExceeded, Message: fmt.Sprintf("max depth %d exceeded", d.maxDepth)}
+	}
+	objA, isObjA := objectOf(a)
+	objB, isObjB := objectOf(b)
+	if isObjA && isObjB {
+		for _, k := range objA.Keys() {
+			va, _ := objA.Get(k)
+			vb, ok := objB.Get(k)
+			if !ok {
+				d.add(childPath(path, k), ChangeRemoved, va, nil)
+				continue
+			}
+			if err := d.diff(va, vb, childPath(path, k), depth+1); err != nil {
+				return err
+			}
+		}
+		for _, k := range objB.Keys() {
+			if _, ok := objA.Get(k); !ok {
+				vb, _ := objB.Get(k)
+				d.add(childPath(path, k), ChangeAdded, nil, vb)
+			}
+		}
+		return nil
+	}
+	arrA, isArrA := arrayOf(a)
+	arrB, isArrB := arrayOf(b)
+	if isArrA && isArrB {
+		for i := 0; i < arrA.Len() || i < arrB.Len(); i++ {
+			switch {
+			case i >= arrB.Len():
+				d.add(indexPath(path, i), ChangeRemoved, arrA.Index(i), nil)
+			case i >= arrA.Len():
+				d.add(indexPath(path, i), ChangeAdded, nil, arrB.Index(i))
+			default:
+				if err := d.diff(arrA.Index(i), arrB.Index(i), indexPath(path, i), depth+1); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	}
+	if isObjA || isObjB || isArrA || isArrB || !scalarsEqual(a, b) {
+		d.add(path, ChangeModified, a, b)
+	}
+	return nil
+}
+
+// scalarsEqual reports whether two scalar values are the same JSON value.
+func scalarsEqual(a, b interface{}) bool {
+	ra, rb := typeRank(a), typeRank(b)
+	if ra != rb {
+		return false
+	}
+	if ra == 4 {
+		return reflect.DeepEqual(a, b)
+	}
+	return compareJSON(a, b) == 0
+}
+
+// EqualAt reports whether path matches the same values at the same paths in
+// both documents, comparing values deeply as Diff does. Contract tests can
+// use it to assert which regions of a payload a change may touch.
+//
+// Example:
+//
+//	same, err := jsonpath.EqualAt(before, after, "$.billing")
+//	if err == nil && !same {
+//	    t.Error("billing must not change")
+//	}
+func EqualAt(a, b []byte, path string, opts ...Option) (bool, error) {
+	changes, err := DiffAt(a, b, path, opts...)
+	if err != nil {
+		return false, err
+	}
+	return len(changes) == 0, nil
+}
@@ -0,0 +1,84 @@
+package jsonpath_test
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/njchilds90/go-jsonpath"
+)
+
+func TestDiff(t *testing.T) {
+	before := []byte(`{"name": "app", "replicas": 1, "tags": ["a", "b"], "spec": {"port": 80, "tls": false}, "old": true}`)
+	after := []byte(`{"name": "app", "replicas": 2.0, "tags": ["a"], "spec": {"port": 80, "tls": {}}, "new": null}`)
+	changes, err := jsonpath.Diff(before, after)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []jsonpath.Change{
+		{Path: "$.old", Kind: jsonpath.ChangeRemoved, Old: true},
+		{Path: "$.replicas", Kind: jsonpath.ChangeModified, Old: 1.0, New: 2.0},
+		{Path: "$.spec.tls", Kind: jsonpath.ChangeModified, Old: false, New: map[string]interface{}{}},
+		{Path: "$.tags[1]", Kind: jsonpath.ChangeRemoved, Old: "b"},
+		{Path: "$.new", Kind: jsonpath.ChangeAdded},
+	}
+	if !reflect.DeepEqual(changes, want) {
+		t.Errorf("expected %v, got %v", want, changes)
+	}
+
+	b, err := json.Marshal(changes[0])
+	if err != nil || string(b) != `{"path":"$.old","kind":"removed","old":true,"new":null}` {
+		t.Errorf("unexpected JSON: %s, %v", b, err)
+	}
+
+	changes, err = jsonpath.Diff([]byte(`{"n": 1}`), []byte(`{"n": 1.0}`))
+	if err != nil || len(changes) != 0 {
+		t.Errorf("expected equal numbers to compare equal, got %v, %v", changes, err)
+	}
+}
+
+func TestDiffAt(t *testing.T) {
+	before := []byte(`{"items": [{"id": 1, "qty": 1}, {"id": 2}], "updated": 1}`)
+	after := []byte(`{"items": [{"id": 1, "qty": 3}], "updated": 2}`)
+	changes, err := jsonpath.DiffAt(before, after, "$.items[*]")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(changes) != 2 || changes[0].Path != "$.items[0].qty" || changes[1].Path != "$.items[1]" || changes[1].Kind != jsonpath.ChangeRemoved {
+		t.Errorf("unexpected changes: %v", changes)
+	}
+
+	if _, err := jsonpath.DiffAt(before, []byte(`{`), "$"); !jsonpath.IsJSONError(err) {
+		t.Errorf("expected ErrInvalidJSON, got %v", err)
+	}
+	if _, err := jsonpath.DiffAt(before, after, "$["); !jsonpath.IsPathError(err) {
+		t.Errorf("expected a path error, got %v", err)
+	}
+}
+
+func TestEqualAt(t *testing.T) {
+	before := []byte(`{"billing": {"plan": "pro", "seats": 5}, "profile": {"name": "ann"}, "tags": ["a"]}`)
+	after := []byte(`{"billing": {"seats": 5.0, "plan": "pro"}, "profile": {"name": "bob"}, "tags": ["a", "b"]}`)
+	tests := []struct {
+		path string
+		want bool
+	}{
+		{"$.billing", true},
+		{"$.profile", false},
+		{"$.tags[0]", true},
+		{"$.tags[*]", false},
+		{"$.missing", true},
+	}
+	for _, tt := range tests {
+		got, err := jsonpath.EqualAt(before, after, tt.path)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.path, err)
+		}
+		if got != tt.want {
+			t.Errorf("%s: expected %v, got %v", tt.path, tt.want, got)
+		}
+	}
+	if _, err := jsonpath.EqualAt(before, []byte(`[`), "$"); !jsonpath.IsJSONError(err) {
+		t.Errorf("expected ErrInvalidJSON, got %v", err)
+	}
+}
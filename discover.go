@@ -0,0 +1,159 @@
+package jsonpath
+
+import (
+	"context"
+	"strconv"
+)
+
+// WithLeavesOnly makes ListPaths list only leaves: scalars and empty objects
+// and arrays, plus containers at the WithMaxDepth limit and, with
+// WithArrayLeaves, arrays.
+func WithLeavesOnly(leaves bool) Option {
+	return func(e *engine) {
+		e.leavesOnly = leaves
+	}
+}
+
+// ListPaths returns the normalized path of every value in the document below
+// the root, in document order with each container before its members. Paths
+// with more segments than WithMaxDepth allows are left out. It lets UIs
+// build field pickers and agents discover a document's structure before
+// issuing targeted queries.
+//
+// Example:
+//
+//	paths, err := jsonpath.ListPaths(data, jsonpath.WithMaxDepth(3), jsonpath.WithLeavesOnly(true))
+//	// ["$.store.bicycle.color", "$.store.bicycle.price", "$.store.book[0]", ...]
+func ListPaths(data []byte, opts ...Option) ([]string, error) {
+	e := newEngine(context.Background(), opts)
+	doc, err := e.parse(data)
+	if err != nil {
+		return nil, err
+	}
+	var paths []string
+	err = e.walk(plainRoot(doc.root), "$", 0, func(path string, _ interface{}, leaf bool) {
+		if leaf || !e.leavesOnly {
+			paths = append(paths, path)
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	return paths, nil
+}
+
+// walk calls fn with the path and value of every value below node, which is
+// at depth, containers before their members. leaf is set for scalars, empty
+// containers, containers at the WithMaxDepth limit and, under
+// WithArrayLeaves, arrays; walk does not descend into leaves.
+func (e *engine) walk(node interface{}, path string, depth int, fn func(path string, v interface{}, leaf bool)) error {
+	if err := e.visit(path, node); err != nil {
+		return err
+	}
+	atLimit := e.maxDepth > 0 && depth+1 >= e.maxDepth
+	if atLimit && e.logger != nil && !isLeaf(node) {
+		e.debug("jsonpath: depth limit reached, members are reported as leaves", "path", path, "max_depth", e.maxDepth)
+	}
+	step := func(child interface{}, childPath string) error {
+		_, isArray := arrayOf(child)
+		leaf := atLimit || isLeaf(child) || (e.arrayLeaves && isArray)
+		fn(childPath, child, leaf)
+		if leaf {
+			return nil
+		}
+		return e.walk(child, childPath, depth+1, fn)
+	}
+	if obj, ok := objectOf(node); ok {
+		for _, k := range obj.Keys() {
+			v, _ := obj.Get(k)
+			if err := step(v, childPath(path, k)); err != nil {
+				return err
+			}
+		}
+	} else if arr, ok := arrayOf(node); ok {
+		for i := 0; i < arr.Len(); i++ {
+			if err := step(arr.Index(i), indexPath(path, i)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// isLeaf reports whether node is a scalar or an empty object or array.
+func isLeaf(node interface{}) bool {
+	if obj, ok := objectOf(node); ok {
+		return len(obj.Keys()) == 0
+	}
+	if arr, ok := arrayOf(node); ok {
+		return arr.Len() == 0
+	}
+	return true
+}
+
+// Keys returns the member names of each object path matches, in the order
+// wildcards visit them, and the indices of each array as decimal strings.
+// Matches that are not containers are skipped. It suits discovering which
+// fields exist under a path without running a wildcard query and parsing
+// the result paths.
+//
+// Example:
+//
+//	keys, err := jsonpath.Keys(data, "$.metadata.annotations")
+//	if err == nil && len(keys) == 1 {
+//	    fmt.Println(keys[0])
+//	}
+func Keys(data []byte, path string, opts ...Option) ([][]string, error) {
+	var keys [][]string
+	err := QueryFunc(context.Background(), data, path, func(r Result) error {
+		if k, ok := memberNames(decodedValue(r.Value)); ok {
+			keys = append(keys, k)
+		}
+		return nil
+	}, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return keys, nil
+}
+
+// IndicesOf returns the array index of each match of path that is an array
+// element, in match order, such as the positions of the elements a filter
+// selects. Matches that are not array elements, such as the root or object
+// members, are skipped. The indices suit targeted JSON Patch operations on
+// the array the path selects from.
+//
+// Example:
+//
+//	idx, err := jsonpath.IndicesOf(data, "$.items[?(@.flag)]")
+//	// idx == []int{0, 3}: patch /items/3 first so /items/0 stays valid
+func IndicesOf(data []byte, path string, opts ...Option) ([]int, error) {
+	indices := []int{}
+	err := QueryFunc(context.Background(), data, path, func(r Result) error {
+		segs, err := ParsePath(r.Path)
+		if err == nil && len(segs) > 0 && segs[len(segs)-1].Kind == SegmentIndex {
+			indices = append(indices, segs[len(segs)-1].Index)
+		}
+		return nil
+	}, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return indices, nil
+}
+
+// memberNames returns the member names of an object node or the indices of
+// an array node.
+func memberNames(node interface{}) ([]string, bool) {
+	if obj, ok := objectOf(node); ok {
+		return append([]string{}, obj.Keys()...), true
+	}
+	if arr, ok := arrayOf(node); ok {
+		names := make([]string, arr.Len())
+		for i := range names {
+			names[i] = strconv.Itoa(i)
+		}
+		return names, true
+	}
+	return nil, false
+}
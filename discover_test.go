@@ -0,0 +1,88 @@
+package jsonpath_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/njchilds90/go-jsonpath"
+)
+
+func TestKeys(t *testing.T) {
+	keys, err := jsonpath.Keys(sampleJSON, "$.store")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(keys, [][]string{{"bicycle", "book"}}) {
+		t.Errorf("unexpected keys: %v", keys)
+	}
+
+	keys, err = jsonpath.Keys(sampleJSON, "$.store.*", jsonpath.WithRawValues())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(keys, [][]string{{"color", "price"}, {"0", "1", "2", "3"}}) {
+		t.Errorf("unexpected keys: %v", keys)
+	}
+
+	keys, err = jsonpath.Keys([]byte(`{"a": {}, "b": 1}`), "$.*")
+	if err != nil || !reflect.DeepEqual(keys, [][]string{{}}) {
+		t.Errorf("expected scalars to be skipped, got %v, %v", keys, err)
+	}
+}
+
+func TestIndicesOf(t *testing.T) {
+	tests := []struct {
+		path string
+		want []int
+	}{
+		{"$.store.book[?(@.isbn)]", []int{2, 3}},
+		{"$.store.book[-1]", []int{3}},
+		{"$.store.book[*].price", []int{}},
+		{"$..[?(@.price < 10)]", []int{0, 2}},
+		{"$.store.bicycle", []int{}},
+		{"$.nope", []int{}},
+	}
+	for _, tt := range tests {
+		got, err := jsonpath.IndicesOf(sampleJSON, tt.path)
+		if err != nil || !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: expected %v, got %v (%v)", tt.path, tt.want, got, err)
+		}
+	}
+
+	got, err := jsonpath.IndicesOf([]byte(`{"a": [{"n": 1}, {"n": 2}], "b": {"c": {"n": 3}}}`), "$..[?(@.n > 1)]")
+	if err != nil || !reflect.DeepEqual(got, []int{1}) {
+		t.Errorf("expected [1], got %v (%v)", got, err)
+	}
+	if _, err := jsonpath.IndicesOf(sampleJSON, "$.store.book[?(@.price >]"); err == nil {
+		t.Error("expected an error for an invalid path")
+	}
+}
+
+func TestListPaths(t *testing.T) {
+	data := []byte(`{"a": {"b": [1, {"c": null}], "e": {}}, "f": "x"}`)
+	tests := []struct {
+		opts []jsonpath.Option
+		want []string
+	}{
+		{nil, []string{"$.a", "$.a.b", "$.a.b[0]", "$.a.b[1]", "$.a.b[1].c", "$.a.e", "$.f"}},
+		{[]jsonpath.Option{jsonpath.WithLeavesOnly(true)}, []string{"$.a.b[0]", "$.a.b[1].c", "$.a.e", "$.f"}},
+		{[]jsonpath.Option{jsonpath.WithMaxDepth(2)}, []string{"$.a", "$.a.b", "$.a.e", "$.f"}},
+		{[]jsonpath.Option{jsonpath.WithMaxDepth(2), jsonpath.WithLeavesOnly(true)}, []string{"$.a.b", "$.a.e", "$.f"}},
+	}
+	for i, tt := range tests {
+		paths, err := jsonpath.ListPaths(data, tt.opts...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(paths, tt.want) {
+			t.Errorf("case %d: expected %v, got %v", i, tt.want, paths)
+		}
+	}
+
+	if _, err := jsonpath.ListPaths(data, jsonpath.WithMaxNodes(3)); !jsonpath.IsBudgetExceeded(err) {
+		t.Errorf("expected ErrBudgetExceeded, got %v", err)
+	}
+	if _, err := jsonpath.ListPaths([]byte(`{`)); !jsonpath.IsJSONError(err) {
+		t.Errorf("expected ErrInvalidJSON, got %v", err)
+	}
+}
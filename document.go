@@ -0,0 +1,229 @@
+package jsonpath
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"math/big"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Document is a decoded JSON value that can be queried by any number of
+// paths without being decoded again. A Document is never modified by
+// queries and is safe for concurrent use.
+type Document struct {
+	root     interface{}
+	raw      []byte // source text, when the document was parsed from bytes
+	inserted []int  // offsets of bytes added to raw when it was made strict
+
+	spansOnce sync.Once
+	spans     map[string]span
+}
+
+// Parse decodes a JSON document once so that many paths can be run against
+// it without unmarshalling the input again.
+//
+// Example:
+//
+//	doc, err := jsonpath.Parse(data)
+//	if err != nil {
+//	    return err
+//	}
+//	titles, _ := titlePath.QueryDocument(doc)
+//	prices, _ := pricePath.QueryDocument(doc)
+//
+// Decoding options such as WithLenientJSON, WithUseNumber, WithExactNumbers
+// and WithDecoder are honoured; other options have no effect here and are
+// passed to the queries instead.
+func Parse(data []byte, opts ...Option) (*Document, error) {
+	return newEngine(context.Background(), opts).parse(data)
+}
+
+// parse decodes data as configured by the engine's decoding options.
+func (e *engine) parse(data []byte) (*Document, error) {
+	text := data
+	var inserted []int
+	if e.lenient {
+		var err error
+		if text, inserted, err = strictJSON(data); err != nil {
+			return nil, &Error{Code: ErrInvalidJSON, Message: "failed to parse JSON", Cause: err}
+		}
+	}
+	var root interface{}
+	var err error
+	if e.decode != nil {
+		root, err = e.decode(text)
+	} else {
+		root, err = e.unmarshal(text)
+	}
+	if err != nil {
+		return nil, &Error{Code: ErrInvalidJSON, Message: "failed to parse JSON", Cause: err}
+	}
+	return &Document{root: root, raw: text, inserted: inserted}, nil
+}
+
+// unmarshal decodes text as json.Unmarshal does, keeping numbers as
+// json.Number under WithUseNumber.
+func (e *engine) unmarshal(text []byte) (interface{}, error) {
+	var v interface{}
+	if !e.useNumber {
+		err := json.Unmarshal(text, &v)
+		return v, err
+	}
+	dec := json.NewDecoder(bytes.NewReader(text))
+	dec.UseNumber()
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return nil, errors.New("invalid data after top-level value")
+	}
+	if e.exactNumbers {
+		v = exactNumbers(v)
+	}
+	return v, nil
+}
+
+// exactNumbers replaces the json.Number values in v, in place, with the
+// exact types WithExactNumbers describes.
+func exactNumbers(v interface{}) interface{} {
+	switch x := v.(type) {
+	case map[string]interface{}:
+		for k, child := range x {
+			x[k] = exactNumbers(child)
+		}
+	case []interface{}:
+		for i, child := range x {
+			x[i] = exactNumbers(child)
+		}
+	case json.Number:
+		return decodeExactNumber(string(x))
+	}
+	return v
+}
+
+// decodeExactNumber converts the JSON number text s to int64, *big.Int, float64
+// or *big.Float, whichever holds it without rounding.
+func decodeExactNumber(s string) interface{} {
+	if !strings.ContainsAny(s, ".eE") {
+		if n, err := strconv.ParseInt(s, 10, 64); err == nil {
+			return n
+		}
+		if n, ok := new(big.Int).SetString(s, 10); ok {
+			return n
+		}
+	}
+	f, err := strconv.ParseFloat(s, 64)
+	if err == nil {
+		exact, _ := new(big.Rat).SetString(s)
+		shortest, _ := new(big.Rat).SetString(strconv.FormatFloat(f, 'g', -1, 64))
+		if exact.Cmp(shortest) == 0 {
+			return f
+		}
+	}
+	// enough bits for every significant digit
+	prec := uint(len(s))*4 + 64
+	if n, _, err := big.ParseFloat(s, 10, prec, big.ToNearestEven); err == nil {
+		return n
+	}
+	return json.Number(s)
+}
+
+// FromDecoder reads exactly one JSON value from dec and returns it as a
+// Document. Settings already applied to the decoder, such as UseNumber,
+// are respected, and any further values remain in the decoder for the
+// next call. At the end of the input the returned error satisfies
+// errors.Is(err, io.EOF).
+//
+// Example:
+//
+//	dec := json.NewDecoder(req.Body)
+//	dec.UseNumber()
+//	doc, err := jsonpath.FromDecoder(dec)
+//	if err != nil {
+//	    return err
+//	}
+//	results, err := doc.Query("$.items[*].id")
+func FromDecoder(dec *json.Decoder) (*Document, error) {
+	if dec == nil {
+		return nil, &Error{Code: ErrInvalidInput, Message: "decoder must not be nil"}
+	}
+	var root interface{}
+	if err := dec.Decode(&root); err != nil {
+		return nil, &Error{Code: ErrInvalidJSON, Message: "failed to parse JSON", Cause: err}
+	}
+	return &Document{root: root}, nil
+}
+
+// Value returns the decoded root value of the document.
+func (d *Document) Value() interface{} {
+	return d.root
+}
+
+// Query executes a JSONPath expression against the document.
+func (d *Document) Query(path string, opts ...Option) ([]Result, error) {
+	return d.QueryContext(context.Background(), path, opts...)
+}
+
+// QueryContext executes a JSONPath expression against the document with context support.
+func (d *Document) QueryContext(ctx context.Context, path string, opts ...Option) ([]Result, error) {
+	if ctx == nil {
+		return nil, &Error{Code: ErrInvalidInput, Message: "context must not be nil"}
+	}
+	e := newEngine(ctx, opts)
+	cp, err := e.compile(path)
+	if err != nil {
+		return nil, err
+	}
+	e.attach(d)
+	return e.collect(d.root, cp.tokens)
+}
+
+// QueryFunc executes a JSONPath expression against the document, calling fn
+// for each match. See the package-level QueryFunc for the semantics of fn.
+func (d *Document) QueryFunc(ctx context.Context, path string, fn func(Result) error, opts ...Option) error {
+	if ctx == nil {
+		return &Error{Code: ErrInvalidInput, Message: "context must not be nil"}
+	}
+	e := newEngine(ctx, opts)
+	cp, err := e.compile(path)
+	if err != nil {
+		return err
+	}
+	e.attach(d)
+	return e.stream(d.root, cp.tokens, fn)
+}
+
+// QueryDocument executes the pre-compiled path against a Document.
+func (cp *CompiledPath) QueryDocument(doc *Document, opts ...Option) ([]Result, error) {
+	return cp.QueryDocumentContext(context.Background(), doc, opts...)
+}
+
+// QueryDocumentContext executes the pre-compiled path against a Document with context.
+func (cp *CompiledPath) QueryDocumentContext(ctx context.Context, doc *Document, opts ...Option) ([]Result, error) {
+	if ctx == nil {
+		return nil, &Error{Code: ErrInvalidInput, Message: "context must not be nil"}
+	}
+	if doc == nil {
+		return nil, &Error{Code: ErrInvalidInput, Message: "document must not be nil"}
+	}
+	e := newEngine(ctx, opts)
+	e.attach(doc)
+	return e.collect(doc.root, cp.tokens)
+}
+
+// spanIndex returns the byte spans of every value in the source text, keyed
+// by normalized path. It is built on first use; documents without source
+// text have none.
+func (d *Document) spanIndex() map[string]span {
+	d.spansOnce.Do(func() {
+		if d.raw != nil {
+			d.spans = indexSpans(d.raw)
+		}
+	})
+	return d.spans
+}
@@ -0,0 +1,119 @@
+package jsonpath_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/njchilds90/go-jsonpath"
+)
+
+func TestFromDecoder(t *testing.T) {
+	dec := json.NewDecoder(strings.NewReader(`{"id": 9007199254740993} {"id": 2}`))
+	dec.UseNumber()
+
+	doc, err := jsonpath.FromDecoder(dec)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	results, err := doc.Query("$.id")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if n, ok := results[0].Value.(json.Number); !ok || n.String() != "9007199254740993" {
+		t.Errorf("expected json.Number from UseNumber decoder, got %T %v", results[0].Value, results[0].Value)
+	}
+
+	// The second value is left in the decoder for the next call.
+	doc, err = jsonpath.FromDecoder(dec)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	cp := jsonpath.MustCompile("$.id")
+	results, err = cp.QueryDocument(doc)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(results) != 1 || results[0].Value.(json.Number).String() != "2" {
+		t.Errorf("unexpected results: %v", results)
+	}
+
+	_, err = jsonpath.FromDecoder(dec)
+	if !errors.Is(err, io.EOF) || !jsonpath.IsJSONError(err) {
+		t.Errorf("expected wrapped io.EOF at end of input, got: %v", err)
+	}
+}
+
+func TestFromDecoderInvalid(t *testing.T) {
+	_, err := jsonpath.FromDecoder(json.NewDecoder(strings.NewReader(`{"a":`)))
+	if !jsonpath.IsJSONError(err) {
+		t.Errorf("expected JSON error, got: %v", err)
+	}
+}
+
+func TestParseQueriedManyTimes(t *testing.T) {
+	doc, err := jsonpath.Parse(sampleJSON)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		path string
+		want int
+	}{
+		{"$..author", 4},
+		{"$..price", 5},
+		{"$.store.bicycle.color", 1},
+	}
+	for _, tt := range tests {
+		results, err := jsonpath.MustCompile(tt.path).QueryDocument(doc)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", tt.path, err)
+		}
+		if len(results) != tt.want {
+			t.Errorf("%s: expected %d results, got %d", tt.path, tt.want, len(results))
+		}
+	}
+
+	var n int
+	err = doc.QueryFunc(context.Background(), "$..price", func(jsonpath.Result) error {
+		n++
+		return nil
+	})
+	if err != nil || n != 5 {
+		t.Errorf("QueryFunc: n=%d err=%v", n, err)
+	}
+
+	if _, err := jsonpath.Parse([]byte("nope")); !jsonpath.IsJSONError(err) {
+		t.Errorf("expected JSON error, got: %v", err)
+	}
+}
+
+func TestDocumentNilContext(t *testing.T) {
+	doc, err := jsonpath.Parse(sampleJSON)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var ctx context.Context
+	if _, err := doc.QueryContext(ctx, "$..price"); !isInputError(err) {
+		t.Errorf("QueryContext: expected invalid input error, got %v", err)
+	}
+	if err := doc.QueryFunc(ctx, "$..price", func(jsonpath.Result) error { return nil }); !isInputError(err) {
+		t.Errorf("QueryFunc: expected invalid input error, got %v", err)
+	}
+	if _, err := jsonpath.MustCompile("$..price").QueryDocumentContext(ctx, doc); !isInputError(err) {
+		t.Errorf("QueryDocumentContext: expected invalid input error, got %v", err)
+	}
+}
+
+func BenchmarkParsedDocument(b *testing.B) {
+	doc, _ := jsonpath.Parse(sampleJSON)
+	cp := jsonpath.MustCompile("$..price")
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, _ = cp.QueryDocument(doc)
+	}
+}
@@ -1,8 +1,18 @@
 package jsonpath
 
-import "fmt"
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+)
 
 // ErrorCode identifies the category of a JSONPath error.
+//
+// The numeric values follow declaration order and are not stable across
+// releases; persist or transmit the name String returns instead. Each code is
+// also a sentinel error, so errors.Is(err, jsonpath.ErrKeyNotFound) reports
+// whether err, or any error it wraps or joins, is an *Error with that code.
 type ErrorCode int
 
 const (
@@ -22,10 +32,75 @@ const (
 	ErrTypeMismatch
 	// ErrMaxDepthExceeded indicates the recursive descent exceeded the configured depth limit.
 	ErrMaxDepthExceeded
-	// ErrCancelled indicates the context was cancelled.
+	// ErrCancelled indicates the context was cancelled or the WithTimeout
+	// deadline passed.
 	ErrCancelled
+	// ErrBudgetExceeded indicates evaluation hit the WithMaxNodes or WithBudget limit.
+	ErrBudgetExceeded
+	// ErrUnsupported indicates a path uses constructs the Transpile target cannot express.
+	ErrUnsupported
+	// ErrNoMatch indicates a path that must match exactly one node matched none.
+	ErrNoMatch
+	// ErrMultipleMatches indicates a path that must match exactly one node matched several.
+	ErrMultipleMatches
+	// ErrLimitExceeded indicates a path exceeded WithMaxPathLength,
+	// WithMaxSelectors or WithMaxFilterDepth.
+	ErrLimitExceeded
 )
 
+var errorCodeNames = map[ErrorCode]string{
+	ErrInvalidPath:      "invalid_path",
+	ErrInvalidJSON:      "invalid_json",
+	ErrInvalidFilter:    "invalid_filter",
+	ErrInvalidInput:     "invalid_input",
+	ErrKeyNotFound:      "key_not_found",
+	ErrIndexOutOfBounds: "index_out_of_bounds",
+	ErrTypeMismatch:     "type_mismatch",
+	ErrMaxDepthExceeded: "max_depth_exceeded",
+	ErrCancelled:        "cancelled",
+	ErrBudgetExceeded:   "budget_exceeded",
+	ErrUnsupported:      "unsupported",
+	ErrNoMatch:          "no_match",
+	ErrMultipleMatches:  "multiple_matches",
+	ErrLimitExceeded:    "limit_exceeded",
+}
+
+// String returns the stable name of the code, such as "invalid_path" or
+// "key_not_found".
+func (c ErrorCode) String() string {
+	if name, ok := errorCodeNames[c]; ok {
+		return name
+	}
+	return "ErrorCode(" + strconv.Itoa(int(c)) + ")"
+}
+
+// Error implements the error interface so codes can be used as sentinels
+// with errors.Is.
+func (c ErrorCode) Error() string {
+	return "jsonpath: " + c.String()
+}
+
+// MarshalText implements encoding.TextMarshaler, encoding the code by name.
+func (c ErrorCode) MarshalText() ([]byte, error) {
+	return []byte(c.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler, accepting the names
+// String returns.
+func (c *ErrorCode) UnmarshalText(text []byte) error {
+	for code, name := range errorCodeNames {
+		if name == string(text) {
+			*c = code
+			return nil
+		}
+	}
+	return &Error{Code: ErrInvalidInput, Message: fmt.Sprintf("unknown error code %q", text)}
+}
+
+// ErrStop can be returned from a QueryFunc callback to end the query early.
+// The query then returns nil rather than ErrStop.
+var ErrStop = errors.New("jsonpath: stop iteration")
+
 // Error is the structured error type returned by all jsonpath operations.
 // Use Code to programmatically distinguish error categories.
 type Error struct {
@@ -35,6 +110,19 @@ type Error struct {
 	Message string
 	// Cause is the underlying error, if any.
 	Cause error
+	// Path is the normalized path of the node at which a strict-mode lookup
+	// failed; it is empty for other errors.
+	Path string
+	// Offset is the byte offset in the path at which parsing failed, for
+	// ErrInvalidPath and ErrInvalidFilter errors from compiling a path.
+	Offset int
+	// Snippet shows the path with a caret under Offset, e.g.
+	//
+	//	$.a[?(@.b = 1)]
+	//	          ^
+	//
+	// It is empty when the error has no offset.
+	Snippet string
 }
 
 // Error implements the error interface.
@@ -45,6 +133,40 @@ func (e *Error) Error() string {
 	return fmt.Sprintf("jsonpath: %s", e.Message)
 }
 
+// MarshalJSON implements json.Marshaler so services can relay errors without
+// parsing their text. The object has the code and message, the offset and
+// snippet of a syntax error, the path of a strict-mode lookup, and the text
+// of the cause; members that do not apply are omitted.
+//
+// Example output:
+//
+//	{"code":"invalid_filter","message":"cannot parse filter expression \"@.b = 1\": unexpected \"= 1\"","offset":10,"snippet":"$.a[?(@.b = 1)]\n          ^"}
+func (e *Error) MarshalJSON() ([]byte, error) {
+	out := struct {
+		Code    ErrorCode `json:"code"`
+		Message string    `json:"message"`
+		Offset  *int      `json:"offset,omitempty"`
+		Snippet string    `json:"snippet,omitempty"`
+		Path    string    `json:"path,omitempty"`
+		Cause   string    `json:"cause,omitempty"`
+	}{Code: e.Code, Message: e.Message, Snippet: e.Snippet, Path: e.Path}
+	if e.Snippet != "" {
+		offset := e.Offset
+		out.Offset = &offset
+	}
+	if e.Cause != nil {
+		out.Cause = e.Cause.Error()
+	}
+	return json.Marshal(out)
+}
+
+// Is reports whether target is the ErrorCode of e, so that errors.Is matches
+// codes used as sentinels.
+func (e *Error) Is(target error) bool {
+	code, ok := target.(ErrorCode)
+	return ok && code == e.Code
+}
+
 // Unwrap returns the underlying cause, supporting errors.Is and errors.As chains.
 func (e *Error) Unwrap() error {
 	return e.Cause
@@ -52,40 +174,50 @@ func (e *Error) Unwrap() error {
 
 // IsPathError returns true if err is a jsonpath path syntax error.
 func IsPathError(err error) bool {
-	if e, ok := err.(*Error); ok {
-		return e.Code == ErrInvalidPath
-	}
-	return false
+	return errors.Is(err, ErrInvalidPath)
 }
 
 // IsJSONError returns true if err is a JSON parsing error.
 func IsJSONError(err error) bool {
-	if e, ok := err.(*Error); ok {
-		return e.Code == ErrInvalidJSON
-	}
-	return false
+	return errors.Is(err, ErrInvalidJSON)
 }
 
 // IsFilterError returns true if err is a filter expression error.
 func IsFilterError(err error) bool {
-	if e, ok := err.(*Error); ok {
-		return e.Code == ErrInvalidFilter
-	}
-	return false
+	return errors.Is(err, ErrInvalidFilter)
 }
 
 // IsNotFound returns true if err indicates a missing key or out-of-bounds index (strict mode).
 func IsNotFound(err error) bool {
-	if e, ok := err.(*Error); ok {
-		return e.Code == ErrKeyNotFound || e.Code == ErrIndexOutOfBounds
-	}
-	return false
+	return errors.Is(err, ErrKeyNotFound) || errors.Is(err, ErrIndexOutOfBounds)
 }
 
 // IsCancelled returns true if err is a context cancellation error.
 func IsCancelled(err error) bool {
-	if e, ok := err.(*Error); ok {
-		return e.Code == ErrCancelled
-	}
-	return false
+	return errors.Is(err, ErrCancelled)
+}
+
+// IsBudgetExceeded returns true if err indicates a node or time budget was exhausted.
+func IsBudgetExceeded(err error) bool {
+	return errors.Is(err, ErrBudgetExceeded)
+}
+
+// IsUnsupported returns true if err indicates a path cannot be transpiled to the requested target.
+func IsUnsupported(err error) bool {
+	return errors.Is(err, ErrUnsupported)
+}
+
+// IsNoMatch returns true if err indicates a path that must match one node matched none.
+func IsNoMatch(err error) bool {
+	return errors.Is(err, ErrNoMatch)
+}
+
+// IsMultipleMatches returns true if err indicates a path that must match one node matched several.
+func IsMultipleMatches(err error) bool {
+	return errors.Is(err, ErrMultipleMatches)
+}
+
+// IsLimitExceeded returns true if err indicates a path was rejected for exceeding a path limit.
+func IsLimitExceeded(err error) bool {
+	return errors.Is(err, ErrLimitExceeded)
 }
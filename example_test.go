@@ -1,6 +1,7 @@
 package jsonpath_test
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"log"
@@ -114,3 +115,36 @@ func ExampleResult_MarshalJSON() {
 	// Output:
 	// {"path":"$.key","value":"value"}
 }
+
+func ExampleQueryFunc() {
+	data := []byte(`{"events":[{"id":1},{"id":2},{"id":3}]}`)
+
+	err := jsonpath.QueryFunc(context.Background(), data, "$.events[*].id", func(r jsonpath.Result) error {
+		fmt.Println(r.Path, r.Value)
+		if r.Value == 2.0 {
+			return jsonpath.ErrStop
+		}
+		return nil
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+	// Output:
+	// $.events[0].id 1
+	// $.events[1].id 2
+}
+
+func ExampleCompiledPath_Explain() {
+	plan := jsonpath.MustCompile("$..book[?(@.price < 10)].title").Explain()
+	for _, step := range plan.Steps {
+		fmt.Printf("%-10s %s\n", step.Kind, step.Selector)
+	}
+	fmt.Println("cost:", plan.Cost)
+	// Output:
+	// root       $
+	// descendant ..
+	// child      .book
+	// filter     [?(@.price < 10)]
+	// child      .title
+	// cost: full-scan
+}
@@ -0,0 +1,343 @@
+package jsonpath
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// CostClass is a coarse, input-independent estimate of how expensive a path
+// is to evaluate. Classes are ordered, so they can be compared with < and >.
+type CostClass int
+
+const (
+	// CostConstant paths perform a fixed number of lookups regardless of input size.
+	CostConstant CostClass = iota
+	// CostLinear paths visit every element of one or more arrays or objects (wildcards, slices).
+	CostLinear
+	// CostFilter paths evaluate a filter expression once per candidate element.
+	CostFilter
+	// CostFullScan paths use recursive descent and visit every node below their start.
+	CostFullScan
+)
+
+var costClassNames = [...]string{"constant", "linear", "filter", "full-scan"}
+
+// String returns the name of the cost class.
+func (c CostClass) String() string {
+	if c >= 0 && int(c) < len(costClassNames) {
+		return costClassNames[c]
+	}
+	return "CostClass(" + strconv.Itoa(int(c)) + ")"
+}
+
+// MarshalText implements encoding.TextMarshaler so plans encode readably.
+func (c CostClass) MarshalText() ([]byte, error) {
+	return []byte(c.String()), nil
+}
+
+// Plan describes how a compiled path will be evaluated, without running it.
+type Plan struct {
+	// Path is the original path expression.
+	Path string `json:"path"`
+	// Steps lists the selectors in evaluation order, starting with the root.
+	Steps []PlanStep `json:"steps"`
+	// Descendant reports whether evaluation needs a recursive descent scan.
+	Descendant bool `json:"descendant"`
+	// Singular reports whether the path can match at most one node.
+	Singular bool `json:"singular"`
+	// Cost is the most expensive cost class of any step.
+	Cost CostClass `json:"cost"`
+}
+
+// PlanStep is one selector of a Plan.
+type PlanStep struct {
+	// Kind is the selector kind: root, child, descendant, wildcard, index,
+	// slice, union, filter, alternatives, function, parent, names, pattern
+	// or default.
+	Kind string `json:"kind"`
+	// Selector is the selector in path syntax, e.g. ".store", "[0]" or "[?(@.price < 10)]".
+	Selector string `json:"selector"`
+	// Cost is the cost class contributed by this step.
+	Cost CostClass `json:"cost"`
+}
+
+// Explain returns the evaluation plan of the compiled path. It is cheap and
+// does not touch any document, so it suits linting and review tooling.
+//
+// Example:
+//
+//	plan := jsonpath.MustCompile("$..book[?(@.price < 10)]").Explain()
+//	if plan.Cost >= jsonpath.CostFullScan {
+//	    log.Printf("%s scans the whole document", plan.Path)
+//	}
+func (cp *CompiledPath) Explain() Plan {
+	plan := Plan{Path: cp.raw, Singular: true}
+	for _, tok := range cp.tokens {
+		step := PlanStep{Kind: tok.kind.String(), Selector: tok.String(), Cost: tok.cost()}
+		plan.Steps = append(plan.Steps, step)
+		if step.Cost > plan.Cost {
+			plan.Cost = step.Cost
+		}
+		switch tok.kind {
+		case tokenRoot, tokenChild, tokenIndex, tokenFunction, tokenParent:
+		default:
+			plan.Singular = false
+		}
+		if hasRecursive(tok) {
+			plan.Descendant = true
+		}
+	}
+	return plan
+}
+
+// Cost classifies the compiled path by its most expensive selector, including
+// paths used inside filter expressions. Gateways can use it to reject or
+// rate-limit expensive user-supplied paths before running them.
+//
+// Example:
+//
+//	cp, err := jsonpath.Compile(userPath)
+//	if err != nil {
+//	    return err
+//	}
+//	if cp.Cost() >= jsonpath.CostFullScan {
+//	    return errors.New("recursive descent is not allowed")
+//	}
+func (cp *CompiledPath) Cost() CostClass {
+	return tokensCost(cp.tokens)
+}
+
+// hasRecursive reports whether tok is, or has an alternative using,
+// recursive descent.
+func hasRecursive(tok token) bool {
+	for _, alt := range tok.alts {
+		for _, t := range alt {
+			if t.kind == tokenRecursive {
+				return true
+			}
+		}
+	}
+	return tok.kind == tokenRecursive
+}
+
+func tokensCost(tokens []token) CostClass {
+	c := CostConstant
+	for _, tok := range tokens {
+		if tc := tok.cost(); tc > c {
+			c = tc
+		}
+	}
+	return c
+}
+
+func (t token) cost() CostClass {
+	switch t.kind {
+	case tokenRecursive:
+		return CostFullScan
+	case tokenFilter:
+		if c := filterCost(t.expr); c > CostFilter {
+			return c
+		}
+		return CostFilter
+	case tokenWildcard, tokenSlice, tokenNames, tokenPattern:
+		return CostLinear
+	case tokenAlternatives, tokenDefault:
+		c := CostConstant
+		for _, alt := range t.alts {
+			if ac := tokensCost(alt); ac > c {
+				c = ac
+			}
+		}
+		return c
+	}
+	return CostConstant
+}
+
+// filterCost returns the most expensive cost class of the paths used in expr.
+func filterCost(expr filterExpr) CostClass {
+	switch x := expr.(type) {
+	case *logicalExpr:
+		l, r := filterCost(x.left), filterCost(x.right)
+		if r > l {
+			return r
+		}
+		return l
+	case *compareExpr:
+		l, r := operandCost(x.left), operandCost(x.right)
+		if r > l {
+			return r
+		}
+		return l
+	case *regexExpr:
+		return operandCost(x.left)
+	case *existsExpr:
+		return operandCost(x.operand)
+	}
+	return CostConstant
+}
+
+func operandCost(op operand) CostClass {
+	switch x := op.(type) {
+	case *pathOperand:
+		return tokensCost(x.tokens)
+	case *callOperand:
+		c := CostConstant
+		for _, a := range x.args {
+			if ac := operandCost(a); ac > c {
+				c = ac
+			}
+		}
+		return c
+	}
+	return CostConstant
+}
+
+var tokenKindNames = map[tokenKind]string{
+	tokenRoot:         "root",
+	tokenChild:        "child",
+	tokenRecursive:    "descendant",
+	tokenWildcard:     "wildcard",
+	tokenIndex:        "index",
+	tokenSlice:        "slice",
+	tokenFilter:       "filter",
+	tokenUnion:        "union",
+	tokenAlternatives: "alternatives",
+	tokenFunction:     "function",
+	tokenParent:       "parent",
+	tokenNames:        "names",
+	tokenPattern:      "pattern",
+	tokenDefault:      "default",
+}
+
+func (k tokenKind) String() string {
+	if name, ok := tokenKindNames[k]; ok {
+		return name
+	}
+	return "tokenKind(" + strconv.Itoa(int(k)) + ")"
+}
+
+// String returns the selector in path syntax.
+func (t token) String() string {
+	switch t.kind {
+	case tokenRoot:
+		return "$"
+	case tokenChild:
+		return formatMember(t.key)
+	case tokenRecursive:
+		return ".."
+	case tokenWildcard:
+		return "[*]"
+	case tokenIndex:
+		return "[" + strconv.Itoa(t.index) + "]"
+	case tokenSlice:
+		parts := make([]string, 0, 3)
+		for i, p := range t.slice {
+			if p != nil {
+				parts = append(parts, strconv.Itoa(*p))
+			} else if i < 2 {
+				parts = append(parts, "")
+			}
+		}
+		return "[" + strings.Join(parts, ":") + "]"
+	case tokenFilter:
+		return "[?(" + t.filter + ")]"
+	case tokenUnion:
+		parts := make([]string, 0, len(t.indices)+len(t.keys))
+		for _, i := range t.indices {
+			parts = append(parts, strconv.Itoa(i))
+		}
+		for _, k := range t.keys {
+			parts = append(parts, quoteKey(k))
+		}
+		return "[" + strings.Join(parts, ",") + "]"
+	case tokenFunction:
+		return "." + t.key + "()"
+	case tokenParent:
+		return "^"
+	case tokenNames:
+		return "~"
+	case tokenPattern:
+		if isRegexToken(t) {
+			return "[" + t.key + "]"
+		}
+		return "." + t.key
+	case tokenAlternatives:
+		parts := make([]string, len(t.alts))
+		for i, alt := range t.alts {
+			parts[i] = formatTokens(alt)
+		}
+		return strings.Join(parts, " | ")
+	case tokenDefault:
+		return formatTokens(t.alts[0]) + " ?? " + formatOperand(t.literal)
+	}
+	return t.kind.String()
+}
+
+// formatTokens returns tokens in path syntax.
+func formatTokens(tokens []token) string {
+	var s strings.Builder
+	for i, tok := range tokens {
+		sel := tok.String()
+		if i > 0 && tokens[i-1].kind == tokenRecursive && strings.HasPrefix(sel, ".") {
+			sel = sel[1:]
+		}
+		s.WriteString(sel)
+	}
+	return s.String()
+}
+
+// formatMember returns the selector for member key: dot notation when the
+// key is a plain ASCII identifier, bracket notation otherwise.
+func formatMember(key string) string {
+	if name, n := readIdentifier(key); n > 0 && name == key && isASCII(key) {
+		return "." + key
+	}
+	return "[" + quoteKey(key) + "]"
+}
+
+// isASCII reports whether s has only ASCII characters. formatMember
+// bracket-quotes other names, although dot notation accepts them, so that
+// paths stay unambiguous to tools with narrower identifier rules.
+func isASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] >= utf8.RuneSelf {
+			return false
+		}
+	}
+	return true
+}
+
+// quoteKey returns key as a single-quoted string literal. Control
+// characters are escaped, so that the literal fits on one line; other
+// characters, emoji included, are written as they are.
+func quoteKey(key string) string {
+	var b strings.Builder
+	b.WriteByte('\'')
+	for i := 0; i < len(key); i++ {
+		switch c := key[i]; c {
+		case '\\', '\'':
+			b.WriteByte('\\')
+			b.WriteByte(c)
+		case '\b':
+			b.WriteString(`\b`)
+		case '\f':
+			b.WriteString(`\f`)
+		case '\n':
+			b.WriteString(`\n`)
+		case '\r':
+			b.WriteString(`\r`)
+		case '\t':
+			b.WriteString(`\t`)
+		default:
+			if c < 0x20 || c == 0x7f {
+				fmt.Fprintf(&b, `\u%04x`, c)
+			} else {
+				b.WriteByte(c)
+			}
+		}
+	}
+	b.WriteByte('\'')
+	return b.String()
+}
@@ -0,0 +1,77 @@
+package jsonpath_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/njchilds90/go-jsonpath"
+)
+
+func TestExplain(t *testing.T) {
+	tests := []struct {
+		path       string
+		cost       jsonpath.CostClass
+		singular   bool
+		descendant bool
+		selectors  []string
+	}{
+		{"$.store.book[0].title", jsonpath.CostConstant, true, false, []string{"$", ".store", ".book", "[0]", ".title"}},
+		{"$.store.book[*].title", jsonpath.CostLinear, false, false, []string{"$", ".store", ".book", "[*]", ".title"}},
+		{"$.book[1:3]", jsonpath.CostLinear, false, false, []string{"$", ".book", "[1:3]"}},
+		{"$.book[?(@.price < 10)]", jsonpath.CostFilter, false, false, []string{"$", ".book", "[?(@.price < 10)]"}},
+		{"$..book[?(@.price < 10)]", jsonpath.CostFullScan, false, true, []string{"$", "..", ".book", "[?(@.price < 10)]"}},
+		{"$['a b','c']", jsonpath.CostConstant, false, false, []string{"$", "['a b','c']"}},
+		{"$['some-key'][::2]", jsonpath.CostLinear, false, false, []string{"$", ".some-key", "[::2]"}},
+	}
+	for _, tt := range tests {
+		plan := jsonpath.MustCompile(tt.path).Explain()
+		if plan.Cost != tt.cost || plan.Singular != tt.singular || plan.Descendant != tt.descendant {
+			t.Errorf("%s: got cost=%s singular=%v descendant=%v", tt.path, plan.Cost, plan.Singular, plan.Descendant)
+		}
+		if len(plan.Steps) != len(tt.selectors) {
+			t.Errorf("%s: expected %d steps, got %d", tt.path, len(tt.selectors), len(plan.Steps))
+			continue
+		}
+		for i, s := range plan.Steps {
+			if s.Selector != tt.selectors[i] {
+				t.Errorf("%s: step %d selector %q, want %q", tt.path, i, s.Selector, tt.selectors[i])
+			}
+		}
+	}
+}
+
+func TestExplainJSON(t *testing.T) {
+	b, err := json.Marshal(jsonpath.MustCompile("$..price").Explain())
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"path":"$..price","steps":[{"kind":"root","selector":"$","cost":"constant"},{"kind":"descendant","selector":"..","cost":"full-scan"},{"kind":"child","selector":".price","cost":"constant"}],"descendant":true,"singular":false,"cost":"full-scan"}`
+	if string(b) != want {
+		t.Errorf("unexpected JSON:\n%s", b)
+	}
+}
+
+func TestCost(t *testing.T) {
+	tests := []struct {
+		path string
+		want jsonpath.CostClass
+	}{
+		{"$", jsonpath.CostConstant},
+		{"$.a.b[3]", jsonpath.CostConstant},
+		{"$.a[0,2]", jsonpath.CostConstant},
+		{"$.a[*]", jsonpath.CostLinear},
+		{"$.a[-3:]", jsonpath.CostLinear},
+		{"$.a[?(@.x)]", jsonpath.CostFilter},
+		{"$.a[?(@.b.c == 'x')]", jsonpath.CostFilter},
+		{"$.a[?(@..x)]", jsonpath.CostFullScan},
+		{"$..a", jsonpath.CostFullScan},
+	}
+	for _, tt := range tests {
+		if got := jsonpath.MustCompile(tt.path).Cost(); got != tt.want {
+			t.Errorf("%s: cost %s, want %s", tt.path, got, tt.want)
+		}
+	}
+	if jsonpath.CostFilter >= jsonpath.CostFullScan || jsonpath.CostLinear >= jsonpath.CostFilter {
+		t.Error("cost classes must be ordered by expense")
+	}
+}
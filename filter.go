@@ -0,0 +1,460 @@
+package jsonpath
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// FilterFunc is a custom function callable from filter expressions, e.g.
+// [?(hasPrefix(@.sku, 'X-'))]. Arguments are the resolved operand values
+// (nil for paths that match nothing). The context is the one the query was
+// started with, so functions can honour deadlines and read request-scoped
+// values such as tenant IDs.
+//
+// A call used on its own is truthy when it returns anything other than
+// nil or false; a call used in a comparison contributes its return value.
+type FilterFunc func(ctx context.Context, args []interface{}) (interface{}, error)
+
+// --- Filter expression AST ---
+
+// filterExpr is a node of a parsed filter expression.
+type filterExpr interface {
+	isFilterExpr()
+}
+
+// logicalExpr is `left && right` or `left || right`.
+type logicalExpr struct {
+	op          string
+	left, right filterExpr
+}
+
+// compareExpr is `left op right` for ==, !=, <, <=, >, >=.
+type compareExpr struct {
+	op          string
+	left, right operand
+}
+
+// regexExpr is `left =~ /pattern/flags`.
+type regexExpr struct {
+	left    operand
+	pattern string
+	flags   string
+	re      *regexp.Regexp
+}
+
+// existsExpr is a bare operand, e.g. `@.isbn` or `isActive(@)`.
+type existsExpr struct {
+	operand operand
+}
+
+func (*logicalExpr) isFilterExpr() {}
+func (*compareExpr) isFilterExpr() {}
+func (*regexExpr) isFilterExpr()   {}
+func (*existsExpr) isFilterExpr()  {}
+
+// operand is a value-producing term of a filter expression.
+type operand interface {
+	isOperand()
+}
+
+// pathOperand is a path relative to the current node, e.g. `@.price`.
+type pathOperand struct {
+	raw    string
+	tokens []token
+}
+
+// literalOperand is a string, number, boolean or null literal.
+type literalOperand struct {
+	value  interface{}
+	number string // exact text of a number, compared under WithUseNumber
+}
+
+// callOperand is a call to a function registered with WithFunction.
+type callOperand struct {
+	name string
+	args []operand
+}
+
+func (*pathOperand) isOperand()    {}
+func (*literalOperand) isOperand() {}
+func (*callOperand) isOperand()    {}
+
+// --- Filter expression parser ---
+
+// parseFilter parses a filter expression (the text between `[?(` and `)]`).
+//
+// Grammar:
+//
+//	or         = and *( "||" and )
+//	and        = primary *( "&&" primary )
+//	primary    = "(" or ")" / comparison
+//	comparison = operand [ ( cmp-op operand ) / ( "=~" regex ) ]
+//	operand    = "@" path / string / number / true / false / null / ":" name / name "(" [ operand *( "," operand ) ] ")"
+func parseFilter(src string) (filterExpr, error) {
+	p := &filterParser{src: src}
+	expr, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	p.skipSpace()
+	if p.pos < len(p.src) {
+		return nil, p.errorf("unexpected %q", p.src[p.pos:])
+	}
+	return expr, nil
+}
+
+type filterParser struct {
+	src string
+	pos int
+}
+
+func (p *filterParser) errorf(format string, args ...interface{}) error {
+	return &Error{Code: ErrInvalidFilter, Message: fmt.Sprintf("cannot parse filter expression %q: %s", p.src, fmt.Sprintf(format, args...)), Offset: p.pos}
+}
+
+func (p *filterParser) skipSpace() {
+	for p.pos < len(p.src) && (p.src[p.pos] == ' ' || p.src[p.pos] == '\t' || p.src[p.pos] == '\n' || p.src[p.pos] == '\r') {
+		p.pos++
+	}
+}
+
+// accept consumes s if it is next in the input.
+func (p *filterParser) accept(s string) bool {
+	p.skipSpace()
+	if strings.HasPrefix(p.src[p.pos:], s) {
+		p.pos += len(s)
+		return true
+	}
+	return false
+}
+
+func (p *filterParser) parseOr() (filterExpr, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.accept("||") {
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = &logicalExpr{op: "||", left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *filterParser) parseAnd() (filterExpr, error) {
+	left, err := p.parsePrimary()
+	if err != nil {
+		return nil, err
+	}
+	for p.accept("&&") {
+		right, err := p.parsePrimary()
+		if err != nil {
+			return nil, err
+		}
+		left = &logicalExpr{op: "&&", left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *filterParser) parsePrimary() (filterExpr, error) {
+	if p.accept("(") {
+		expr, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if !p.accept(")") {
+			return nil, p.errorf("missing ')'")
+		}
+		return expr, nil
+	}
+
+	left, err := p.parseOperand()
+	if err != nil {
+		return nil, err
+	}
+
+	if p.accept("=~") {
+		return p.parseRegex(left)
+	}
+	for _, op := range []string{"==", "!=", "<=", ">=", "<", ">"} {
+		if p.accept(op) {
+			right, err := p.parseOperand()
+			if err != nil {
+				return nil, err
+			}
+			return &compareExpr{op: op, left: left, right: right}, nil
+		}
+	}
+	return &existsExpr{operand: left}, nil
+}
+
+func (p *filterParser) parseRegex(left operand) (filterExpr, error) {
+	p.skipSpace()
+	if p.pos >= len(p.src) || p.src[p.pos] != '/' {
+		return nil, p.errorf("expected /pattern/ after '=~'")
+	}
+	start := p.pos + 1
+	pattern, flags, n, ok := readRegex(p.src[p.pos:])
+	if !ok {
+		return nil, p.errorf("unterminated regex")
+	}
+	p.pos += n
+
+	re, err := compileRegex(pattern, flags)
+	if err != nil {
+		return nil, &Error{Code: ErrInvalidFilter, Message: fmt.Sprintf("invalid regex: %v", err), Offset: start}
+	}
+	return &regexExpr{left: left, pattern: pattern, flags: flags, re: re}, nil
+}
+
+func (p *filterParser) parseOperand() (operand, error) {
+	p.skipSpace()
+	if p.pos >= len(p.src) {
+		return nil, p.errorf("unexpected end of expression")
+	}
+
+	c := p.src[p.pos]
+	switch {
+	case c == '@':
+		return p.parsePath()
+	case c == '\'' || c == '"':
+		s, err := p.parseString()
+		if err != nil {
+			return nil, err
+		}
+		return &literalOperand{value: s}, nil
+	case c == '-' || (c >= '0' && c <= '9'):
+		start := p.pos
+		p.pos++
+		for p.pos < len(p.src) && strings.IndexByte("0123456789.eE+-", p.src[p.pos]) >= 0 {
+			p.pos++
+		}
+		n, err := strconv.ParseFloat(p.src[start:p.pos], 64)
+		if err != nil {
+			return nil, p.errorf("invalid number %q", p.src[start:p.pos])
+		}
+		return &literalOperand{value: n, number: p.src[start:p.pos]}, nil
+	case c == ':':
+		name, advance := readIdentifier(p.src[p.pos+1:])
+		if name == "" {
+			return nil, p.errorf("expected a parameter name after ':'")
+		}
+		p.pos += 1 + advance
+		return &paramOperand{name: name}, nil
+	}
+
+	name, advance := readIdentifier(p.src[p.pos:])
+	if name == "" {
+		return nil, p.errorf("unexpected %q", p.src[p.pos:])
+	}
+	p.pos += advance
+	switch name {
+	case "true":
+		return &literalOperand{value: true}, nil
+	case "false":
+		return &literalOperand{value: false}, nil
+	case "null":
+		return &literalOperand{value: nil}, nil
+	}
+	if !p.accept("(") {
+		return nil, p.errorf("unknown identifier %q", name)
+	}
+	call := &callOperand{name: name}
+	if p.accept(")") {
+		return call, nil
+	}
+	for {
+		arg, err := p.parseOperand()
+		if err != nil {
+			return nil, err
+		}
+		call.args = append(call.args, arg)
+		if p.accept(")") {
+			return call, nil
+		}
+		if !p.accept(",") {
+			return nil, p.errorf("expected ',' or ')' in call to %s", name)
+		}
+	}
+}
+
+// parsePath reads a relative path starting at '@' up to the next operator,
+// whitespace, or closing delimiter outside of brackets.
+func (p *filterParser) parsePath() (operand, error) {
+	start := p.pos
+	depth := 0
+	var quote byte
+	for p.pos < len(p.src) {
+		c := p.src[p.pos]
+		if quote != 0 {
+			if c == '\\' {
+				p.pos++
+			} else if c == quote {
+				quote = 0
+			}
+			p.pos++
+			continue
+		}
+		if depth == 0 && strings.IndexByte(" \t\r\n=!<>&|),", c) >= 0 {
+			break
+		}
+		switch c {
+		case '\'', '"':
+			quote = c
+		case '[':
+			depth++
+		case ']':
+			depth--
+		}
+		p.pos++
+	}
+	raw := p.src[start:p.pos]
+	tokens, err := tokenize("$" + raw[1:])
+	if err != nil {
+		return nil, shiftError(err, start)
+	}
+	if usesParent(tokens) {
+		return nil, &Error{Code: ErrInvalidPath, Message: "the parent selector ^ is not supported in filters", Offset: start}
+	}
+	return &pathOperand{raw: raw, tokens: tokens}, nil
+}
+
+func (p *filterParser) parseString() (string, error) {
+	s, n, ok := readQuoted(p.src[p.pos:])
+	if !ok {
+		return "", p.errorf("unterminated string")
+	}
+	p.pos += n
+	return s, nil
+}
+
+// --- Filter expression evaluation ---
+
+// matchFilter reports whether node satisfies expr.
+func (e *engine) matchFilter(node interface{}, expr filterExpr) (bool, error) {
+	switch x := expr.(type) {
+	case *logicalExpr:
+		left, err := e.matchFilter(node, x.left)
+		if err != nil {
+			return false, err
+		}
+		if x.op == "||" && left {
+			return true, nil
+		}
+		if x.op == "&&" && !left {
+			return false, nil
+		}
+		return e.matchFilter(node, x.right)
+
+	case *compareExpr:
+		lv, lok, err := e.resolveOperand(node, x.left)
+		if err != nil {
+			return false, err
+		}
+		rv, rok, err := e.resolveOperand(node, x.right)
+		if err != nil {
+			return false, err
+		}
+		if !lok || !rok {
+			if e.logger != nil {
+				e.debug("jsonpath: compare", "left", formatOperand(x.left), "op", x.op, "right", formatOperand(x.right), "result", false, "reason", "an operand matched nothing")
+			}
+			return false, nil
+		}
+		ok, err := compareValues(lv, x.op, rv)
+		if e.logger != nil && err == nil {
+			e.debug("jsonpath: compare", "left", formatOperand(x.left), "left_value", lv, "op", x.op, "right", formatOperand(x.right), "right_value", rv, "result", ok)
+		}
+		return ok, err
+
+	case *regexExpr:
+		v, ok, err := e.resolveOperand(node, x.left)
+		if err != nil || !ok {
+			return false, err
+		}
+		s, isString := v.(string)
+		ok = isString && x.re.MatchString(s)
+		if e.logger != nil {
+			e.debug("jsonpath: regex", "left", formatOperand(x.left), "left_value", v, "pattern", x.pattern, "result", ok)
+		}
+		return ok, nil
+
+	case *existsExpr:
+		v, ok, err := e.resolveOperand(node, x.operand)
+		if err != nil || !ok {
+			return false, err
+		}
+		if b, isBool := v.(bool); isBool {
+			if _, isPath := x.operand.(*pathOperand); !isPath {
+				return b, nil
+			}
+		}
+		return v != nil, nil
+	}
+	return false, &Error{Code: ErrInvalidFilter, Message: fmt.Sprintf("unknown filter node %T", expr)}
+}
+
+// resolveOperand returns the value of op evaluated against node. The bool
+// result is false when a path operand matches nothing.
+func (e *engine) resolveOperand(node interface{}, op operand) (interface{}, bool, error) {
+	switch x := op.(type) {
+	case *literalOperand:
+		if e.useNumber && x.number != "" {
+			return json.Number(x.number), true, nil
+		}
+		return x.value, true, nil
+
+	case *pathOperand:
+		// Operand paths are never strict: a missing key simply fails the comparison.
+		var value interface{}
+		found := false
+		err := e.operandEngine().stream(node, x.tokens, func(r Result) error {
+			value, found = r.Value, true
+			return ErrStop
+		})
+		if err != nil {
+			if IsCancelled(err) || IsBudgetExceeded(err) {
+				return nil, false, err
+			}
+			return nil, false, nil
+		}
+		return value, found, nil
+
+	case *callOperand:
+		fn, ok := e.funcs[x.name]
+		if !ok && x.name == "type" && len(x.args) == 1 {
+			return e.typeOf(node, x.args[0])
+		}
+		if !ok {
+			return nil, false, &Error{Code: ErrInvalidFilter, Message: fmt.Sprintf("unknown function %q", x.name)}
+		}
+		args := make([]interface{}, len(x.args))
+		for i, a := range x.args {
+			v, _, err := e.resolveOperand(node, a)
+			if err != nil {
+				return nil, false, err
+			}
+			args[i] = v
+		}
+		ctx, cancel := e.funcContext()
+		v, err := fn(ctx, args)
+		cancel()
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, false, &Error{Code: ErrCancelled, Message: "context cancelled", Cause: err}
+			}
+			return nil, false, &Error{Code: ErrInvalidFilter, Message: fmt.Sprintf("function %s failed", x.name), Cause: err}
+		}
+		return v, true, nil
+
+	case *paramOperand:
+		return nil, false, &Error{Code: ErrInvalidFilter, Message: fmt.Sprintf("parameter :%s is not bound; see CompiledPath.Bind", x.name)}
+	}
+	return nil, false, &Error{Code: ErrInvalidFilter, Message: fmt.Sprintf("unknown operand %T", op)}
+}
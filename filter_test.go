@@ -0,0 +1,110 @@
+package jsonpath_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/njchilds90/go-jsonpath"
+)
+
+type tenantKey struct{}
+
+func TestFilterFunctionReceivesContext(t *testing.T) {
+	var seen []interface{}
+	hasPrefix := func(ctx context.Context, args []interface{}) (interface{}, error) {
+		seen = append(seen, ctx.Value(tenantKey{}))
+		s, _ := args[0].(string)
+		p, _ := args[1].(string)
+		return strings.HasPrefix(s, p), nil
+	}
+
+	ctx := context.WithValue(context.Background(), tenantKey{}, "acme")
+	results, err := jsonpath.QueryContext(ctx, sampleJSON, `$.store.book[?(hasPrefix(@.title, 'S'))].title`,
+		jsonpath.WithFunction("hasPrefix", hasPrefix))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("expected 2 results, got %d: %v", len(results), results)
+	}
+	if len(seen) != 4 || seen[0] != "acme" {
+		t.Errorf("function did not see the query context: %v", seen)
+	}
+}
+
+func TestFilterFunctionInComparison(t *testing.T) {
+	length := func(_ context.Context, args []interface{}) (interface{}, error) {
+		s, _ := args[0].(string)
+		return float64(len(s)), nil
+	}
+	results, err := jsonpath.Query(sampleJSON, `$.store.book[?(len(@.title) < 10)].title`,
+		jsonpath.WithFunction("len", length))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(results) != 1 || results[0].Value != "Moby Dick" {
+		t.Errorf("unexpected results: %v", results)
+	}
+}
+
+func TestFilterFunctionErrors(t *testing.T) {
+	_, err := jsonpath.Query(sampleJSON, `$.store.book[?(missing(@))]`)
+	if !jsonpath.IsFilterError(err) {
+		t.Errorf("expected filter error for unknown function, got: %v", err)
+	}
+
+	boom := errors.New("boom")
+	fail := func(context.Context, []interface{}) (interface{}, error) { return nil, boom }
+	_, err = jsonpath.Query(sampleJSON, `$.store.book[?(fail())]`, jsonpath.WithFunction("fail", fail))
+	if !errors.Is(err, boom) {
+		t.Errorf("expected wrapped function error, got: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	stop := func(context.Context, []interface{}) (interface{}, error) {
+		cancel()
+		return nil, context.Canceled
+	}
+	_, err = jsonpath.QueryContext(ctx, sampleJSON, `$.store.book[?(stop())]`, jsonpath.WithFunction("stop", stop))
+	if !jsonpath.IsCancelled(err) {
+		t.Errorf("expected cancellation, got: %v", err)
+	}
+}
+
+func TestFilterExpressions(t *testing.T) {
+	tests := []struct {
+		path string
+		want int
+	}{
+		{`$.store.book[?(@.title =~ /sword/i)]`, 1},
+		{`$.store.book[?(@.author == 'Herman Melville')]`, 1},
+		{`$.store.book[?((@.price < 9 || @.price > 20) && @.isbn)]`, 2},
+		{`$.store.book[?(@.category != "fiction")]`, 1},
+		{`$.store.book[?(@.title == 'a && b')]`, 0},
+	}
+	for _, tt := range tests {
+		results, err := jsonpath.Query(sampleJSON, tt.path)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", tt.path, err)
+			continue
+		}
+		if len(results) != tt.want {
+			t.Errorf("%s: expected %d results, got %d", tt.path, tt.want, len(results))
+		}
+	}
+}
+
+func TestFilterParseErrorAtCompile(t *testing.T) {
+	for _, path := range []string{
+		`$.a[?(@.x ==)]`,
+		`$.a[?(@.x =~ /[/)]`,
+		`$.a[?(@.x == 'open)]`,
+		`$.a[?(bogus)]`,
+	} {
+		if _, err := jsonpath.Compile(path); !jsonpath.IsFilterError(err) {
+			t.Errorf("%s: expected filter error, got: %v", path, err)
+		}
+	}
+}
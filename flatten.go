@@ -0,0 +1,164 @@
+package jsonpath
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// WithArrayLeaves makes Flatten and ListPaths treat arrays as leaves: an
+// array is one entry holding the whole array rather than one entry per
+// element.
+func WithArrayLeaves() Option {
+	return func(e *engine) {
+		e.arrayLeaves = true
+	}
+}
+
+// Flatten returns the leaves of the document keyed by their normalized
+// paths: scalars, and empty objects and arrays, which keep their place in
+// the structure. A scalar document is returned under "$". Containers at the
+// WithMaxDepth limit are kept whole. Flattened views suit diffing, indexing
+// and key/value stores; Unflatten rebuilds the document.
+//
+// Example:
+//
+//	flat, err := jsonpath.Flatten([]byte(`{"a": {"b": [1, 2]}}`))
+//	// flat == map[string]interface{}{"$.a.b[0]": 1.0, "$.a.b[1]": 2.0}
+func Flatten(data []byte, opts ...Option) (map[string]interface{}, error) {
+	e := newEngine(context.Background(), opts)
+	doc, err := e.parse(data)
+	if err != nil {
+		return nil, err
+	}
+	root := plainRoot(doc.root)
+	flat := map[string]interface{}{}
+	_, isArray := arrayOf(root)
+	if isLeaf(root) || (e.arrayLeaves && isArray) {
+		flat["$"] = root
+		return flat, nil
+	}
+	err = e.walk(root, "$", 0, func(path string, v interface{}, leaf bool) {
+		if leaf {
+			flat[path] = v
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	return flat, nil
+}
+
+// Unflatten builds the JSON document whose leaves flat holds, the inverse
+// of Flatten. Keys are singular paths such as $.a.b[0].c, or JSON Pointers
+// such as /a/b/0/c. Elements missing from an array are null. Keys that
+// disagree about the document's shape, such as $.a and $.a.b, or $.a[0] and
+// $.a.b, fail with ErrTypeMismatch naming both keys.
+//
+// Example:
+//
+//	data, err := jsonpath.Unflatten(map[string]interface{}{
+//	    "$.user.name":      "ann",
+//	    "$.user.emails[0]": "ann@example.com",
+//	})
+//	// data == {"user":{"emails":["ann@example.com"],"name":"ann"}}
+func Unflatten(flat map[string]interface{}) ([]byte, error) {
+	entries := make([]flatEntry, 0, len(flat))
+	for key, v := range flat {
+		path := key
+		if key == "" || strings.HasPrefix(key, "/") {
+			p, err := PointerToPath(key)
+			if err != nil {
+				return nil, err
+			}
+			path = p
+		}
+		segs, err := ParsePath(path)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, flatEntry{key: key, segs: segs, value: v})
+	}
+	// parents sort before their members, so a conflict is always met below a leaf
+	sort.Slice(entries, func(i, j int) bool {
+		return comparePaths(entries[i].segs, entries[j].segs) < 0
+	})
+
+	var root interface{}
+	for _, en := range entries {
+		var err error
+		if root, err = en.insert(root, "$", en.segs); err != nil {
+			return nil, err
+		}
+	}
+	return json.Marshal(root)
+}
+
+type flatEntry struct {
+	key   string
+	segs  []Segment
+	value interface{}
+}
+
+// flatLeaf is a value placed by Unflatten. Wrapping it keeps a leaf that
+// holds an object or array from being taken for a container other keys
+// may add to.
+type flatLeaf struct {
+	key string
+	v   interface{}
+}
+
+func (l flatLeaf) MarshalJSON() ([]byte, error) {
+	return json.Marshal(l.v)
+}
+
+// insert places the entry's value at segs below node, which is at path, and
+// returns node, created if it is nil.
+func (en flatEntry) insert(node interface{}, path string, segs []Segment) (interface{}, error) {
+	if leaf, ok := node.(flatLeaf); ok {
+		return nil, &Error{Code: ErrTypeMismatch, Message: fmt.Sprintf("keys %s and %s conflict: %s is a value", leaf.key, en.key, path)}
+	}
+	if len(segs) == 0 {
+		if node != nil {
+			return nil, &Error{Code: ErrTypeMismatch, Message: fmt.Sprintf("key %s sets %s, which other keys give members", en.key, path)}
+		}
+		return flatLeaf{key: en.key, v: en.value}, nil
+	}
+	seg := segs[0]
+	if seg.Kind == SegmentName {
+		if node == nil {
+			node = map[string]interface{}{}
+		}
+		obj, ok := node.(map[string]interface{})
+		if !ok {
+			return nil, &Error{Code: ErrTypeMismatch, Message: fmt.Sprintf("key %s names a member of %s, which other keys make an array", en.key, path)}
+		}
+		child, err := en.insert(obj[seg.Name], childPath(path, seg.Name), segs[1:])
+		if err != nil {
+			return nil, err
+		}
+		obj[seg.Name] = child
+		return obj, nil
+	}
+	if seg.Index < 0 {
+		return nil, &Error{Code: ErrInvalidPath, Message: fmt.Sprintf("key %s has negative index %d", en.key, seg.Index)}
+	}
+	if node == nil {
+		node = []interface{}{}
+	}
+	arr, ok := node.([]interface{})
+	if !ok {
+		return nil, &Error{Code: ErrTypeMismatch, Message: fmt.Sprintf("key %s indexes %s, which other keys make an object", en.key, path)}
+	}
+	for len(arr) <= seg.Index {
+		arr = append(arr, nil)
+	}
+	child, err := en.insert(arr[seg.Index], indexPath(path, seg.Index), segs[1:])
+	if err != nil {
+		return nil, err
+	}
+	arr[seg.Index] = child
+	return arr, nil
+}
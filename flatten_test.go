@@ -0,0 +1,103 @@
+package jsonpath_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/njchilds90/go-jsonpath"
+)
+
+func TestFlatten(t *testing.T) {
+	data := []byte(`{"a": {"b": [1, {"c": null}], "e": {}}, "f": "x"}`)
+	flat, err := jsonpath.Flatten(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]interface{}{
+		"$.a.b[0]":   1.0,
+		"$.a.b[1].c": nil,
+		"$.a.e":      map[string]interface{}{},
+		"$.f":        "x",
+	}
+	if !reflect.DeepEqual(flat, want) {
+		t.Errorf("expected %v, got %v", want, flat)
+	}
+
+	flat, err = jsonpath.Flatten(data, jsonpath.WithArrayLeaves())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b, ok := flat["$.a.b"].([]interface{}); !ok || len(b) != 2 || len(flat) != 3 {
+		t.Errorf("expected arrays to be kept whole, got %v", flat)
+	}
+
+	flat, err = jsonpath.Flatten(data, jsonpath.WithMaxDepth(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := flat["$.a"].(map[string]interface{}); !ok || len(flat) != 2 {
+		t.Errorf("expected containers at the depth limit to be kept whole, got %v", flat)
+	}
+
+	flat, err = jsonpath.Flatten([]byte(`"x"`))
+	if err != nil || !reflect.DeepEqual(flat, map[string]interface{}{"$": "x"}) {
+		t.Errorf("unexpected scalar flattening: %v, %v", flat, err)
+	}
+}
+
+func TestUnflatten(t *testing.T) {
+	data := []byte(`{"a":{"b":[1,{"c":null}],"e":{}},"f":"x","g":[]}`)
+	flat, err := jsonpath.Flatten(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out, err := jsonpath.Unflatten(flat)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(out) != string(data) {
+		t.Errorf("expected a round trip, got %s", out)
+	}
+
+	out, err = jsonpath.Unflatten(map[string]interface{}{
+		"/user/name":         "ann",
+		"$.user.emails[1]":   "ann@example.com",
+		"$.user['a b']":      true,
+		"$.user.address":     map[string]interface{}{"city": "Oslo"},
+		"/user/emails/0/tag": "work",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"user":{"a b":true,"address":{"city":"Oslo"},"emails":[{"tag":"work"},"ann@example.com"],"name":"ann"}}`
+	if string(out) != want {
+		t.Errorf("expected %s, got %s", want, out)
+	}
+
+	out, err = jsonpath.Unflatten(map[string]interface{}{"$": 1.5})
+	if err != nil || string(out) != "1.5" {
+		t.Errorf("unexpected root value: %s, %v", out, err)
+	}
+}
+
+func TestUnflattenConflicts(t *testing.T) {
+	tests := []map[string]interface{}{
+		{"$.a": 1, "$.a.b": 2},
+		{"$.a": map[string]interface{}{}, "$.a.b": 2},
+		{"$.a[0]": 1, "$.a.b": 2},
+		{"$.a.b": 1, "$.a[0]": 2},
+		{"$.a": 1, "/a": 2},
+	}
+	for _, flat := range tests {
+		_, err := jsonpath.Unflatten(flat)
+		if !isTypeMismatch(err) {
+			t.Errorf("%v: expected ErrTypeMismatch, got %v", flat, err)
+		}
+	}
+	if _, err := jsonpath.Unflatten(map[string]interface{}{"$.a[*]": 1}); !jsonpath.IsPathError(err) {
+		t.Errorf("expected a path error for a non-singular key, got %v", err)
+	}
+	if _, err := jsonpath.Unflatten(map[string]interface{}{"$.a[-1]": 1}); !jsonpath.IsPathError(err) {
+		t.Errorf("expected a path error for a negative index, got %v", err)
+	}
+}
@@ -0,0 +1,148 @@
+package jsonpath
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"strconv"
+	"time"
+)
+
+// firstValue returns the value of the first match of path, if any. Errors
+// count as no match.
+func firstValue(data []byte, path string) (interface{}, bool) {
+	var v interface{}
+	found := false
+	err := QueryFunc(context.Background(), data, path, func(r Result) error {
+		v, found = r.Value, true
+		return ErrStop
+	})
+	return v, err == nil && found
+}
+
+// GetString returns the first match of path as a string, or def when nothing
+// matches or the query fails. Numbers and booleans are formatted as in JSON;
+// objects, arrays and null yield def.
+//
+// Example:
+//
+//	host := jsonpath.GetString(config, "$.server.host", "localhost")
+func GetString(data []byte, path string, def string) string {
+	v, ok := firstValue(data, path)
+	if !ok {
+		return def
+	}
+	switch s := v.(type) {
+	case string:
+		return s
+	case bool:
+		return strconv.FormatBool(s)
+	case json.Number:
+		return s.String()
+	}
+	if f, ok := toFloat64(v); ok {
+		return strconv.FormatFloat(f, 'f', -1, 64)
+	}
+	return def
+}
+
+// GetFloat returns the first match of path as a float64, or def when nothing
+// matches, the query fails, or the value is neither a number nor a string
+// holding one.
+//
+// Example:
+//
+//	ratio := jsonpath.GetFloat(config, "$.limits.ratio", 0.5)
+func GetFloat(data []byte, path string, def float64) float64 {
+	v, ok := firstValue(data, path)
+	if !ok {
+		return def
+	}
+	if s, ok := v.(string); ok {
+		if f, err := strconv.ParseFloat(s, 64); err == nil {
+			return f
+		}
+		return def
+	}
+	if f, ok := toFloat64(v); ok {
+		return f
+	}
+	return def
+}
+
+// GetInt returns the first match of path as an int, or def when nothing
+// matches, the query fails, or the value is not a whole number (or a string
+// holding one) that fits in an int.
+//
+// Example:
+//
+//	port := jsonpath.GetInt(config, "$.server.port", 8080)
+func GetInt(data []byte, path string, def int) int {
+	v, ok := firstValue(data, path)
+	if !ok {
+		return def
+	}
+	switch n := v.(type) {
+	case string:
+		if i, err := strconv.ParseInt(n, 10, strconv.IntSize); err == nil {
+			return int(i)
+		}
+		return def
+	case json.Number:
+		if i, err := n.Int64(); err == nil && i >= math.MinInt && i <= math.MaxInt {
+			return int(i)
+		}
+	}
+	if f, ok := toFloat64(v); ok && f == math.Trunc(f) && f >= math.MinInt && f < math.MaxInt {
+		return int(f)
+	}
+	return def
+}
+
+// GetBool returns the first match of path as a bool, or def when nothing
+// matches, the query fails, or the value is neither a boolean nor a string
+// strconv.ParseBool accepts.
+//
+// Example:
+//
+//	debug := jsonpath.GetBool(config, "$.debug", false)
+func GetBool(data []byte, path string, def bool) bool {
+	v, ok := firstValue(data, path)
+	if !ok {
+		return def
+	}
+	switch b := v.(type) {
+	case bool:
+		return b
+	case string:
+		if parsed, err := strconv.ParseBool(b); err == nil {
+			return parsed
+		}
+	}
+	return def
+}
+
+// GetTime returns the first match of path as a time.Time, or def when nothing
+// matches or the query fails. Strings are parsed as RFC 3339 and numbers are
+// taken as Unix seconds; anything else yields def.
+//
+// Example:
+//
+//	expires := jsonpath.GetTime(token, "$.exp", time.Now().Add(time.Hour))
+func GetTime(data []byte, path string, def time.Time) time.Time {
+	v, ok := firstValue(data, path)
+	if !ok {
+		return def
+	}
+	if s, ok := v.(string); ok {
+		if t, err := time.Parse(time.RFC3339Nano, s); err == nil {
+			return t
+		}
+		return def
+	}
+	if f, ok := toFloat64(v); ok && !math.IsInf(f, 0) && !math.IsNaN(f) {
+		sec, frac := math.Modf(f)
+		return time.Unix(int64(sec), int64(math.Round(frac*1e9)))
+	}
+	return def
+}
@@ -0,0 +1,90 @@
+package jsonpath_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/njchilds90/go-jsonpath"
+)
+
+var configJSON = []byte(`{
+	"server": {"host": "example.com", "port": 9000, "timeout": "30", "ratio": 0.25},
+	"debug": "true",
+	"verbose": false,
+	"created": "2024-01-31T10:00:00Z",
+	"expires": 1706695200.5,
+	"tags": ["a"]
+}`)
+
+func TestGetString(t *testing.T) {
+	tests := []struct{ path, want string }{
+		{"$.server.host", "example.com"},
+		{"$.server.port", "9000"},
+		{"$.server.ratio", "0.25"},
+		{"$.verbose", "false"},
+		{"$.tags", "fallback"},
+		{"$.missing", "fallback"},
+		{"bad path", "fallback"},
+	}
+	for _, tt := range tests {
+		if got := jsonpath.GetString(configJSON, tt.path, "fallback"); got != tt.want {
+			t.Errorf("%s: expected %q, got %q", tt.path, tt.want, got)
+		}
+	}
+}
+
+func TestGetNumbers(t *testing.T) {
+	if got := jsonpath.GetFloat(configJSON, "$.server.ratio", 1); got != 0.25 {
+		t.Errorf("expected 0.25, got %v", got)
+	}
+	if got := jsonpath.GetFloat(configJSON, "$.server.timeout", 1); got != 30 {
+		t.Errorf("expected 30 from a string, got %v", got)
+	}
+	if got := jsonpath.GetFloat(configJSON, "$.server.host", 1); got != 1 {
+		t.Errorf("expected the default, got %v", got)
+	}
+
+	tests := []struct {
+		path string
+		want int
+	}{
+		{"$.server.port", 9000},
+		{"$.server.timeout", 30},
+		{"$.server.ratio", -1},
+		{"$.server.host", -1},
+		{"$.missing", -1},
+	}
+	for _, tt := range tests {
+		if got := jsonpath.GetInt(configJSON, tt.path, -1); got != tt.want {
+			t.Errorf("%s: expected %d, got %d", tt.path, tt.want, got)
+		}
+	}
+	if got := jsonpath.GetInt([]byte(`{"n": 1e300}`), "$.n", -1); got != -1 {
+		t.Errorf("expected the default for an overflowing value, got %d", got)
+	}
+}
+
+func TestGetBool(t *testing.T) {
+	if !jsonpath.GetBool(configJSON, "$.debug", false) {
+		t.Error("expected true from a string")
+	}
+	if jsonpath.GetBool(configJSON, "$.verbose", true) {
+		t.Error("expected false")
+	}
+	if !jsonpath.GetBool(configJSON, "$.server.host", true) {
+		t.Error("expected the default for a non-boolean string")
+	}
+}
+
+func TestGetTime(t *testing.T) {
+	def := time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+	if got := jsonpath.GetTime(configJSON, "$.created", def); !got.Equal(time.Date(2024, 1, 31, 10, 0, 0, 0, time.UTC)) {
+		t.Errorf("unexpected time: %v", got)
+	}
+	if got := jsonpath.GetTime(configJSON, "$.expires", def); !got.Equal(time.Date(2024, 1, 31, 10, 0, 0, 5e8, time.UTC)) {
+		t.Errorf("unexpected Unix time: %v", got)
+	}
+	if got := jsonpath.GetTime(configJSON, "$.server.host", def); !got.Equal(def) {
+		t.Errorf("expected the default, got %v", got)
+	}
+}
@@ -0,0 +1,110 @@
+package jsonpath
+
+import (
+	"context"
+	"strconv"
+)
+
+// NodeKind is the JSON type of a node passed to a WithOnVisit hook.
+type NodeKind int
+
+const (
+	// NodeNull is JSON null.
+	NodeNull NodeKind = iota + 1
+	// NodeBool is true or false.
+	NodeBool
+	// NodeNumber is a number of any Go numeric type, or a json.Number.
+	NodeNumber
+	// NodeString is a string.
+	NodeString
+	// NodeArray is an array, slice or custom Array.
+	NodeArray
+	// NodeObject is an object, map, struct or custom Object.
+	NodeObject
+	// NodeOther is any other Go value, such as a time.Time from a decoded
+	// TOML or BSON document.
+	NodeOther
+)
+
+var nodeKindNames = map[NodeKind]string{
+	NodeNull:   "null",
+	NodeBool:   "boolean",
+	NodeNumber: "number",
+	NodeString: "string",
+	NodeArray:  "array",
+	NodeObject: "object",
+	NodeOther:  "other",
+}
+
+// String returns the lower-case name of the kind, such as "object".
+func (k NodeKind) String() string {
+	if name, ok := nodeKindNames[k]; ok {
+		return name
+	}
+	return "NodeKind(" + strconv.Itoa(int(k)) + ")"
+}
+
+// MarshalText implements encoding.TextMarshaler, writing the kind's name.
+func (k NodeKind) MarshalText() ([]byte, error) {
+	return []byte(k.String()), nil
+}
+
+// kindOf returns the NodeKind of node.
+func kindOf(node interface{}) NodeKind {
+	switch node.(type) {
+	case nil:
+		return NodeNull
+	case bool:
+		return NodeBool
+	case string:
+		return NodeString
+	}
+	if _, ok := objectOf(node); ok {
+		return NodeObject
+	}
+	if _, ok := arrayOf(node); ok {
+		return NodeArray
+	}
+	if _, ok := toFloat64(node); ok {
+		return NodeNumber
+	}
+	return NodeOther
+}
+
+// WithOnMatch calls fn with the query's context and each match as
+// evaluation finds it, before WithSort or WithOrder(DocumentOrder) reorder
+// results and before a streaming callback sees it. Use it for progress
+// reporting or sampling without changing how results are consumed. fn runs
+// on the evaluating goroutine; to stop early, cancel the query's context.
+//
+// Example:
+//
+//	var found int
+//	results, err := jsonpath.Query(data, "$..price", jsonpath.WithOnMatch(func(context.Context, jsonpath.Result) { found++ }))
+func WithOnMatch(fn func(ctx context.Context, r Result)) Option {
+	return func(e *engine) {
+		e.onMatch = fn
+	}
+}
+
+// WithOnVisit calls fn with the query's context and the normalized path and
+// kind of each node the evaluator visits, the nodes WithMaxNodes counts, in the order it visits
+// them. Nodes visited while resolving filter operands are not reported. Like
+// WithOnMatch it runs on the evaluating goroutine, and cancelling the query's
+// context from fn stops evaluation.
+//
+// Example:
+//
+//	ctx, cancel := context.WithCancel(ctx)
+//	defer cancel()
+//	visits := 0
+//	results, err := jsonpath.QueryContext(ctx, data, "$..id", jsonpath.WithOnVisit(func(ctx context.Context, path string, kind jsonpath.NodeKind) {
+//	    if visits++; visits == 1e6 {
+//	        cancel()
+//	    }
+//	}))
+func WithOnVisit(fn func(ctx context.Context, path string, kind NodeKind)) Option {
+	return func(e *engine) {
+		e.onVisit = fn
+	}
+}
@@ -0,0 +1,120 @@
+package jsonpath_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/njchilds90/go-jsonpath"
+)
+
+func TestOnMatch(t *testing.T) {
+	var seen []string
+	results, err := jsonpath.Query(sampleJSON, "$.store.book[*].price", jsonpath.WithSort(jsonpath.ByValueDesc), jsonpath.WithOnMatch(func(_ context.Context, r jsonpath.Result) {
+		seen = append(seen, r.Path)
+	}))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []string{"$.store.book[0].price", "$.store.book[1].price", "$.store.book[2].price", "$.store.book[3].price"}
+	if len(seen) != len(want) || len(results) != len(want) {
+		t.Fatalf("expected %d matches, saw %v and got %v", len(want), seen, results)
+	}
+	for i := range want {
+		if seen[i] != want[i] {
+			t.Errorf("match %d: expected %s in evaluation order, got %s", i, want[i], seen[i])
+		}
+	}
+
+	n := 0
+	count, err := jsonpath.Count(sampleJSON, "$..price", jsonpath.WithOnMatch(func(_ context.Context, r jsonpath.Result) {
+		if r.Path == "" {
+			t.Error("expected Count to build paths for the hook")
+		}
+		n++
+	}))
+	if err != nil || count != 5 || n != 5 {
+		t.Fatalf("expected 5 matches and 5 calls, got %d and %d (%v)", count, n, err)
+	}
+}
+
+func TestOnVisit(t *testing.T) {
+	kinds := map[string]jsonpath.NodeKind{}
+	_, err := jsonpath.Query(sampleJSON, "$.store.bicycle.*", jsonpath.WithOnVisit(func(_ context.Context, path string, kind jsonpath.NodeKind) {
+		kinds[path] = kind
+	}))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := map[string]jsonpath.NodeKind{
+		"$":                     jsonpath.NodeObject,
+		"$.store":               jsonpath.NodeObject,
+		"$.store.bicycle":       jsonpath.NodeObject,
+		"$.store.bicycle.color": jsonpath.NodeString,
+		"$.store.bicycle.price": jsonpath.NodeNumber,
+	}
+	for path, kind := range want {
+		if kinds[path] != kind {
+			t.Errorf("%s: expected %v, got %v", path, kind, kinds[path])
+		}
+	}
+	if len(kinds) != len(want) {
+		t.Errorf("expected %d visits, got %v", len(want), kinds)
+	}
+
+	// filter operands are resolved without reporting visits
+	visits := 0
+	_, err = jsonpath.Query(sampleJSON, "$.store.book[?(@.price < 10)]", jsonpath.WithOnVisit(func(_ context.Context, path string, kind jsonpath.NodeKind) {
+		if kind != jsonpath.NodeObject && kind != jsonpath.NodeArray {
+			t.Errorf("%s: unexpected visit of a %v", path, kind)
+		}
+		visits++
+	}))
+	if err != nil || visits == 0 {
+		t.Fatalf("expected visits, got %d (%v)", visits, err)
+	}
+}
+
+func TestOnVisitCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	visits := 0
+	_, err := jsonpath.QueryContext(ctx, sampleJSON, "$..*", jsonpath.WithOnVisit(func(context.Context, string, jsonpath.NodeKind) {
+		if visits++; visits == 3 {
+			cancel()
+		}
+	}))
+	if !jsonpath.IsCancelled(err) || visits > 4 {
+		t.Fatalf("expected cancellation soon after the third visit, got %v after %d visits", err, visits)
+	}
+}
+
+func TestHooksContext(t *testing.T) {
+	type key struct{}
+	ctx := context.WithValue(context.Background(), key{}, "request-1")
+	var got []interface{}
+	m := &recordingMetrics{}
+	_, err := jsonpath.QueryContext(ctx, sampleJSON, "$.store.bicycle.color",
+		jsonpath.WithOnMatch(func(ctx context.Context, _ jsonpath.Result) { got = append(got, ctx.Value(key{})) }),
+		jsonpath.WithOnVisit(func(ctx context.Context, _ string, _ jsonpath.NodeKind) { got = append(got, ctx.Value(key{})) }),
+		jsonpath.WithMetrics(m))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(got) < 2 {
+		t.Fatalf("expected visits and a match, got %v", got)
+	}
+	for _, v := range got {
+		if v != "request-1" {
+			t.Errorf("expected the query's context, got value %v", v)
+		}
+	}
+	if len(m.contexts) != 2 || m.contexts[0].Value(key{}) != "request-1" || m.contexts[1].Value(key{}) != "request-1" {
+		t.Errorf("expected the query's context in Metrics, got %v", m.contexts)
+	}
+}
+
+func TestNodeKindString(t *testing.T) {
+	if jsonpath.NodeObject.String() != "object" || jsonpath.NodeBool.String() != "boolean" || jsonpath.NodeKind(0).String() != "NodeKind(0)" {
+		t.Errorf("unexpected names %v %v %v", jsonpath.NodeObject, jsonpath.NodeBool, jsonpath.NodeKind(0))
+	}
+}
@@ -0,0 +1,199 @@
+// Package httpjsonpath serves JSONPath queries over HTTP: clients POST a JSON
+// document and name a path, and receive the matches in a JSON envelope.
+//
+// A successful query answers 200 with
+//
+//	{"results": [{"path": "$.a", "value": 1}], "count": 1, "truncated": false}
+//
+// and a failed one answers 4xx or 5xx with
+//
+//	{"error": {"code": "invalid_path", "message": "jsonpath: ..."}}
+//
+// Error codes and their statuses:
+//
+//	method_not_allowed  405  the request was not a POST
+//	missing_path        400  no path parameter or header was given
+//	body_too_large      413  the body exceeded Handler.MaxBodyBytes
+//	invalid_path        400  the path is malformed
+//	invalid_filter      400  a filter expression is malformed
+//	invalid_json        400  the body is not valid JSON
+//	invalid_input       400  the request could not be read
+//	limit_exceeded      400  the path exceeded a limit such as WithMaxPathLength
+//	key_not_found       422  strict mode: a key is missing
+//	index_out_of_bounds 422  strict mode: an index is out of range
+//	type_mismatch       422  strict mode: a node had the wrong type
+//	max_depth_exceeded  422  recursive descent went deeper than MaxDepth
+//	budget_exceeded     422  evaluation hit MaxNodes or Budget
+//	cancelled           503  the request was cancelled
+//	internal            500  any other failure
+package httpjsonpath
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/njchilds90/go-jsonpath"
+)
+
+const (
+	// PathParam is the query parameter naming the path to run.
+	PathParam = "path"
+	// PathHeader is the request header naming the path to run, used when the
+	// query parameter is absent.
+	PathHeader = "X-JSONPath"
+
+	// DefaultMaxBodyBytes is the body size limit when Handler.MaxBodyBytes is 0.
+	DefaultMaxBodyBytes = 10 << 20
+)
+
+// Handler is an http.Handler that runs the JSONPath named by the PathParam
+// query parameter or PathHeader header against the JSON request body. The
+// zero value is ready to use; its limits only bound the body size.
+//
+// Example:
+//
+//	http.Handle("/query", &httpjsonpath.Handler{
+//	    MaxDepth:   32,
+//	    MaxResults: 1000,
+//	    Budget:     100 * time.Millisecond,
+//	    Cache:      jsonpath.NewCache(512),
+//	})
+type Handler struct {
+	// MaxBodyBytes limits the request body. Default is DefaultMaxBodyBytes.
+	MaxBodyBytes int64
+	// MaxDepth limits recursive descent, as WithMaxDepth. Default is the
+	// jsonpath package default.
+	MaxDepth int
+	// MaxResults limits the matches returned; further matches are dropped
+	// and the response is marked truncated. Default is 0 (unlimited).
+	MaxResults int
+	// MaxNodes limits the nodes visited, as WithMaxNodes.
+	MaxNodes int
+	// Budget limits evaluation time, as WithBudget.
+	Budget time.Duration
+	// Cache, if set, caches compiled paths across requests.
+	Cache *jsonpath.Cache
+	// Options are applied to every query after the limits above.
+	Options []jsonpath.Option
+}
+
+// response is the success envelope.
+type response struct {
+	Results   []jsonpath.Result `json:"results"`
+	Count     int               `json:"count"`
+	Truncated bool              `json:"truncated"`
+}
+
+// errorResponse is the error envelope.
+type errorResponse struct {
+	Error errorBody `json:"error"`
+}
+
+type errorBody struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// ServeHTTP implements http.Handler.
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		writeError(w, http.StatusMethodNotAllowed, "method_not_allowed", "use POST with the JSON document as the body")
+		return
+	}
+	path := r.URL.Query().Get(PathParam)
+	if path == "" {
+		path = r.Header.Get(PathHeader)
+	}
+	if path == "" {
+		writeError(w, http.StatusBadRequest, "missing_path", "set the "+PathParam+" query parameter or the "+PathHeader+" header")
+		return
+	}
+
+	limit := h.MaxBodyBytes
+	if limit <= 0 {
+		limit = DefaultMaxBodyBytes
+	}
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, limit))
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			writeError(w, http.StatusRequestEntityTooLarge, "body_too_large", err.Error())
+			return
+		}
+		writeError(w, http.StatusBadRequest, "invalid_input", err.Error())
+		return
+	}
+
+	resp := response{Results: []jsonpath.Result{}}
+	err = jsonpath.QueryFunc(r.Context(), body, path, func(res jsonpath.Result) error {
+		if h.MaxResults > 0 && len(resp.Results) == h.MaxResults {
+			resp.Truncated = true
+			return jsonpath.ErrStop
+		}
+		resp.Results = append(resp.Results, res)
+		return nil
+	}, h.options()...)
+	if err != nil {
+		status, code := classify(err)
+		writeError(w, status, code, err.Error())
+		return
+	}
+	resp.Count = len(resp.Results)
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func (h *Handler) options() []jsonpath.Option {
+	var opts []jsonpath.Option
+	if h.MaxDepth > 0 {
+		opts = append(opts, jsonpath.WithMaxDepth(h.MaxDepth))
+	}
+	if h.MaxNodes > 0 {
+		opts = append(opts, jsonpath.WithMaxNodes(h.MaxNodes))
+	}
+	if h.Budget > 0 {
+		opts = append(opts, jsonpath.WithBudget(h.Budget))
+	}
+	if h.Cache != nil {
+		opts = append(opts, jsonpath.WithCache(h.Cache))
+	}
+	return append(opts, h.Options...)
+}
+
+var errorStatus = map[jsonpath.ErrorCode]int{
+	jsonpath.ErrInvalidPath:      http.StatusBadRequest,
+	jsonpath.ErrInvalidJSON:      http.StatusBadRequest,
+	jsonpath.ErrInvalidFilter:    http.StatusBadRequest,
+	jsonpath.ErrInvalidInput:     http.StatusBadRequest,
+	jsonpath.ErrLimitExceeded:    http.StatusBadRequest,
+	jsonpath.ErrKeyNotFound:      http.StatusUnprocessableEntity,
+	jsonpath.ErrIndexOutOfBounds: http.StatusUnprocessableEntity,
+	jsonpath.ErrTypeMismatch:     http.StatusUnprocessableEntity,
+	jsonpath.ErrMaxDepthExceeded: http.StatusUnprocessableEntity,
+	jsonpath.ErrBudgetExceeded:   http.StatusUnprocessableEntity,
+	jsonpath.ErrCancelled:        http.StatusServiceUnavailable,
+}
+
+// classify returns the status and envelope code for a query error.
+func classify(err error) (int, string) {
+	var e *jsonpath.Error
+	if errors.As(err, &e) {
+		if status, ok := errorStatus[e.Code]; ok {
+			return status, e.Code.String()
+		}
+	}
+	return http.StatusInternalServerError, "internal"
+}
+
+func writeError(w http.ResponseWriter, status int, code, message string) {
+	writeJSON(w, status, errorResponse{Error: errorBody{Code: code, Message: message}})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,114 @@
+package httpjsonpath_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/njchilds90/go-jsonpath"
+	"github.com/njchilds90/go-jsonpath/httpjsonpath"
+)
+
+const store = `{"store": {"book": [
+	{"title": "Sayings of the Century", "price": 8.95},
+	{"title": "Sword of Honour", "price": 12.99},
+	{"title": "Moby Dick", "price": 8.99}
+]}}`
+
+type envelope struct {
+	Results []struct {
+		Path  string      `json:"path"`
+		Value interface{} `json:"value"`
+	} `json:"results"`
+	Count     int  `json:"count"`
+	Truncated bool `json:"truncated"`
+	Error     *struct {
+		Code    string `json:"code"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+func serve(t *testing.T, h http.Handler, req *http.Request) (int, envelope) {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if ct := rec.Header().Get("Content-Type"); ct != "application/json" {
+		t.Errorf("expected application/json, got %q", ct)
+	}
+	var env envelope
+	if err := json.Unmarshal(rec.Body.Bytes(), &env); err != nil {
+		t.Fatalf("invalid response body %q: %v", rec.Body.String(), err)
+	}
+	return rec.Code, env
+}
+
+func post(path, body string) *http.Request {
+	return httptest.NewRequest(http.MethodPost, "/query?path="+url.QueryEscape(path), strings.NewReader(body))
+}
+
+func TestHandler(t *testing.T) {
+	code, env := serve(t, &httpjsonpath.Handler{}, post("$.store.book[?(@.price < 10)].title", store))
+	if code != http.StatusOK || env.Error != nil {
+		t.Fatalf("unexpected response %d: %+v", code, env.Error)
+	}
+	if env.Count != 2 || env.Truncated || env.Results[1].Path != "$.store.book[2].title" || env.Results[1].Value != "Moby Dick" {
+		t.Errorf("unexpected envelope: %+v", env)
+	}
+}
+
+func TestHandlerPathHeader(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "/query", strings.NewReader(store))
+	req.Header.Set(httpjsonpath.PathHeader, "$.store.missing")
+	code, env := serve(t, &httpjsonpath.Handler{Cache: jsonpath.NewCache(8)}, req)
+	if code != http.StatusOK || env.Count != 0 || env.Results == nil {
+		t.Errorf("unexpected response %d: %+v", code, env)
+	}
+}
+
+func TestHandlerMaxResults(t *testing.T) {
+	code, env := serve(t, &httpjsonpath.Handler{MaxResults: 2}, post("$..title", store))
+	if code != http.StatusOK || env.Count != 2 || !env.Truncated {
+		t.Errorf("unexpected response %d: %+v", code, env)
+	}
+
+	code, env = serve(t, &httpjsonpath.Handler{MaxResults: 3}, post("$..title", store))
+	if code != http.StatusOK || env.Count != 3 || env.Truncated {
+		t.Errorf("unexpected response %d: %+v", code, env)
+	}
+}
+
+func TestHandlerErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		handler *httpjsonpath.Handler
+		req     *http.Request
+		status  int
+		code    string
+	}{
+		{"method", &httpjsonpath.Handler{}, httptest.NewRequest(http.MethodGet, "/query?path=$", nil), http.StatusMethodNotAllowed, "method_not_allowed"},
+		{"missing path", &httpjsonpath.Handler{}, httptest.NewRequest(http.MethodPost, "/query", strings.NewReader(store)), http.StatusBadRequest, "missing_path"},
+		{"bad path", &httpjsonpath.Handler{}, post("store", store), http.StatusBadRequest, "invalid_path"},
+		{"bad filter", &httpjsonpath.Handler{}, post("$[?(@.a ==)]", store), http.StatusBadRequest, "invalid_filter"},
+		{"bad json", &httpjsonpath.Handler{}, post("$.a", "{"), http.StatusBadRequest, "invalid_json"},
+		{"too large", &httpjsonpath.Handler{MaxBodyBytes: 16}, post("$.a", store), http.StatusRequestEntityTooLarge, "body_too_large"},
+		{"max nodes", &httpjsonpath.Handler{MaxNodes: 3}, post("$..price", store), http.StatusUnprocessableEntity, "budget_exceeded"},
+		{"max depth", &httpjsonpath.Handler{MaxDepth: 1}, post("$..price", store), http.StatusUnprocessableEntity, "max_depth_exceeded"},
+		{
+			"strict", &httpjsonpath.Handler{Options: []jsonpath.Option{jsonpath.WithAllowMissingKeys(true)}},
+			post("$.nope", store), http.StatusUnprocessableEntity, "key_not_found",
+		},
+	}
+	for _, tt := range tests {
+		code, env := serve(t, tt.handler, tt.req)
+		if code != tt.status || env.Error == nil || env.Error.Code != tt.code {
+			t.Errorf("%s: expected %d %s, got %d %+v", tt.name, tt.status, tt.code, code, env.Error)
+			continue
+		}
+		if env.Error.Message == "" {
+			t.Errorf("%s: expected an error message", tt.name)
+		}
+	}
+}
@@ -0,0 +1,46 @@
+//go:build go1.23
+
+package jsonpath
+
+import (
+	"context"
+	"iter"
+)
+
+// All returns an iterator over the matches of the compiled path in root, a
+// value such as one produced by json.Unmarshal. Evaluation is lazy: matches
+// are produced as the iterator is consumed, and breaking out of the loop
+// stops the evaluation. An evaluation error is yielded once, as the last pair.
+//
+// Example:
+//
+//	for r, err := range cp.All(doc) {
+//	    if err != nil {
+//	        return err
+//	    }
+//	    fmt.Println(r.Path, r.Value)
+//	}
+func (cp *CompiledPath) All(root interface{}, opts ...Option) iter.Seq2[Result, error] {
+	return cp.AllContext(context.Background(), root, opts...)
+}
+
+// AllContext is like All but evaluates with the given context.
+func (cp *CompiledPath) AllContext(ctx context.Context, root interface{}, opts ...Option) iter.Seq2[Result, error] {
+	return func(yield func(Result, error) bool) {
+		if ctx == nil {
+			yield(Result{}, &Error{Code: ErrInvalidInput, Message: "context must not be nil"})
+			return
+		}
+		stopped := false
+		err := newEngine(ctx, opts).stream(root, cp.tokens, func(r Result) error {
+			if !yield(r, nil) {
+				stopped = true
+				return ErrStop
+			}
+			return nil
+		})
+		if err != nil && !stopped {
+			yield(Result{}, err)
+		}
+	}
+}
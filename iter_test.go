@@ -0,0 +1,80 @@
+//go:build go1.23
+
+package jsonpath_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/njchilds90/go-jsonpath"
+)
+
+func TestCompiledPathAll(t *testing.T) {
+	var doc interface{}
+	if err := json.Unmarshal(sampleJSON, &doc); err != nil {
+		t.Fatal(err)
+	}
+
+	cp := jsonpath.MustCompile("$..price")
+	var n int
+	for r, err := range cp.All(doc) {
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if _, ok := r.Value.(float64); !ok {
+			t.Errorf("unexpected value %v at %s", r.Value, r.Path)
+		}
+		n++
+	}
+	if n != 5 {
+		t.Errorf("expected 5 matches, got %d", n)
+	}
+
+	n = 0
+	for range cp.All(doc) {
+		n++
+		if n == 2 {
+			break
+		}
+	}
+	if n != 2 {
+		t.Errorf("expected early break after 2 matches, got %d", n)
+	}
+}
+
+func TestCompiledPathAllError(t *testing.T) {
+	var doc interface{}
+	if err := json.Unmarshal(sampleJSON, &doc); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	var last error
+	for _, err := range jsonpath.MustCompile("$..price").AllContext(ctx, doc) {
+		last = err
+	}
+	if !jsonpath.IsCancelled(last) {
+		t.Errorf("expected cancellation error, got: %v", last)
+	}
+}
+
+func TestCompiledPathAllBreakAllErrors(t *testing.T) {
+	var doc interface{}
+	if err := json.Unmarshal(sampleJSON, &doc); err != nil {
+		t.Fatal(err)
+	}
+
+	n := 0
+	for _, err := range jsonpath.MustCompile("$.store.book[*].isbn").All(doc, jsonpath.WithAllowMissingKeys(true), jsonpath.WithAllErrors()) {
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+		n++
+		break
+	}
+	if n != 1 {
+		t.Errorf("expected to break after 1 match, got %d", n)
+	}
+}
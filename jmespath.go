@@ -0,0 +1,368 @@
+package jsonpath
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// FromJMESPath converts a JMESPath expression to the equivalent JSONPath
+// expression, for the subset the two languages share:
+//
+//   - identifiers and quoted identifiers: a.b, "first name"
+//   - indices and slices: a[0], a[-1], a[1:3], a[::2]
+//   - wildcard projections: a[*].b, a.*.b
+//   - flatten: a[].b, converted to a[*].b, which selects the same values
+//     unless the array holds arrays
+//   - filter projections with comparisons, && and ||: a[?b > `10` && c == 'x']
+//
+// Bare identifiers in a filter test for presence, where JMESPath tests for
+// truthiness, so [?enabled] also matches members whose value is false.
+// Pipes, multiselect lists and hashes, functions and negation have no
+// JSONPath counterpart and fail with ErrUnsupported; malformed expressions
+// fail with ErrInvalidPath.
+//
+// Where a JMESPath projection produces one array, the JSONPath query
+// produces one Result per element.
+//
+// Example:
+//
+//	path, err := jsonpath.FromJMESPath("Reservations[].Instances[?State.Name == 'running'].InstanceId")
+//	// path == "$.Reservations[*].Instances[?(@.State.Name == 'running')].InstanceId"
+func FromJMESPath(expr string) (string, error) {
+	c := &jmesConverter{src: expr}
+	c.skipSpace()
+	if c.pos >= len(c.src) {
+		return "", c.errorf("expression must not be empty")
+	}
+	path, err := c.chain("$", true)
+	if err != nil {
+		return "", err
+	}
+	c.skipSpace()
+	if c.pos < len(c.src) {
+		return "", c.unexpected()
+	}
+	return path, nil
+}
+
+// CompileJMESPath converts a JMESPath expression with FromJMESPath and
+// compiles the result.
+func CompileJMESPath(expr string) (*CompiledPath, error) {
+	path, err := FromJMESPath(expr)
+	if err != nil {
+		return nil, err
+	}
+	return Compile(path)
+}
+
+type jmesConverter struct {
+	src string
+	pos int
+}
+
+func (c *jmesConverter) errorf(format string, args ...interface{}) error {
+	return &Error{Code: ErrInvalidPath, Message: fmt.Sprintf("cannot parse JMESPath expression %q: %s", c.src, fmt.Sprintf(format, args...))}
+}
+
+func (c *jmesConverter) unsupported(construct string) error {
+	return &Error{Code: ErrUnsupported, Message: fmt.Sprintf("cannot convert JMESPath expression %q: %s has no JSONPath equivalent", c.src, construct)}
+}
+
+// unexpected reports the character at the current position, naming the
+// JMESPath constructs that cannot be converted.
+func (c *jmesConverter) unexpected() error {
+	rest := c.src[c.pos:]
+	switch {
+	case strings.HasPrefix(rest, "||"), strings.HasPrefix(rest, "&&"):
+		return c.unsupported("a logical expression outside a filter")
+	case rest[0] == '|':
+		return c.unsupported("a pipe")
+	case rest[0] == '{':
+		return c.unsupported("a multiselect hash")
+	case rest[0] == '!':
+		return c.unsupported("negation")
+	case rest[0] == '&':
+		return c.unsupported("an expression reference")
+	case rest[0] == '(':
+		return c.unsupported("a function call")
+	}
+	return c.errorf("unexpected %q at position %d", rest[0], c.pos)
+}
+
+func (c *jmesConverter) skipSpace() {
+	for c.pos < len(c.src) && strings.IndexByte(" \t\r\n", c.src[c.pos]) >= 0 {
+		c.pos++
+	}
+}
+
+func (c *jmesConverter) peek() byte {
+	c.skipSpace()
+	if c.pos >= len(c.src) {
+		return 0
+	}
+	return c.src[c.pos]
+}
+
+// chain converts a sub-expression — identifiers joined by dots, brackets
+// and wildcards — appending its selectors to path. At the start of the
+// chain an identifier, a bracket, '*' or '@' is expected.
+func (c *jmesConverter) chain(path string, first bool) (string, error) {
+	for {
+		switch ch := c.peek(); {
+		case ch == '[':
+			if rest := strings.TrimLeft(c.src[c.pos+1:], " "); rest != "" && strings.IndexByte("?]*", rest[0]) < 0 && !isJMESIndexStart(rest) {
+				return "", c.unsupported("a multiselect list")
+			}
+			sel, err := c.bracket()
+			if err != nil {
+				return "", err
+			}
+			path += sel
+		case first && ch == '@':
+			c.pos++
+		case first && ch == '*':
+			c.pos++
+			path += ".*"
+		case first && (ch == '"' || isJMESIdentStart(ch)):
+			sel, err := c.identifier()
+			if err != nil {
+				return "", err
+			}
+			path += sel
+		case !first && ch == '.':
+			c.pos++
+			switch ch := c.peek(); {
+			case ch == '*':
+				c.pos++
+				path += ".*"
+			case ch == '"' || isJMESIdentStart(ch):
+				sel, err := c.identifier()
+				if err != nil {
+					return "", err
+				}
+				path += sel
+			case ch == '[':
+				return "", c.unsupported("a multiselect list")
+			case ch == '{':
+				return "", c.unsupported("a multiselect hash")
+			default:
+				return "", c.errorf("expected identifier after '.' at position %d", c.pos)
+			}
+		default:
+			if first {
+				if ch == 0 {
+					return "", c.errorf("unexpected end of expression")
+				}
+				return "", c.unexpected()
+			}
+			return path, nil
+		}
+		first = false
+	}
+}
+
+func isJMESIdentStart(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+// isJMESIndexStart reports whether s, the text after '[', starts an index
+// or slice.
+func isJMESIndexStart(s string) bool {
+	return s[0] == '-' || s[0] == ':' || (s[0] >= '0' && s[0] <= '9')
+}
+
+// identifier converts an unquoted or quoted identifier to a member selector.
+func (c *jmesConverter) identifier() (string, error) {
+	var name string
+	if c.src[c.pos] == '"' {
+		end := c.pos + 1
+		for end < len(c.src) && c.src[end] != '"' {
+			if c.src[end] == '\\' {
+				end++
+			}
+			end++
+		}
+		if end >= len(c.src) {
+			return "", c.errorf("unterminated quoted identifier")
+		}
+		if err := json.Unmarshal([]byte(c.src[c.pos:end+1]), &name); err != nil {
+			return "", c.errorf("invalid quoted identifier %s", c.src[c.pos:end+1])
+		}
+		c.pos = end + 1
+	} else {
+		start := c.pos
+		for c.pos < len(c.src) && (isJMESIdentStart(c.src[c.pos]) || (c.src[c.pos] >= '0' && c.src[c.pos] <= '9')) {
+			c.pos++
+		}
+		name = c.src[start:c.pos]
+		if c.peek() == '(' {
+			return "", c.unsupported("the function " + name + "()")
+		}
+	}
+	return formatMember(name), nil
+}
+
+// bracket converts an index, slice, wildcard, flatten or filter bracket.
+func (c *jmesConverter) bracket() (string, error) {
+	c.pos++ // '['
+	switch c.peek() {
+	case ']':
+		c.pos++
+		return "[*]", nil
+	case '*':
+		c.pos++
+		if c.peek() != ']' {
+			return "", c.errorf("expected ']' after '[*' at position %d", c.pos)
+		}
+		c.pos++
+		return "[*]", nil
+	case '?':
+		c.pos++
+		cond, err := c.or()
+		if err != nil {
+			return "", err
+		}
+		if c.peek() != ']' {
+			return "", c.errorf("expected ']' after filter at position %d", c.pos)
+		}
+		c.pos++
+		return "[?(" + cond + ")]", nil
+	}
+
+	end := strings.IndexByte(c.src[c.pos:], ']')
+	if end < 0 {
+		return "", c.errorf("unclosed '['")
+	}
+	inner := strings.ReplaceAll(c.src[c.pos:c.pos+end], " ", "")
+	parts := strings.Split(inner, ":")
+	if len(parts) > 3 || (len(parts) == 1 && parts[0] == "") {
+		return "", c.errorf("invalid index %q", inner)
+	}
+	for _, p := range parts {
+		if _, err := strconv.Atoi(p); p != "" && err != nil {
+			return "", c.errorf("invalid index %q", inner)
+		}
+	}
+	c.pos += end + 1
+	return "[" + inner + "]", nil
+}
+
+// or, and and comparison convert a filter expression.
+func (c *jmesConverter) or() (string, error) {
+	left, err := c.and()
+	if err != nil {
+		return "", err
+	}
+	for c.accept("||") {
+		right, err := c.and()
+		if err != nil {
+			return "", err
+		}
+		left += " || " + right
+	}
+	return left, nil
+}
+
+func (c *jmesConverter) and() (string, error) {
+	left, err := c.comparison()
+	if err != nil {
+		return "", err
+	}
+	for c.accept("&&") {
+		right, err := c.comparison()
+		if err != nil {
+			return "", err
+		}
+		left += " && " + right
+	}
+	return left, nil
+}
+
+func (c *jmesConverter) accept(s string) bool {
+	c.skipSpace()
+	if strings.HasPrefix(c.src[c.pos:], s) {
+		c.pos += len(s)
+		return true
+	}
+	return false
+}
+
+func (c *jmesConverter) comparison() (string, error) {
+	switch c.peek() {
+	case '(':
+		c.pos++
+		inner, err := c.or()
+		if err != nil {
+			return "", err
+		}
+		if !c.accept(")") {
+			return "", c.errorf("missing ')' at position %d", c.pos)
+		}
+		return "(" + inner + ")", nil
+	case '!':
+		if !strings.HasPrefix(c.src[c.pos:], "!=") {
+			return "", c.unsupported("negation")
+		}
+	}
+
+	left, err := c.operand()
+	if err != nil {
+		return "", err
+	}
+	for _, op := range []string{"==", "!=", "<=", ">=", "<", ">"} {
+		if c.accept(op) {
+			right, err := c.operand()
+			if err != nil {
+				return "", err
+			}
+			return left + " " + op + " " + right, nil
+		}
+	}
+	return left, nil
+}
+
+// operand converts a literal or a sub-expression relative to the current
+// element.
+func (c *jmesConverter) operand() (string, error) {
+	switch ch := c.peek(); ch {
+	case '`':
+		end := strings.IndexByte(c.src[c.pos+1:], '`')
+		if end < 0 {
+			return "", c.errorf("unterminated literal")
+		}
+		raw := strings.ReplaceAll(c.src[c.pos+1:c.pos+1+end], "\\`", "`")
+		c.pos += end + 2
+		var v interface{}
+		if err := json.Unmarshal([]byte(raw), &v); err != nil {
+			return "", c.errorf("invalid JSON literal `%s`", raw)
+		}
+		switch x := v.(type) {
+		case string:
+			return quoteKey(x), nil
+		case float64:
+			return strconv.FormatFloat(x, 'g', -1, 64), nil
+		case bool:
+			return strconv.FormatBool(x), nil
+		case nil:
+			return "null", nil
+		}
+		return "", c.unsupported("an array or object literal")
+	case '\'':
+		var b strings.Builder
+		i := c.pos + 1
+		for ; i < len(c.src) && c.src[i] != '\''; i++ {
+			if c.src[i] == '\\' && i+1 < len(c.src) && c.src[i+1] == '\'' {
+				i++
+			}
+			b.WriteByte(c.src[i])
+		}
+		if i >= len(c.src) {
+			return "", c.errorf("unterminated raw string")
+		}
+		c.pos = i + 1
+		return quoteKey(b.String()), nil
+	}
+	return c.chain("@", true)
+}
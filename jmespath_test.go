@@ -0,0 +1,79 @@
+package jsonpath_test
+
+import (
+	"testing"
+
+	"github.com/njchilds90/go-jsonpath"
+)
+
+func TestFromJMESPath(t *testing.T) {
+	tests := []struct {
+		expr string
+		want string
+	}{
+		{"store.book", "$.store.book"},
+		{"store.book[0].title", "$.store.book[0].title"},
+		{`"first name".last`, "$['first name'].last"},
+		{"items[-1]", "$.items[-1]"},
+		{"items[1:3]", "$.items[1:3]"},
+		{"items[::2]", "$.items[::2]"},
+		{"items[*].id", "$.items[*].id"},
+		{"store.*.price", "$.store.*.price"},
+		{"Reservations[].Instances[].InstanceId", "$.Reservations[*].Instances[*].InstanceId"},
+		{"[0].a", "$[0].a"},
+		{"@", "$"},
+		{"book[?price < `10`].title", "$.book[?(@.price < 10)].title"},
+		{"book[?category == 'fiction' && price > `10`]", "$.book[?(@.category == 'fiction' && @.price > 10)]"},
+		{"book[?(a == `true` || b == `null`) && c != `\"x\"`]", "$.book[?((@.a == true || @.b == null) && @.c != 'x')]"},
+		{"book[?isbn]", "$.book[?(@.isbn)]"},
+		{"nums[?@ > `2`]", "$.nums[?(@ > 2)]"},
+		{"book[?State.Name == 'it\\'s']", `$.book[?(@.State.Name == 'it\'s')]`},
+		{`"a]b".c`, "$['a]b'].c"},
+	}
+	for _, tt := range tests {
+		got, err := jsonpath.FromJMESPath(tt.expr)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", tt.expr, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("%s: expected %s, got %s", tt.expr, tt.want, got)
+		}
+	}
+}
+
+func TestFromJMESPathUnsupported(t *testing.T) {
+	for _, expr := range []string{
+		"a | b",
+		"a.[b, c]",
+		"[a, b]",
+		"a.{x: b}",
+		"length(a)",
+		"a[?!b]",
+		"sort_by(a, &b)",
+		"a[?b == `[1]`]",
+	} {
+		if _, err := jsonpath.FromJMESPath(expr); !jsonpath.IsUnsupported(err) {
+			t.Errorf("%s: expected unsupported error, got: %v", expr, err)
+		}
+	}
+	for _, expr := range []string{"", "a.", "a[", "a[1:x]", "a[?b == `nope`]", "a b"} {
+		if _, err := jsonpath.FromJMESPath(expr); !jsonpath.IsPathError(err) {
+			t.Errorf("%q: expected path error, got: %v", expr, err)
+		}
+	}
+}
+
+func TestCompileJMESPath(t *testing.T) {
+	cp, err := jsonpath.CompileJMESPath("store.book[?price < `10`].title")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	results, err := cp.Query(sampleJSON)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(results) != 2 || results[0].Value != "Sayings of the Century" || results[1].Value != "Moby Dick" {
+		t.Errorf("unexpected results: %v", results)
+	}
+}
@@ -25,13 +25,19 @@ package jsonpath
 
 import (
 	"context"
+	"encoding"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"log/slog"
 	"math"
+	"math/big"
 	"reflect"
-	"regexp"
 	"strconv"
 	"strings"
+	"time"
+	"unicode/utf16"
+	"unicode/utf8"
 )
 
 // Result represents a single match from a JSONPath query.
@@ -41,14 +47,35 @@ type Result struct {
 	Path string
 	// Value is the matched JSON value. Use type assertions or json.Unmarshal to work with it.
 	Value interface{}
+	// Start and End are the byte offsets of the matched value in the source
+	// document, so that data[Start:End] is its raw JSON text. They are only
+	// set when WithOffsets is used with a []byte input; otherwise both are 0.
+	Start, End int
+	// Substring is set on the matches of string slices under
+	// WithStringSlices: Path is then the path of the sliced string, and
+	// Substring locates the characters selected from it. It is nil
+	// otherwise.
+	Substring *Substring
 }
 
-// MarshalJSON implements json.Marshaler for Result.
+// MarshalJSON implements json.Marshaler for Result. A *big.Float value,
+// as WithExactNumbers decodes, is written as a number with all its digits.
 func (r Result) MarshalJSON() ([]byte, error) {
-	return json.Marshal(map[string]interface{}{
+	m := map[string]interface{}{
 		"path":  r.Path,
 		"value": r.Value,
-	})
+	}
+	if f, ok := r.Value.(*big.Float); ok && !f.IsInf() {
+		m["value"] = json.Number(f.Text('g', -1))
+	}
+	if r.End > 0 {
+		m["start"] = r.Start
+		m["end"] = r.End
+	}
+	if r.Substring != nil {
+		m["substring"] = r.Substring
+	}
+	return json.Marshal(m)
 }
 
 // Option configures JSONPath query behavior.
@@ -70,6 +97,174 @@ func WithAllowMissingKeys(strict bool) Option {
 	}
 }
 
+// WithAllErrors makes strict mode (WithAllowMissingKeys(true)) carry on past
+// missing keys, out-of-range indices and type mismatches instead of stopping
+// at the first. The query then returns the results it found together with
+// every violation, joined with errors.Join; each is an *Error whose Path is
+// the node where the lookup failed. Without strict mode it has no effect.
+//
+// Example:
+//
+//	results, err := jsonpath.Query(data, "$.users[*].email",
+//	    jsonpath.WithAllowMissingKeys(true), jsonpath.WithAllErrors())
+//	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+//	    for _, v := range joined.Unwrap() {
+//	        log.Printf("no email at %s", v.(*jsonpath.Error).Path)
+//	    }
+//	}
+func WithAllErrors() Option {
+	return func(e *engine) {
+		e.allErrors = true
+	}
+}
+
+// WithUseNumber decodes numbers in []byte and io.Reader input as json.Number
+// rather than float64, as json.Decoder.UseNumber does, so 64-bit IDs and
+// other long numbers keep every digit in Result.Value. Filters then compare
+// json.Number values, and number literals written in the path, exactly;
+// custom functions receive number literals as json.Number too. It has no
+// effect with WithDecoder.
+//
+// Example:
+//
+//	results, err := jsonpath.Query(data, "$.orders[?(@.id == 9007199254740993)]", jsonpath.WithUseNumber())
+func WithUseNumber() Option {
+	return func(e *engine) {
+		e.useNumber = true
+	}
+}
+
+// WithExactNumbers decodes numbers in []byte and io.Reader input so that
+// Result.Value holds them without rounding: integers as int64, or *big.Int
+// when they do not fit, and other numbers as float64, or *big.Float when
+// float64 would lose digits. Filters and sorting compare them exactly, as
+// under WithUseNumber. It has no effect with WithDecoder. Note that
+// encoding/json writes a *big.Float inside a matched object or array as a
+// string.
+//
+// Example:
+//
+//	results, err := jsonpath.Query(ledger, "$.entries[*].amount", jsonpath.WithExactNumbers())
+//	// 12345678901234567890 is a *big.Int, 19.99 a float64, 42 an int64
+func WithExactNumbers() Option {
+	return func(e *engine) {
+		e.useNumber = true
+		e.exactNumbers = true
+	}
+}
+
+// WithCaseInsensitiveKeys makes member names in paths match object keys
+// regardless of case, so $.UserName selects "username" or "userName". A key
+// that matches exactly is preferred; otherwise the first matching key in
+// lexical order is selected. Result paths name the key as the document
+// spells it. It applies to filter operands too.
+func WithCaseInsensitiveKeys() Option {
+	return func(e *engine) {
+		e.foldKeys = true
+	}
+}
+
+// WithMaxNodes aborts evaluation with ErrBudgetExceeded once more than n nodes
+// have been visited, including nodes visited while resolving filter operands.
+// Default is 0 (unlimited). Use it to bound the cost of user-supplied paths.
+func WithMaxNodes(n int) Option {
+	return func(e *engine) {
+		e.maxNodes = n
+	}
+}
+
+// WithBudget aborts evaluation with ErrBudgetExceeded once it has run for
+// longer than d. Unlike a context deadline it needs no cooperation from the
+// caller. Default is 0 (unlimited).
+func WithBudget(d time.Duration) Option {
+	return func(e *engine) {
+		e.budget = d
+	}
+}
+
+// WithTimeout cancels evaluation once it has run for longer than d, as if
+// it had been given a context with that timeout: the error has code
+// ErrCancelled and wraps context.DeadlineExceeded, and FilterFuncs receive
+// a context with the deadline. It composes with the caller's context, so
+// whichever ends first stops the query. Default is 0 (no timeout).
+//
+// Example:
+//
+//	results, err := jsonpath.Query(data, "$..price", jsonpath.WithTimeout(100*time.Millisecond))
+func WithTimeout(d time.Duration) Option {
+	return func(e *engine) {
+		e.timeout = d
+	}
+}
+
+// WithCache makes string-path functions such as Query look up compiled paths
+// in c instead of parsing the path on every call. See NewCache.
+func WithCache(c *Cache) Option {
+	return func(e *engine) {
+		e.cache = c
+	}
+}
+
+// WithOffsets fills Result.Start and Result.End with the byte offsets of each
+// match in the source JSON. It applies to queries over []byte input and to
+// Documents created with Parse; other inputs have no source text to point into.
+// The offsets index is built on first use and kept by the Document.
+func WithOffsets() Option {
+	return func(e *engine) {
+		e.offsets = true
+	}
+}
+
+// WithRawValues makes Result.Value a json.RawMessage holding the matched
+// value's source text rather than its decoded form, which saves re-encoding
+// matches that are only forwarded or stored. The RawMessage shares memory
+// with the input. Like WithOffsets it applies to []byte input and Documents
+// created with Parse; other inputs keep decoded values.
+func WithRawValues() Option {
+	return func(e *engine) {
+		e.rawValues = true
+	}
+}
+
+// WithDecoder replaces encoding/json for decoding []byte input in Query,
+// QueryFunc, CompiledPath.Query, Parse and QueryLines, so a faster decoder
+// such as sonic, jsoniter or go-json can be used. decode must return the
+// same kinds of values as json.Unmarshal into an interface{}. Source
+// offsets and raw values still work, as they are read from the input bytes.
+//
+// Example:
+//
+//	results, err := jsonpath.Query(data, "$.items[*].id", jsonpath.WithDecoder(func(b []byte) (interface{}, error) {
+//	    var v interface{}
+//	    err := sonic.Unmarshal(b, &v)
+//	    return v, err
+//	}))
+func WithDecoder(decode func([]byte) (interface{}, error)) Option {
+	return func(e *engine) {
+		e.decode = decode
+	}
+}
+
+// WithExpectedResults hints that a query will produce about n matches so the
+// result slice can be allocated once up front. It never limits the results.
+func WithExpectedResults(n int) Option {
+	return func(e *engine) {
+		e.expected = n
+	}
+}
+
+// WithFunction registers fn under name so filter expressions can call it,
+// e.g. [?(hasPrefix(@.sku, 'X-'))]. The function receives the query's
+// context, so deadlines and request-scoped values reach custom code.
+func WithFunction(name string, fn FilterFunc) Option {
+	return func(e *engine) {
+		if e.funcs == nil {
+			e.funcs = make(map[string]FilterFunc)
+		}
+		e.funcs[name] = fn
+	}
+}
+
 // Query executes a JSONPath expression against a JSON document and returns all matches.
 //
 // Example:
@@ -94,22 +289,26 @@ func QueryContext(ctx context.Context, data []byte, path string, opts ...Option)
 		return nil, &Error{Code: ErrInvalidInput, Message: "context must not be nil"}
 	}
 
-	var root interface{}
-	if err := json.Unmarshal(data, &root); err != nil {
-		return nil, &Error{Code: ErrInvalidJSON, Message: "failed to parse JSON", Cause: err}
+	doc, err := Parse(data, opts...)
+	if err != nil {
+		return nil, err
 	}
 
-	return QueryValueContext(ctx, root, path, opts...)
+	return doc.QueryContext(ctx, path, opts...)
 }
 
 // QueryValue executes a JSONPath expression against an already-parsed Go value.
-// Accepts any value produced by json.Unmarshal (map[string]interface{}, []interface{}, etc.).
+// Accepts any value produced by json.Unmarshal (map[string]interface{}, []interface{}, etc.),
+// as well as Go structs, slices, maps and pointers to them, whose members are
+// named by their json struct tags as encoding/json would name them.
 //
 // Example:
 //
 //	var doc interface{}
 //	json.Unmarshal(data, &doc)
 //	results, err := jsonpath.QueryValue(doc, "$.users[*].name")
+//
+//	addrs, err := jsonpath.QueryValue(&cfg, "$.servers[*].addr") // cfg is a struct
 func QueryValue(root interface{}, path string, opts ...Option) ([]Result, error) {
 	return QueryValueContext(context.Background(), root, path, opts...)
 }
@@ -120,25 +319,36 @@ func QueryValueContext(ctx context.Context, root interface{}, path string, opts
 		return nil, &Error{Code: ErrInvalidInput, Message: "context must not be nil"}
 	}
 
-	e := &engine{
-		maxDepth: 100,
-	}
-	for _, opt := range opts {
-		opt(e)
-	}
-	e.ctx = ctx
+	e := newEngine(ctx, opts)
 
-	tokens, err := tokenize(path)
+	cp, err := e.compile(path)
 	if err != nil {
 		return nil, err
 	}
 
-	results, err := e.evaluate(root, tokens, "$")
+	return e.collect(root, cp.tokens)
+}
+
+// QueryFunc executes a JSONPath expression and calls fn for each match as it is
+// found, without accumulating a result slice. Return ErrStop from fn to end the
+// query early without error; any other error aborts the query and is returned as is.
+//
+// Example:
+//
+//	err := jsonpath.QueryFunc(ctx, data, "$..entry[*]", func(r jsonpath.Result) error {
+//	    return enc.Encode(r.Value)
+//	})
+func QueryFunc(ctx context.Context, data []byte, path string, fn func(Result) error, opts ...Option) error {
+	if ctx == nil {
+		return &Error{Code: ErrInvalidInput, Message: "context must not be nil"}
+	}
+
+	doc, err := Parse(data, opts...)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
-	return results, nil
+	return doc.QueryFunc(ctx, path, fn, opts...)
 }
 
 // First returns the first result from a JSONPath query, or nil if no results.
@@ -161,6 +371,39 @@ func First(data []byte, path string, opts ...Option) (*Result, error) {
 	return &results[0], nil
 }
 
+// QueryOne returns the single match of a path that must select exactly one
+// node, as configuration lookups and ID-based extractions do. It fails with
+// ErrNoMatch when nothing matches and ErrMultipleMatches when more than one
+// node does; evaluation stops at the second match.
+//
+// Example:
+//
+//	r, err := jsonpath.QueryOne(data, "$.users[?(@.id == 42)].email")
+//	if jsonpath.IsMultipleMatches(err) {
+//	    return fmt.Errorf("duplicate user id 42")
+//	}
+func QueryOne(data []byte, path string, opts ...Option) (Result, error) {
+	cp, err := newEngine(context.Background(), opts).compile(path)
+	if err != nil {
+		return Result{}, err
+	}
+	return cp.QueryOne(data, opts...)
+}
+
+// Count returns the number of matches of a JSONPath expression without
+// collecting them. See CompiledPath.Count.
+//
+// Example:
+//
+//	n, err := jsonpath.Count(data, "$.orders[?(@.status == 'open')]")
+func Count(data []byte, path string, opts ...Option) (int, error) {
+	cp, err := newEngine(context.Background(), opts).compile(path)
+	if err != nil {
+		return 0, err
+	}
+	return cp.Count(data, opts...)
+}
+
 // Values extracts just the values from a query result, discarding path information.
 //
 // Example:
@@ -237,12 +480,10 @@ type CompiledPath struct {
 }
 
 // Compile parses and validates a JSONPath expression, returning a CompiledPath for reuse.
-func Compile(path string) (*CompiledPath, error) {
-	tokens, err := tokenize(path)
-	if err != nil {
-		return nil, err
-	}
-	return &CompiledPath{raw: path, tokens: tokens}, nil
+// Options such as WithMaxPathLength reject paths that exceed their limits;
+// the others are ignored here and take effect when the path is queried.
+func Compile(path string, opts ...Option) (*CompiledPath, error) {
+	return newEngine(context.Background(), opts).compile(path)
 }
 
 // MustCompile compiles a JSONPath expression and panics if invalid.
@@ -266,17 +507,59 @@ func (cp *CompiledPath) QueryContext(ctx context.Context, data []byte, opts ...O
 		return nil, &Error{Code: ErrInvalidInput, Message: "context must not be nil"}
 	}
 
-	var root interface{}
-	if err := json.Unmarshal(data, &root); err != nil {
-		return nil, &Error{Code: ErrInvalidJSON, Message: "failed to parse JSON", Cause: err}
+	doc, err := Parse(data, opts...)
+	if err != nil {
+		return nil, err
 	}
 
-	e := &engine{maxDepth: 100, ctx: ctx}
-	for _, opt := range opts {
-		opt(e)
+	return cp.QueryDocumentContext(ctx, doc, opts...)
+}
+
+// QueryFunc executes the pre-compiled path against a JSON document, calling fn
+// for each match. See the package-level QueryFunc for the semantics of fn.
+func (cp *CompiledPath) QueryFunc(ctx context.Context, data []byte, fn func(Result) error, opts ...Option) error {
+	if ctx == nil {
+		return &Error{Code: ErrInvalidInput, Message: "context must not be nil"}
 	}
 
-	return e.evaluate(root, cp.tokens, "$")
+	doc, err := Parse(data, opts...)
+	if err != nil {
+		return err
+	}
+
+	e := newEngine(ctx, opts)
+	e.attach(doc)
+	return e.stream(doc.root, cp.tokens, fn)
+}
+
+// Count returns the number of matches of the pre-compiled path. Unless
+// strict mode or WithStats needs them, result paths are never built, so
+// counting is cheaper than len(Query(...)).
+func (cp *CompiledPath) Count(data []byte, opts ...Option) (int, error) {
+	return cp.CountContext(context.Background(), data, opts...)
+}
+
+// CountContext is Count with context support.
+func (cp *CompiledPath) CountContext(ctx context.Context, data []byte, opts ...Option) (int, error) {
+	if ctx == nil {
+		return 0, &Error{Code: ErrInvalidInput, Message: "context must not be nil"}
+	}
+	e := newEngine(ctx, opts)
+	doc, err := e.parse(data)
+	if err != nil {
+		return 0, err
+	}
+	e.noPaths = e.stats == nil && !e.strictKeys && e.onMatch == nil && e.onVisit == nil && e.logger == nil && !e.unique && !usesParent(cp.tokens)
+	e.sortOrder = 0 // order does not change a count
+	n := 0
+	err = e.stream(doc.root, cp.tokens, func(Result) error {
+		n++
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return n, nil
 }
 
 // QueryValue executes the pre-compiled path against a parsed Go value.
@@ -286,59 +569,264 @@ func (cp *CompiledPath) QueryValue(root interface{}, opts ...Option) ([]Result,
 
 // QueryValueContext executes the pre-compiled path against a parsed Go value with context.
 func (cp *CompiledPath) QueryValueContext(ctx context.Context, root interface{}, opts ...Option) ([]Result, error) {
-	e := &engine{maxDepth: 100, ctx: ctx}
-	for _, opt := range opts {
-		opt(e)
+	return newEngine(ctx, opts).collect(root, cp.tokens)
+}
+
+// First returns the first match of the pre-compiled path, or nil if there is
+// none. Evaluation stops at the first match.
+func (cp *CompiledPath) First(data []byte, opts ...Option) (*Result, error) {
+	return cp.FirstContext(context.Background(), data, opts...)
+}
+
+// FirstContext is First with context support.
+func (cp *CompiledPath) FirstContext(ctx context.Context, data []byte, opts ...Option) (*Result, error) {
+	var first *Result
+	err := cp.QueryFunc(ctx, data, func(r Result) error {
+		first = &r
+		return ErrStop
+	}, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return first, nil
+}
+
+// QueryOne returns the single match of the pre-compiled path. See the
+// package-level QueryOne.
+func (cp *CompiledPath) QueryOne(data []byte, opts ...Option) (Result, error) {
+	var one *Result
+	err := cp.QueryFunc(context.Background(), data, func(r Result) error {
+		if one != nil {
+			return &Error{Code: ErrMultipleMatches, Message: fmt.Sprintf("path %s matched more than one node (%s, %s)", cp.raw, one.Path, r.Path)}
+		}
+		one = &r
+		return nil
+	}, opts...)
+	if err != nil {
+		return Result{}, err
+	}
+	if one == nil {
+		return Result{}, &Error{Code: ErrNoMatch, Message: fmt.Sprintf("path %s matched nothing", cp.raw)}
+	}
+	return *one, nil
+}
+
+// Values returns the values matched by the pre-compiled path.
+func (cp *CompiledPath) Values(data []byte, opts ...Option) ([]interface{}, error) {
+	return cp.ValuesContext(context.Background(), data, opts...)
+}
+
+// ValuesContext is Values with context support.
+func (cp *CompiledPath) ValuesContext(ctx context.Context, data []byte, opts ...Option) ([]interface{}, error) {
+	vals := []interface{}{}
+	err := cp.QueryFunc(ctx, data, func(r Result) error {
+		vals = append(vals, r.Value)
+		return nil
+	}, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return vals, nil
+}
+
+// Paths returns the normalized paths matched by the pre-compiled path.
+func (cp *CompiledPath) Paths(data []byte, opts ...Option) ([]string, error) {
+	return cp.PathsContext(context.Background(), data, opts...)
+}
+
+// PathsContext is Paths with context support.
+func (cp *CompiledPath) PathsContext(ctx context.Context, data []byte, opts ...Option) ([]string, error) {
+	paths := []string{}
+	err := cp.QueryFunc(ctx, data, func(r Result) error {
+		paths = append(paths, r.Path)
+		return nil
+	}, opts...)
+	if err != nil {
+		return nil, err
 	}
-	return e.evaluate(root, cp.tokens, "$")
+	return paths, nil
+}
+
+// Exists reports whether the pre-compiled path matches at least one value.
+// Evaluation stops at the first match.
+func (cp *CompiledPath) Exists(data []byte, opts ...Option) (bool, error) {
+	return cp.ExistsContext(context.Background(), data, opts...)
+}
+
+// ExistsContext is Exists with context support.
+func (cp *CompiledPath) ExistsContext(ctx context.Context, data []byte, opts ...Option) (bool, error) {
+	first, err := cp.FirstContext(ctx, data, opts...)
+	return first != nil, err
 }
 
 // String returns the original path string.
 func (cp *CompiledPath) String() string {
+	if cp == nil {
+		return ""
+	}
 	return cp.raw
 }
 
+// Set compiles s into cp. With String it makes *CompiledPath a flag.Value,
+// so an invalid path is rejected while the command line is parsed.
+//
+// Example:
+//
+//	var sel jsonpath.CompiledPath
+//	flag.Var(&sel, "select", "JSONPath of the values to print")
+//	flag.Parse()
+func (cp *CompiledPath) Set(s string) error {
+	return cp.UnmarshalText([]byte(s))
+}
+
+// MarshalText implements encoding.TextMarshaler, returning the original path
+// string. With UnmarshalText it lets a CompiledPath be a field of a JSON,
+// YAML or TOML configuration struct, held by value or by pointer. The zero
+// CompiledPath marshals as empty text.
+func (cp CompiledPath) MarshalText() ([]byte, error) {
+	return []byte(cp.raw), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler. It compiles text, so an
+// invalid path in a configuration file is reported when the file is loaded
+// rather than when the path is first used. Empty text, as the zero
+// CompiledPath marshals, sets cp to the zero CompiledPath.
+//
+// Example:
+//
+//	var cfg struct {
+//	    Select jsonpath.CompiledPath `json:"select"`
+//	}
+//	err := json.Unmarshal([]byte(`{"select": "$.items[?(@.active)]"}`), &cfg)
+func (cp *CompiledPath) UnmarshalText(text []byte) error {
+	if len(text) == 0 {
+		*cp = CompiledPath{}
+		return nil
+	}
+	compiled, err := Compile(string(text))
+	if err != nil {
+		return err
+	}
+	*cp = *compiled
+	return nil
+}
+
 // --- Internal token types ---
 
 type tokenKind int
 
 const (
-	tokenRoot       tokenKind = iota // $
-	tokenChild                       // .key or ['key']
-	tokenRecursive                   // ..
-	tokenWildcard                    // *
-	tokenIndex                       // [n]
-	tokenSlice                       // [start:end:step]
-	tokenFilter                      // [?(...)]
-	tokenUnion                       // [key1,key2] or [0,1,2]
+	tokenRoot         tokenKind = iota // $
+	tokenChild                         // .key or ['key']
+	tokenRecursive                     // ..
+	tokenWildcard                      // *
+	tokenIndex                         // [n]
+	tokenSlice                         // [start:end:step]
+	tokenFilter                        // [?(...)]
+	tokenUnion                         // [key1,key2] or [0,1,2]
+	tokenAlternatives                  // $.a | $.b
+	tokenFunction                      // .length()
+	tokenParent                        // ^
+	tokenNames                         // ~
+	tokenPattern                       // .cpu_* or [/^feature_/]
+	tokenDefault                       // $.a ?? 30
 )
 
 type token struct {
 	kind    tokenKind
-	key     string   // for child
+	key     string   // for child, the name of a function, or the source of a pattern
 	index   int      // for index
 	indices []int    // for union of indices
 	keys    []string // for union of keys
 	slice   [3]*int  // start, end, step (nil = absent)
 	filter  string   // for filter expression
+	expr    filterExpr
+	alts    [][]token       // for alternatives, each starting with the root, or the path of a default
+	literal *literalOperand // for default
 }
 
 // --- Tokenizer ---
 
 func tokenize(path string) ([]token, error) {
-	if path == "" {
-		return nil, &Error{Code: ErrInvalidPath, Message: "path must not be empty"}
+	s := &pathScanner{}
+	tokens := s.scan(path, 0)
+	if len(s.diags) > 0 {
+		err := s.diags[0].err
+		err.Snippet = snippet(path, err.Offset)
+		return nil, err
+	}
+	return tokens, nil
+}
+
+// snippet returns path with a caret line under offset.
+func snippet(path string, offset int) string {
+	if offset > len(path) {
+		offset = len(path)
 	}
+	return path + "\n" + strings.Repeat(" ", utf8.RuneCountInString(path[:offset])) + "^"
+}
 
-	path = strings.TrimSpace(path)
+// shiftError moves the offset of a parse error from err by n bytes, to
+// place it in the text enclosing the part that failed.
+func shiftError(err error, n int) error {
+	if e, ok := err.(*Error); ok {
+		e.Offset += n
+	}
+	return err
+}
 
-	if path[0] != '$' {
-		return nil, &Error{Code: ErrInvalidPath, Message: "path must start with '$'"}
+// pathScanner turns path text into tokens. It stops at the first problem
+// unless all is set, in which case it skips past each problem and carries
+// on, so that Validate can report every one.
+type pathScanner struct {
+	all   bool
+	diags []Diagnostic
+	// gateParent rejects ^, for Validate without WithParentSelector.
+	gateParent bool
+}
+
+// fail records a problem at offset and reports whether to carry on.
+func (s *pathScanner) fail(offset int, code string, err *Error, suggestion string) bool {
+	err.Offset = offset
+	s.diags = append(s.diags, Diagnostic{Offset: offset, Code: code, Message: err.Message, Suggestion: suggestion, err: err})
+	return s.all
+}
+
+// scan tokenizes path, which starts at offset base of the text given to
+// Compile or Validate.
+func (s *pathScanner) scan(path string, base int) []token {
+	trimmed := strings.TrimSpace(path)
+	base += strings.Index(path, trimmed)
+	path = trimmed
+	if path == "" {
+		s.fail(base, "empty-path", &Error{Code: ErrInvalidPath, Message: "path must not be empty"}, "")
+		return nil
+	}
+	if left, right, ok := splitDefault(path); ok {
+		return s.scanDefault(left, right, base)
+	}
+	if parts := splitAlternatives(path); len(parts) > 1 {
+		return s.scanAlternatives(parts, base)
 	}
 
 	tokens := []token{{kind: tokenRoot}}
 	i := 1
+	if path[0] != '$' {
+		if !s.fail(base, "missing-root", &Error{Code: ErrInvalidPath, Message: "path must start with '$'"}, "did you mean "+withRoot(path)+"?") {
+			return nil
+		}
+		switch path[0] {
+		case '@':
+		case '.', '[':
+			i = 0
+		default:
+			key, advance := readIdentifier(path)
+			tokens = append(tokens, token{kind: tokenChild, key: key})
+			i = advance
+		}
+	}
 
+	memberStart, memberEnd := -1, -1 // the last dot-notation member name
 	for i < len(path) {
 		switch {
 		case path[i] == '.':
@@ -346,89 +834,377 @@ func tokenize(path string) ([]token, error) {
 				tokens = append(tokens, token{kind: tokenRecursive})
 				i += 2
 				// after .., if there's a key or wildcard, collect it
-				if i < len(path) && path[i] != '[' && path[i] != '.' {
+				if glob, n := readGlob(path[i:]); n > 0 && glob != "*" {
+					tokens = append(tokens, globToken(glob))
+					i += n
+				} else if i < len(path) && path[i] == '*' {
+					tokens = append(tokens, token{kind: tokenWildcard})
+					i++
+				} else if i < len(path) && path[i] != '[' && path[i] != '.' {
 					key, advance := readIdentifier(path[i:])
 					if key == "*" {
 						tokens = append(tokens, token{kind: tokenWildcard})
 					} else if key != "" {
 						tokens = append(tokens, token{kind: tokenChild, key: key})
+						memberStart, memberEnd = i, i+advance
 					}
 					i += advance
 				}
 			} else {
 				i++
 				if i >= len(path) {
-					return nil, &Error{Code: ErrInvalidPath, Message: "unexpected end after '.'"}
+					s.fail(base+i-1, "trailing-dot", &Error{Code: ErrInvalidPath, Message: "unexpected end after '.'"}, "remove the trailing '.'")
+					return nil
 				}
 				key, advance := readIdentifier(path[i:])
-				if key == "*" {
+				if glob, n := readGlob(path[i:]); n > 0 && glob != "*" {
+					tokens = append(tokens, globToken(glob))
+					advance = n
+				} else if path[i] == '*' {
 					tokens = append(tokens, token{kind: tokenWildcard})
+					advance = 1
+				} else if key != "" && strings.HasPrefix(path[i+advance:], "()") {
+					if !pathFunctions[key] {
+						err := &Error{Code: ErrInvalidPath, Message: fmt.Sprintf("unknown function %s() at position %d", key, base+i)}
+						if !s.fail(base+i, "unknown-function", err, "use length() or size()") {
+							return nil
+						}
+					}
+					tokens = append(tokens, token{kind: tokenFunction, key: key})
+					advance += len("()")
+					if i+advance < len(path) {
+						err := &Error{Code: ErrInvalidPath, Message: fmt.Sprintf("%s() must be the last selector of the path", key)}
+						if !s.fail(base+i+advance, "function-not-last", err, "remove the selectors after "+key+"()") {
+							return nil
+						}
+						advance = len(path) - i
+					}
 				} else if key != "" {
 					tokens = append(tokens, token{kind: tokenChild, key: key})
+					memberStart, memberEnd = i, i+advance
 				} else {
-					return nil, &Error{Code: ErrInvalidPath, Message: fmt.Sprintf("expected key after '.' at position %d", i)}
+					end := nextSelector(path, i)
+					err := &Error{Code: ErrInvalidPath, Message: fmt.Sprintf("expected key after '.' at position %d", base+i)}
+					if !s.fail(base+i, "invalid-member", err, quoteSuggestion(path, i, end)) {
+						return nil
+					}
+					advance = end - i
 				}
 				i += advance
 			}
 		case path[i] == '[':
 			t, advance, err := parseBracket(path[i:])
 			if err != nil {
-				return nil, err
+				code, suggestion := bracketProblem(path[i:], err)
+				if !s.fail(base+i+err.(*Error).Offset, code, err.(*Error), suggestion) {
+					return nil
+				}
+				if end := bracketEnd(path[i:]); end >= 0 {
+					i += end + 1
+				} else {
+					i = len(path)
+				}
+				continue
 			}
 			tokens = append(tokens, t)
 			i += advance
+		case path[i] == '^':
+			if s.gateParent {
+				if !s.fail(base+i, "parent-selector", &Error{Code: ErrInvalidPath, Message: parentGateMessage}, "enable ^ with WithParentSelector") {
+					return nil
+				}
+			}
+			tokens = append(tokens, token{kind: tokenParent})
+			i++
+		case path[i] == '~':
+			tokens = append(tokens, token{kind: tokenNames})
+			i++
+			if i < len(path) {
+				err := &Error{Code: ErrInvalidPath, Message: "~ must be the last selector of the path"}
+				if !s.fail(base+i, "names-not-last", err, "remove the selectors after ~") {
+					return nil
+				}
+				i = len(path)
+			}
 		default:
-			return nil, &Error{Code: ErrInvalidPath, Message: fmt.Sprintf("unexpected character '%c' at position %d", path[i], i)}
+			end := nextSelector(path, i)
+			suggestion := ""
+			if i == memberEnd {
+				suggestion = quoteSuggestion(path, memberStart, end)
+			}
+			err := &Error{Code: ErrInvalidPath, Message: fmt.Sprintf("unexpected character '%c' at position %d", path[i], base+i)}
+			if !s.fail(base+i, "unexpected-character", err, suggestion) {
+				return nil
+			}
+			i = end
 		}
 	}
 
-	return tokens, nil
+	return tokens
 }
 
-func readIdentifier(s string) (string, int) {
-	i := 0
-	for i < len(s) && (isAlphaNum(s[i]) || s[i] == '_' || s[i] == '-') {
-		i++
+// scanAlternatives tokenizes the alternatives of a path such as $.a | $.b.
+func (s *pathScanner) scanAlternatives(parts []string, base int) []token {
+	alts := make([][]token, len(parts))
+	offset := base
+	for i, part := range parts {
+		if strings.TrimSpace(part) == "" {
+			err := &Error{Code: ErrInvalidPath, Message: fmt.Sprintf("alternative %d of the path is empty", i+1)}
+			if !s.fail(offset, "empty-alternative", err, "remove the extra '|'") {
+				return nil
+			}
+		} else {
+			n := len(s.diags)
+			alts[i] = s.scan(part, offset)
+			if len(s.diags) > n && !s.all {
+				return nil
+			}
+		}
+		offset += len(part) + 1
 	}
-	return s[:i], i
+	return []token{{kind: tokenRoot}, {kind: tokenAlternatives, alts: alts}}
 }
 
-func isAlphaNum(c byte) bool {
-	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')
+// nextSelector returns the offset of the first '.' or '[' in path after i,
+// or the end of path.
+func nextSelector(path string, i int) int {
+	if j := strings.IndexAny(path[i+1:], ".["); j >= 0 {
+		return i + 1 + j
+	}
+	return len(path)
 }
 
-func parseBracket(s string) (token, int, error) {
-	// s starts with '['
-	end := strings.Index(s, "]")
-	if end < 0 {
-		return token{}, 0, &Error{Code: ErrInvalidPath, Message: "unclosed '['"}
+// quoteSuggestion suggests bracket notation for the member name that spans
+// path[start:end] and follows a '.'.
+func quoteSuggestion(path string, start, end int) string {
+	key := strings.Trim(path[start:end], `'"`)
+	if key == "" || start == 0 || path[start-1] != '.' {
+		return ""
 	}
+	return "did you mean " + path[:start-1] + "[" + quoteKey(key) + "]" + path[end:] + "?"
+}
 
-	inner := s[1:end]
-
-	// Filter: [?(...)]
-	if strings.HasPrefix(inner, "?(") && strings.HasSuffix(inner, ")") {
-		expr := inner[2 : len(inner)-1]
-		return token{kind: tokenFilter, filter: expr}, end + 1, nil
+// withRoot returns path made to start with '$'.
+func withRoot(path string) string {
+	switch path[0] {
+	case '@':
+		return "$" + path[1:]
+	case '.', '[':
+		return "$" + path
 	}
+	return "$." + path
+}
 
-	// Wildcard: [*]
-	if inner == "*" {
-		return token{kind: tokenWildcard}, end + 1, nil
+// bracketProblem classifies an error from parseBracket.
+func bracketProblem(s string, err error) (code, suggestion string) {
+	switch {
+	case !strings.Contains(s, "]"):
+		return "unclosed-bracket", "close the bracket with ']'"
+	case IsFilterError(err):
+		if filter := s[:bracketEnd(s)]; loneEquals(filter) {
+			return "invalid-filter", "use == to compare values"
+		}
+		return "invalid-filter", ""
 	}
+	return "invalid-bracket", ""
+}
 
-	// Quoted key: ['key'] or ["key"]
-	if (strings.HasPrefix(inner, "'") && strings.HasSuffix(inner, "'")) ||
-		(strings.HasPrefix(inner, `"`) && strings.HasSuffix(inner, `"`)) {
-		key := inner[1 : len(inner)-1]
-		return token{kind: tokenChild, key: key}, end + 1, nil
+// loneEquals reports whether s has an '=' that is not part of ==, !=, <=,
+// >= or =~.
+func loneEquals(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] != '=' {
+			continue
+		}
+		if i+1 < len(s) && (s[i+1] == '=' || s[i+1] == '~') {
+			i++
+			continue
+		}
+		if i > 0 && strings.IndexByte("=!<>", s[i-1]) >= 0 {
+			continue
+		}
+		return true
 	}
+	return false
+}
 
-	// Union: [a,b,c]
-	if strings.Contains(inner, ",") {
-		parts := strings.Split(inner, ",")
-		// Try integer union first
-		allInts := true
+// readIdentifier reads the member name at the start of s written in dot
+// notation: letters, digits, '_', '-' and any non-ASCII characters, such as
+// $.ключ or $.🚀.
+func readIdentifier(s string) (string, int) {
+	i := 0
+	for i < len(s) && (isAlphaNum(s[i]) || s[i] == '_' || s[i] == '-' || s[i] >= utf8.RuneSelf) {
+		i++
+	}
+	return s[:i], i
+}
+
+func isAlphaNum(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')
+}
+
+// readQuoted reads the string literal at the start of s, which begins with
+// ' or ", and returns its value and length. Backslash escapes are those of
+// JSON strings, including \uXXXX and surrogate pairs for characters outside
+// the Basic Multilingual Plane; a backslash before any other character,
+// or before a malformed \u, stands for that character, as quoteKey writes
+// quotes and backslashes. ok is false if the literal is not closed.
+func readQuoted(s string) (value string, n int, ok bool) {
+	var b strings.Builder
+	for i := 1; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c == s[0]:
+			return b.String(), i + 1, true
+		case c != '\\' || i+1 == len(s):
+			b.WriteByte(c)
+			continue
+		}
+		i++
+		switch c = s[i]; c {
+		case 'b':
+			b.WriteByte('\b')
+		case 'f':
+			b.WriteByte('\f')
+		case 'n':
+			b.WriteByte('\n')
+		case 'r':
+			b.WriteByte('\r')
+		case 't':
+			b.WriteByte('\t')
+		case 'u':
+			r, n := readUnicodeEscape(s[i-1:])
+			if n == 0 {
+				b.WriteByte(c)
+				continue
+			}
+			b.WriteRune(r)
+			i += n - 2
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return "", 0, false
+}
+
+// readUnicodeEscape decodes the \uXXXX escape at the start of s, joining a
+// surrogate pair written as two escapes, and returns the rune and the length
+// of the escapes, or 0 if s does not start with one. A lone surrogate
+// decodes to U+FFFD.
+func readUnicodeEscape(s string) (rune, int) {
+	hex := func(s string) (rune, bool) {
+		if len(s) < 6 || s[0] != '\\' || s[1] != 'u' {
+			return 0, false
+		}
+		v, err := strconv.ParseUint(s[2:6], 16, 16)
+		return rune(v), err == nil
+	}
+	r, ok := hex(s)
+	if !ok {
+		return 0, 0
+	}
+	if utf16.IsSurrogate(r) {
+		if lo, ok := hex(s[6:]); ok {
+			if pair := utf16.DecodeRune(r, lo); pair != utf8.RuneError {
+				return pair, 12
+			}
+		}
+	}
+	return r, 6
+}
+
+// bracketEnd returns the index of the ']' that closes the bracket s starts
+// with, skipping quoted strings and nested brackets. If those are unbalanced
+// it falls back to the first ']', so that the error is reported inside the
+// bracket; it returns -1 only if there is none.
+func bracketEnd(s string) int {
+	depth := 0
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '\'', '"':
+			_, n, ok := readQuoted(s[i:])
+			if !ok {
+				return strings.IndexByte(s, ']')
+			}
+			i += n - 1
+		case '[':
+			depth++
+		case ']':
+			if depth--; depth == 0 {
+				return i
+			}
+		}
+	}
+	return strings.IndexByte(s, ']')
+}
+
+func parseBracket(s string) (token, int, error) {
+	// s starts with '['
+	end := bracketEnd(s)
+	if end < 0 {
+		return token{}, 0, &Error{Code: ErrInvalidPath, Message: "unclosed '['"}
+	}
+
+	inner := s[1:end]
+
+	// Filter: [?(...)]
+	if strings.HasPrefix(inner, "?(") && strings.HasSuffix(inner, ")") {
+		filter := inner[2 : len(inner)-1]
+		expr, err := parseFilter(filter)
+		if err == nil {
+			return token{kind: tokenFilter, filter: filter, expr: expr}, end + 1, nil
+		}
+		// the parentheses need not be a pair, as in [?(@.a) && (@.b)]
+		if _, whole := parseFilter(inner[1:]); whole != nil {
+			return token{}, 0, shiftError(err, len("[?("))
+		}
+	}
+
+	// Filter without parentheses, as in RFC 9535: [?@.price < 10]
+	if strings.HasPrefix(inner, "?") {
+		filter := strings.TrimSpace(inner[1:])
+		expr, err := parseFilter(filter)
+		if err != nil {
+			return token{}, 0, shiftError(err, len("[?")+strings.Index(inner[1:], filter))
+		}
+		return token{kind: tokenFilter, filter: filter, expr: expr}, end + 1, nil
+	}
+
+	// Script: [(@.length-1)], the length-relative subset of Goessner's
+	// script expressions, which selects the same element as [-1]
+	if strings.HasPrefix(inner, "(") && strings.HasSuffix(inner, ")") {
+		n, err := parseScript(inner[1 : len(inner)-1])
+		if err != nil {
+			return token{}, 0, shiftError(err, len("[("))
+		}
+		return token{kind: tokenIndex, index: n}, end + 1, nil
+	}
+
+	// Member name pattern: [/^feature_/i]
+	if strings.HasPrefix(inner, "/") {
+		t, err := parsePatternBracket(inner)
+		if err != nil {
+			return token{}, 0, err
+		}
+		return t, end + 1, nil
+	}
+
+	// Wildcard: [*]
+	if inner == "*" {
+		return token{kind: tokenWildcard}, end + 1, nil
+	}
+
+	// Quoted key: ['key'] or ["key"], but not a quoted union like ['a','b']
+	if trimmed := strings.TrimSpace(inner); strings.HasPrefix(trimmed, "'") || strings.HasPrefix(trimmed, `"`) {
+		if key, n, ok := readQuoted(trimmed); ok && n == len(trimmed) {
+			return token{kind: tokenChild, key: key}, end + 1, nil
+		}
+	}
+
+	// Union: [a,b,c], where quoted keys may contain commas
+	if parts := splitUnion(inner); len(parts) > 1 {
+		// Try integer union first
+		allInts := true
 		indices := make([]int, 0, len(parts))
 		for _, p := range parts {
 			p = strings.TrimSpace(p)
@@ -446,9 +1222,10 @@ func parseBracket(s string) (token, int, error) {
 		keys := make([]string, len(parts))
 		for i, p := range parts {
 			p = strings.TrimSpace(p)
-			if (strings.HasPrefix(p, "'") && strings.HasSuffix(p, "'")) ||
-				(strings.HasPrefix(p, `"`) && strings.HasSuffix(p, `"`)) {
-				p = p[1 : len(p)-1]
+			if strings.HasPrefix(p, "'") || strings.HasPrefix(p, `"`) {
+				if key, n, ok := readQuoted(p); ok && n == len(p) {
+					p = key
+				}
 			}
 			keys[i] = p
 		}
@@ -459,17 +1236,20 @@ func parseBracket(s string) (token, int, error) {
 	if strings.Contains(inner, ":") {
 		parts := strings.Split(inner, ":")
 		if len(parts) < 2 || len(parts) > 3 {
-			return token{}, 0, &Error{Code: ErrInvalidPath, Message: fmt.Sprintf("invalid slice: %s", inner)}
+			return token{}, 0, &Error{Code: ErrInvalidPath, Message: fmt.Sprintf("invalid slice: %s", inner), Offset: 1}
 		}
 		var slice [3]*int
+		offset := 1
 		for i, p := range parts {
+			at := offset
+			offset += len(p) + 1
 			p = strings.TrimSpace(p)
 			if p == "" {
 				continue
 			}
 			n, err := strconv.Atoi(p)
 			if err != nil {
-				return token{}, 0, &Error{Code: ErrInvalidPath, Message: fmt.Sprintf("invalid slice component: %s", p)}
+				return token{}, 0, &Error{Code: ErrInvalidPath, Message: fmt.Sprintf("invalid slice component: %s", p), Offset: at}
 			}
 			slice[i] = &n
 		}
@@ -482,130 +1262,567 @@ func parseBracket(s string) (token, int, error) {
 		// Could be a bare key like [key]
 		key := strings.TrimSpace(inner)
 		if key == "" {
-			return token{}, 0, &Error{Code: ErrInvalidPath, Message: "empty brackets"}
+			return token{}, 0, &Error{Code: ErrInvalidPath, Message: "empty brackets", Offset: 1}
 		}
 		return token{kind: tokenChild, key: key}, end + 1, nil
 	}
 	return token{kind: tokenIndex, index: n}, end + 1, nil
 }
 
+// splitUnion splits the text of a bracket selector at the commas outside
+// quoted keys.
+func splitUnion(s string) []string {
+	var parts []string
+	start := 0
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '\'', '"':
+			if _, n, ok := readQuoted(s[i:]); ok {
+				i += n - 1
+			} else {
+				i = len(s)
+			}
+		case ',':
+			parts = append(parts, s[start:i])
+			start = i + 1
+		}
+	}
+	return append(parts, s[start:])
+}
+
+// parseScript parses the body of a script subscript: an index, or
+// @.length minus an index, as in $[(@.length-1)]. The result is an index
+// as accepted by tokenIndex, negative when counted from the end. Scripts
+// that select at or past the end, such as (@.length) or (@.length+1), can
+// never match and are rejected like any other unsupported script.
+func parseScript(s string) (int, error) {
+	body := strings.TrimSpace(s)
+	if n, err := strconv.Atoi(body); err == nil {
+		return n, nil
+	}
+	unsupported := &Error{Code: ErrInvalidPath, Message: fmt.Sprintf("unsupported script expression: (%s); only (@.length-n) and integer indices are supported", s)}
+	rest, ok := strings.CutPrefix(body, "@.length")
+	if !ok {
+		return 0, unsupported
+	}
+	rest, ok = strings.CutPrefix(strings.TrimSpace(rest), "-")
+	if !ok {
+		return 0, unsupported
+	}
+	n, err := strconv.Atoi(strings.TrimSpace(rest))
+	if err != nil || n <= 0 {
+		return 0, unsupported
+	}
+	return -n, nil
+}
+
 // --- Evaluator ---
 
 type engine struct {
-	ctx        context.Context
-	maxDepth   int
-	strictKeys bool
+	ctx           context.Context
+	maxDepth      int
+	strictKeys    bool
+	allErrors     bool
+	funcs         map[string]FilterFunc
+	maxNodes      int
+	budget        time.Duration
+	timeout       time.Duration
+	limits        pathLimits
+	onMatch       func(context.Context, Result)
+	onVisit       func(ctx context.Context, path string, kind NodeKind)
+	logger        *slog.Logger
+	metrics       Metrics
+	tracer        func(TraceStep)
+	kinds         []NodeKind
+	allowParent   bool
+	stringSlices  bool
+	skip          int
+	maxResults    int
+	sampleSize    int
+	seed          int64
+	unique        bool
+	cache         *Cache
+	offsets       bool
+	rawValues     bool
+	stats         *Stats
+	expected      int
+	lenient       bool
+	decode        func([]byte) (interface{}, error)
+	useNumber     bool
+	exactNumbers  bool
+	strictNumeric bool
+	sortOrder     SortOrder
+	order         Order
+	foldKeys      bool
+	noPaths       bool // skip building result paths, for Count
+	allAlts       bool
+	leavesOnly    bool
+	arrayLeaves   bool
+
+	spans       map[string]span // from the Document, when offsets or raw values are requested
+	sourceSpans map[string]span // from the Document, for DocumentOrder
+	raw         []byte
+	inserted    []int
+
+	st *evalState
+}
+
+// evalState is the mutable part of an evaluation. It is shared with the
+// sub-engines that resolve filter operands so limits apply to the whole query.
+type evalState struct {
+	stats      Stats
+	started    time.Time
+	deadline   time.Time // from WithBudget
+	expires    time.Time // from WithTimeout
+	violations []error   // strict-mode errors recorded under WithAllErrors
+
+	// the query being reported to WithMetrics
+	query        string
+	queryStats   Stats
+	queryStarted time.Time
+
+	// the node evaluation starts from, for ^
+	root     interface{}
+	rootPath string
+}
+
+func newEngine(ctx context.Context, opts []Option) *engine {
+	e := &engine{maxDepth: 100}
+	for _, opt := range opts {
+		opt(e)
+	}
+	e.ctx = ctx
+	e.st = &evalState{started: time.Now()}
+	if e.budget > 0 {
+		e.st.deadline = e.st.started.Add(e.budget)
+	}
+	if e.timeout > 0 {
+		e.st.expires = e.st.started.Add(e.timeout)
+	}
+	if e.logger != nil && !e.logger.Enabled(ctx, slog.LevelDebug) {
+		e.logger = nil
+	}
+	return e
+}
+
+// compile parses path, consulting the cache configured with WithCache, and
+// enforces the path limits.
+func (e *engine) compile(path string) (*CompiledPath, error) {
+	cp, err := e.compileLimited(path)
+	if err != nil && e.metrics != nil {
+		e.metrics.QueryStarted(e.ctx, path)
+		e.metrics.QueryFinished(e.ctx, path, Stats{}, err)
+	}
+	if e.logger != nil {
+		if err != nil {
+			e.debug("jsonpath: compile failed", "path", path, "error", err)
+		} else {
+			e.debug("jsonpath: compiled", "path", path, "selectors", formatTokens(cp.tokens))
+		}
+	}
+	return cp, err
+}
+
+// compileLimited is compile without logging.
+func (e *engine) compileLimited(path string) (*CompiledPath, error) {
+	if err := e.limits.checkLength(path); err != nil {
+		return nil, err
+	}
+	var cp *CompiledPath
+	if e.cache != nil {
+		var err error
+		if cp, err = e.cache.Compile(path); err != nil {
+			return nil, err
+		}
+	} else {
+		tokens, err := tokenize(path)
+		if err != nil {
+			return nil, err
+		}
+		cp = &CompiledPath{raw: path, tokens: tokens}
+	}
+	if err := e.limits.check(cp.tokens); err != nil {
+		return nil, err
+	}
+	if !e.allowParent && usesParent(cp.tokens) {
+		return nil, &Error{Code: ErrInvalidPath, Message: parentGateMessage}
+	}
+	return cp, nil
+}
+
+// attach makes document-level metadata available to the evaluation.
+func (e *engine) attach(doc *Document) {
+	if e.offsets || e.rawValues {
+		e.spans = doc.spanIndex()
+		e.raw = doc.raw
+		e.inserted = doc.inserted
+	}
+	if e.order == DocumentOrder {
+		e.sourceSpans = doc.spanIndex()
+	}
+}
+
+// operandEngine returns an engine for resolving filter operands: it shares
+// the evaluation state but never reports missing keys.
+func (e *engine) operandEngine() *engine {
+	sub := *e
+	sub.strictKeys = false
+	sub.spans = nil
+	sub.stats = nil
+	sub.onMatch = nil
+	sub.onVisit = nil
+	sub.logger = nil
+	sub.metrics = nil
+	sub.tracer = nil
+	sub.kinds = nil
+	sub.sortOrder, sub.order = 0, LexicalOrder
+	sub.skip, sub.maxResults, sub.sampleSize = 0, 0, 0
+	return &sub
+}
+
+// visit accounts for one visited node, reports it to WithOnVisit and
+// enforces WithMaxNodes, WithBudget and WithTimeout.
+func (e *engine) visit(path string, node interface{}) error {
+	e.st.stats.NodesVisited++
+	if e.onVisit != nil {
+		e.onVisit(e.ctx, path, kindOf(node))
+	}
+	if e.stats != nil || e.metrics != nil {
+		if d := pathDepth(path); d > e.st.stats.MaxDepth {
+			e.st.stats.MaxDepth = d
+		}
+	}
+	visited := e.st.stats.NodesVisited
+	if e.maxNodes > 0 && visited > e.maxNodes {
+		return e.stop(path, &Error{Code: ErrBudgetExceeded, Message: fmt.Sprintf("node budget of %d exceeded", e.maxNodes)})
+	}
+	// Reading the clock on every node is measurable; every 64th is plenty.
+	if visited%64 != 1 || (e.st.deadline.IsZero() && e.st.expires.IsZero()) {
+		return nil
+	}
+	now := time.Now()
+	if !e.st.deadline.IsZero() && now.After(e.st.deadline) {
+		return e.stop(path, &Error{Code: ErrBudgetExceeded, Message: fmt.Sprintf("time budget of %s exceeded", e.budget)})
+	}
+	if !e.st.expires.IsZero() && now.After(e.st.expires) {
+		return e.stop(path, &Error{Code: ErrCancelled, Message: fmt.Sprintf("timeout of %s exceeded", e.timeout), Cause: context.DeadlineExceeded})
+	}
+	return nil
+}
+
+// funcContext returns the context passed to FilterFuncs: the caller's, with
+// the deadline of WithTimeout applied.
+func (e *engine) funcContext() (context.Context, context.CancelFunc) {
+	if e.st.expires.IsZero() {
+		return e.ctx, func() {}
+	}
+	return context.WithDeadline(e.ctx, e.st.expires)
+}
+
+// lookupError reports a strict-mode violation at path. Under WithAllErrors it
+// is recorded instead and evaluation carries on past the node.
+func (e *engine) lookupError(code ErrorCode, path, format string, args ...interface{}) error {
+	err := &Error{Code: code, Message: fmt.Sprintf(format, args...), Path: path}
+	if e.allErrors {
+		e.st.violations = append(e.st.violations, err)
+		return nil
+	}
+	return err
+}
+
+// violations forgets the violations recorded since mark and returns them
+// joined into one error, or nil if there are none.
+func (e *engine) violations(mark int) error {
+	v := e.st.violations[mark:]
+	e.st.violations = e.st.violations[:mark]
+	return errors.Join(v...)
+}
+
+// member returns the member of obj named key, and the key it is stored
+// under, honouring WithCaseInsensitiveKeys.
+func (e *engine) member(obj Object, key string) (interface{}, string, bool) {
+	if v, ok := obj.Get(key); ok || !e.foldKeys {
+		return v, key, ok
+	}
+	for _, k := range obj.Keys() {
+		if strings.EqualFold(k, key) {
+			v, _ := obj.Get(k)
+			return v, k, true
+		}
+	}
+	return nil, "", false
 }
 
-func (e *engine) evaluate(node interface{}, tokens []token, currentPath string) ([]Result, error) {
+// emitFunc receives each match as the evaluator produces it. Returning an
+// error (including ErrStop) aborts the evaluation with that error.
+type emitFunc func(Result) error
+
+// collect evaluates tokens against root and gathers every match.
+func (e *engine) collect(root interface{}, tokens []token) ([]Result, error) {
+	return e.collectAt(plainRoot(root), tokens, "$")
+}
+
+// collectAt evaluates tokens against node, whose normalized path is path.
+func (e *engine) collectAt(node interface{}, tokens []token, path string) ([]Result, error) {
+	e.begin(tokens)
+	results, err := e.gatherAt(node, tokens, path)
+	e.report(err)
+	return results, err
+}
+
+// gatherAt is collectAt without reporting to WithMetrics and WithStats.
+func (e *engine) gatherAt(node interface{}, tokens []token, path string) ([]Result, error) {
+	e.anchor(tokens, node, path)
+	add, collected := e.gather()
+	mark := len(e.st.violations)
+	err := e.evaluate(node, tokens, path, e.sink(add))
+	if err != nil && err != ErrStop {
+		e.violations(mark)
+		return nil, err
+	}
+	return e.arrange(collected()), e.violations(mark)
+}
+
+// stream evaluates tokens against root, passing every match to fn.
+// ErrStop returned by fn ends the evaluation without error, dropping the
+// violations recorded under WithAllErrors.
+func (e *engine) stream(root interface{}, tokens []token, fn func(Result) error) error {
+	e.begin(tokens)
+	err := e.run(root, tokens, fn)
+	e.report(err)
+	return err
+}
+
+// run is stream without reporting to WithMetrics and WithStats.
+func (e *engine) run(root interface{}, tokens []token, fn func(Result) error) error {
+	if e.reorders() {
+		results, violations := e.gatherAt(plainRoot(root), tokens, "$")
+		if results == nil {
+			return violations
+		}
+		for _, r := range results {
+			if err := fn(r); err != nil {
+				if err == ErrStop {
+					return nil
+				}
+				return err
+			}
+		}
+		return violations
+	}
+	e.anchor(tokens, plainRoot(root), "$")
+	stopped := false
+	next := fn
+	fn = func(r Result) error {
+		err := next(r)
+		stopped = err == ErrStop
+		return err
+	}
+	mark := len(e.st.violations)
+	err := e.evaluate(plainRoot(root), tokens, "$", e.sink(fn))
+	violations := e.violations(mark)
+	if err == nil || err == ErrStop {
+		err = violations
+	}
+	if stopped {
+		err = nil
+	}
+	return err
+}
+
+// sink wraps fn with the per-result post-processing configured by options.
+func (e *engine) sink(fn emitFunc) emitFunc {
+	if e.onMatch != nil {
+		next := fn
+		fn = func(r Result) error {
+			e.onMatch(e.ctx, r)
+			return next(r)
+		}
+	}
+	if e.tracer != nil {
+		next := fn
+		fn = func(r Result) error {
+			e.tracer(TraceStep{Action: TraceMatch, Path: r.Path})
+			return next(r)
+		}
+	}
+	if e.stats != nil || e.metrics != nil {
+		next := fn
+		fn = func(r Result) error {
+			e.st.stats.Matches++
+			return next(r)
+		}
+	}
+	if e.spans != nil {
+		next := fn
+		fn = func(r Result) error {
+			if sp, ok := e.spans[r.Path]; ok && r.Substring == nil {
+				r.Start, r.End = sourceOffset(e.inserted, sp.start), sourceOffset(e.inserted, sp.end)
+				if e.rawValues {
+					r.Value = json.RawMessage(e.raw[sp.start:sp.end])
+				}
+			}
+			return next(r)
+		}
+	}
+	if e.pages() && !e.reorders() {
+		fn = e.page(fn)
+	}
+	if e.unique {
+		fn = e.dedupe(fn)
+	}
+	if e.kinds != nil {
+		fn = e.typeFilter(fn)
+	}
+	return fn
+}
+
+func (e *engine) evaluate(node interface{}, tokens []token, currentPath string, emit emitFunc) error {
+	if err := e.visit(currentPath, node); err != nil {
+		return err
+	}
+
 	if len(tokens) == 0 {
-		return []Result{{Path: currentPath, Value: node}}, nil
+		return emit(Result{Path: currentPath, Value: node})
 	}
 
 	select {
 	case <-e.ctx.Done():
-		return nil, &Error{Code: ErrCancelled, Message: "context cancelled", Cause: e.ctx.Err()}
+		return e.stop(currentPath, &Error{Code: ErrCancelled, Message: "context cancelled", Cause: e.ctx.Err()})
 	default:
 	}
 
 	tok := tokens[0]
 	rest := tokens[1:]
+	if e.logger != nil {
+		e.debug("jsonpath: apply selector", "path", currentPath, "selector", tok.String(), "node", kindOf(node).String())
+	}
+	if e.tracer != nil && tok.kind != tokenRoot && tok.kind != tokenAlternatives && tok.kind != tokenDefault {
+		e.traceApply(currentPath, tok, node)
+	}
 
 	switch tok.kind {
 	case tokenRoot:
-		return e.evaluate(node, rest, "$")
+		return e.evaluate(node, rest, "$", emit)
 
 	case tokenChild:
-		obj, ok := node.(map[string]interface{})
+		obj, ok := objectOf(node)
 		if !ok {
 			if e.strictKeys {
-				return nil, &Error{Code: ErrTypeMismatch, Message: fmt.Sprintf("expected object at %s, got %T", currentPath, node)}
+				return e.lookupError(ErrTypeMismatch, currentPath, "expected object at %s, got %T", currentPath, node)
 			}
-			return nil, nil
+			return nil
 		}
-		val, exists := obj[tok.key]
+		val, key, exists := e.member(obj, tok.key)
 		if !exists {
+			if e.tracer != nil {
+				e.traceReject(currentPath, tok.String(), "key %s not found", quoteKey(tok.key))
+			}
 			if e.strictKeys {
-				return nil, &Error{Code: ErrKeyNotFound, Message: fmt.Sprintf("key '%s' not found at %s", tok.key, currentPath)}
+				return e.lookupError(ErrKeyNotFound, currentPath, "key '%s' not found at %s", tok.key, currentPath)
 			}
-			return nil, nil
+			return nil
 		}
-		return e.evaluate(val, rest, currentPath+"."+tok.key)
+		return e.evaluate(val, rest, e.childPath(currentPath, key), emit)
 
 	case tokenWildcard:
-		return e.evalWildcard(node, rest, currentPath)
+		return e.evalWildcard(node, rest, currentPath, emit)
 
 	case tokenIndex:
-		arr, ok := node.([]interface{})
+		arr, ok := arrayOf(node)
 		if !ok {
 			if e.strictKeys {
-				return nil, &Error{Code: ErrTypeMismatch, Message: fmt.Sprintf("expected array at %s, got %T", currentPath, node)}
+				return e.lookupError(ErrTypeMismatch, currentPath, "expected array at %s, got %T", currentPath, node)
 			}
-			return nil, nil
+			return nil
 		}
-		idx := normalizeIndex(tok.index, len(arr))
-		if idx < 0 || idx >= len(arr) {
+		idx := normalizeIndex(tok.index, arr.Len())
+		if idx < 0 || idx >= arr.Len() {
+			if e.tracer != nil {
+				e.traceReject(currentPath, tok.String(), "index %d out of bounds (length %d)", tok.index, arr.Len())
+			}
 			if e.strictKeys {
-				return nil, &Error{Code: ErrIndexOutOfBounds, Message: fmt.Sprintf("index %d out of bounds at %s (length %d)", tok.index, currentPath, len(arr))}
+				return e.lookupError(ErrIndexOutOfBounds, currentPath, "index %d out of bounds at %s (length %d)", tok.index, currentPath, arr.Len())
 			}
-			return nil, nil
+			return nil
 		}
-		return e.evaluate(arr[idx], rest, fmt.Sprintf("%s[%d]", currentPath, idx))
+		return e.evaluate(arr.Index(idx), rest, e.indexPath(currentPath, idx), emit)
 
 	case tokenSlice:
-		return e.evalSlice(node, tok.slice, rest, currentPath)
+		return e.evalSlice(node, tok.slice, rest, currentPath, emit)
 
 	case tokenUnion:
-		return e.evalUnion(node, tok, rest, currentPath)
+		return e.evalUnion(node, tok, rest, currentPath, emit)
 
 	case tokenRecursive:
-		return e.evalRecursive(node, rest, currentPath, 0)
+		return e.evalRecursive(node, rest, currentPath, 0, emit)
 
 	case tokenFilter:
-		return e.evalFilter(node, tok.filter, rest, currentPath)
+		return e.evalFilter(node, tok.expr, rest, currentPath, emit)
+
+	case tokenAlternatives:
+		return e.evalAlternatives(node, tok.alts, currentPath, emit)
+
+	case tokenDefault:
+		return e.evalDefault(node, tok, currentPath, emit)
+
+	case tokenFunction:
+		return e.evalFunction(node, tok, rest, currentPath, emit)
+
+	case tokenParent:
+		return e.evalParent(rest, currentPath, emit)
+
+	case tokenNames:
+		return e.evalNames(node, tok, rest, currentPath, emit)
+
+	case tokenPattern:
+		return e.evalPattern(node, tok, rest, currentPath, emit)
 
 	default:
-		return nil, &Error{Code: ErrInvalidPath, Message: fmt.Sprintf("unknown token kind: %d", tok.kind)}
+		return &Error{Code: ErrInvalidPath, Message: fmt.Sprintf("unknown token kind: %d", tok.kind)}
 	}
 }
 
-func (e *engine) evalWildcard(node interface{}, rest []token, currentPath string) ([]Result, error) {
-	var results []Result
-	switch v := node.(type) {
-	case map[string]interface{}:
-		// sort keys for deterministic output
-		keys := sortedKeys(v)
-		for _, k := range keys {
-			r, err := e.evaluate(v[k], rest, currentPath+"."+k)
-			if err != nil {
-				return nil, err
-			}
-			results = append(results, r...)
+func (e *engine) evalWildcard(node interface{}, rest []token, currentPath string, emit emitFunc) error {
+	if obj, ok := objectOf(node); ok {
+		// members come in the order WithOrder selects, lexical by default
+		err := e.eachMember(obj, func(k string, v interface{}) error {
+			return e.evaluate(v, rest, e.childPath(currentPath, k), emit)
+		})
+		if err != nil {
+			return err
 		}
-	case []interface{}:
-		for i, item := range v {
-			r, err := e.evaluate(item, rest, fmt.Sprintf("%s[%d]", currentPath, i))
-			if err != nil {
-				return nil, err
+	} else if arr, ok := arrayOf(node); ok {
+		for i, n := 0, arr.Len(); i < n; i++ {
+			if err := e.evaluate(arr.Index(i), rest, e.indexPath(currentPath, i), emit); err != nil {
+				return err
 			}
-			results = append(results, r...)
 		}
 	}
-	return results, nil
+	return nil
 }
 
-func (e *engine) evalSlice(node interface{}, slice [3]*int, rest []token, currentPath string) ([]Result, error) {
-	arr, ok := node.([]interface{})
+func (e *engine) evalSlice(node interface{}, slice [3]*int, rest []token, currentPath string, emit emitFunc) error {
+	if str, ok := node.(string); ok && e.stringSlices {
+		return e.evalSubstring(str, slice, rest, currentPath, emit)
+	}
+	arr, ok := arrayOf(node)
 	if !ok {
-		return nil, nil
+		return nil
 	}
-	n := len(arr)
+	return eachSliceIndex(slice, arr.Len(), func(i int) error {
+		return e.evaluate(arr.Index(i), rest, e.indexPath(currentPath, i), emit)
+	})
+}
 
+// eachSliceIndex calls fn with each index slice selects from a sequence of
+// length n, in order.
+func eachSliceIndex(slice [3]*int, n int, fn func(int) error) error {
 	step := 1
 	if slice[2] != nil {
 		step = *slice[2]
 		if step == 0 {
-			return nil, &Error{Code: ErrInvalidPath, Message: "slice step cannot be zero"}
+			return &Error{Code: ErrInvalidPath, Message: "slice step cannot be zero"}
 		}
 	}
 
@@ -625,292 +1842,177 @@ func (e *engine) evalSlice(node interface{}, slice [3]*int, rest []token, curren
 		end = normalizeIndex(*slice[1], n)
 	}
 
-	var results []Result
 	if step > 0 {
 		for i := start; i < end && i < n; i += step {
 			if i < 0 {
 				continue
 			}
-			r, err := e.evaluate(arr[i], rest, fmt.Sprintf("%s[%d]", currentPath, i))
-			if err != nil {
-				return nil, err
+			if err := fn(i); err != nil {
+				return err
 			}
-			results = append(results, r...)
 		}
 	} else {
 		for i := start; i > end && i >= 0; i += step {
 			if i >= n {
 				continue
 			}
-			r, err := e.evaluate(arr[i], rest, fmt.Sprintf("%s[%d]", currentPath, i))
-			if err != nil {
-				return nil, err
+			if err := fn(i); err != nil {
+				return err
 			}
-			results = append(results, r...)
 		}
 	}
-	return results, nil
+	return nil
 }
 
-func (e *engine) evalUnion(node interface{}, tok token, rest []token, currentPath string) ([]Result, error) {
-	var results []Result
-
+func (e *engine) evalUnion(node interface{}, tok token, rest []token, currentPath string, emit emitFunc) error {
 	if len(tok.indices) > 0 {
-		arr, ok := node.([]interface{})
+		arr, ok := arrayOf(node)
 		if !ok {
-			return nil, nil
+			return nil
 		}
 		for _, idx := range tok.indices {
-			i := normalizeIndex(idx, len(arr))
-			if i < 0 || i >= len(arr) {
+			i := normalizeIndex(idx, arr.Len())
+			if i < 0 || i >= arr.Len() {
+				if e.tracer != nil {
+					e.traceReject(currentPath, tok.String(), "index %d out of bounds (length %d)", idx, arr.Len())
+				}
 				continue
 			}
-			r, err := e.evaluate(arr[i], rest, fmt.Sprintf("%s[%d]", currentPath, i))
-			if err != nil {
-				return nil, err
+			if err := e.evaluate(arr.Index(i), rest, e.indexPath(currentPath, i), emit); err != nil {
+				return err
 			}
-			results = append(results, r...)
 		}
 	} else {
-		obj, ok := node.(map[string]interface{})
+		obj, ok := objectOf(node)
 		if !ok {
-			return nil, nil
+			return nil
 		}
-		for _, key := range tok.keys {
-			val, exists := obj[key]
+		for _, want := range tok.keys {
+			val, key, exists := e.member(obj, want)
 			if !exists {
+				if e.tracer != nil {
+					e.traceReject(currentPath, tok.String(), "key %s not found", quoteKey(want))
+				}
 				continue
 			}
-			r, err := e.evaluate(val, rest, currentPath+"."+key)
-			if err != nil {
-				return nil, err
+			if err := e.evaluate(val, rest, e.childPath(currentPath, key), emit); err != nil {
+				return err
 			}
-			results = append(results, r...)
 		}
 	}
-	return results, nil
+	return nil
 }
 
-func (e *engine) evalRecursive(node interface{}, rest []token, currentPath string, depth int) ([]Result, error) {
+func (e *engine) evalRecursive(node interface{}, rest []token, currentPath string, depth int, emit emitFunc) error {
 	if e.maxDepth > 0 && depth > e.maxDepth {
-		return nil, &Error{Code: ErrMaxDepthExceeded, Message: fmt.Sprintf("max depth %d exceeded", e.maxDepth)}
+		return e.stop(currentPath, &Error{Code: ErrMaxDepthExceeded, Message: fmt.Sprintf("max depth %d exceeded", e.maxDepth)})
 	}
 
 	select {
 	case <-e.ctx.Done():
-		return nil, &Error{Code: ErrCancelled, Message: "context cancelled"}
+		return e.stop(currentPath, &Error{Code: ErrCancelled, Message: "context cancelled"})
 	default:
 	}
 
-	var results []Result
-
 	// Apply rest tokens to current node
 	if len(rest) > 0 {
-		r, err := e.evaluate(node, rest, currentPath)
-		if err != nil {
-			return nil, err
+		if err := e.evalDescendant(node, rest, currentPath, emit); err != nil {
+			return err
 		}
-		results = append(results, r...)
 	} else {
-		results = append(results, Result{Path: currentPath, Value: node})
-	}
-
-	// Recurse into children
-	switch v := node.(type) {
-	case map[string]interface{}:
-		keys := sortedKeys(v)
-		for _, k := range keys {
-			r, err := e.evalRecursive(v[k], rest, currentPath+"."+k, depth+1)
-			if err != nil {
-				return nil, err
-			}
-			results = append(results, r...)
+		if err := e.visit(currentPath, node); err != nil {
+			return err
 		}
-	case []interface{}:
-		for i, item := range v {
-			r, err := e.evalRecursive(item, rest, fmt.Sprintf("%s[%d]", currentPath, i), depth+1)
-			if err != nil {
-				return nil, err
-			}
-			results = append(results, r...)
+		if err := emit(Result{Path: currentPath, Value: node}); err != nil {
+			return err
 		}
 	}
 
-	return results, nil
-}
-
-func (e *engine) evalFilter(node interface{}, expr string, rest []token, currentPath string) ([]Result, error) {
-	var results []Result
-
-	evalItem := func(item interface{}, itemPath string) error {
-		ok, err := evalFilterExpr(item, expr)
+	// Recurse into children
+	if obj, ok := objectOf(node); ok {
+		err := e.eachMember(obj, func(k string, v interface{}) error {
+			return e.evalRecursive(v, rest, e.childPath(currentPath, k), depth+1, emit)
+		})
 		if err != nil {
 			return err
 		}
-		if ok {
-			r, err := e.evaluate(item, rest, itemPath)
-			if err != nil {
+	} else if arr, ok := arrayOf(node); ok {
+		for i, n := 0, arr.Len(); i < n; i++ {
+			if err := e.evalRecursive(arr.Index(i), rest, e.indexPath(currentPath, i), depth+1, emit); err != nil {
 				return err
 			}
-			results = append(results, r...)
 		}
-		return nil
 	}
 
-	switch v := node.(type) {
-	case []interface{}:
-		for i, item := range v {
-			if err := evalItem(item, fmt.Sprintf("%s[%d]", currentPath, i)); err != nil {
-				return nil, err
-			}
-		}
-	case map[string]interface{}:
-		keys := sortedKeys(v)
-		for _, k := range keys {
-			if err := evalItem(v[k], currentPath+"."+k); err != nil {
-				return nil, err
-			}
-		}
-	}
-
-	return results, nil
+	return nil
 }
 
-// --- Filter expression evaluator ---
-
-// evalFilterExpr evaluates a filter expression like @.price < 30 against a node.
-// Supports: comparison operators (<, >, <=, >=, ==, !=), existence (@.key),
-// regex (@.key =~ /pattern/), and logical operators (&& and ||).
-func evalFilterExpr(node interface{}, expr string) (bool, error) {
-	expr = strings.TrimSpace(expr)
-
-	// Logical OR (lowest precedence)
-	if idx := findLogicalOp(expr, "||"); idx >= 0 {
-		left, err := evalFilterExpr(node, expr[:idx])
-		if err != nil {
-			return false, err
-		}
-		if left {
-			return true, nil
-		}
-		return evalFilterExpr(node, expr[idx+2:])
-	}
-
-	// Logical AND
-	if idx := findLogicalOp(expr, "&&"); idx >= 0 {
-		left, err := evalFilterExpr(node, expr[:idx])
-		if err != nil {
-			return false, err
-		}
-		if !left {
-			return false, nil
-		}
-		return evalFilterExpr(node, expr[idx+2:])
-	}
-
-	// Parenthesized expression
-	if strings.HasPrefix(expr, "(") && strings.HasSuffix(expr, ")") {
-		return evalFilterExpr(node, expr[1:len(expr)-1])
+// evalDescendant applies rest, whose first selector follows .., to one of
+// the nodes recursive descent visits. A descendant segment tries its
+// selector on every node, so in strict mode the nodes a member name or index
+// does not apply to are skipped rather than reported as missing; only the
+// selectors after it must match.
+func (e *engine) evalDescendant(node interface{}, rest []token, currentPath string, emit emitFunc) error {
+	if e.strictKeys && !e.selectable(node, rest[0]) {
+		return e.visit(currentPath, node)
 	}
+	return e.evaluate(node, rest, currentPath, emit)
+}
 
-	// Regex: @.key =~ /pattern/
-	regexRE := regexp.MustCompile(`^(@[\w.\[\]'"*]+)\s*=~\s*/(.+)/([gimsuy]*)$`)
-	if m := regexRE.FindStringSubmatch(expr); m != nil {
-		lv, err := resolveFilterValue(node, m[1])
-		if err != nil {
-			return false, nil
-		}
-		s, ok := lv.(string)
+// selectable reports whether the child or index selector tok finds a value
+// in node. Other selectors always apply.
+func (e *engine) selectable(node interface{}, tok token) bool {
+	switch tok.kind {
+	case tokenChild:
+		obj, ok := objectOf(node)
 		if !ok {
-			return false, nil
-		}
-		re, err := regexp.Compile(m[2])
-		if err != nil {
-			return false, &Error{Code: ErrInvalidFilter, Message: fmt.Sprintf("invalid regex: %v", err)}
+			return false
 		}
-		return re.MatchString(s), nil
-	}
-
-	// Comparison: lhs op rhs
-	compRE := regexp.MustCompile(`^(.+?)\s*(==|!=|<=|>=|<|>)\s*(.+)$`)
-	if m := compRE.FindStringSubmatch(expr); m != nil {
-		lhs, op, rhs := strings.TrimSpace(m[1]), m[2], strings.TrimSpace(m[3])
-		lv, lerr := resolveFilterValue(node, lhs)
-		rv, rerr := resolveFilterValue(node, rhs)
-		if lerr != nil || rerr != nil {
-			return false, nil
+		_, _, exists := e.member(obj, tok.key)
+		return exists
+	case tokenIndex:
+		arr, ok := arrayOf(node)
+		if !ok {
+			return false
 		}
-		return compareValues(lv, op, rv)
-	}
-
-	// Existence check: @.key
-	if strings.HasPrefix(expr, "@") {
-		val, err := resolveFilterValue(node, expr)
-		return err == nil && val != nil, nil
+		idx := normalizeIndex(tok.index, arr.Len())
+		return idx >= 0 && idx < arr.Len()
 	}
-
-	return false, &Error{Code: ErrInvalidFilter, Message: fmt.Sprintf("cannot parse filter expression: %s", expr)}
+	return true
 }
 
-// findLogicalOp finds the position of a logical operator outside parentheses.
-func findLogicalOp(expr, op string) int {
-	depth := 0
-	for i := 0; i < len(expr)-len(op)+1; i++ {
-		switch expr[i] {
-		case '(':
-			depth++
-		case ')':
-			depth--
+func (e *engine) evalFilter(node interface{}, expr filterExpr, rest []token, currentPath string, emit emitFunc) error {
+	evalItem := func(item interface{}, itemPath string) error {
+		e.st.stats.FiltersEvaluated++
+		ok, err := e.matchFilter(item, expr)
+		if err != nil {
+			return err
 		}
-		if depth == 0 && expr[i:i+len(op)] == op {
-			return i
+		if e.logger != nil {
+			e.debug("jsonpath: filter", "path", itemPath, "filter", formatFilter(expr), "matched", ok)
 		}
-	}
-	return -1
-}
-
-// resolveFilterValue resolves a filter operand, which may be a path (@.key) or a literal.
-func resolveFilterValue(node interface{}, operand string) (interface{}, error) {
-	operand = strings.TrimSpace(operand)
-
-	if strings.HasPrefix(operand, "@") {
-		// Path relative to current node
-		subPath := "$" + operand[1:]
-		e := &engine{maxDepth: 10, ctx: context.Background()}
-		tokens, err := tokenize(subPath)
-		if err != nil {
-			return nil, err
+		if ok {
+			return e.evaluate(item, rest, itemPath, emit)
 		}
-		results, err := e.evaluate(node, tokens, "$")
-		if err != nil || len(results) == 0 {
-			return nil, fmt.Errorf("not found")
+		if e.tracer != nil {
+			e.traceReject(itemPath, "[?("+formatFilter(expr)+")]", "filter is false")
 		}
-		return results[0].Value, nil
-	}
-
-	// String literal
-	if (strings.HasPrefix(operand, "'") && strings.HasSuffix(operand, "'")) ||
-		(strings.HasPrefix(operand, `"`) && strings.HasSuffix(operand, `"`)) {
-		return operand[1 : len(operand)-1], nil
-	}
-
-	// Boolean
-	if operand == "true" {
-		return true, nil
-	}
-	if operand == "false" {
-		return false, nil
-	}
-	if operand == "null" {
-		return nil, nil
+		return nil
 	}
 
-	// Number
-	if n, err := strconv.ParseFloat(operand, 64); err == nil {
-		return n, nil
+	if arr, ok := arrayOf(node); ok {
+		for i, n := 0, arr.Len(); i < n; i++ {
+			if err := evalItem(arr.Index(i), e.indexPath(currentPath, i)); err != nil {
+				return err
+			}
+		}
+	} else if obj, ok := objectOf(node); ok {
+		return e.eachMember(obj, func(k string, v interface{}) error {
+			return evalItem(v, e.childPath(currentPath, k))
+		})
 	}
 
-	return nil, fmt.Errorf("cannot resolve operand: %s", operand)
+	return nil
 }
 
 func compareValues(lv interface{}, op string, rv interface{}) (bool, error) {
@@ -919,25 +2021,46 @@ func compareValues(lv interface{}, op string, rv interface{}) (bool, error) {
 	rf, rok := toFloat64(rv)
 
 	if lok && rok {
+		c := compareNumbers(lv, rv, lf, rf)
 		switch op {
 		case "==":
-			return lf == rf, nil
+			return c == 0, nil
 		case "!=":
-			return lf != rf, nil
+			return c != 0, nil
 		case "<":
-			return lf < rf, nil
+			return c < 0, nil
 		case "<=":
-			return lf <= rf, nil
+			return c <= 0, nil
 		case ">":
-			return lf > rf, nil
+			return c > 0, nil
 		case ">=":
-			return lf >= rf, nil
+			return c >= 0, nil
+		}
+	}
+
+	// Times compare chronologically
+	if lt, ok := lv.(time.Time); ok {
+		if rt, ok := rv.(time.Time); ok {
+			switch op {
+			case "==":
+				return lt.Equal(rt), nil
+			case "!=":
+				return !lt.Equal(rt), nil
+			case "<":
+				return lt.Before(rt), nil
+			case "<=":
+				return !lt.After(rt), nil
+			case ">":
+				return lt.After(rt), nil
+			case ">=":
+				return !lt.Before(rt), nil
+			}
 		}
 	}
 
 	// String comparison
-	ls := fmt.Sprintf("%v", lv)
-	rs := fmt.Sprintf("%v", rv)
+	ls := scalarString(lv)
+	rs := scalarString(rv)
 	switch op {
 	case "==":
 		return ls == rs, nil
@@ -964,15 +2087,162 @@ func toFloat64(v interface{}) (float64, bool) {
 		return float64(n), true
 	case int:
 		return float64(n), true
+	case int8:
+		return float64(n), true
+	case int16:
+		return float64(n), true
+	case int32:
+		return float64(n), true
 	case int64:
 		return float64(n), true
+	case uint:
+		return float64(n), true
+	case uint8:
+		return float64(n), true
+	case uint16:
+		return float64(n), true
+	case uint32:
+		return float64(n), true
+	case uint64:
+		return float64(n), true
 	case json.Number:
 		f, err := n.Float64()
 		return f, err == nil
+	case *big.Int:
+		f, _ := new(big.Float).SetInt(n).Float64()
+		return f, true
+	case *big.Float:
+		f, _ := n.Float64()
+		return f, true
+	case string, bool, nil:
+		return math.NaN(), false
+	}
+
+	// named numeric types, such as bson's DateTime
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(rv.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return float64(rv.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return rv.Float(), true
 	}
 	return math.NaN(), false
 }
 
+// compareNumbers returns -1, 0 or 1 as the number a is less than, equal to
+// or greater than b; fa and fb are their float64 values. Numbers decoded
+// under WithUseNumber or WithExactNumbers compare exactly with each other
+// and with integers, so long IDs that float64 cannot tell apart stay
+// distinct.
+func compareNumbers(a, b interface{}, fa, fb float64) int {
+	if ia, ok := a.(int64); ok {
+		if ib, ok := b.(int64); ok {
+			return compareInts64(ia, ib)
+		}
+	}
+	if isDecodedNumber(a) || isDecodedNumber(b) {
+		if ra, ok := exactNumber(a); ok {
+			if rb, ok := exactNumber(b); ok {
+				return ra.Cmp(rb)
+			}
+		}
+	}
+	switch {
+	case fa < fb:
+		return -1
+	case fa > fb:
+		return 1
+	}
+	return 0
+}
+
+func compareInts64(a, b int64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+// isDecodedNumber reports whether v is a number as WithUseNumber or
+// WithExactNumbers decode them, other than float64.
+func isDecodedNumber(v interface{}) bool {
+	switch v.(type) {
+	case json.Number, int64, *big.Int, *big.Float:
+		return true
+	}
+	return false
+}
+
+// exactNumber returns the value of a json.Number or integer without rounding.
+func exactNumber(v interface{}) (*big.Rat, bool) {
+	switch n := v.(type) {
+	case json.Number:
+		return new(big.Rat).SetString(string(n))
+	case *big.Int:
+		return new(big.Rat).SetInt(n), true
+	case *big.Float:
+		if n.IsInf() {
+			return nil, false
+		}
+		r, _ := n.Rat(nil)
+		return r, true
+	}
+	switch rv := reflect.ValueOf(v); rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return new(big.Rat).SetInt64(rv.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return new(big.Rat).SetFrac(new(big.Int).SetUint64(rv.Uint()), big.NewInt(1)), true
+	}
+	return nil, false
+}
+
+// scalarString returns the text used to compare v as a string. Values that
+// marshal to text, such as time.Time and TOML local dates, use that form so
+// they compare against string literals like '2024-01-31'.
+func scalarString(v interface{}) string {
+	if m, ok := v.(encoding.TextMarshaler); ok {
+		if b, err := m.MarshalText(); err == nil {
+			return string(b)
+		}
+	}
+	return fmt.Sprintf("%v", v)
+}
+
+// childPath is the package-level childPath, or "" when the engine does not
+// need result paths.
+func (e *engine) childPath(parent, key string) string {
+	if e.noPaths {
+		return ""
+	}
+	return childPath(parent, key)
+}
+
+// indexPath is the package-level indexPath, or "" when the engine does not
+// need result paths.
+func (e *engine) indexPath(parent string, i int) string {
+	if e.noPaths {
+		return ""
+	}
+	return indexPath(parent, i)
+}
+
+// childPath returns the normalized path of member key of the node at parent:
+// dot notation for identifiers, otherwise an escaped bracket selector, so the
+// path parses back to the same member.
+func childPath(parent, key string) string {
+	return parent + formatMember(key)
+}
+
+// indexPath returns the normalized path of element i of the array at parent.
+func indexPath(parent string, i int) string {
+	return parent + "[" + strconv.Itoa(i) + "]"
+}
+
 func normalizeIndex(idx, length int) int {
 	if idx < 0 {
 		return length + idx
@@ -993,6 +2263,3 @@ func sortedKeys(m map[string]interface{}) []string {
 	}
 	return keys
 }
-
-// Ensure reflect is used (for potential future struct traversal)
-var _ = reflect.TypeOf
@@ -1,8 +1,15 @@
 package jsonpath_test
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"math/big"
+	"strings"
 	"testing"
 	"time"
 
@@ -134,6 +141,47 @@ func TestQueryRecursivePrice(t *testing.T) {
 	}
 }
 
+func TestDescendantBrackets(t *testing.T) {
+	// the descendant segment examples of RFC 9535, section 2.5.2.3
+	data := []byte(`{"o": {"j": 1, "k": 2}, "a": [5, 3, [{"j": 4}, {"k": 6}]]}`)
+	tests := []struct {
+		path string
+		want []string
+	}{
+		{"$..j", []string{"$.a[2][0].j", "$.o.j"}},
+		{"$..['j']", []string{"$.a[2][0].j", "$.o.j"}},
+		{"$..[0]", []string{"$.a[0]", "$.a[2][0]"}},
+		{"$.a..[0,1]", []string{"$.a[0]", "$.a[1]", "$.a[2][0]", "$.a[2][1]"}},
+		{"$..['j','k']", []string{"$.a[2][0].j", "$.a[2][1].k", "$.o.j", "$.o.k"}},
+		{"$..[?(@.j)]", []string{"$.o", "$.a[2][0]"}},
+		{"$..[1:]", []string{"$.a[1]", "$.a[2]", "$.a[2][1]"}},
+	}
+	for _, tt := range tests {
+		for _, strict := range []bool{false, true} {
+			results, err := jsonpath.Query(data, tt.path, jsonpath.WithAllowMissingKeys(strict))
+			if err != nil {
+				t.Errorf("%s (strict %v): unexpected error: %v", tt.path, strict, err)
+				continue
+			}
+			if len(results) != len(tt.want) {
+				t.Errorf("%s (strict %v): expected %v, got %v", tt.path, strict, tt.want, results)
+				continue
+			}
+			for i, r := range results {
+				if r.Path != tt.want[i] {
+					t.Errorf("%s (strict %v): expected %s, got %s", tt.path, strict, tt.want[i], r.Path)
+				}
+			}
+		}
+	}
+
+	// in strict mode the selectors after the descendant's own must still match
+	_, err := jsonpath.Query(sampleJSON, "$..book[*].isbn", jsonpath.WithAllowMissingKeys(true))
+	if !jsonpath.IsNotFound(err) {
+		t.Errorf("expected a missing isbn to be reported, got %v", err)
+	}
+}
+
 func TestQuerySlice(t *testing.T) {
 	results, err := jsonpath.Query(sampleJSON, "$.store.book[0:2].title")
 	if err != nil {
@@ -360,6 +408,46 @@ func TestErrorTypes(t *testing.T) {
 	}
 }
 
+func TestErrorOffsets(t *testing.T) {
+	tests := []struct {
+		path   string
+		offset int
+	}{
+		{"$x", 1},
+		{"$.a[", 3},
+		{"$.a[]", 4},
+		{"$.a[1:x]", 6},
+		{"$.a[?(@.b = 1)]", 10},
+		{"$.a[?(@.b =~ /(/)]", 14},
+		{"$.a[?(@.b[ == 1)]", 9},
+		{"$.a | ", 5},
+	}
+	for _, tt := range tests {
+		_, err := jsonpath.Compile(tt.path)
+		var e *jsonpath.Error
+		if !errors.As(err, &e) {
+			t.Fatalf("%q: expected *Error, got %v", tt.path, err)
+		}
+		if e.Offset != tt.offset {
+			t.Errorf("%q: expected offset %d, got %d (%v)", tt.path, tt.offset, e.Offset, err)
+		}
+		want := tt.path + "\n" + strings.Repeat(" ", tt.offset) + "^"
+		if e.Snippet != want {
+			t.Errorf("%q: expected snippet\n%s\ngot\n%s", tt.path, want, e.Snippet)
+		}
+	}
+
+	_, err := jsonpath.Compile("$['ключ'][")
+	if e := err.(*jsonpath.Error); e.Offset != 13 || e.Snippet != "$['ключ'][\n         ^" {
+		t.Errorf("expected byte offset 13 and a caret under the last '[', got %d\n%s", e.Offset, e.Snippet)
+	}
+
+	_, err = jsonpath.Query([]byte("not json"), "$")
+	if e := err.(*jsonpath.Error); e.Snippet != "" {
+		t.Errorf("expected no snippet for a JSON error, got %q", e.Snippet)
+	}
+}
+
 func TestStrictMode(t *testing.T) {
 	_, err := jsonpath.Query(sampleJSON, "$.nonexistent", jsonpath.WithAllowMissingKeys(true))
 	if err == nil {
@@ -370,6 +458,362 @@ func TestStrictMode(t *testing.T) {
 	}
 }
 
+func TestErrorMarshalJSON(t *testing.T) {
+	_, err := jsonpath.Compile("$.a[?(@.b = 1)]")
+	b, jerr := json.Marshal(err)
+	if jerr != nil {
+		t.Fatal(jerr)
+	}
+	want := `{"code":"invalid_filter","message":"cannot parse filter expression \"@.b = 1\": unexpected \"= 1\"","offset":10,"snippet":"$.a[?(@.b = 1)]\n          ^"}`
+	if string(b) != want {
+		t.Errorf("expected %s, got %s", want, b)
+	}
+
+	_, err = jsonpath.Query(sampleJSON, "$.store.pen", jsonpath.WithAllowMissingKeys(true))
+	b, _ = json.Marshal(err)
+	if want := `{"code":"key_not_found","message":"key 'pen' not found at $.store","path":"$.store"}`; string(b) != want {
+		t.Errorf("expected %s, got %s", want, b)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = jsonpath.QueryContext(ctx, sampleJSON, "$..price")
+	b, _ = json.Marshal(err)
+	if want := `{"code":"cancelled","message":"context cancelled","cause":"context canceled"}`; string(b) != want {
+		t.Errorf("expected %s, got %s", want, b)
+	}
+}
+
+func TestErrorCodeSentinels(t *testing.T) {
+	_, err := jsonpath.Query(sampleJSON, "$.store.pen", jsonpath.WithAllowMissingKeys(true))
+	if !errors.Is(err, jsonpath.ErrKeyNotFound) || errors.Is(err, jsonpath.ErrInvalidPath) {
+		t.Errorf("expected errors.Is to match only ErrKeyNotFound, got %v", err)
+	}
+	wrapped := fmt.Errorf("loading config: %w", err)
+	if !errors.Is(wrapped, jsonpath.ErrKeyNotFound) || !jsonpath.IsNotFound(wrapped) {
+		t.Errorf("expected wrapped error to match, got %v", wrapped)
+	}
+
+	if jsonpath.ErrKeyNotFound.String() != "key_not_found" || jsonpath.ErrInvalidPath.Error() != "jsonpath: invalid_path" {
+		t.Errorf("unexpected names %q, %q", jsonpath.ErrKeyNotFound.String(), jsonpath.ErrInvalidPath.Error())
+	}
+	if s := jsonpath.ErrorCode(99).String(); s != "ErrorCode(99)" {
+		t.Errorf("expected ErrorCode(99), got %q", s)
+	}
+
+	var code jsonpath.ErrorCode
+	if err := json.Unmarshal([]byte(`"multiple_matches"`), &code); err != nil || code != jsonpath.ErrMultipleMatches {
+		t.Errorf("expected ErrMultipleMatches, got %v (%v)", code, err)
+	}
+	if err := json.Unmarshal([]byte(`"bogus"`), &code); err == nil {
+		t.Error("expected unknown code name to fail")
+	}
+}
+
+func TestAllErrors(t *testing.T) {
+	strict := []jsonpath.Option{jsonpath.WithAllowMissingKeys(true), jsonpath.WithAllErrors()}
+	results, err := jsonpath.Query(sampleJSON, "$.store.book[*].isbn", strict...)
+	if len(results) != 2 {
+		t.Fatalf("expected the 2 isbns alongside the errors, got %v", results)
+	}
+	joined, ok := err.(interface{ Unwrap() []error })
+	if !ok {
+		t.Fatalf("expected joined errors, got %v", err)
+	}
+	var paths []string
+	for _, v := range joined.Unwrap() {
+		if !jsonpath.IsNotFound(v) {
+			t.Errorf("expected not-found violation, got %v", v)
+		}
+		paths = append(paths, v.(*jsonpath.Error).Path)
+	}
+	if strings.Join(paths, " ") != "$.store.book[0] $.store.book[1]" {
+		t.Errorf("unexpected violation paths: %v", paths)
+	}
+
+	var streamed int
+	err = jsonpath.QueryFunc(context.Background(), sampleJSON, "$.store.book[*].isbn", func(jsonpath.Result) error {
+		streamed++
+		return nil
+	}, strict...)
+	if streamed != 2 || len(err.(interface{ Unwrap() []error }).Unwrap()) != 2 {
+		t.Errorf("QueryFunc: expected 2 matches and 2 violations, got %d and %v", streamed, err)
+	}
+	err = jsonpath.QueryFunc(context.Background(), sampleJSON, "$.store.book[*].isbn", func(jsonpath.Result) error {
+		return jsonpath.ErrStop
+	}, strict...)
+	if err != nil {
+		t.Errorf("QueryFunc: expected ErrStop to drop the violations, got %v", err)
+	}
+
+	if _, err := jsonpath.Query(sampleJSON, "$.store.book[*].isbn", jsonpath.WithAllErrors()); err != nil {
+		t.Errorf("expected no error outside strict mode, got %v", err)
+	}
+	if _, err := jsonpath.Query(sampleJSON, "$.missing | $.expensive", strict...); err != nil {
+		t.Errorf("expected a matching alternative to discard earlier violations, got %v", err)
+	}
+
+	out, err := jsonpath.MultiQuery(sampleJSON, map[string]string{"isbn": "$.store.book[*].isbn", "color": "$.store.bicycle.color"}, strict...)
+	if len(out["isbn"]) != 2 || len(out["color"]) != 1 {
+		t.Errorf("unexpected MultiQuery results: %v", out)
+	}
+	if err == nil || !strings.Contains(err.Error(), "isbn: key 'isbn' not found at $.store.book[0]") {
+		t.Errorf("expected named violations, got %v", err)
+	}
+}
+
+func TestUseNumber(t *testing.T) {
+	data := []byte(`{"orders":[{"id":9007199254740993,"total":0.1},{"id":9007199254740992,"total":2}]}`)
+	path := "$.orders[?(@.id == 9007199254740993)].id"
+
+	results, err := jsonpath.Query(data, path)
+	if err != nil || len(results) != 2 {
+		t.Fatalf("expected float64 decoding to conflate the ids, got %v (%v)", results, err)
+	}
+
+	results, err = jsonpath.Query(data, path, jsonpath.WithUseNumber())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 1 || results[0].Value != json.Number("9007199254740993") {
+		t.Fatalf("expected the exact id, got %v", results)
+	}
+	b, _ := json.Marshal(results[0])
+	if !strings.Contains(string(b), `"value":9007199254740993`) {
+		t.Errorf("expected the id to marshal unchanged, got %s", b)
+	}
+
+	results, _ = jsonpath.Query(data, "$.orders[?(@.total < 1)].total", jsonpath.WithUseNumber())
+	if len(results) != 1 || results[0].Value != json.Number("0.1") {
+		t.Errorf("expected total 0.1, got %v", results)
+	}
+
+	results, _ = jsonpath.Query(data, "$.orders[*].id", jsonpath.WithUseNumber(), jsonpath.WithSort(jsonpath.ByValueAsc))
+	if len(results) != 2 || results[0].Value != json.Number("9007199254740992") {
+		t.Errorf("expected exact ordering, got %v", results)
+	}
+
+	cp := jsonpath.MustCompile("$.orders[?(@.id == :id)].id").MustBind(map[string]interface{}{"id": int64(9007199254740992)})
+	results, _ = cp.Query(data, jsonpath.WithUseNumber())
+	if len(results) != 1 || results[0].Value != json.Number("9007199254740992") {
+		t.Errorf("expected bound int64 to compare exactly, got %v", results)
+	}
+
+	var n int
+	err = jsonpath.QueryReaderFunc(context.Background(), bytes.NewReader(data), "$.orders[*].id", func(r jsonpath.Result) error {
+		if _, ok := r.Value.(json.Number); ok {
+			n++
+		}
+		return nil
+	}, jsonpath.WithUseNumber())
+	if err != nil || n != 2 {
+		t.Errorf("expected reader input to decode json.Number, got %d (%v)", n, err)
+	}
+
+	if _, err := jsonpath.Query([]byte(`{"a":1} x`), "$.a", jsonpath.WithUseNumber()); !jsonpath.IsJSONError(err) {
+		t.Errorf("expected trailing data to be rejected, got %v", err)
+	}
+}
+
+func TestExactNumbers(t *testing.T) {
+	data := []byte(`{"n":[42,-7,12345678901234567890,19.99,3.14159265358979323846264338327950288,1e400]}`)
+	results, err := jsonpath.Query(data, "$.n[*]", jsonpath.WithExactNumbers())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 6 {
+		t.Fatalf("expected 6 numbers, got %v", results)
+	}
+	if results[0].Value != int64(42) || results[1].Value != int64(-7) || results[3].Value != 19.99 {
+		t.Errorf("expected int64, int64 and float64, got %T %T %T", results[0].Value, results[1].Value, results[3].Value)
+	}
+	if n, ok := results[2].Value.(*big.Int); !ok || n.String() != "12345678901234567890" {
+		t.Errorf("expected *big.Int, got %T %v", results[2].Value, results[2].Value)
+	}
+	for _, i := range []int{4, 5} {
+		if _, ok := results[i].Value.(*big.Float); !ok {
+			t.Errorf("expected *big.Float at %d, got %T", i, results[i].Value)
+		}
+	}
+	b, _ := json.Marshal(results[4])
+	if !strings.Contains(string(b), `"value":3.14159265358979323846264338327950288`) {
+		t.Errorf("expected every digit to round-trip, got %s", b)
+	}
+
+	results, _ = jsonpath.Query(data, "$.n[?(@ > 12345678901234567889)]", jsonpath.WithExactNumbers())
+	if len(results) != 2 || results[0].Path != "$.n[2]" {
+		t.Errorf("expected the big integer and 1e400, got %v", results)
+	}
+	results, _ = jsonpath.Query(data, "$.n[?(@ < 0)]", jsonpath.WithExactNumbers())
+	if len(results) != 1 || results[0].Value != int64(-7) {
+		t.Errorf("expected -7, got %v", results)
+	}
+}
+
+func TestCaseInsensitiveKeys(t *testing.T) {
+	data := []byte(`{"Users":[{"userName":"ann","Active":true},{"username":"bob","UserName":"Bob","active":false}]}`)
+	fold := jsonpath.WithCaseInsensitiveKeys()
+
+	if results, _ := jsonpath.Query(data, "$.users[*].UserName"); len(results) != 0 {
+		t.Errorf("expected case-sensitive matching by default, got %v", results)
+	}
+
+	results, err := jsonpath.Query(data, "$.users[*].UserName", fold)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, r := range results {
+		got = append(got, r.Path+"="+r.Value.(string))
+	}
+	if strings.Join(got, " ") != "$.Users[0].userName=ann $.Users[1].UserName=Bob" {
+		t.Errorf("expected exact match preferred and document spelling in paths, got %v", got)
+	}
+
+	results, _ = jsonpath.Query(data, "$.USERS[?(@.ACTIVE == true)]['USERNAME','username']", fold)
+	if len(results) != 2 || results[0].Path != "$.Users[0].userName" {
+		t.Errorf("expected filter operands and unions to fold case, got %v", results)
+	}
+
+	_, err = jsonpath.Query(data, "$.users[0].email", fold, jsonpath.WithAllowMissingKeys(true))
+	if !jsonpath.IsNotFound(err) {
+		t.Errorf("expected a missing key in strict mode, got %v", err)
+	}
+
+	out, _ := jsonpath.MultiQuery(data, map[string]string{"names": "$.users[*].username"}, fold)
+	if len(out["names"]) != 2 {
+		t.Errorf("expected MultiQuery to fold case, got %v", out)
+	}
+}
+
+func TestResultPathEscaping(t *testing.T) {
+	data := []byte(`{"a.b":1,"it's":2,"x y":3,"":4,"back\\slash":5,"q\"uote":6,"plain":7,"a]b":8}`)
+	results, err := jsonpath.Query(data, "$.*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]bool{
+		`$['']`: true, `$['a.b']`: true, `$['back\\slash']`: true, `$['it\'s']`: true,
+		`$.plain`: true, `$['q"uote']`: true, `$['x y']`: true, `$['a]b']`: true,
+	}
+	for _, r := range results {
+		if !want[r.Path] {
+			t.Errorf("unexpected path %s", r.Path)
+		}
+		again, err := jsonpath.Query(data, r.Path)
+		if err != nil || len(again) != 1 || again[0].Value != r.Value {
+			t.Errorf("%s: expected to select %v again, got %v (%v)", r.Path, r.Value, again, err)
+		}
+		segs := r.Segments()
+		if len(segs) != 1 || jsonpath.FormatPath(segs) != r.Path {
+			t.Errorf("%s: segments %v do not format back", r.Path, segs)
+		}
+	}
+}
+
+func TestBracketQuoting(t *testing.T) {
+	data := []byte(`{"a]b":1,"say \"hi\"":2,"xs":[{"t":"a]b","tags":["x"],"k]":5},{"t":"c","tags":[]}]}`)
+	tests := []struct {
+		path string
+		want []interface{}
+	}{
+		{`$['a]b']`, []interface{}{float64(1)}},
+		{`$["say \"hi\""]`, []interface{}{float64(2)}},
+		{`$['a]b','say "hi"']`, []interface{}{float64(1), float64(2)}},
+		{`$.xs[?(@.t == 'a]b')].t`, []interface{}{"a]b"}},
+		{`$.xs[?(@.tags[0] == 'x')].t`, []interface{}{"a]b"}},
+		{`$.xs[?(@['k]'] == 5)].t`, []interface{}{"a]b"}},
+		{`$.xs[?(@.t == 'c' || @['k]'])].t`, []interface{}{"a]b", "c"}},
+	}
+	for _, tt := range tests {
+		results, err := jsonpath.Query(data, tt.path)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", tt.path, err)
+			continue
+		}
+		if len(results) != len(tt.want) {
+			t.Errorf("%s: expected %v, got %v", tt.path, tt.want, results)
+			continue
+		}
+		for i, r := range results {
+			if r.Value != tt.want[i] {
+				t.Errorf("%s: expected %v, got %v", tt.path, tt.want[i], r.Value)
+			}
+		}
+	}
+}
+
+func TestUnionQuotedSeparators(t *testing.T) {
+	data := []byte(`{"a,b":1,"c":2,"x:y":3,"it's, ok":4,"a":5,"b":6}`)
+	tests := []struct {
+		path string
+		want []string
+	}{
+		{`$['a,b','c']`, []string{"$['a,b']", "$.c"}},
+		{`$["a,b", "x:y"]`, []string{"$['a,b']", "$['x:y']"}},
+		{`$['x:y']`, []string{"$['x:y']"}},
+		{`$[ 'x:y' ]`, []string{"$['x:y']"}},
+		{`$['it\'s, ok','a']`, []string{"$['it\\'s, ok']", "$.a"}},
+		{`$['a','b']`, []string{"$.a", "$.b"}},
+	}
+	for _, tt := range tests {
+		results, err := jsonpath.Query(data, tt.path)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", tt.path, err)
+			continue
+		}
+		if len(results) != len(tt.want) {
+			t.Errorf("%s: expected %v, got %v", tt.path, tt.want, results)
+			continue
+		}
+		for i, r := range results {
+			if r.Path != tt.want[i] {
+				t.Errorf("%s: expected %s, got %s", tt.path, tt.want[i], r.Path)
+			}
+		}
+	}
+
+	plan := jsonpath.MustCompile(`$['a,b','x:y']`).Explain()
+	if sel := plan.Steps[1].Selector; sel != `['a,b','x:y']` {
+		t.Errorf("expected the union to format back, got %s", sel)
+	}
+}
+
+func TestScriptSubscript(t *testing.T) {
+	tests := []struct {
+		path string
+		want []interface{}
+	}{
+		{"$..book[(@.length-1)].title", []interface{}{"The Lord of the Rings"}},
+		{"$.store.book[( @.length - 4 )].title", []interface{}{"Sayings of the Century"}},
+		{"$.store.book[(@.length-5)].title", nil},
+		{"$.store.book[(1)].title", []interface{}{"Sword of Honour"}},
+		{"$.store.bicycle[(@.length-1)]", nil},
+	}
+	for _, tt := range tests {
+		results, err := jsonpath.Query(sampleJSON, tt.path)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", tt.path, err)
+			continue
+		}
+		if len(results) != len(tt.want) {
+			t.Errorf("%s: expected %v, got %v", tt.path, tt.want, results)
+			continue
+		}
+		for i, r := range results {
+			if r.Value != tt.want[i] {
+				t.Errorf("%s: expected %v, got %v", tt.path, tt.want[i], r.Value)
+			}
+		}
+	}
+
+	for _, path := range []string{"$.a[(@.length)]", "$.a[(@.length+1)]", "$.a[(@.length-0)]", "$.a[(@.length/2)]", "$.a[(@.size-1)]"} {
+		if _, err := jsonpath.Compile(path); !jsonpath.IsPathError(err) {
+			t.Errorf("%s: expected invalid path error, got %v", path, err)
+		}
+	}
+}
+
 func TestResultMarshalJSON(t *testing.T) {
 	results, err := jsonpath.Query(sampleJSON, "$.expensive")
 	if err != nil {
@@ -477,3 +921,353 @@ func BenchmarkFilter(b *testing.B) {
 		_, _ = jsonpath.Query(sampleJSON, "$.store.book[?(@.price < 10)].title")
 	}
 }
+
+func TestMaxNodes(t *testing.T) {
+	_, err := jsonpath.Query(sampleJSON, "$..price", jsonpath.WithMaxNodes(5))
+	if !jsonpath.IsBudgetExceeded(err) {
+		t.Fatalf("expected budget error, got: %v", err)
+	}
+
+	// Filter operands count against the same budget.
+	_, err = jsonpath.Query(sampleJSON, "$.store.book[?(@.price < 10)]", jsonpath.WithMaxNodes(6))
+	if !jsonpath.IsBudgetExceeded(err) {
+		t.Fatalf("expected budget error from filter operands, got: %v", err)
+	}
+
+	results, err := jsonpath.Query(sampleJSON, "$..price", jsonpath.WithMaxNodes(1000))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(results) != 5 {
+		t.Fatalf("expected 5 results, got %d", len(results))
+	}
+}
+
+func TestBudget(t *testing.T) {
+	_, err := jsonpath.Query(sampleJSON, "$..price", jsonpath.WithBudget(time.Nanosecond))
+	if !jsonpath.IsBudgetExceeded(err) {
+		t.Fatalf("expected budget error, got: %v", err)
+	}
+
+	if _, err := jsonpath.Query(sampleJSON, "$..price", jsonpath.WithBudget(time.Minute)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestWithTimeout(t *testing.T) {
+	_, err := jsonpath.Query(sampleJSON, "$..price", jsonpath.WithTimeout(time.Nanosecond))
+	if !jsonpath.IsCancelled(err) || !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected cancelled error wrapping the deadline, got: %v", err)
+	}
+
+	var deadline bool
+	cheap := func(ctx context.Context, args []interface{}) (interface{}, error) {
+		_, deadline = ctx.Deadline()
+		return true, nil
+	}
+	_, err = jsonpath.Query(sampleJSON, "$.store.book[?(cheap())]", jsonpath.WithTimeout(time.Minute), jsonpath.WithFunction("cheap", cheap))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !deadline {
+		t.Error("expected filter functions to see the deadline")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := jsonpath.QueryContext(ctx, sampleJSON, "$..price", jsonpath.WithTimeout(time.Minute)); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected the caller's cancellation, got: %v", err)
+	}
+}
+
+func TestQueryFunc(t *testing.T) {
+	var titles []interface{}
+	err := jsonpath.QueryFunc(context.Background(), sampleJSON, "$.store.book[*].title", func(r jsonpath.Result) error {
+		titles = append(titles, r.Value)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(titles) != 4 {
+		t.Fatalf("expected 4 titles, got %d", len(titles))
+	}
+}
+
+func TestQueryFuncStop(t *testing.T) {
+	calls := 0
+	err := jsonpath.QueryFunc(context.Background(), sampleJSON, "$..price", func(r jsonpath.Result) error {
+		calls++
+		if calls == 2 {
+			return jsonpath.ErrStop
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("expected ErrStop to be swallowed, got: %v", err)
+	}
+	if calls != 2 {
+		t.Errorf("expected iteration to stop after 2 calls, got %d", calls)
+	}
+}
+
+func TestQueryFuncCallbackError(t *testing.T) {
+	want := errors.New("sink full")
+	err := jsonpath.QueryFunc(context.Background(), sampleJSON, "$..price", func(jsonpath.Result) error {
+		return want
+	})
+	if err != want {
+		t.Fatalf("expected callback error, got: %v", err)
+	}
+
+	err = jsonpath.QueryFunc(context.Background(), []byte("{"), "$", func(jsonpath.Result) error { return nil })
+	if !jsonpath.IsJSONError(err) {
+		t.Fatalf("expected JSON error, got: %v", err)
+	}
+}
+
+func TestWithExpectedResults(t *testing.T) {
+	results, err := jsonpath.Query(sampleJSON, "$..price", jsonpath.WithExpectedResults(16))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(results) != 5 || cap(results) != 16 {
+		t.Errorf("expected 5 results in a slice of capacity 16, got len=%d cap=%d", len(results), cap(results))
+	}
+
+	// The hint is not a limit.
+	results, err = jsonpath.Query(sampleJSON, "$..price", jsonpath.WithExpectedResults(1))
+	if err != nil || len(results) != 5 {
+		t.Errorf("expected 5 results, got %d (err %v)", len(results), err)
+	}
+}
+
+func TestWithDecoder(t *testing.T) {
+	calls := 0
+	decode := func(data []byte) (interface{}, error) {
+		calls++
+		dec := json.NewDecoder(bytes.NewReader(data))
+		dec.UseNumber()
+		var v interface{}
+		err := dec.Decode(&v)
+		return v, err
+	}
+
+	results, err := jsonpath.Query(sampleJSON, "$.expensive", jsonpath.WithDecoder(decode), jsonpath.WithOffsets())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if calls != 1 {
+		t.Errorf("expected the decoder to be called once, got %d", calls)
+	}
+	if len(results) != 1 || results[0].Value != json.Number("10") {
+		t.Errorf("expected the decoder's json.Number, got %v", results)
+	}
+	if string(sampleJSON[results[0].Start:results[0].End]) != "10" {
+		t.Errorf("offsets not preserved: %d-%d", results[0].Start, results[0].End)
+	}
+
+	failing := func([]byte) (interface{}, error) { return nil, errors.New("boom") }
+	if _, err := jsonpath.MustCompile("$.a").Query([]byte(`{}`), jsonpath.WithDecoder(failing)); !jsonpath.IsJSONError(err) {
+		t.Errorf("expected JSON error from decoder failure, got: %v", err)
+	}
+}
+
+func BenchmarkWildcardExpectedResults(b *testing.B) {
+	var items []interface{}
+	for i := 0; i < 1000; i++ {
+		items = append(items, map[string]interface{}{"id": float64(i)})
+	}
+	doc := map[string]interface{}{"items": items}
+	cp := jsonpath.MustCompile("$.items[*].id")
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, _ = cp.QueryValue(doc, jsonpath.WithExpectedResults(1000))
+	}
+}
+
+func TestCompiledPathText(t *testing.T) {
+	var cfg struct {
+		Select  jsonpath.CompiledPath  `json:"select"`
+		Exclude *jsonpath.CompiledPath `json:"exclude"`
+	}
+	if err := json.Unmarshal([]byte(`{"select": "$.store.book[?(@.price < 10)].title", "exclude": "$..isbn"}`), &cfg); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	results, err := cfg.Select.Query(sampleJSON)
+	if err != nil || len(results) != 2 {
+		t.Errorf("unexpected results: %v %v", results, err)
+	}
+	if cfg.Exclude == nil || cfg.Exclude.String() != "$..isbn" {
+		t.Errorf("unexpected pointer field: %v", cfg.Exclude)
+	}
+
+	out, err := json.Marshal(&cfg)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var back map[string]string
+	if err := json.Unmarshal(out, &back); err != nil || back["select"] != "$.store.book[?(@.price < 10)].title" || back["exclude"] != "$..isbn" {
+		t.Errorf("unexpected marshaled config %s: %v", out, err)
+	}
+
+	// by value, and the zero path, round-trip too
+	var byValue struct {
+		Select jsonpath.CompiledPath  `json:"select"`
+		Unset  jsonpath.CompiledPath  `json:"unset"`
+		Zero   *jsonpath.CompiledPath `json:"zero"`
+	}
+	byValue.Select = *jsonpath.MustCompile("$..author")
+	byValue.Zero = &jsonpath.CompiledPath{}
+	out, err = json.Marshal(byValue)
+	if err != nil || string(out) != `{"select":"$..author","unset":"","zero":""}` {
+		t.Fatalf("unexpected marshaled config %s: %v", out, err)
+	}
+	byValue.Select, byValue.Zero = jsonpath.CompiledPath{}, nil
+	if err := json.Unmarshal(out, &byValue); err != nil {
+		t.Fatalf("expected the config to round-trip, got %v", err)
+	}
+	if byValue.Select.String() != "$..author" || byValue.Unset.String() != "" || byValue.Zero == nil || byValue.Zero.String() != "" {
+		t.Errorf("unexpected round-tripped config: %+v", byValue)
+	}
+
+	err = json.Unmarshal([]byte(`{"select": "store.book"}`), &cfg)
+	var pathErr *jsonpath.Error
+	if !errors.As(err, &pathErr) || pathErr.Code != jsonpath.ErrInvalidPath {
+		t.Errorf("expected the path error at load time, got %v", err)
+	}
+}
+
+func TestCompiledPathFlag(t *testing.T) {
+	var _ flag.Value = (*jsonpath.CompiledPath)(nil)
+
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	var sel jsonpath.CompiledPath
+	fs.Var(&sel, "select", "path to select")
+	if err := fs.Parse([]string{"-select", "$..author"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if results, err := sel.Query(sampleJSON); err != nil || len(results) != 4 {
+		t.Errorf("unexpected results: %v %v", results, err)
+	}
+
+	err := fs.Parse([]string{"-select", "$.a[?(@.b ==)]"})
+	if err == nil || !strings.Contains(err.Error(), "jsonpath:") {
+		t.Errorf("expected the path error from flag parsing, got %v", err)
+	}
+	if sel.String() != "$..author" {
+		t.Errorf("expected a failed Set to keep the previous path, got %s", sel.String())
+	}
+	if (*jsonpath.CompiledPath)(nil).String() != "" {
+		t.Error("expected an empty string for a nil path")
+	}
+}
+
+func TestCompiledPathHelpers(t *testing.T) {
+	cp := jsonpath.MustCompile("$.store.book[?(@.price < 10)]")
+
+	first, err := cp.First(sampleJSON)
+	if err != nil || first == nil || first.Path != "$.store.book[0]" {
+		t.Errorf("unexpected first: %v %v", first, err)
+	}
+	if ok, err := cp.Exists(sampleJSON); err != nil || !ok {
+		t.Errorf("expected a match, got %v %v", ok, err)
+	}
+	paths, err := cp.Paths(sampleJSON)
+	if err != nil || len(paths) != 2 || paths[1] != "$.store.book[2]" {
+		t.Errorf("unexpected paths: %v %v", paths, err)
+	}
+	values, err := jsonpath.MustCompile("$.store.book[*].price").Values(sampleJSON)
+	if err != nil || len(values) != 4 || values[1] != 12.99 {
+		t.Errorf("unexpected values: %v %v", values, err)
+	}
+
+	none := jsonpath.MustCompile("$.store.missing")
+	if first, err := none.First(sampleJSON); err != nil || first != nil {
+		t.Errorf("expected no match, got %v %v", first, err)
+	}
+	if ok, err := none.Exists(sampleJSON); err != nil || ok {
+		t.Errorf("expected no match, got %v %v", ok, err)
+	}
+	if values, err := none.Values(sampleJSON); err != nil || values == nil || len(values) != 0 {
+		t.Errorf("expected an empty slice, got %v %v", values, err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := cp.ValuesContext(ctx, sampleJSON); !jsonpath.IsCancelled(err) {
+		t.Errorf("expected cancellation, got %v", err)
+	}
+	if _, err := cp.PathsContext(ctx, sampleJSON); !jsonpath.IsCancelled(err) {
+		t.Errorf("expected cancellation, got %v", err)
+	}
+	if _, err := cp.ExistsContext(ctx, sampleJSON); !jsonpath.IsCancelled(err) {
+		t.Errorf("expected cancellation, got %v", err)
+	}
+	if _, err := cp.First([]byte("{")); !jsonpath.IsJSONError(err) {
+		t.Errorf("expected JSON error, got %v", err)
+	}
+}
+
+func TestQueryOne(t *testing.T) {
+	r, err := jsonpath.QueryOne(sampleJSON, "$.store.book[?(@.isbn == '0-395-19395-8')].title")
+	if err != nil || r.Value != "The Lord of the Rings" || r.Path != "$.store.book[3].title" {
+		t.Errorf("unexpected result: %v %v", r, err)
+	}
+	if _, err := jsonpath.QueryOne(sampleJSON, "$.store.missing"); !jsonpath.IsNoMatch(err) {
+		t.Errorf("expected no match, got %v", err)
+	}
+	_, err = jsonpath.QueryOne(sampleJSON, "$..author")
+	if !jsonpath.IsMultipleMatches(err) || !strings.Contains(err.Error(), "$.store.book[1].author") {
+		t.Errorf("expected multiple matches naming the first two, got %v", err)
+	}
+	if _, err := jsonpath.QueryOne(sampleJSON, "store"); !jsonpath.IsPathError(err) {
+		t.Errorf("expected path error, got %v", err)
+	}
+
+	cp := jsonpath.MustCompile("$.store.bicycle.color")
+	if r, err := cp.QueryOne(sampleJSON); err != nil || r.Value != "red" {
+		t.Errorf("unexpected result: %v %v", r, err)
+	}
+}
+
+func TestCount(t *testing.T) {
+	tests := []struct {
+		path string
+		want int
+	}{
+		{"$..price", 5},
+		{"$.store.book[?(@.price < 10)]", 2},
+		{"$..book[-1:]", 1},
+		{"$.store.*", 2},
+		{"$.store.missing", 0},
+	}
+	for _, tt := range tests {
+		n, err := jsonpath.Count(sampleJSON, tt.path)
+		if err != nil || n != tt.want {
+			t.Errorf("%s: expected %d, got %d %v", tt.path, tt.want, n, err)
+		}
+	}
+
+	var stats jsonpath.Stats
+	if n, err := jsonpath.MustCompile("$..author").Count(sampleJSON, jsonpath.WithStats(&stats)); err != nil || n != 4 || stats.Matches != 4 || stats.MaxDepth == 0 {
+		t.Errorf("unexpected count with stats: %d %v %+v", n, err, stats)
+	}
+	if _, err := jsonpath.Count(sampleJSON, "$.store.missing", jsonpath.WithAllowMissingKeys(true)); !jsonpath.IsNotFound(err) || !strings.Contains(err.Error(), "at $.store") {
+		t.Errorf("expected a strict-mode error naming the path, got %v", err)
+	}
+	if _, err := jsonpath.Count(sampleJSON, "store"); !jsonpath.IsPathError(err) {
+		t.Errorf("expected path error, got %v", err)
+	}
+	if _, err := jsonpath.Count([]byte("{"), "$"); !jsonpath.IsJSONError(err) {
+		t.Errorf("expected JSON error, got %v", err)
+	}
+}
+
+func BenchmarkCount(b *testing.B) {
+	cp := jsonpath.MustCompile("$..price")
+	for i := 0; i < b.N; i++ {
+		cp.Count(sampleJSON)
+	}
+}
@@ -0,0 +1,47 @@
+package jsonpath
+
+import (
+	"encoding/json"
+	"strconv"
+	"unicode/utf8"
+)
+
+// pathFunctions are the functions a path may end with, as in
+// $.store.book.length(). size() is an alias found in other libraries.
+var pathFunctions = map[string]bool{
+	"length": true,
+	"size":   true,
+}
+
+// evalFunction applies the function tok to node. length() and size() match
+// the number of elements of an array, members of an object or characters
+// (Unicode code points) of a string, and nothing for other values. The
+// match's path is the node's path followed by the function, such as
+// $.store.book.length().
+func (e *engine) evalFunction(node interface{}, tok token, rest []token, currentPath string, emit emitFunc) error {
+	var n int
+	if arr, ok := arrayOf(node); ok {
+		n = arr.Len()
+	} else if obj, ok := objectOf(node); ok {
+		n = len(obj.Keys())
+	} else if s, ok := node.(string); ok {
+		n = utf8.RuneCountInString(s)
+	} else {
+		if e.tracer != nil {
+			e.traceMismatch(currentPath, tok.String(), "array, object or string", node)
+		}
+		return nil
+	}
+	return e.evaluate(e.number(n), rest, currentPath+tok.String(), emit)
+}
+
+// number returns n as the type the document's numbers decode to.
+func (e *engine) number(n int) interface{} {
+	switch {
+	case e.exactNumbers:
+		return int64(n)
+	case e.useNumber:
+		return json.Number(strconv.Itoa(n))
+	}
+	return float64(n)
+}
@@ -0,0 +1,79 @@
+package jsonpath_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/njchilds90/go-jsonpath"
+)
+
+func TestLengthFunction(t *testing.T) {
+	tests := []struct {
+		path string
+		want []interface{}
+	}{
+		{"$.store.book.length()", []interface{}{float64(4)}},
+		{"$.store.book.size()", []interface{}{float64(4)}},
+		{"$.store.bicycle.length()", []interface{}{float64(2)}},
+		{"$.store.bicycle.color.length()", []interface{}{float64(3)}},
+		{"$.store.book[*].title.length()", []interface{}{float64(22), float64(15), float64(9), float64(21)}},
+		{"$.store.bicycle.price.length()", nil},
+		{"$.nope.length()", nil},
+	}
+	for _, tt := range tests {
+		results, err := jsonpath.Query(sampleJSON, tt.path)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", tt.path, err)
+			continue
+		}
+		if len(results) != len(tt.want) {
+			t.Errorf("%s: expected %v, got %v", tt.path, tt.want, results)
+			continue
+		}
+		for i, r := range results {
+			if r.Value != tt.want[i] {
+				t.Errorf("%s: expected %v, got %v", tt.path, tt.want[i], r.Value)
+			}
+		}
+	}
+
+	r, err := jsonpath.QueryOne(sampleJSON, "$.store.book.length()")
+	if err != nil || r.Path != "$.store.book.length()" {
+		t.Fatalf("expected the function in the result path, got %+v (%v)", r, err)
+	}
+	if _, err := jsonpath.Compile(r.Path); err != nil {
+		t.Errorf("expected %s to parse back, got %v", r.Path, err)
+	}
+
+	results, err := jsonpath.Query([]byte(`{"s":"café"}`), "$.s.length()")
+	if err != nil || len(results) != 1 || results[0].Value != float64(4) {
+		t.Errorf("expected 4 characters, got %v (%v)", results, err)
+	}
+	results, err = jsonpath.Query(sampleJSON, "$.store.book.length()", jsonpath.WithUseNumber())
+	if err != nil || len(results) != 1 || results[0].Value != json.Number("4") {
+		t.Errorf("expected json.Number 4, got %v (%v)", results, err)
+	}
+	results, err = jsonpath.Query(sampleJSON, "$.store.book.length()", jsonpath.WithExactNumbers())
+	if err != nil || len(results) != 1 || results[0].Value != int64(4) {
+		t.Errorf("expected int64 4, got %v (%v)", results, err)
+	}
+}
+
+func TestLengthFunctionErrors(t *testing.T) {
+	for _, path := range []string{"$.a.count()", "$.a.length().b", "$.a.length()[0]"} {
+		if _, err := jsonpath.Compile(path); !jsonpath.IsPathError(err) {
+			t.Errorf("%s: expected a path error, got %v", path, err)
+		}
+	}
+
+	expr, err := jsonpath.Transpile("$.items.length()", jsonpath.TargetJQ)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := `.items | select(type == "array" or type == "object" or type == "string") | length`; expr != want {
+		t.Errorf("expected %s, got %s", want, expr)
+	}
+	if _, err := jsonpath.Transpile("$.items.length()", jsonpath.TargetPostgres); !jsonpath.IsUnsupported(err) {
+		t.Errorf("expected unsupported, got %v", err)
+	}
+}
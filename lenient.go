@@ -0,0 +1,163 @@
+package jsonpath
+
+import (
+	"fmt"
+	"sort"
+)
+
+// WithLenientJSON accepts human-edited JSON such as tsconfig.json or VS Code
+// settings: // and /* */ comments, trailing commas before ] and }, and
+// unquoted object keys made of letters, digits, '_' and '$'. It applies to
+// []byte input and to Parse. Offsets from WithOffsets still index the
+// original input; raw values from WithRawValues are the strict JSON form of
+// the match, with comments blanked out.
+//
+// Example:
+//
+//	results, err := jsonpath.Query(tsconfig, "$.compilerOptions.paths", jsonpath.WithLenientJSON())
+func WithLenientJSON() Option {
+	return func(e *engine) {
+		e.lenient = true
+	}
+}
+
+// strictJSON rewrites lenient JSON as strict JSON. Comments and trailing
+// commas are replaced by spaces so offsets are kept; quotes added around
+// bare keys are the only insertions, and their offsets in the output are
+// returned in ascending order for sourceOffset.
+func strictJSON(data []byte) ([]byte, []int, error) {
+	out := make([]byte, 0, len(data)+16)
+	var inserted []int
+
+	for i := 0; i < len(data); {
+		c := data[i]
+		switch {
+		case c == '"':
+			end, err := skipStringLiteral(data, i)
+			if err != nil {
+				return nil, nil, err
+			}
+			out = append(out, data[i:end]...)
+			i = end
+
+		case c == '/':
+			end, err := skipComment(data, i)
+			if err != nil {
+				return nil, nil, err
+			}
+			for _, b := range data[i:end] {
+				if b != '\n' && b != '\r' {
+					b = ' '
+				}
+				out = append(out, b)
+			}
+			i = end
+
+		case c == ',':
+			j, err := skipBlank(data, i+1)
+			if err != nil {
+				return nil, nil, err
+			}
+			if j < len(data) && (data[j] == ']' || data[j] == '}') {
+				out = append(out, ' ')
+			} else {
+				out = append(out, ',')
+			}
+			i++
+
+		case isBareKeyStart(c):
+			end := i + 1
+			for end < len(data) && isBareKeyPart(data[end]) {
+				end++
+			}
+			j, err := skipBlank(data, end)
+			if err != nil {
+				return nil, nil, err
+			}
+			if j < len(data) && data[j] == ':' {
+				inserted = append(inserted, len(out))
+				out = append(out, '"')
+				out = append(out, data[i:end]...)
+				inserted = append(inserted, len(out))
+				out = append(out, '"')
+			} else {
+				out = append(out, data[i:end]...)
+			}
+			i = end
+
+		default:
+			out = append(out, c)
+			i++
+		}
+	}
+	return out, inserted, nil
+}
+
+// skipStringLiteral returns the offset just past the string starting at i.
+func skipStringLiteral(data []byte, i int) (int, error) {
+	for j := i + 1; j < len(data); j++ {
+		switch data[j] {
+		case '\\':
+			j++
+		case '"':
+			return j + 1, nil
+		}
+	}
+	return 0, fmt.Errorf("unterminated string at offset %d", i)
+}
+
+// skipComment returns the offset just past the comment starting at i.
+func skipComment(data []byte, i int) (int, error) {
+	if i+1 < len(data) {
+		switch data[i+1] {
+		case '/':
+			j := i + 2
+			for j < len(data) && data[j] != '\n' {
+				j++
+			}
+			return j, nil
+		case '*':
+			for j := i + 2; j+1 < len(data); j++ {
+				if data[j] == '*' && data[j+1] == '/' {
+					return j + 2, nil
+				}
+			}
+			return 0, fmt.Errorf("unterminated comment at offset %d", i)
+		}
+	}
+	return 0, fmt.Errorf("unexpected '/' at offset %d", i)
+}
+
+// skipBlank returns the offset of the first byte at or after i that is not
+// whitespace or part of a comment.
+func skipBlank(data []byte, i int) (int, error) {
+	for i < len(data) {
+		switch data[i] {
+		case ' ', '\t', '\n', '\r':
+			i++
+		case '/':
+			end, err := skipComment(data, i)
+			if err != nil {
+				return 0, err
+			}
+			i = end
+		default:
+			return i, nil
+		}
+	}
+	return i, nil
+}
+
+func isBareKeyStart(c byte) bool {
+	return c == '_' || c == '$' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || c >= 0x80
+}
+
+func isBareKeyPart(c byte) bool {
+	return isBareKeyStart(c) || (c >= '0' && c <= '9')
+}
+
+// sourceOffset maps an offset in the strict form of a lenient document back
+// to the original input.
+func sourceOffset(inserted []int, off int) int {
+	return off - sort.SearchInts(inserted, off)
+}
@@ -0,0 +1,83 @@
+package jsonpath_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/njchilds90/go-jsonpath"
+)
+
+var tsconfig = []byte(`{
+  // compiler settings
+  compilerOptions: {
+    target: "es2020", /* keep in sync with CI */
+    strict: true,
+    paths: {"@app/*": ["src/*",],},
+  },
+  "include": ["src", "test",], // trailing commas everywhere
+}`)
+
+func TestWithLenientJSON(t *testing.T) {
+	tests := []struct {
+		path     string
+		expected interface{}
+	}{
+		{"$.compilerOptions.target", "es2020"},
+		{"$.compilerOptions.strict", true},
+		{"$.compilerOptions.paths['@app/*'][0]", "src/*"},
+		{"$.include[-1]", "test"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			results, err := jsonpath.Query(tsconfig, tt.path, jsonpath.WithLenientJSON())
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(results) != 1 || results[0].Value != tt.expected {
+				t.Errorf("expected %v, got %v", tt.expected, results)
+			}
+		})
+	}
+}
+
+func TestWithLenientJSONRequired(t *testing.T) {
+	if _, err := jsonpath.Query(tsconfig, "$.include"); !jsonpath.IsJSONError(err) {
+		t.Errorf("expected JSON error without WithLenientJSON, got: %v", err)
+	}
+	if _, err := jsonpath.Query([]byte(`{"a": 1 /* open`), "$.a", jsonpath.WithLenientJSON()); !jsonpath.IsJSONError(err) {
+		t.Errorf("expected JSON error for unterminated comment, got: %v", err)
+	}
+}
+
+func TestWithLenientJSONStringsUntouched(t *testing.T) {
+	data := []byte(`{url: "http://example.com/*x*/", "k,]": "a, }"}`)
+	results, err := jsonpath.Query(data, "$.*", jsonpath.WithLenientJSON())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(results) != 2 || results[0].Value != "a, }" || results[1].Value != "http://example.com/*x*/" {
+		t.Errorf("unexpected results: %v", results)
+	}
+}
+
+func TestWithLenientJSONOffsets(t *testing.T) {
+	doc, err := jsonpath.Parse(tsconfig, jsonpath.WithLenientJSON())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	results, err := doc.Query("$.compilerOptions.paths", jsonpath.WithOffsets())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := string(tsconfig[results[0].Start:results[0].End]); got != `{"@app/*": ["src/*",],}` {
+		t.Errorf("offsets do not index the original input: %q", got)
+	}
+
+	raw, err := doc.Query("$.compilerOptions.paths", jsonpath.WithRawValues())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !json.Valid(raw[0].Value.(json.RawMessage)) {
+		t.Errorf("raw value is not strict JSON: %s", raw[0].Value)
+	}
+}
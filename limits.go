@@ -0,0 +1,256 @@
+package jsonpath
+
+import (
+	"fmt"
+	"regexp/syntax"
+)
+
+// pathLimits bounds the size of paths accepted from untrusted sources. They
+// are checked when a path is compiled, before it is evaluated.
+type pathLimits struct {
+	maxLength      int
+	maxSelectors   int
+	maxFilterDepth int
+	regex          RegexLimits
+}
+
+// RegexLimits bounds the regular expressions of =~ filters. Patterns are
+// compiled once, when the path is compiled, so a path that passes costs no
+// further compilation per element. Zero fields are unlimited.
+type RegexLimits struct {
+	// MaxLength is the longest pattern accepted, in bytes.
+	MaxLength int
+	// MaxSize is the largest compiled pattern accepted, in RE2 program
+	// instructions. Matching costs time proportional to the size times the
+	// length of the input, so this bounds the work per element.
+	MaxSize int
+	// MaxPatterns is the number of distinct patterns a path may use.
+	MaxPatterns int
+}
+
+// WithMaxPathLength rejects paths longer than n bytes with ErrLimitExceeded,
+// before they are parsed. Like the other path limits it applies where a path
+// is compiled: Compile, and functions that take a path string such as Query.
+// Default is 0 (unlimited).
+//
+// Example:
+//
+//	cp, err := jsonpath.Compile(userPath, jsonpath.WithMaxPathLength(512), jsonpath.WithMaxSelectors(32), jsonpath.WithMaxFilterDepth(4))
+//	if jsonpath.IsLimitExceeded(err) {
+//	    return fmt.Errorf("path too complex: %w", err)
+//	}
+func WithMaxPathLength(n int) Option {
+	return func(e *engine) {
+		e.limits.maxLength = n
+	}
+}
+
+// WithMaxSelectors rejects paths with more than n selectors with
+// ErrLimitExceeded. Every selector after the root counts, including those of
+// each alternative and of the paths inside filters. Default is 0
+// (unlimited).
+func WithMaxSelectors(n int) Option {
+	return func(e *engine) {
+		e.limits.maxSelectors = n
+	}
+}
+
+// WithMaxFilterDepth rejects paths whose filter expressions nest deeper than
+// n with ErrLimitExceeded. A filter with a single test has depth 1; each &&,
+// || or function call around a test, and each filter inside a filter's path,
+// adds one. Default is 0 (unlimited).
+func WithMaxFilterDepth(n int) Option {
+	return func(e *engine) {
+		e.limits.maxFilterDepth = n
+	}
+}
+
+// WithRegexLimits rejects paths whose =~ patterns exceed limits with
+// ErrLimitExceeded. Like the other path limits it is checked when the path
+// is compiled.
+//
+// Example:
+//
+//	cp, err := jsonpath.Compile(userPath, jsonpath.WithRegexLimits(jsonpath.RegexLimits{MaxLength: 100, MaxSize: 1000, MaxPatterns: 2}))
+func WithRegexLimits(l RegexLimits) Option {
+	return func(e *engine) {
+		e.limits.regex = l
+	}
+}
+
+// checkLength enforces WithMaxPathLength.
+func (l pathLimits) checkLength(path string) error {
+	if l.maxLength > 0 && len(path) > l.maxLength {
+		return &Error{Code: ErrLimitExceeded, Message: fmt.Sprintf("path of %d bytes exceeds the limit of %d", len(path), l.maxLength)}
+	}
+	return nil
+}
+
+// check enforces WithMaxSelectors, WithMaxFilterDepth and WithRegexLimits
+// on compiled tokens.
+func (l pathLimits) check(tokens []token) error {
+	if l.regex != (RegexLimits{}) {
+		if err := l.regex.check(tokens); err != nil {
+			return err
+		}
+	}
+	if l.maxSelectors == 0 && l.maxFilterDepth == 0 {
+		return nil
+	}
+	selectors, depth := measureTokens(tokens)
+	if l.maxSelectors > 0 && selectors > l.maxSelectors {
+		return &Error{Code: ErrLimitExceeded, Message: fmt.Sprintf("path has %d selectors, more than the limit of %d", selectors, l.maxSelectors)}
+	}
+	if l.maxFilterDepth > 0 && depth > l.maxFilterDepth {
+		return &Error{Code: ErrLimitExceeded, Message: fmt.Sprintf("filters nest %d deep, more than the limit of %d", depth, l.maxFilterDepth)}
+	}
+	return nil
+}
+
+// measureTokens returns the number of selectors in tokens and the depth of
+// their deepest filter.
+func measureTokens(tokens []token) (selectors, depth int) {
+	for _, tok := range tokens {
+		switch tok.kind {
+		case tokenRoot:
+		case tokenAlternatives, tokenDefault:
+			for _, alt := range tok.alts {
+				n, d := measureTokens(alt)
+				selectors += n
+				depth = max(depth, d)
+			}
+		case tokenFilter:
+			selectors++
+			n, d := measureFilter(tok.expr)
+			selectors += n
+			depth = max(depth, d)
+		default:
+			selectors++
+		}
+	}
+	return selectors, depth
+}
+
+// measureFilter returns the number of selectors in the paths of expr and
+// its depth.
+func measureFilter(expr filterExpr) (selectors, depth int) {
+	switch x := expr.(type) {
+	case *logicalExpr:
+		ln, ld := measureFilter(x.left)
+		rn, rd := measureFilter(x.right)
+		return ln + rn, 1 + max(ld, rd)
+	case *compareExpr:
+		ln, ld := measureOperand(x.left)
+		rn, rd := measureOperand(x.right)
+		return ln + rn, 1 + max(ld, rd)
+	case *regexExpr:
+		n, d := measureOperand(x.left)
+		return n, 1 + d
+	case *existsExpr:
+		n, d := measureOperand(x.operand)
+		return n, 1 + d
+	}
+	return 0, 1
+}
+
+// measureOperand returns the number of selectors in op and the depth of the
+// filters it contains.
+func measureOperand(op operand) (selectors, depth int) {
+	switch x := op.(type) {
+	case *pathOperand:
+		return measureTokens(x.tokens)
+	case *callOperand:
+		for _, a := range x.args {
+			n, d := measureOperand(a)
+			selectors += n
+			depth = max(depth, d)
+		}
+		return selectors, depth + 1
+	}
+	return 0, 0
+}
+
+// check enforces the limits on the patterns of tokens.
+func (l RegexLimits) check(tokens []token) error {
+	seen := map[string]bool{}
+	var err error
+	eachFilter(tokens, func(expr filterExpr) {
+		x, ok := expr.(*regexExpr)
+		if !ok || err != nil || seen[x.re.String()] {
+			return
+		}
+		seen[x.re.String()] = true
+		switch {
+		case l.MaxLength > 0 && len(x.pattern) > l.MaxLength:
+			err = &Error{Code: ErrLimitExceeded, Message: fmt.Sprintf("regex /%s/ of %d bytes exceeds the limit of %d", x.pattern, len(x.pattern), l.MaxLength)}
+		case l.MaxPatterns > 0 && len(seen) > l.MaxPatterns:
+			err = &Error{Code: ErrLimitExceeded, Message: fmt.Sprintf("path uses more than %d distinct regex patterns", l.MaxPatterns)}
+		case l.MaxSize > 0:
+			if size := regexSize(x.re.String()); size > l.MaxSize {
+				err = &Error{Code: ErrLimitExceeded, Message: fmt.Sprintf("regex /%s/ compiles to %d instructions, more than the limit of %d", x.pattern, size, l.MaxSize)}
+			}
+		}
+	})
+	return err
+}
+
+// regexSize returns the number of instructions of the RE2 program for expr,
+// which has already compiled once.
+func regexSize(expr string) int {
+	re, err := syntax.Parse(expr, syntax.Perl)
+	if err != nil {
+		return 0
+	}
+	prog, err := syntax.Compile(re.Simplify())
+	if err != nil {
+		return 0
+	}
+	return len(prog.Inst)
+}
+
+// eachFilter calls fn for every node of every filter expression in tokens,
+// including those of alternatives and of filters nested in operand paths,
+// and for the regular expression of each member name pattern.
+func eachFilter(tokens []token, fn func(filterExpr)) {
+	for _, tok := range tokens {
+		switch tok.kind {
+		case tokenAlternatives, tokenDefault:
+			for _, alt := range tok.alts {
+				eachFilter(alt, fn)
+			}
+		case tokenFilter:
+			eachFilterExpr(tok.expr, fn)
+		case tokenPattern:
+			if isRegexToken(tok) {
+				fn(tok.expr)
+			}
+		}
+	}
+}
+
+func eachFilterExpr(expr filterExpr, fn func(filterExpr)) {
+	fn(expr)
+	switch x := expr.(type) {
+	case *logicalExpr:
+		eachFilterExpr(x.left, fn)
+		eachFilterExpr(x.right, fn)
+	case *compareExpr:
+		eachOperandFilter(x.left, fn)
+		eachOperandFilter(x.right, fn)
+	case *regexExpr:
+		eachOperandFilter(x.left, fn)
+	case *existsExpr:
+		eachOperandFilter(x.operand, fn)
+	}
+}
+
+func eachOperandFilter(op operand, fn func(filterExpr)) {
+	switch x := op.(type) {
+	case *pathOperand:
+		eachFilter(x.tokens, fn)
+	case *callOperand:
+		for _, a := range x.args {
+			eachOperandFilter(a, fn)
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package jsonpath_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/njchilds90/go-jsonpath"
+)
+
+func TestPathLimits(t *testing.T) {
+	tests := []struct {
+		path string
+		opt  jsonpath.Option
+		want bool // rejected
+	}{
+		{"$.store.book[0].title", jsonpath.WithMaxPathLength(21), false},
+		{"$.store.book[0].title", jsonpath.WithMaxPathLength(20), true},
+		{"$.store.book[0].title", jsonpath.WithMaxSelectors(4), false},
+		{"$.store.book[0].title", jsonpath.WithMaxSelectors(3), true},
+		{"$.a | $.b.c", jsonpath.WithMaxSelectors(3), false},
+		{"$.a | $.b.c", jsonpath.WithMaxSelectors(2), true},
+		{"$.book[?(@.price < 10)].title", jsonpath.WithMaxSelectors(4), false},
+		{"$.book[?(@.price < 10)].title", jsonpath.WithMaxSelectors(3), true},
+		{"$.book[?(@.price < 10)]", jsonpath.WithMaxFilterDepth(1), false},
+		{"$.book[?(@.price < 10 && @.isbn)]", jsonpath.WithMaxFilterDepth(1), true},
+		{"$.book[?(@.price < 10 && @.isbn)]", jsonpath.WithMaxFilterDepth(2), false},
+		{"$[?(@.a[?(@.b[?(@.c)])])]", jsonpath.WithMaxFilterDepth(2), true},
+		{"$[?(@.a[?(@.b[?(@.c)])])]", jsonpath.WithMaxFilterDepth(3), false},
+	}
+	for _, tt := range tests {
+		_, err := jsonpath.Compile(tt.path, tt.opt)
+		if tt.want != jsonpath.IsLimitExceeded(err) {
+			t.Errorf("%s: expected rejected=%v, got: %v", tt.path, tt.want, err)
+		}
+		_, err = jsonpath.Query(sampleJSON, tt.path, tt.opt)
+		if tt.want != jsonpath.IsLimitExceeded(err) {
+			t.Errorf("%s: Query: expected rejected=%v, got: %v", tt.path, tt.want, err)
+		}
+	}
+}
+
+func TestPathLimitsBeforeParsing(t *testing.T) {
+	// a path too long to be worth parsing is rejected even though it is
+	// malformed
+	path := "$" + strings.Repeat("[?(", 10000)
+	if _, err := jsonpath.Compile(path, jsonpath.WithMaxPathLength(1024)); !jsonpath.IsLimitExceeded(err) {
+		t.Fatalf("expected limit error, got: %v", err)
+	}
+
+	// limits also apply to paths served from a cache
+	cache := jsonpath.NewCache(8)
+	if _, err := jsonpath.Query(sampleJSON, "$.store.book[*].title", jsonpath.WithCache(cache)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	_, err := jsonpath.Query(sampleJSON, "$.store.book[*].title", jsonpath.WithCache(cache), jsonpath.WithMaxSelectors(2))
+	if !jsonpath.IsLimitExceeded(err) {
+		t.Fatalf("expected limit error for a cached path, got: %v", err)
+	}
+}
+
+func TestRegexLimits(t *testing.T) {
+	tests := []struct {
+		path   string
+		limits jsonpath.RegexLimits
+		want   bool // rejected
+	}{
+		{"$.store.book[?(@.author =~ /Tolkien/)]", jsonpath.RegexLimits{MaxLength: 7}, false},
+		{"$.store.book[?(@.author =~ /Tolkien/)]", jsonpath.RegexLimits{MaxLength: 6}, true},
+		{"$.store.book[?(@.author =~ /a/ || @.title =~ /a/)]", jsonpath.RegexLimits{MaxPatterns: 1}, false},
+		{"$.store.book[?(@.author =~ /a/ || @.title =~ /b/)]", jsonpath.RegexLimits{MaxPatterns: 1}, true},
+		{"$.store.book[?(@.author =~ /a/)] | $.store.book[?(@.title =~ /b/)]", jsonpath.RegexLimits{MaxPatterns: 1}, true},
+		{"$.store.book[?(@.author =~ /a/)]", jsonpath.RegexLimits{MaxSize: 100}, false},
+		{"$.store.book[?(@.author =~ /(a{1,50}){1,10}/)]", jsonpath.RegexLimits{MaxSize: 100}, true},
+		{"$.store.book[?(@.tags[?(@ =~ /(a{1,50}){1,10}/)])]", jsonpath.RegexLimits{MaxSize: 100}, true},
+	}
+	for _, tt := range tests {
+		_, err := jsonpath.Compile(tt.path, jsonpath.WithRegexLimits(tt.limits))
+		if tt.want != jsonpath.IsLimitExceeded(err) {
+			t.Errorf("%s: expected rejected=%v, got: %v", tt.path, tt.want, err)
+		}
+	}
+}
@@ -0,0 +1,132 @@
+package jsonpath
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+)
+
+// LineResult is a match from one document of a JSON Lines stream.
+type LineResult struct {
+	// Line is the 1-based line number of the document that matched.
+	Line int `json:"line"`
+	// Result is the match within that document. Its offsets, if requested,
+	// are relative to the start of the line.
+	Result Result `json:"result"`
+}
+
+// QueryLines applies a JSONPath expression to every document of a JSON Lines
+// (NDJSON) stream and returns the matches tagged with their line numbers.
+// Blank lines are skipped. A line that is not valid JSON ends the query with
+// an ErrInvalidJSON error naming the line.
+//
+// Example:
+//
+//	f, _ := os.Open("app.log.jsonl")
+//	defer f.Close()
+//	results, err := jsonpath.QueryLines(f, "$.request.path")
+//	for _, r := range results {
+//	    fmt.Printf("line %d: %v\n", r.Line, r.Result.Value)
+//	}
+func QueryLines(r io.Reader, path string, opts ...Option) ([]LineResult, error) {
+	var results []LineResult
+	err := QueryLinesFunc(context.Background(), r, path, func(lr LineResult) error {
+		results = append(results, lr)
+		return nil
+	}, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// QueryLinesFunc is the streaming form of QueryLines: it reads one line at a
+// time and calls fn for each match, so memory stays flat however long the
+// stream is. Returning ErrStop from fn ends the query without error; any
+// other error is returned as is. Limits such as WithMaxNodes, WithBudget and
+// WithTimeout, the counts recorded by WithStats and WithMetrics, and the
+// matches WithResultOffset, WithMaxResults and WithSample select cover the
+// whole stream, which WithMetrics reports as one query. WithSort and
+// WithUniqueNodes apply to each line.
+func QueryLinesFunc(ctx context.Context, r io.Reader, path string, fn func(LineResult) error, opts ...Option) error {
+	if ctx == nil {
+		return &Error{Code: ErrInvalidInput, Message: "context must not be nil"}
+	}
+	if r == nil {
+		return &Error{Code: ErrInvalidInput, Message: "reader must not be nil"}
+	}
+	e := newEngine(ctx, opts)
+	cp, err := e.compile(path)
+	if err != nil {
+		return err
+	}
+	e.begin(cp.tokens)
+	err = e.queryLines(r, cp, fn)
+	e.report(err)
+	return err
+}
+
+// queryLines evaluates cp against each document of the JSON Lines stream r.
+func (e *engine) queryLines(r io.Reader, cp *CompiledPath, fn func(LineResult) error) error {
+	// Paging and sampling apply to the matches of all lines, not per line.
+	page := pageFunc(e.skip, e.maxResults, fn)
+	emit := page
+	var sample *reservoir[LineResult]
+	if e.sampleSize > 0 {
+		sample = newReservoir[LineResult](e.sampleSize, e.seed)
+		emit = sample.add
+	}
+	e.skip, e.maxResults, e.sampleSize = 0, 0, 0
+
+	br := bufio.NewReader(r)
+	stopped := false
+	for line := 1; !stopped; line++ {
+		if err := e.ctx.Err(); err != nil {
+			return &Error{Code: ErrCancelled, Message: "context cancelled", Cause: err}
+		}
+		text, readErr := br.ReadBytes('\n')
+		if readErr != nil && readErr != io.EOF {
+			return &Error{Code: ErrInvalidInput, Message: fmt.Sprintf("failed to read line %d", line), Cause: readErr}
+		}
+
+		if len(bytes.TrimSpace(text)) > 0 {
+			doc, err := e.parse(text)
+			if err != nil {
+				if pe, ok := err.(*Error); ok {
+					pe.Message = fmt.Sprintf("line %d: %s", line, pe.Message)
+				}
+				return err
+			}
+			e.attach(doc)
+			n := line
+			err = e.run(doc.root, cp.tokens, func(res Result) error {
+				err := emit(LineResult{Line: n, Result: res})
+				if err == ErrStop {
+					stopped = true
+				}
+				return err
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		if readErr == io.EOF {
+			break
+		}
+	}
+	if sample == nil {
+		return nil
+	}
+	for _, lr := range sample.sample() {
+		if err := page(lr); err != nil {
+			if err == ErrStop {
+				return nil
+			}
+			return err
+		}
+	}
+	return nil
+}
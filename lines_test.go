@@ -0,0 +1,117 @@
+package jsonpath_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/njchilds90/go-jsonpath"
+)
+
+const logLines = `{"level":"info","msg":"started"}
+{"level":"error","msg":"disk full"}
+
+{"level":"error","msg":"retrying"}
+`
+
+func TestQueryLines(t *testing.T) {
+	results, err := jsonpath.QueryLines(strings.NewReader(logLines), "$.msg")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := []struct {
+		line int
+		msg  string
+	}{{1, "started"}, {2, "disk full"}, {4, "retrying"}}
+	if len(results) != len(expected) {
+		t.Fatalf("expected %d results, got %d: %v", len(expected), len(results), results)
+	}
+	for i, r := range results {
+		if r.Line != expected[i].line || r.Result.Value != expected[i].msg || r.Result.Path != "$.msg" {
+			t.Errorf("result %d: expected line %d %q, got %+v", i, expected[i].line, expected[i].msg, r)
+		}
+	}
+}
+
+func TestQueryLinesNoTrailingNewline(t *testing.T) {
+	results, err := jsonpath.QueryLines(strings.NewReader(`{"a":1}`+"\n"+`{"a":2}`), "$.a")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(results) != 2 || results[1].Line != 2 || results[1].Result.Value != 2.0 {
+		t.Errorf("unexpected results: %v", results)
+	}
+}
+
+func TestQueryLinesFuncStop(t *testing.T) {
+	var lines []int
+	err := jsonpath.QueryLinesFunc(context.Background(), strings.NewReader(logLines), "$.level", func(r jsonpath.LineResult) error {
+		lines = append(lines, r.Line)
+		if r.Result.Value == "error" {
+			return jsonpath.ErrStop
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(lines) != 2 || lines[1] != 2 {
+		t.Errorf("expected to stop after line 2, got %v", lines)
+	}
+
+	sentinel := errors.New("boom")
+	err = jsonpath.QueryLinesFunc(context.Background(), strings.NewReader(logLines), "$.level", func(jsonpath.LineResult) error {
+		return sentinel
+	})
+	if err != sentinel {
+		t.Errorf("expected callback error, got: %v", err)
+	}
+}
+
+func TestQueryLinesInvalidLine(t *testing.T) {
+	_, err := jsonpath.QueryLines(strings.NewReader("{\"a\":1}\n{oops}\n"), "$.a")
+	if !jsonpath.IsJSONError(err) || !strings.Contains(err.Error(), "line 2") {
+		t.Errorf("expected JSON error naming line 2, got: %v", err)
+	}
+	if _, err := jsonpath.QueryLines(strings.NewReader(logLines), "bad"); !jsonpath.IsPathError(err) {
+		t.Errorf("expected path error, got: %v", err)
+	}
+}
+
+func TestQueryLinesSharedLimits(t *testing.T) {
+	// each line visits 2 nodes; the limit applies to the stream as a whole
+	_, err := jsonpath.QueryLines(strings.NewReader(logLines), "$.msg", jsonpath.WithMaxNodes(5))
+	if !jsonpath.IsBudgetExceeded(err) {
+		t.Errorf("expected budget error, got: %v", err)
+	}
+}
+
+func TestQueryLinesWholeStream(t *testing.T) {
+	lines := "{\"a\":[1,2]}\n{\"a\":[3,4]}\n"
+	results, err := jsonpath.QueryLines(strings.NewReader(lines), "$.a[*]", jsonpath.WithResultOffset(1), jsonpath.WithMaxResults(2))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(results) != 2 || results[0].Result.Value != 2.0 || results[1].Line != 2 || results[1].Result.Value != 3.0 {
+		t.Errorf("expected one page across lines, got %+v", results)
+	}
+
+	results, err = jsonpath.QueryLines(strings.NewReader(lines), "$.a[*]", jsonpath.WithSample(3, 1))
+	if err != nil || len(results) != 3 {
+		t.Fatalf("expected a sample of 3 across lines, got %+v (%v)", results, err)
+	}
+	for i := 1; i < len(results); i++ {
+		if results[i].Result.Value.(float64) <= results[i-1].Result.Value.(float64) {
+			t.Errorf("expected the sample in stream order, got %+v", results)
+		}
+	}
+
+	m := &recordingMetrics{}
+	if _, err := jsonpath.QueryLines(strings.NewReader(lines), "$.a[0]", jsonpath.WithMetrics(m)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := []string{"start $.a[0]", "finish $.a[0] ok"}; strings.Join(m.events, "; ") != strings.Join(want, "; ") || m.stats[0].Matches != 2 {
+		t.Errorf("expected the stream reported as one query with 2 matches, got %v %+v", m.events, m.stats)
+	}
+}
@@ -0,0 +1,36 @@
+package jsonpath
+
+import "log/slog"
+
+// WithLogger writes debug-level events describing the evaluation to l, to
+// answer questions such as why a filter did not match. Events are logged
+// for the compiled selectors, each selector applied to a node, each filter
+// decision with the values compared, alternatives that matched nothing,
+// and limits such as WithMaxNodes or WithMaxDepth when they stop the query.
+// Messages start with "jsonpath:" and carry the normalized path of the node
+// as the "path" attribute. Nothing is logged, and evaluation costs nothing
+// extra, unless l is enabled for slog.LevelDebug.
+//
+// Example:
+//
+//	logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelDebug}))
+//	results, err := jsonpath.Query(data, "$.items[?(@.price < 10)]", jsonpath.WithLogger(logger))
+func WithLogger(l *slog.Logger) Option {
+	return func(e *engine) {
+		e.logger = l
+	}
+}
+
+// debug logs msg and args at debug level. Callers check e.logger first, so
+// that the arguments are not built when nothing is logged.
+func (e *engine) debug(msg string, args ...interface{}) {
+	e.logger.DebugContext(e.ctx, msg, args...)
+}
+
+// stop logs err, a limit that ends the evaluation at path, and returns it.
+func (e *engine) stop(path string, err *Error) error {
+	if e.logger != nil {
+		e.debug("jsonpath: evaluation stopped", "path", path, "code", err.Code.String(), "reason", err.Message)
+	}
+	return err
+}
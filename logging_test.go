@@ -0,0 +1,67 @@
+package jsonpath_test
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/njchilds90/go-jsonpath"
+)
+
+func TestWithLogger(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	_, err := jsonpath.Query(sampleJSON, "$.store.book[?(@.price < 9)].title", jsonpath.WithLogger(logger))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	out := buf.String()
+	for _, want := range []string{
+		`msg="jsonpath: compiled" path="$.store.book[?(@.price < 9)].title"`,
+		`msg="jsonpath: apply selector" path=$.store selector=.book node=object`,
+		`msg="jsonpath: filter" path=$.store.book[0] filter="@.price < 9" matched=true`,
+		`msg="jsonpath: filter" path=$.store.book[1] filter="@.price < 9" matched=false`,
+		`msg="jsonpath: compare" left=@.price left_value=12.99 op=< right=9 right_value=9 result=false`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected log to contain %s, got:\n%s", want, out)
+		}
+	}
+
+	buf.Reset()
+	if _, err := jsonpath.Query(sampleJSON, "$.store.book[?(@.isbn == 'x')]", jsonpath.WithLogger(logger)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := `msg="jsonpath: compare" left=@.isbn op="==" right='x' result=false reason="an operand matched nothing"`; !strings.Contains(buf.String(), want) {
+		t.Errorf("expected log to contain %s, got:\n%s", want, buf.String())
+	}
+
+	buf.Reset()
+	_, err = jsonpath.Query(sampleJSON, "$..price", jsonpath.WithLogger(logger), jsonpath.WithMaxNodes(3))
+	if !jsonpath.IsBudgetExceeded(err) {
+		t.Fatalf("expected budget error, got: %v", err)
+	}
+	if !strings.Contains(buf.String(), `msg="jsonpath: evaluation stopped"`) || !strings.Contains(buf.String(), "code=budget_exceeded") {
+		t.Errorf("expected the limit to be logged, got:\n%s", buf.String())
+	}
+
+	buf.Reset()
+	if _, err := jsonpath.Query(sampleJSON, "$.nope | $.expensive", jsonpath.WithLogger(logger)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(buf.String(), `msg="jsonpath: alternative matched nothing" path=$ alternative=$.nope`) {
+		t.Errorf("expected the failed alternative to be logged, got:\n%s", buf.String())
+	}
+}
+
+func TestWithLoggerNotEnabled(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelInfo}))
+	if _, err := jsonpath.Query(sampleJSON, "$..book[?(@.isbn)]", jsonpath.WithLogger(logger)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if buf.Len() != 0 {
+		t.Errorf("expected nothing logged above debug level, got:\n%s", buf.String())
+	}
+}
@@ -0,0 +1,90 @@
+package jsonpath
+
+import (
+	"context"
+	"time"
+)
+
+// Metrics receives a summary of every query, so that services embedding the
+// engine can monitor its cost in one place. Implementations must be safe for
+// concurrent use when the option is shared between goroutines.
+//
+// An adapter for Prometheus, counting queries by error code and observing
+// their duration and nodes visited, might look like:
+//
+//	type promMetrics struct {
+//	    jsonpath.NopMetrics
+//	    queries  *prometheus.CounterVec   // labels: code
+//	    duration prometheus.Histogram
+//	    nodes    prometheus.Histogram
+//	}
+//
+//	func (m promMetrics) QueryFinished(ctx context.Context, path string, stats jsonpath.Stats, err error) {
+//	    code := "ok"
+//	    var jerr *jsonpath.Error
+//	    if errors.As(err, &jerr) {
+//	        code = jerr.Code.String()
+//	    } else if err != nil {
+//	        code = "other"
+//	    }
+//	    m.queries.WithLabelValues(code).Inc()
+//	    m.duration.Observe(stats.Duration.Seconds())
+//	    m.nodes.Observe(float64(stats.NodesVisited))
+//	}
+type Metrics interface {
+	// QueryStarted is called with the query's context when evaluation of
+	// path, in normalized form, begins.
+	QueryStarted(ctx context.Context, path string)
+	// QueryFinished is called when it ends, with the work done and the error
+	// the query returns, if any. A path that fails to compile is reported
+	// as started and finished with empty Stats.
+	QueryFinished(ctx context.Context, path string, stats Stats, err error)
+}
+
+// NopMetrics is a Metrics that does nothing. Queries without WithMetrics
+// behave as if given it; embed it to implement only some methods.
+type NopMetrics struct{}
+
+// QueryStarted does nothing.
+func (NopMetrics) QueryStarted(context.Context, string) {}
+
+// QueryFinished does nothing.
+func (NopMetrics) QueryFinished(context.Context, string, Stats, error) {}
+
+// WithMetrics reports every query evaluated with these options to m. For
+// MultiQuery each path is reported as a query of its own.
+//
+// Example:
+//
+//	opts := []jsonpath.Option{jsonpath.WithMetrics(promMetrics)}
+//	results, err := jsonpath.Query(data, "$..price", opts...)
+func WithMetrics(m Metrics) Option {
+	return func(e *engine) {
+		e.metrics = m
+	}
+}
+
+// begin reports the start of an evaluation of tokens to WithMetrics.
+func (e *engine) begin(tokens []token) {
+	if e.metrics == nil {
+		return
+	}
+	e.st.query = formatTokens(tokens)
+	e.st.queryStats = e.st.stats
+	e.st.queryStarted = time.Now()
+	e.metrics.QueryStarted(e.ctx, e.st.query)
+}
+
+// finish reports the end of the evaluation begin reported, with the work
+// done since then.
+func (e *engine) finish(err error) {
+	if e.metrics == nil {
+		return
+	}
+	s := e.st.stats
+	s.NodesVisited -= e.st.queryStats.NodesVisited
+	s.FiltersEvaluated -= e.st.queryStats.FiltersEvaluated
+	s.Matches -= e.st.queryStats.Matches
+	s.Duration = time.Since(e.st.queryStarted)
+	e.metrics.QueryFinished(e.ctx, e.st.query, s, err)
+}
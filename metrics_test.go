@@ -0,0 +1,76 @@
+package jsonpath_test
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/njchilds90/go-jsonpath"
+)
+
+type recordingMetrics struct {
+	events   []string
+	stats    []jsonpath.Stats
+	contexts []context.Context
+}
+
+func (m *recordingMetrics) QueryStarted(ctx context.Context, path string) {
+	m.contexts = append(m.contexts, ctx)
+	m.events = append(m.events, "start "+path)
+}
+
+func (m *recordingMetrics) QueryFinished(ctx context.Context, path string, stats jsonpath.Stats, err error) {
+	m.contexts = append(m.contexts, ctx)
+	code := "ok"
+	if e, ok := err.(*jsonpath.Error); ok {
+		code = e.Code.String()
+	}
+	m.events = append(m.events, fmt.Sprintf("finish %s %s", path, code))
+	m.stats = append(m.stats, stats)
+}
+
+func TestWithMetrics(t *testing.T) {
+	m := &recordingMetrics{}
+	opt := jsonpath.WithMetrics(m)
+	if _, err := jsonpath.Query(sampleJSON, "$.store.book[?(@.price < 10)].title", opt); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	jsonpath.Query(sampleJSON, "$.store[", opt)
+	jsonpath.Query(sampleJSON, "$..price", opt, jsonpath.WithMaxNodes(2))
+	jsonpath.Count(sampleJSON, "$..author", opt)
+
+	want := []string{
+		"start $.store.book[?(@.price < 10)].title",
+		"finish $.store.book[?(@.price < 10)].title ok",
+		"start $.store[",
+		"finish $.store[ invalid_path",
+		"start $..price",
+		"finish $..price budget_exceeded",
+		"start $..author",
+		"finish $..author ok",
+	}
+	if strings.Join(m.events, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("expected events\n%s\ngot\n%s", strings.Join(want, "\n"), strings.Join(m.events, "\n"))
+	}
+	if s := m.stats[0]; s.Matches != 2 || s.FiltersEvaluated != 4 || s.NodesVisited == 0 || s.Duration <= 0 {
+		t.Errorf("unexpected stats for the first query: %+v", s)
+	}
+	if s := m.stats[3]; s.Matches != 4 {
+		t.Errorf("expected Count to report 4 matches, got %+v", s)
+	}
+}
+
+func TestWithMetricsMultiQuery(t *testing.T) {
+	m := &recordingMetrics{}
+	_, err := jsonpath.MultiQuery(sampleJSON, map[string]string{
+		"titles": "$.store.book[*].title",
+		"color":  "$.store.bicycle.color",
+	}, jsonpath.WithMetrics(m))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(m.stats) != 2 || m.stats[0].Matches != 1 || m.stats[1].Matches != 4 {
+		t.Fatalf("expected a query per path with its own matches, got %v %+v", m.events, m.stats)
+	}
+}
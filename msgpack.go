@@ -0,0 +1,242 @@
+package jsonpath
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"time"
+)
+
+// ParseMsgpack decodes a MessagePack document into a Document so that the
+// paths compiled for the JSON form of a message can be run against the
+// msgpack form directly.
+//
+// Values decode to the types encoding/json produces, with these additions:
+// integers are int64 (uint64 when they do not fit), bin values are []byte,
+// the timestamp extension is time.Time and other extensions are the []byte
+// of their payload. Maps with any non-string key are
+// map[interface{}]interface{}, whose keys are matched by their string form.
+//
+// A truncated message, a format byte msgpack does not define, a bad
+// timestamp or bytes after the top-level value fail with ErrInvalidJSON.
+// There is no separate code for binary formats: msgpack decode failures
+// reuse ErrInvalidJSON, so IsJSONError reports them, and the error's Cause
+// says what was wrong.
+//
+// Example:
+//
+//	ids := jsonpath.MustCompile("$.events[*].id")
+//	doc, err := jsonpath.ParseMsgpack(msg)
+//	if err != nil {
+//	    return err
+//	}
+//	results, err := ids.QueryDocument(doc)
+func ParseMsgpack(data []byte) (*Document, error) {
+	d := msgpackDecoder{data: data}
+	root, err := d.value(0)
+	if err == nil && d.pos != len(data) {
+		err = fmt.Errorf("%d bytes after top-level value", len(data)-d.pos)
+	}
+	if err != nil {
+		return nil, &Error{Code: ErrInvalidJSON, Message: "failed to parse MessagePack", Cause: err}
+	}
+	return &Document{root: root}, nil
+}
+
+// QueryMsgpack executes a JSONPath expression against MessagePack-encoded
+// data. See ParseMsgpack for how msgpack values are represented in results.
+func QueryMsgpack(data []byte, path string, opts ...Option) ([]Result, error) {
+	doc, err := ParseMsgpack(data)
+	if err != nil {
+		return nil, err
+	}
+	return doc.Query(path, opts...)
+}
+
+type msgpackDecoder struct {
+	data []byte
+	pos  int
+}
+
+// next returns the following n bytes.
+func (d *msgpackDecoder) next(n int) ([]byte, error) {
+	if n < 0 || len(d.data)-d.pos < n {
+		return nil, io.ErrUnexpectedEOF
+	}
+	b := d.data[d.pos : d.pos+n]
+	d.pos += n
+	return b, nil
+}
+
+// uint reads a big-endian unsigned integer of size bytes.
+func (d *msgpackDecoder) uint(size int) (uint64, error) {
+	b, err := d.next(size)
+	if err != nil {
+		return 0, err
+	}
+	var n uint64
+	for _, c := range b {
+		n = n<<8 | uint64(c)
+	}
+	return n, nil
+}
+
+// length reads a size-byte length and checks that many items can follow.
+func (d *msgpackDecoder) length(size int) (int, error) {
+	n, err := d.uint(size)
+	if err != nil {
+		return 0, err
+	}
+	if n > uint64(len(d.data)-d.pos) {
+		return 0, io.ErrUnexpectedEOF
+	}
+	return int(n), nil
+}
+
+func (d *msgpackDecoder) value(depth int) (interface{}, error) {
+	if depth > maxDecodeDepth {
+		return nil, errors.New("maximum nesting depth exceeded")
+	}
+	b, err := d.next(1)
+	if err != nil {
+		return nil, err
+	}
+	c := b[0]
+
+	switch {
+	case c <= 0x7f:
+		return int64(c), nil
+	case c >= 0xe0:
+		return int64(int8(c)), nil
+	case c <= 0x8f:
+		return d.object(int(c&0x0f), depth)
+	case c <= 0x9f:
+		return d.array(int(c&0x0f), depth)
+	case c <= 0xbf:
+		s, err := d.next(int(c & 0x1f))
+		return string(s), err
+	}
+
+	switch c {
+	case 0xc0:
+		return nil, nil
+	case 0xc2:
+		return false, nil
+	case 0xc3:
+		return true, nil
+	case 0xc4, 0xc5, 0xc6:
+		n, err := d.length(1 << (c - 0xc4))
+		if err != nil {
+			return nil, err
+		}
+		bin, _ := d.next(n)
+		return append([]byte(nil), bin...), nil
+	case 0xc7, 0xc8, 0xc9:
+		n, err := d.length(1 << (c - 0xc7))
+		if err != nil {
+			return nil, err
+		}
+		return d.ext(n)
+	case 0xca:
+		n, err := d.uint(4)
+		return float64(math.Float32frombits(uint32(n))), err
+	case 0xcb:
+		n, err := d.uint(8)
+		return math.Float64frombits(n), err
+	case 0xcc, 0xcd, 0xce, 0xcf:
+		n, err := d.uint(1 << (c - 0xcc))
+		if n > math.MaxInt64 {
+			return n, err
+		}
+		return int64(n), err
+	case 0xd0, 0xd1, 0xd2, 0xd3:
+		size := 1 << (c - 0xd0)
+		n, err := d.uint(size)
+		// sign-extend from size bytes
+		shift := uint(64 - 8*size)
+		return int64(n<<shift) >> shift, err
+	case 0xd4, 0xd5, 0xd6, 0xd7, 0xd8:
+		return d.ext(1 << (c - 0xd4))
+	case 0xd9, 0xda, 0xdb:
+		n, err := d.length(1 << (c - 0xd9))
+		if err != nil {
+			return nil, err
+		}
+		s, _ := d.next(n)
+		return string(s), nil
+	case 0xdc, 0xdd:
+		n, err := d.length(2 << (c - 0xdc))
+		if err != nil {
+			return nil, err
+		}
+		return d.array(n, depth)
+	case 0xde, 0xdf:
+		n, err := d.length(2 << (c - 0xde))
+		if err != nil {
+			return nil, err
+		}
+		return d.object(n, depth)
+	}
+	return nil, fmt.Errorf("invalid format byte 0x%02x", c)
+}
+
+func (d *msgpackDecoder) array(n, depth int) (interface{}, error) {
+	arr := make([]interface{}, 0, n)
+	for i := 0; i < n; i++ {
+		v, err := d.value(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+		arr = append(arr, v)
+	}
+	return arr, nil
+}
+
+func (d *msgpackDecoder) object(n, depth int) (interface{}, error) {
+	b := newObjectBuilder(n)
+	for i := 0; i < n; i++ {
+		k, err := d.value(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+		v, err := d.value(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+		if err := b.set(k, v); err != nil {
+			return nil, err
+		}
+	}
+	return b.value(), nil
+}
+
+// ext reads an extension type byte and n bytes of payload.
+func (d *msgpackDecoder) ext(n int) (interface{}, error) {
+	typ, err := d.next(1)
+	if err != nil {
+		return nil, err
+	}
+	payload, err := d.next(n)
+	if err != nil {
+		return nil, err
+	}
+	if int8(typ[0]) != -1 {
+		return append([]byte(nil), payload...), nil
+	}
+
+	// timestamp extension
+	switch n {
+	case 4:
+		return time.Unix(int64(binary.BigEndian.Uint32(payload)), 0).UTC(), nil
+	case 8:
+		v := binary.BigEndian.Uint64(payload)
+		return time.Unix(int64(v&0x3ffffffff), int64(v>>34)).UTC(), nil
+	case 12:
+		nsec := binary.BigEndian.Uint32(payload)
+		sec := int64(binary.BigEndian.Uint64(payload[4:]))
+		return time.Unix(sec, int64(nsec)).UTC(), nil
+	}
+	return nil, fmt.Errorf("invalid timestamp length %d", n)
+}
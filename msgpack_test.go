@@ -0,0 +1,91 @@
+package jsonpath_test
+
+import (
+	"math"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/njchilds90/go-jsonpath"
+)
+
+// msgpackSample encodes
+// {"id": 7, "tags": ["a", "b"], "neg": -3, "i16": -300, "big": MaxUint64, 1: bin(0x01), "ts": timestamp(60)}.
+var msgpackSample = []byte{
+	0x87,
+	0xa2, 'i', 'd', 0x07,
+	0xa4, 't', 'a', 'g', 's', 0x92, 0xa1, 'a', 0xa1, 'b',
+	0xa3, 'n', 'e', 'g', 0xfd,
+	0xa3, 'i', '1', '6', 0xd1, 0xfe, 0xd4,
+	0xa3, 'b', 'i', 'g', 0xcf, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff,
+	0x01, 0xc4, 0x01, 0x01,
+	0xa2, 't', 's', 0xd6, 0xff, 0x00, 0x00, 0x00, 0x3c,
+}
+
+func TestQueryMsgpack(t *testing.T) {
+	tests := []struct {
+		path     string
+		expected []interface{}
+	}{
+		{"$.id", []interface{}{int64(7)}},
+		{"$.tags[*]", []interface{}{"a", "b"}},
+		{"$.neg", []interface{}{int64(-3)}},
+		{"$.i16", []interface{}{int64(-300)}},
+		{"$.big", []interface{}{uint64(math.MaxUint64)}},
+		{"$['1']", []interface{}{[]byte{1}}},
+		{"$.ts", []interface{}{time.Unix(60, 0).UTC()}},
+		{"$[?(@ < 0)]", []interface{}{int64(-300), int64(-3)}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			results, err := jsonpath.QueryMsgpack(msgpackSample, tt.path)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			got := make([]interface{}, len(results))
+			for i, r := range results {
+				got[i] = r.Value
+			}
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("expected %#v, got %#v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestMsgpackMatchesJSONPaths(t *testing.T) {
+	cp := jsonpath.MustCompile("$.tags[?(@ != 'a')]")
+	fromJSON, err := cp.Query([]byte(`{"id":7,"tags":["a","b"]}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	doc, err := jsonpath.ParseMsgpack(msgpackSample)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	fromMsgpack, err := cp.QueryDocument(doc)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(fromJSON) != 1 || len(fromMsgpack) != 1 || fromJSON[0].Path != fromMsgpack[0].Path || fromJSON[0].Value != fromMsgpack[0].Value {
+		t.Errorf("expected identical results, got %v and %v", fromJSON, fromMsgpack)
+	}
+}
+
+func TestParseMsgpackInvalid(t *testing.T) {
+	inputs := map[string][]byte{
+		"empty":     {},
+		"never":     {0xc1},
+		"truncated": {0x92, 0x01},
+		"trailing":  {0x01, 0x02},
+		"huge":      {0xdd, 0xff, 0xff, 0xff, 0xff},
+		"map key":   {0x81, 0x90, 0x01},
+	}
+	for name, data := range inputs {
+		t.Run(name, func(t *testing.T) {
+			if _, err := jsonpath.ParseMsgpack(data); !jsonpath.IsJSONError(err) {
+				t.Errorf("expected invalid input error, got: %v", err)
+			}
+		})
+	}
+}
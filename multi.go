@@ -0,0 +1,147 @@
+package jsonpath
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// MultiQuery runs several named paths against one document and returns the
+// results of each under its name. The document is parsed once, and leading
+// member and index selectors that paths share, such as $.store.book in
+// $.store.book[*].title and $.store.book[*].price, are resolved once.
+// The first failing path, in name order, fails the call with an error that
+// names it. Under WithAllErrors, strict-mode violations of every path are
+// named and joined, and returned with the results.
+//
+// Example:
+//
+//	out, err := jsonpath.MultiQuery(data, map[string]string{
+//	    "titles": "$.store.book[*].title",
+//	    "prices": "$.store.book[*].price",
+//	})
+//	titles := out["titles"]
+func MultiQuery(data []byte, paths map[string]string, opts ...Option) (map[string][]Result, error) {
+	return MultiQueryContext(context.Background(), data, paths, opts...)
+}
+
+// MultiQueryContext is MultiQuery with context support.
+func MultiQueryContext(ctx context.Context, data []byte, paths map[string]string, opts ...Option) (map[string][]Result, error) {
+	if ctx == nil {
+		return nil, &Error{Code: ErrInvalidInput, Message: "context must not be nil"}
+	}
+	names := make([]string, 0, len(paths))
+	for name := range paths {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	e := newEngine(ctx, opts)
+	compiled := make(map[string]*CompiledPath, len(paths))
+	for _, name := range names {
+		cp, err := e.compile(paths[name])
+		if err != nil {
+			return nil, namedError(name, err)
+		}
+		compiled[name] = cp
+	}
+	doc, err := e.parse(data)
+	if err != nil {
+		return nil, err
+	}
+	e.attach(doc)
+
+	prefixes := map[string]prefixNode{"$": {node: plainRoot(doc.root), path: "$"}}
+	out := make(map[string][]Result, len(paths))
+	var violations []error
+	for _, name := range names {
+		tokens := compiled[name].tokens
+		start, n := prefixNode{node: plainRoot(doc.root), path: "$"}, 1
+		// strict mode must see every missing key, and case-insensitive keys
+		// are not shared, so both walk paths in full
+		if !e.strictKeys && !e.foldKeys {
+			start, n = resolvePrefix(prefixes, tokens)
+		}
+		add, collected := e.gather()
+		e.begin(tokens)
+		e.anchor(tokens, plainRoot(doc.root), "$")
+		mark := len(e.st.violations)
+		err := e.evaluate(start.node, tokens[n:], start.path, e.sink(add))
+		if err != nil && err != ErrStop {
+			e.report(err)
+			return nil, namedError(name, err)
+		}
+		e.finish(errors.Join(e.st.violations[mark:]...))
+		for _, v := range e.st.violations[mark:] {
+			violations = append(violations, namedError(name, v))
+		}
+		e.st.violations = e.st.violations[:mark]
+		out[name] = e.arrange(collected())
+	}
+	e.recordStats()
+	return out, errors.Join(violations...)
+}
+
+// prefixNode is the node a singular path prefix selects.
+type prefixNode struct {
+	node    interface{}
+	path    string
+	missing bool
+}
+
+// resolvePrefix walks the leading member and index selectors of tokens,
+// reusing and recording the nodes they select in prefixes, which is keyed by
+// the selectors' text. It returns the deepest node reached and how many
+// tokens it accounts for.
+func resolvePrefix(prefixes map[string]prefixNode, tokens []token) (prefixNode, int) {
+	cur := prefixes["$"]
+	key := "$"
+	n := 1
+	for ; n < len(tokens); n++ {
+		tok := tokens[n]
+		if tok.kind != tokenChild && tok.kind != tokenIndex {
+			break
+		}
+		key += "\x00" + tok.String()
+		next, ok := prefixes[key]
+		if !ok {
+			next = stepInto(cur, tok)
+			prefixes[key] = next
+		}
+		if next.missing {
+			// nothing can match; evaluating the selector again yields no results
+			return cur, n
+		}
+		cur = next
+	}
+	return cur, n
+}
+
+// stepInto applies a member or index selector to a prefix node.
+func stepInto(p prefixNode, tok token) prefixNode {
+	if tok.kind == tokenChild {
+		if obj, ok := objectOf(p.node); ok {
+			if v, ok := obj.Get(tok.key); ok {
+				return prefixNode{node: v, path: childPath(p.path, tok.key)}
+			}
+		}
+		return prefixNode{missing: true}
+	}
+	if arr, ok := arrayOf(p.node); ok {
+		if i := normalizeIndex(tok.index, arr.Len()); i >= 0 && i < arr.Len() {
+			return prefixNode{node: arr.Index(i), path: indexPath(p.path, i)}
+		}
+	}
+	return prefixNode{missing: true}
+}
+
+// namedError prefixes a query error with the name of the path that failed.
+func namedError(name string, err error) error {
+	if e, ok := err.(*Error); ok {
+		named := *e
+		named.Message = fmt.Sprintf("%s: %s", name, e.Message)
+		return &named
+	}
+	return fmt.Errorf("%s: %w", name, err)
+}
@@ -0,0 +1,76 @@
+package jsonpath_test
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/njchilds90/go-jsonpath"
+)
+
+func TestMultiQuery(t *testing.T) {
+	paths := map[string]string{
+		"titles":  "$.store.book[*].title",
+		"prices":  "$.store.book[*].price",
+		"last":    "$.store.book[-1].author",
+		"color":   "$.store.bicycle.color",
+		"missing": "$.store.book[9].title",
+		"all":     "$..price",
+		"cheap":   "$.store.book[?(@.price < 10)].title",
+	}
+	out, err := jsonpath.MultiQuery(sampleJSON, paths)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// every path must give exactly what it gives on its own
+	for name, path := range paths {
+		want, err := jsonpath.Query(sampleJSON, path)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", name, err)
+		}
+		if !reflect.DeepEqual(out[name], want) {
+			t.Errorf("%s: expected %v, got %v", name, want, out[name])
+		}
+	}
+	if out["last"][0].Path != "$.store.book[3].author" {
+		t.Errorf("expected a normalized index, got %s", out["last"][0].Path)
+	}
+}
+
+func TestMultiQuerySharedPrefix(t *testing.T) {
+	var shared, separate jsonpath.Stats
+	paths := map[string]string{"a": "$.store.book[0].title", "b": "$.store.book[0].author", "c": "$.store.book[0].price"}
+	if _, err := jsonpath.MultiQuery(sampleJSON, paths, jsonpath.WithStats(&shared)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, p := range paths {
+		var s jsonpath.Stats
+		jsonpath.Query(sampleJSON, p, jsonpath.WithStats(&s))
+		separate.NodesVisited += s.NodesVisited
+	}
+	if shared.NodesVisited >= separate.NodesVisited || shared.Matches != 3 {
+		t.Errorf("expected the shared prefix to save work: %d vs %d, %d matches", shared.NodesVisited, separate.NodesVisited, shared.Matches)
+	}
+}
+
+func TestMultiQueryErrors(t *testing.T) {
+	_, err := jsonpath.MultiQuery(sampleJSON, map[string]string{"ok": "$.a", "broken": "$.a["})
+	if !jsonpath.IsPathError(err) || !strings.Contains(err.Error(), "broken: ") {
+		t.Errorf("expected a path error naming the path, got %v", err)
+	}
+
+	_, err = jsonpath.MultiQuery(sampleJSON, map[string]string{"strict": "$.store.nope.title"}, jsonpath.WithAllowMissingKeys(true))
+	if !jsonpath.IsNotFound(err) || !strings.Contains(err.Error(), "strict: ") {
+		t.Errorf("expected a strict-mode error naming the path, got %v", err)
+	}
+
+	if _, err := jsonpath.MultiQuery([]byte("{"), map[string]string{"a": "$"}); !jsonpath.IsJSONError(err) {
+		t.Errorf("expected JSON error, got %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := jsonpath.MultiQueryContext(ctx, sampleJSON, map[string]string{"a": "$..price"}); !jsonpath.IsCancelled(err) {
+		t.Errorf("expected cancellation, got %v", err)
+	}
+}
@@ -0,0 +1,19 @@
+package jsonpath
+
+// evalNames applies the ~ selector, an extension that matches the member
+// names of each object instead of their values: $.store.book[0]~ matches
+// "author", "category" and so on, in the order wildcards visit members. The
+// path of each name is that of its member followed by ~, such as
+// $.store.book[0].author~. Nodes other than objects match nothing.
+func (e *engine) evalNames(node interface{}, tok token, rest []token, currentPath string, emit emitFunc) error {
+	obj, ok := objectOf(node)
+	if !ok {
+		if e.tracer != nil {
+			e.traceMismatch(currentPath, tok.String(), "object", node)
+		}
+		return nil
+	}
+	return e.eachMember(obj, func(k string, _ interface{}) error {
+		return e.evaluate(k, rest, e.childPath(currentPath, k)+tok.String(), emit)
+	})
+}
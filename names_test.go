@@ -0,0 +1,48 @@
+package jsonpath_test
+
+import (
+	"testing"
+
+	"github.com/njchilds90/go-jsonpath"
+)
+
+func TestNamesSelector(t *testing.T) {
+	results, err := jsonpath.Query(sampleJSON, "$.store.book[0]~")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []string{"author", "category", "price", "title"}
+	if len(results) != len(want) {
+		t.Fatalf("expected %v, got %v", want, results)
+	}
+	for i, r := range results {
+		if r.Value != want[i] || r.Path != "$.store.book[0]."+want[i]+"~" {
+			t.Errorf("result %d: expected %s, got %v at %s", i, want[i], r.Value, r.Path)
+		}
+	}
+
+	tests := []struct {
+		path string
+		want int
+	}{
+		{"$.store~", 2},
+		{"$.store.book[*]~", 18},
+		{"$.store.book~", 0},
+		{"$.store.bicycle.color~", 0},
+		{"$.nope~", 0},
+		{"$..*~", 22},
+	}
+	for _, tt := range tests {
+		n, err := jsonpath.Count(sampleJSON, tt.path)
+		if err != nil || n != tt.want {
+			t.Errorf("%s: expected %d names, got %d (%v)", tt.path, tt.want, n, err)
+		}
+	}
+
+	if _, err := jsonpath.Compile("$.store~.book"); !jsonpath.IsPathError(err) {
+		t.Errorf("expected ~ to be the last selector, got %v", err)
+	}
+	if expr, err := jsonpath.Transpile("$.store~", jsonpath.TargetJQ); err != nil || expr != ".store | objects | keys[]" {
+		t.Errorf("unexpected jq translation %q (%v)", expr, err)
+	}
+}
@@ -0,0 +1,550 @@
+package jsonpath
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Object is implemented by custom object-like document nodes, such as
+// ordered maps or lazily decoded AST nodes, so they can be queried without
+// converting them to map[string]interface{}. Values returned by Get may be
+// Objects or Arrays themselves; other values are treated as scalars and
+// should be plain Go values (string, float64, bool, nil, ...) for filters to
+// compare them. A node should implement only one of Object and Array.
+//
+// Example:
+//
+//	type OrderedMap struct {
+//	    keys   []string
+//	    values map[string]interface{}
+//	}
+//
+//	func (m *OrderedMap) Get(key string) (interface{}, bool) { v, ok := m.values[key]; return v, ok }
+//	func (m *OrderedMap) Keys() []string                      { return m.keys }
+type Object interface {
+	// Get returns the member named key and whether it exists.
+	Get(key string) (interface{}, bool)
+	// Keys returns the member names in the order wildcards, filters and
+	// recursive descent visit them.
+	Keys() []string
+}
+
+// Array is implemented by custom array-like document nodes. See Object.
+type Array interface {
+	// Len returns the number of elements.
+	Len() int
+	// Index returns the element at i, where 0 <= i < Len().
+	Index(i int) interface{}
+}
+
+// objectOf returns node as an Object when it is object-like. Besides the
+// map[string]interface{} produced by encoding/json and custom Objects, these
+// are objects:
+//   - maps with interface{} keys, as produced by gopkg.in/yaml.v2
+//   - other maps, such as bson.M or map[int]interface{}; keys that are not
+//     strings are matched by their string form, so $['8080'] selects the key
+//     8080 and $.* visits keys in the order of their string forms
+//   - slices of structs with a string Key and an interface{} Value field,
+//     such as bson.D, whose members keep their order
+//   - structs, whose members are named and filtered like encoding/json
+//     names them, and keep their declaration order
+//
+// Pointers and interfaces are followed. Values that marshal themselves
+// (json.Marshaler, encoding.TextMarshaler), such as time.Time, are scalars.
+func objectOf(node interface{}) (Object, bool) {
+	switch v := node.(type) {
+	case map[string]interface{}:
+		return stringMap(v), true
+	case map[interface{}]interface{}:
+		return anyMap(v), true
+	case nil, string, float64, bool, json.Number, int, int64, []interface{}:
+		return nil, false
+	case Object:
+		return v, true
+	case Array:
+		return nil, false
+	}
+
+	rv, ok := reflectContainer(node)
+	if !ok {
+		return nil, false
+	}
+	switch rv.Kind() {
+	case reflect.Map:
+		return reflectMap{rv}, true
+	case reflect.Slice:
+		if isOrderedDoc(rv.Type()) {
+			return orderedDoc{rv}, true
+		}
+	case reflect.Struct:
+		return structObject{rv, cachedFields(rv.Type())}, true
+	}
+	return nil, false
+}
+
+// arrayOf returns node as an Array when it is array-like. Besides
+// []interface{} and custom Arrays, other slices and arrays are arrays, such as the
+// []map[string]interface{} TOML decoders produce for arrays of tables, or
+// bson.A. Byte slices are scalars.
+func arrayOf(node interface{}) (Array, bool) {
+	switch v := node.(type) {
+	case []interface{}:
+		return sliceArray(v), true
+	case []map[string]interface{}:
+		return mapSliceArray(v), true
+	case nil, string, float64, bool, json.Number, int, int64, map[string]interface{}, []byte:
+		return nil, false
+	case Array:
+		return v, true
+	}
+
+	rv, ok := reflectContainer(node)
+	if !ok {
+		return nil, false
+	}
+	switch rv.Kind() {
+	case reflect.Slice, reflect.Array:
+		if rv.Type().Elem().Kind() != reflect.Uint8 && !isOrderedDoc(rv.Type()) {
+			return reflectArray{rv}, true
+		}
+	}
+	return nil, false
+}
+
+var (
+	jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+// reflectContainer follows pointers and interfaces from node and returns the
+// value they lead to, unless it is nil or marshals itself.
+func reflectContainer(node interface{}) (reflect.Value, bool) {
+	rv := reflect.ValueOf(node)
+	for {
+		if !rv.IsValid() || marshalsItself(rv.Type()) {
+			return reflect.Value{}, false
+		}
+		if rv.Kind() != reflect.Ptr && rv.Kind() != reflect.Interface {
+			return rv, true
+		}
+		if rv.IsNil() {
+			return reflect.Value{}, false
+		}
+		rv = rv.Elem()
+	}
+}
+
+func marshalsItself(t reflect.Type) bool {
+	return t.Implements(jsonMarshalerType) || t.Implements(textMarshalerType)
+}
+
+// plainRoot converts a protobuf google.protobuf.Struct, ListValue or Value
+// root (*structpb.Struct, *structpb.ListValue, *structpb.Value) to the
+// equivalent plain Go value, without going through JSON text. Other roots
+// are returned unchanged.
+func plainRoot(root interface{}) interface{} {
+	switch v := root.(type) {
+	case interface{ AsMap() map[string]interface{} }:
+		return v.AsMap()
+	case interface{ AsSlice() []interface{} }:
+		return v.AsSlice()
+	case interface{ AsInterface() interface{} }:
+		return v.AsInterface()
+	}
+	return root
+}
+
+type stringMap map[string]interface{}
+
+func (m stringMap) Get(key string) (interface{}, bool) {
+	v, ok := m[key]
+	return v, ok
+}
+
+func (m stringMap) Keys() []string { return sortedKeys(m) }
+
+// anyMap is a map with interface{} keys. Keys are matched by their string
+// form, so the YAML key 8080 is selected by $['8080']. Keys sharing a string
+// form, such as 1 and "1", are one member, whose value is that of the string
+// key if there is one.
+type anyMap map[interface{}]interface{}
+
+func (m anyMap) Get(key string) (interface{}, bool) {
+	if v, ok := m[key]; ok {
+		return v, true
+	}
+	for k, v := range m {
+		if _, isString := k.(string); !isString && keyString(k) == key {
+			return v, true
+		}
+	}
+	return nil, false
+}
+
+func (m anyMap) Keys() []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, keyString(k))
+	}
+	return uniqueSorted(keys)
+}
+
+// uniqueSorted sorts keys and drops repeats, which arise when map keys of
+// different values, such as 1 and "1", share a string form.
+func uniqueSorted(keys []string) []string {
+	sort.Strings(keys)
+	out := keys[:0]
+	for _, k := range keys {
+		if len(out) == 0 || out[len(out)-1] != k {
+			out = append(out, k)
+		}
+	}
+	return out
+}
+
+// maxDecodeDepth bounds the nesting of binary documents, matching the limit
+// encoding/json applies to JSON text.
+const maxDecodeDepth = 10000
+
+// objectBuilder assembles an object decoded from a binary format. It builds a
+// map[string]interface{} and switches to map[interface{}]interface{} at the
+// first key that is not a string. Byte string keys are stored as strings.
+type objectBuilder struct {
+	obj    map[string]interface{}
+	anyObj map[interface{}]interface{}
+}
+
+func newObjectBuilder(sizeHint int) objectBuilder {
+	if sizeHint < 0 {
+		sizeHint = 0
+	}
+	return objectBuilder{obj: make(map[string]interface{}, sizeHint)}
+}
+
+func (b *objectBuilder) set(k, v interface{}) error {
+	switch key := k.(type) {
+	case string:
+		if b.anyObj == nil {
+			b.obj[key] = v
+			return nil
+		}
+	case []byte:
+		return b.set(string(key), v)
+	case []interface{}, map[string]interface{}, map[interface{}]interface{}:
+		return fmt.Errorf("unsupported map key type %T", k)
+	}
+	if b.anyObj == nil {
+		b.anyObj = make(map[interface{}]interface{}, len(b.obj)+1)
+		for sk, sv := range b.obj {
+			b.anyObj[sk] = sv
+		}
+	}
+	b.anyObj[k] = v
+	return nil
+}
+
+func (b *objectBuilder) value() interface{} {
+	if b.anyObj != nil {
+		return b.anyObj
+	}
+	return b.obj
+}
+
+// keyString returns the member name used for a map key: the text of keys
+// that implement encoding.TextMarshaler, as with encoding/json, and the
+// formatted value of other keys.
+func keyString(k interface{}) string {
+	switch v := k.(type) {
+	case string:
+		return v
+	case encoding.TextMarshaler:
+		if b, err := v.MarshalText(); err == nil {
+			return string(b)
+		}
+	}
+	rv := reflect.ValueOf(k)
+	if rv.Kind() == reflect.String {
+		return rv.String()
+	}
+	return fmt.Sprint(k)
+}
+
+type sliceArray []interface{}
+
+func (a sliceArray) Len() int                { return len(a) }
+func (a sliceArray) Index(i int) interface{} { return a[i] }
+
+type mapSliceArray []map[string]interface{}
+
+func (a mapSliceArray) Len() int                { return len(a) }
+func (a mapSliceArray) Index(i int) interface{} { return a[i] }
+
+type reflectMap struct{ v reflect.Value }
+
+func (m reflectMap) Get(key string) (interface{}, bool) {
+	kt := m.v.Type().Key()
+	var k reflect.Value
+	switch kind := kt.Kind(); {
+	case kt.Implements(textMarshalerType):
+	case kind == reflect.String:
+		k = reflect.ValueOf(key).Convert(kt)
+	case kind >= reflect.Int && kind <= reflect.Int64:
+		n, err := strconv.ParseInt(key, 10, 64)
+		if err != nil || strconv.FormatInt(n, 10) != key || reflect.Zero(kt).OverflowInt(n) {
+			return nil, false
+		}
+		k = reflect.ValueOf(n).Convert(kt)
+	case kind >= reflect.Uint && kind <= reflect.Uintptr:
+		n, err := strconv.ParseUint(key, 10, 64)
+		if err != nil || strconv.FormatUint(n, 10) != key || reflect.Zero(kt).OverflowUint(n) {
+			return nil, false
+		}
+		k = reflect.ValueOf(n).Convert(kt)
+	}
+	if k.IsValid() {
+		if v := m.v.MapIndex(k); v.IsValid() {
+			return v.Interface(), true
+		}
+		return nil, false
+	}
+
+	// other key types are matched by their string form
+	iter := m.v.MapRange()
+	for iter.Next() {
+		if keyString(iter.Key().Interface()) == key {
+			return iter.Value().Interface(), true
+		}
+	}
+	return nil, false
+}
+
+func (m reflectMap) Keys() []string {
+	keys := make([]string, 0, m.v.Len())
+	iter := m.v.MapRange()
+	for iter.Next() {
+		keys = append(keys, keyString(iter.Key().Interface()))
+	}
+	return uniqueSorted(keys)
+}
+
+type reflectArray struct{ v reflect.Value }
+
+func (a reflectArray) Len() int                { return a.v.Len() }
+func (a reflectArray) Index(i int) interface{} { return a.v.Index(i).Interface() }
+
+// isOrderedDoc reports whether t is a slice of Key/Value structs, the shape
+// of bson.D.
+func isOrderedDoc(t reflect.Type) bool {
+	if t.Kind() != reflect.Slice || t.Elem().Kind() != reflect.Struct {
+		return false
+	}
+	k, ok := t.Elem().FieldByName("Key")
+	if !ok || k.Type.Kind() != reflect.String {
+		return false
+	}
+	v, ok := t.Elem().FieldByName("Value")
+	return ok && v.Type.Kind() == reflect.Interface
+}
+
+// orderedDoc is a bson.D-like slice of Key/Value structs. Members are
+// traversed in slice order; when a key repeats, the first member wins.
+type orderedDoc struct{ v reflect.Value }
+
+func (d orderedDoc) Get(key string) (interface{}, bool) {
+	for i, n := 0, d.v.Len(); i < n; i++ {
+		e := d.v.Index(i)
+		if e.FieldByName("Key").String() == key {
+			return e.FieldByName("Value").Interface(), true
+		}
+	}
+	return nil, false
+}
+
+func (d orderedDoc) Keys() []string {
+	n := d.v.Len()
+	keys := make([]string, 0, n)
+	seen := make(map[string]bool, n)
+	for i := 0; i < n; i++ {
+		k := d.v.Index(i).FieldByName("Key").String()
+		if !seen[k] {
+			seen[k] = true
+			keys = append(keys, k)
+		}
+	}
+	return keys
+}
+
+// structObject is a struct viewed through its encoding/json field names.
+type structObject struct {
+	v      reflect.Value
+	fields []structField
+}
+
+// structField is a member of a struct type as encoding/json sees it.
+type structField struct {
+	name      string
+	index     []int
+	omitEmpty bool
+	depth     int
+	tagged    bool
+}
+
+func (s structObject) Get(key string) (interface{}, bool) {
+	for _, f := range s.fields {
+		if f.name == key {
+			return s.field(f)
+		}
+	}
+	return nil, false
+}
+
+func (s structObject) Keys() []string {
+	keys := make([]string, 0, len(s.fields))
+	for _, f := range s.fields {
+		if _, ok := s.field(f); ok {
+			keys = append(keys, f.name)
+		}
+	}
+	return keys
+}
+
+// field returns the value of f, which is absent when it is reached through
+// a nil embedded pointer or is empty and tagged omitempty.
+func (s structObject) field(f structField) (interface{}, bool) {
+	v := s.v
+	for i, x := range f.index {
+		if i > 0 && v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				return nil, false
+			}
+			v = v.Elem()
+		}
+		v = v.Field(x)
+	}
+	if f.omitEmpty && isEmptyValue(v) {
+		return nil, false
+	}
+	if (v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface) && v.IsNil() {
+		return nil, true
+	}
+	return v.Interface(), true
+}
+
+var fieldCache sync.Map // reflect.Type -> []structField
+
+func cachedFields(t reflect.Type) []structField {
+	if f, ok := fieldCache.Load(t); ok {
+		return f.([]structField)
+	}
+	f, _ := fieldCache.LoadOrStore(t, typeFields(t))
+	return f.([]structField)
+}
+
+// typeFields lists the members of struct type t in declaration order,
+// following the encoding/json rules: exported fields only, names from json
+// tags, "-" skipped, and fields of untagged embedded structs promoted. Of
+// the fields sharing a name, the shallowest wins, then the only tagged one
+// among them; if that leaves several, the name is dropped.
+func typeFields(t reflect.Type) []structField {
+	var all []structField
+	walking := map[reflect.Type]bool{}
+
+	var walk func(t reflect.Type, index []int, depth int)
+	walk = func(t reflect.Type, index []int, depth int) {
+		if walking[t] {
+			return
+		}
+		walking[t] = true
+		defer delete(walking, t)
+		for i := 0; i < t.NumField(); i++ {
+			sf := t.Field(i)
+			tag := sf.Tag.Get("json")
+			if tag == "-" {
+				continue
+			}
+			name, opts, _ := strings.Cut(tag, ",")
+			idx := append(append([]int(nil), index...), i)
+
+			if sf.Anonymous && name == "" {
+				ft := sf.Type
+				if ft.Kind() == reflect.Ptr {
+					ft = ft.Elem()
+				}
+				if ft.Kind() == reflect.Struct {
+					walk(ft, idx, depth+1)
+					continue
+				}
+			}
+			if !sf.IsExported() {
+				continue
+			}
+			if name == "" {
+				name = sf.Name
+			}
+			all = append(all, structField{
+				name:      name,
+				index:     idx,
+				omitEmpty: strings.Contains(","+opts+",", ",omitempty,"),
+				depth:     depth,
+				tagged:    tag != "",
+			})
+		}
+	}
+	walk(t, nil, 0)
+
+	byName := map[string][]int{}
+	for i, f := range all {
+		byName[f.name] = append(byName[f.name], i)
+	}
+	var fields []structField
+	for i, f := range all {
+		if dominantField(all, byName[f.name]) == i {
+			fields = append(fields, f)
+		}
+	}
+	return fields
+}
+
+// dominantField returns which of the fields at candidates, which share a
+// name, the name refers to, or -1 if none dominates and the name is dropped.
+func dominantField(fields []structField, candidates []int) int {
+	best, ties := -1, 0
+	for _, i := range candidates {
+		f := fields[i]
+		switch {
+		case best < 0 || f.depth < fields[best].depth || (f.depth == fields[best].depth && f.tagged && !fields[best].tagged):
+			best, ties = i, 0
+		case f.depth == fields[best].depth && f.tagged == fields[best].tagged:
+			ties++
+		}
+	}
+	if ties > 0 {
+		return -1
+	}
+	return best
+}
+
+// isEmptyValue reports whether v is empty in the sense of omitempty.
+func isEmptyValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return v.Len() == 0
+	case reflect.Bool:
+		return !v.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int() == 0
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return v.Uint() == 0
+	case reflect.Float32, reflect.Float64:
+		return v.Float() == 0
+	case reflect.Interface, reflect.Ptr:
+		return v.IsNil()
+	}
+	return false
+}
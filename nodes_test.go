@@ -0,0 +1,390 @@
+package jsonpath_test
+
+import (
+	"encoding/json"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/njchilds90/go-jsonpath"
+)
+
+// yamlV2Manifest mirrors what gopkg.in/yaml.v2 produces for a small
+// Kubernetes-style manifest: every mapping is a map[interface{}]interface{}.
+func yamlV2Manifest() interface{} {
+	return map[interface{}]interface{}{
+		"kind": "Deployment",
+		"spec": map[interface{}]interface{}{
+			"replicas": 3,
+			"containers": []interface{}{
+				map[interface{}]interface{}{"name": "web", "image": "nginx:1.25", "ports": map[interface{}]interface{}{8080: "http"}},
+				map[interface{}]interface{}{"name": "sidecar", "image": "envoy:1.29"},
+			},
+		},
+	}
+}
+
+func TestQueryValueInterfaceKeyedMaps(t *testing.T) {
+	doc := yamlV2Manifest()
+
+	tests := []struct {
+		path  string
+		paths []string
+	}{
+		{"$.kind", []string{"$.kind"}},
+		{"$.spec.containers[*].image", []string{"$.spec.containers[0].image", "$.spec.containers[1].image"}},
+		{"$.spec.*", []string{"$.spec.containers", "$.spec.replicas"}},
+		{"$..name", []string{"$.spec.containers[0].name", "$.spec.containers[1].name"}},
+		{"$.spec.containers[?(@.name == 'sidecar')].image", []string{"$.spec.containers[1].image"}},
+		{"$.spec.containers[0]['name','image']", []string{"$.spec.containers[0].name", "$.spec.containers[0].image"}},
+		{"$.spec.containers[0].ports['8080']", []string{"$.spec.containers[0].ports.8080"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			results, err := jsonpath.QueryValue(doc, tt.path)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(results) != len(tt.paths) {
+				t.Fatalf("expected %d results, got %d: %v", len(tt.paths), len(results), results)
+			}
+			for i, r := range results {
+				if r.Path != tt.paths[i] {
+					t.Errorf("result %d: expected path %s, got %s", i, tt.paths[i], r.Path)
+				}
+			}
+		})
+	}
+}
+
+func TestQueryValueInterfaceKeyedMapsStrict(t *testing.T) {
+	_, err := jsonpath.QueryValue(yamlV2Manifest(), "$.spec.missing", jsonpath.WithAllowMissingKeys(true))
+	if !jsonpath.IsNotFound(err) {
+		t.Errorf("expected not-found error, got: %v", err)
+	}
+}
+
+// tomlConfig mirrors what github.com/BurntSushi/toml produces: integers are
+// int64, datetimes are time.Time and arrays of tables are
+// []map[string]interface{}.
+func tomlConfig() interface{} {
+	return map[string]interface{}{
+		"title": "releases",
+		"release": []map[string]interface{}{
+			{"version": "1.0.0", "build": int64(100), "date": time.Date(2023, 6, 1, 0, 0, 0, 0, time.UTC)},
+			{"version": "1.1.0", "build": int64(142), "date": time.Date(2024, 2, 15, 0, 0, 0, 0, time.UTC)},
+		},
+	}
+}
+
+func TestQueryValueTOMLTypes(t *testing.T) {
+	doc := tomlConfig()
+
+	tests := []struct {
+		path     string
+		expected []interface{}
+	}{
+		{"$.release[*].version", []interface{}{"1.0.0", "1.1.0"}},
+		{"$.release[-1].build", []interface{}{int64(142)}},
+		{"$.release[?(@.build > 120)].version", []interface{}{"1.1.0"}},
+		{"$.release[?(@.date >= '2024-01-01')].version", []interface{}{"1.1.0"}},
+		{"$.release[?(@.date == '2023-06-01T00:00:00Z')].build", []interface{}{int64(100)}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			results, err := jsonpath.QueryValue(doc, tt.path)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(results) != len(tt.expected) {
+				t.Fatalf("expected %d results, got %d: %v", len(tt.expected), len(results), results)
+			}
+			for i, r := range results {
+				if r.Value != tt.expected[i] {
+					t.Errorf("result %d: expected %v (%T), got %v (%T)", i, tt.expected[i], tt.expected[i], r.Value, r.Value)
+				}
+			}
+		})
+	}
+}
+
+// Local stand-ins with the conversion methods of structpb.Struct,
+// structpb.ListValue and structpb.Value.
+type (
+	protoStruct struct{ fields map[string]interface{} }
+	protoList   struct{ values []interface{} }
+	protoValue  struct{ v interface{} }
+)
+
+func (s *protoStruct) AsMap() map[string]interface{} { return s.fields }
+func (l *protoList) AsSlice() []interface{}          { return l.values }
+func (v *protoValue) AsInterface() interface{}       { return v.v }
+
+func TestQueryValueProtobufStruct(t *testing.T) {
+	s := &protoStruct{fields: map[string]interface{}{
+		"user": map[string]interface{}{"name": "ada", "roles": []interface{}{"admin", "dev"}},
+	}}
+	results, err := jsonpath.QueryValue(s, "$.user.roles[?(@ == 'dev')]")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(results) != 1 || results[0].Value != "dev" || results[0].Path != "$.user.roles[1]" {
+		t.Errorf("unexpected results: %v", results)
+	}
+
+	l := &protoList{values: []interface{}{1.0, 2.0, 3.0}}
+	if results, _ := jsonpath.QueryValue(l, "$[-1]"); len(results) != 1 || results[0].Value != 3.0 {
+		t.Errorf("unexpected list results: %v", results)
+	}
+
+	v := &protoValue{v: "scalar"}
+	if results, _ := jsonpath.QueryValue(v, "$"); len(results) != 1 || results[0].Value != "scalar" {
+		t.Errorf("unexpected value results: %v", results)
+	}
+}
+
+type serverConfig struct {
+	Addr    string   `json:"addr"`
+	Weight  int      `json:"weight,omitempty"`
+	Tags    []string `json:"tags,omitempty"`
+	Secret  string   `json:"-"`
+	Backup  *serverConfig
+	private string
+}
+
+type Meta struct {
+	Owner   string    `json:"owner"`
+	Created time.Time `json:"created"`
+}
+
+type appConfig struct {
+	Meta
+	Name    string          `json:"name"`
+	Servers []*serverConfig `json:"servers"`
+	Limits  map[string]int  `json:"limits"`
+}
+
+func TestQueryValueStructs(t *testing.T) {
+	created := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	cfg := &appConfig{
+		Meta: Meta{Owner: "ops", Created: created},
+		Name: "api",
+		Servers: []*serverConfig{
+			{Addr: "10.0.0.1:80", Weight: 3, Tags: []string{"primary"}, Secret: "s", private: "p"},
+			{Addr: "10.0.0.2:80", Backup: &serverConfig{Addr: "10.0.1.2:80"}},
+		},
+		Limits: map[string]int{"rps": 100},
+	}
+
+	tests := []struct {
+		path     string
+		expected []interface{}
+	}{
+		{"$.servers[*].addr", []interface{}{"10.0.0.1:80", "10.0.0.2:80"}},
+		{"$.owner", []interface{}{"ops"}},
+		{"$.created", []interface{}{created}},
+		{"$.servers[0].tags[0]", []interface{}{"primary"}},
+		{"$.servers[?(@.weight > 1)].addr", []interface{}{"10.0.0.1:80"}},
+		{"$.servers[1].Backup.addr", []interface{}{"10.0.1.2:80"}},
+		{"$.servers[0].Backup", []interface{}{nil}},
+		{"$.limits.rps", []interface{}{100}},
+		{"$..addr", []interface{}{"10.0.0.1:80", "10.0.0.2:80", "10.0.1.2:80"}},
+		// omitempty, "-" and unexported fields are not members
+		{"$.servers[1].weight", nil},
+		{"$.servers[0].Secret", nil},
+		{"$.servers[0].private", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			results, err := jsonpath.QueryValue(cfg, tt.path)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			var got []interface{}
+			for _, r := range results {
+				got = append(got, r.Value)
+			}
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("expected %#v, got %#v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestQueryValueStructKeysInDeclarationOrder(t *testing.T) {
+	results, err := jsonpath.QueryValue(serverConfig{Addr: "a", Weight: 1}, "$.*")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var paths []string
+	for _, r := range results {
+		paths = append(paths, r.Path)
+	}
+	expected := []string{"$.addr", "$.weight", "$.Backup"}
+	if !reflect.DeepEqual(paths, expected) {
+		t.Errorf("expected %v, got %v", expected, paths)
+	}
+}
+
+type (
+	conflictA struct {
+		ID   int
+		Name string
+	}
+	conflictB struct {
+		ID int
+	}
+	conflictC struct {
+		Note string `json:"note"`
+	}
+	conflictD struct {
+		Note string
+	}
+	conflicted struct {
+		conflictA
+		conflictB
+		conflictC
+		conflictD
+		*conflicted
+		Kind string `json:"kind"`
+	}
+)
+
+func TestQueryValueStructFieldConflicts(t *testing.T) {
+	v := conflicted{conflictA: conflictA{1, "a"}, conflictB: conflictB{2}, conflictC: conflictC{"c"}, conflictD: conflictD{"d"}, Kind: "k"}
+	results, err := jsonpath.QueryValue(v, "$.*")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	got := map[string]interface{}{}
+	for _, r := range results {
+		got[strings.TrimPrefix(r.Path, "$.")] = r.Value
+	}
+	// the members encoding/json writes: ID is ambiguous and dropped
+	out, _ := json.Marshal(v)
+	var want map[string]interface{}
+	if err := json.Unmarshal(out, &want); err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != len(want) || got["note"] != "c" || got["Name"] != "a" || got["ID"] != nil || want["note"] != "c" {
+		t.Errorf("expected the members of %s, got %v", out, got)
+	}
+}
+
+// color is a map key type that names itself, like many enum types.
+type color int
+
+func (c color) MarshalText() ([]byte, error) {
+	return []byte([]string{"red", "green"}[c]), nil
+}
+
+func TestQueryValueNonStringKeys(t *testing.T) {
+	doc := map[string]interface{}{
+		"ports":  map[int]interface{}{443: "https", 80: "http", 8080: "alt"},
+		"flags":  map[bool]string{true: "on"},
+		"bytes":  map[uint8]int{7: 1},
+		"colors": map[color]int{0: 10, 1: 20},
+		"mixed":  map[interface{}]interface{}{1: "one", "two": 2},
+		"clash":  map[interface{}]interface{}{1: "int", "1": "string"},
+		"nan":    map[float64]int{math.NaN(): 1, math.NaN(): 2},
+	}
+
+	tests := []struct {
+		path  string
+		paths []string
+	}{
+		{"$.ports['443']", []string{"$.ports.443"}},
+		{"$.ports.*", []string{"$.ports.443", "$.ports.80", "$.ports.8080"}},
+		{"$.ports['0443']", nil},
+		{"$.ports['x']", nil},
+		{"$.flags['true']", []string{"$.flags.true"}},
+		{"$.bytes['7']", []string{"$.bytes.7"}},
+		{"$.bytes['300']", nil},
+		{"$.colors.green", []string{"$.colors.green"}},
+		{"$.mixed.*", []string{"$.mixed.1", "$.mixed.two"}},
+		{"$..[?(@ == 'http')]", []string{"$.ports.80"}},
+		// keys with the same string form are one member
+		{"$.clash.*", []string{"$.clash.1"}},
+		{"$.clash[?(@ == 'string')]", []string{"$.clash.1"}},
+		{"$.nan.*", []string{"$.nan.NaN"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			results, err := jsonpath.QueryValue(doc, tt.path)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			var got []string
+			for _, r := range results {
+				got = append(got, r.Path)
+			}
+			if !reflect.DeepEqual(got, tt.paths) {
+				t.Errorf("expected %v, got %v", tt.paths, got)
+			}
+		})
+	}
+}
+
+// orderedMap is a custom Object that keeps insertion order.
+type orderedMap struct {
+	keys   []string
+	values map[string]interface{}
+}
+
+func (m *orderedMap) Get(key string) (interface{}, bool) {
+	v, ok := m.values[key]
+	return v, ok
+}
+
+func (m *orderedMap) Keys() []string { return m.keys }
+
+// lazyArray is a custom Array whose elements are produced on access.
+type lazyArray struct {
+	n        int
+	accessed []int
+}
+
+func (a *lazyArray) Len() int { return a.n }
+
+func (a *lazyArray) Index(i int) interface{} {
+	a.accessed = append(a.accessed, i)
+	return &orderedMap{keys: []string{"n"}, values: map[string]interface{}{"n": float64(i)}}
+}
+
+func TestQueryValueCustomContainers(t *testing.T) {
+	arr := &lazyArray{n: 100}
+	doc := &orderedMap{
+		keys:   []string{"zebra", "apple", "items"},
+		values: map[string]interface{}{"zebra": 1.0, "apple": 2.0, "items": arr},
+	}
+
+	results, err := jsonpath.QueryValue(doc, "$.*")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var paths []string
+	for _, r := range results {
+		paths = append(paths, r.Path)
+	}
+	if expected := []string{"$.zebra", "$.apple", "$.items"}; !reflect.DeepEqual(paths, expected) {
+		t.Errorf("expected custom key order %v, got %v", expected, paths)
+	}
+
+	results, err = jsonpath.QueryValue(doc, "$.items[2:4].n")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(results) != 2 || results[0].Value != 2.0 || results[1].Path != "$.items[3].n" {
+		t.Errorf("unexpected results: %v", results)
+	}
+	if !reflect.DeepEqual(arr.accessed, []int{2, 3}) {
+		t.Errorf("expected only elements 2 and 3 to be produced, got %v", arr.accessed)
+	}
+
+	results, err = jsonpath.QueryValue(doc, "$.items[?(@.n > 97)].n")
+	if err != nil || len(results) != 2 {
+		t.Errorf("expected 2 filter matches, got %v (err %v)", results, err)
+	}
+}
@@ -0,0 +1,137 @@
+package jsonpath
+
+import "encoding/json"
+
+// span is the half-open byte range [start, end) of a value in its source.
+type span struct {
+	start, end int
+}
+
+// indexSpans records the span of every value in data, keyed by the same
+// normalized paths the evaluator produces. data must already be known to
+// be valid JSON; scanning stops quietly at anything unexpected.
+func indexSpans(data []byte) map[string]span {
+	s := &spanScanner{data: data, spans: make(map[string]span)}
+	s.value("$")
+	return s.spans
+}
+
+type spanScanner struct {
+	data  []byte
+	pos   int
+	spans map[string]span
+}
+
+func (s *spanScanner) skipSpace() {
+	for s.pos < len(s.data) {
+		switch s.data[s.pos] {
+		case ' ', '\t', '\n', '\r':
+			s.pos++
+		default:
+			return
+		}
+	}
+}
+
+func (s *spanScanner) value(path string) bool {
+	s.skipSpace()
+	if s.pos >= len(s.data) {
+		return false
+	}
+	start := s.pos
+	switch s.data[s.pos] {
+	case '{':
+		s.pos++
+		for {
+			s.skipSpace()
+			if s.pos >= len(s.data) {
+				return false
+			}
+			if s.data[s.pos] == '}' {
+				s.pos++
+				break
+			}
+			if s.data[s.pos] == ',' {
+				s.pos++
+				continue
+			}
+			keyStart := s.pos
+			if !s.skipString() {
+				return false
+			}
+			key := s.decodeKey(s.data[keyStart:s.pos])
+			s.skipSpace()
+			if s.pos >= len(s.data) || s.data[s.pos] != ':' {
+				return false
+			}
+			s.pos++
+			if !s.value(childPath(path, key)) {
+				return false
+			}
+		}
+	case '[':
+		s.pos++
+		for i := 0; ; {
+			s.skipSpace()
+			if s.pos >= len(s.data) {
+				return false
+			}
+			if s.data[s.pos] == ']' {
+				s.pos++
+				break
+			}
+			if s.data[s.pos] == ',' {
+				s.pos++
+				continue
+			}
+			if !s.value(indexPath(path, i)) {
+				return false
+			}
+			i++
+		}
+	case '"':
+		if !s.skipString() {
+			return false
+		}
+	default:
+		for s.pos < len(s.data) {
+			c := s.data[s.pos]
+			if c == ',' || c == '}' || c == ']' || c == ' ' || c == '\t' || c == '\n' || c == '\r' {
+				break
+			}
+			s.pos++
+		}
+	}
+	s.spans[path] = span{start: start, end: s.pos}
+	return true
+}
+
+// skipString advances past the string literal starting at s.pos.
+func (s *spanScanner) skipString() bool {
+	s.pos++ // opening quote
+	for s.pos < len(s.data) {
+		switch s.data[s.pos] {
+		case '\\':
+			s.pos += 2
+		case '"':
+			s.pos++
+			return true
+		default:
+			s.pos++
+		}
+	}
+	return false
+}
+
+func (s *spanScanner) decodeKey(quoted []byte) string {
+	for _, c := range quoted {
+		if c == '\\' {
+			var key string
+			if err := json.Unmarshal(quoted, &key); err == nil {
+				return key
+			}
+			break
+		}
+	}
+	return string(quoted[1 : len(quoted)-1])
+}
@@ -0,0 +1,117 @@
+package jsonpath_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/njchilds90/go-jsonpath"
+)
+
+func TestWithOffsets(t *testing.T) {
+	data := []byte(`{
+  "items": [ {"id": 1, "tags": ["a", "b"]}, {"id": 22, "tags": []} ],
+  "esc\"aped": {"k": true},
+  "n": null
+}`)
+
+	tests := []struct {
+		path string
+		want []string
+	}{
+		{"$.items[*].id", []string{`1`, `22`}},
+		{"$.items[0].tags", []string{`["a", "b"]`}},
+		{"$.items[1]", []string{`{"id": 22, "tags": []}`}},
+		{`$['esc"aped'].k`, []string{`true`}},
+		{"$.n", []string{`null`}},
+		{"$..tags[*]", []string{`"a"`, `"b"`}},
+	}
+	for _, tt := range tests {
+		results, err := jsonpath.Query(data, tt.path, jsonpath.WithOffsets())
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", tt.path, err)
+		}
+		if len(results) != len(tt.want) {
+			t.Fatalf("%s: expected %d results, got %d", tt.path, len(tt.want), len(results))
+		}
+		for i, r := range results {
+			if got := string(data[r.Start:r.End]); got != tt.want[i] {
+				t.Errorf("%s: result %d spans %q, want %q", tt.path, i, got, tt.want[i])
+			}
+		}
+	}
+}
+
+func TestWithOffsetsDocumentAndStreaming(t *testing.T) {
+	data := []byte(`{"a": {"b": [10, 20]}}`)
+	doc, err := jsonpath.Parse(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	results, err := jsonpath.MustCompile("$.a.b[1]").QueryDocument(doc, jsonpath.WithOffsets())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := string(data[results[0].Start:results[0].End]); got != "20" {
+		t.Errorf("unexpected span %q", got)
+	}
+
+	err = jsonpath.QueryFunc(context.Background(), data, "$.a", func(r jsonpath.Result) error {
+		if got := string(data[r.Start:r.End]); got != `{"b": [10, 20]}` {
+			t.Errorf("unexpected span %q", got)
+		}
+		return nil
+	}, jsonpath.WithOffsets())
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestOffsetsNotRequested(t *testing.T) {
+	results, err := jsonpath.Query([]byte(`{"a": 1}`), "$.a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if results[0].Start != 0 || results[0].End != 0 {
+		t.Errorf("expected no offsets by default, got %d..%d", results[0].Start, results[0].End)
+	}
+	b, _ := json.Marshal(results[0])
+	if string(b) != `{"path":"$.a","value":1}` {
+		t.Errorf("unexpected JSON: %s", b)
+	}
+}
+
+func TestWithRawValues(t *testing.T) {
+	data := []byte(`{"users": [{"name": "ann", "meta": {"x": [1, 2.50]}}, {"name": "bob"}]}`)
+
+	results, err := jsonpath.Query(data, "$.users[*]", jsonpath.WithRawValues())
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{`{"name": "ann", "meta": {"x": [1, 2.50]}}`, `{"name": "bob"}`}
+	for i, r := range results {
+		raw, ok := r.Value.(json.RawMessage)
+		if !ok {
+			t.Fatalf("expected json.RawMessage, got %T", r.Value)
+		}
+		if string(raw) != want[i] {
+			t.Errorf("got %s, want %s", raw, want[i])
+		}
+	}
+
+	// Filters still see decoded values; only the output is raw.
+	results, err = jsonpath.Query(data, `$.users[?(@.name == "bob")].name`, jsonpath.WithRawValues())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 1 || string(results[0].Value.(json.RawMessage)) != `"bob"` {
+		t.Errorf("unexpected results: %v", results)
+	}
+
+	// Re-encoding a raw result embeds the original text verbatim.
+	b, _ := json.Marshal(results[0])
+	if string(b) != `{"end":68,"path":"$.users[1].name","start":63,"value":"bob"}` {
+		t.Errorf("unexpected JSON: %s", b)
+	}
+}
@@ -0,0 +1,80 @@
+package jsonpath
+
+import "sort"
+
+// Order selects the order in which WithOrder delivers the members of
+// objects, and so the order of results.
+type Order int
+
+const (
+	// LexicalOrder visits object members sorted by key, so output is
+	// deterministic whatever the input. It is the default.
+	LexicalOrder Order = iota
+	// DocumentOrder returns results in the order they appear in the source
+	// text, for []byte input and Documents created with Parse. Other inputs
+	// have no source text and keep lexical order, except structs and custom
+	// Objects, which have their own order. Results are collected before the
+	// first callback of streaming queries such as QueryFunc.
+	DocumentOrder
+	// Unordered visits the members of decoded JSON objects in Go's map
+	// iteration order, which varies from run to run, and skips sorting keys.
+	// Use it when the caller does not depend on the order of results.
+	Unordered
+)
+
+// WithOrder sets the order of results: LexicalOrder, DocumentOrder or
+// Unordered. WithSort, when also given, orders results afterwards; results
+// it considers equal keep the order chosen here.
+//
+// Example:
+//
+//	// config keys in the order the file lists them
+//	results, err := jsonpath.Query(data, "$.steps.*", jsonpath.WithOrder(jsonpath.DocumentOrder))
+func WithOrder(order Order) Option {
+	return func(e *engine) {
+		e.order = order
+	}
+}
+
+// eachMember calls fn for each member of obj, sorted by key unless
+// WithOrder(Unordered) lets a decoded map be visited as Go ranges over it.
+func (e *engine) eachMember(obj Object, fn func(key string, v interface{}) error) error {
+	if m, ok := obj.(stringMap); ok && e.order == Unordered {
+		for k, v := range m {
+			if err := fn(k, v); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	for _, k := range obj.Keys() {
+		v, _ := obj.Get(k)
+		if err := fn(k, v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// reorders reports whether results are reordered or sampled once
+// collected, so that streaming queries must collect them first.
+func (e *engine) reorders() bool {
+	return e.sortOrder != 0 || (e.order == DocumentOrder && e.sourceSpans != nil) || e.sampleSize > 0
+}
+
+// arrange orders collected results as WithOrder and WithSort ask, and
+// returns the page of them WithResultOffset and WithMaxResults select.
+func (e *engine) arrange(results []Result) []Result {
+	if e.order == DocumentOrder && e.sourceSpans != nil {
+		sort.SliceStable(results, func(i, j int) bool {
+			return e.sourceSpans[results[i].Path].start < e.sourceSpans[results[j].Path].start
+		})
+	}
+	if e.sortOrder != 0 {
+		SortResults(results, e.sortOrder)
+	}
+	if e.pages() && e.reorders() {
+		return e.pageOf(results)
+	}
+	return results
+}
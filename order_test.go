@@ -0,0 +1,70 @@
+package jsonpath_test
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/njchilds90/go-jsonpath"
+)
+
+var stepsJSON = []byte(`{"steps":{"fetch":{"run":"git"},"build":{"run":"go"},"test":{"run":"go test"},"archive":{"run":"tar"}}}`)
+
+func TestWithOrder(t *testing.T) {
+	tests := []struct {
+		order jsonpath.Order
+		want  string
+	}{
+		{jsonpath.LexicalOrder, "archive build fetch test"},
+		{jsonpath.DocumentOrder, "fetch build test archive"},
+	}
+	for _, tt := range tests {
+		paths, err := jsonpath.Paths(stepsJSON, "$.steps.*", jsonpath.WithOrder(tt.order))
+		if err != nil {
+			t.Fatal(err)
+		}
+		var names []string
+		for _, p := range paths {
+			names = append(names, strings.TrimPrefix(p, "$.steps."))
+		}
+		if got := strings.Join(names, " "); got != tt.want {
+			t.Errorf("order %d: expected %s, got %s", tt.order, tt.want, got)
+		}
+	}
+}
+
+func TestDocumentOrderDescendants(t *testing.T) {
+	var got []string
+	err := jsonpath.QueryFunc(context.Background(), stepsJSON, "$..run", func(r jsonpath.Result) error {
+		got = append(got, r.Value.(string))
+		return nil
+	}, jsonpath.WithOrder(jsonpath.DocumentOrder))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(got, ",") != "git,go,go test,tar" {
+		t.Errorf("expected document order, got %v", got)
+	}
+
+	// WithSort orders afterwards; equal values keep document order
+	results, _ := jsonpath.Query([]byte(`{"z":1,"y":0,"x":1}`), "$.*", jsonpath.WithOrder(jsonpath.DocumentOrder), jsonpath.WithSort(jsonpath.ByValueAsc))
+	var paths []string
+	for _, r := range results {
+		paths = append(paths, r.Path)
+	}
+	if strings.Join(paths, " ") != "$.y $.z $.x" {
+		t.Errorf("expected $.y $.z $.x, got %v", paths)
+	}
+}
+
+func TestUnordered(t *testing.T) {
+	paths, err := jsonpath.Paths(stepsJSON, "$.steps[?(@.run)]", jsonpath.WithOrder(jsonpath.Unordered))
+	if err != nil {
+		t.Fatal(err)
+	}
+	sort.Strings(paths)
+	if strings.Join(paths, " ") != "$.steps.archive $.steps.build $.steps.fetch $.steps.test" {
+		t.Errorf("expected every step, got %v", paths)
+	}
+}
@@ -0,0 +1,67 @@
+package jsonpath
+
+// WithResultOffset skips the first n matches, so that with WithMaxResults a
+// UI can page through the matches of an expensive query without running it
+// unbounded and slicing the results: page k of size m is
+// WithResultOffset(k*m) with WithMaxResults(m). Matches are counted in the
+// order they are returned, after WithOrder and WithSort, so pages are stable
+// for the same document unless WithOrder(Unordered) is set. Default is 0.
+//
+// Example:
+//
+//	// the third page of 50 entries
+//	page, err := jsonpath.Query(data, "$..entry[*]", jsonpath.WithResultOffset(100), jsonpath.WithMaxResults(50))
+func WithResultOffset(n int) Option {
+	return func(e *engine) {
+		e.skip = max(n, 0)
+	}
+}
+
+// WithMaxResults returns at most n matches, those after WithResultOffset.
+// Evaluation ends once it has them, unless WithSort or
+// WithOrder(DocumentOrder) needs every match to order them first. Default is
+// 0 (unlimited).
+func WithMaxResults(n int) Option {
+	return func(e *engine) {
+		e.maxResults = max(n, 0)
+	}
+}
+
+// pages reports whether WithResultOffset or WithMaxResults is set.
+func (e *engine) pages() bool {
+	return e.skip > 0 || e.maxResults > 0
+}
+
+// page wraps fn to drop the matches before the offset and end evaluation
+// with ErrStop once the page is full.
+func (e *engine) page(fn emitFunc) emitFunc {
+	return pageFunc(e.skip, e.maxResults, fn)
+}
+
+// pageFunc wraps fn to drop the first skip values and return ErrStop once
+// it has passed limit more, if limit is positive.
+func pageFunc[T any](skip, limit int, fn func(T) error) func(T) error {
+	seen := 0
+	return func(v T) error {
+		seen++
+		if seen <= skip {
+			return nil
+		}
+		if err := fn(v); err != nil {
+			return err
+		}
+		if limit > 0 && seen >= skip+limit {
+			return ErrStop
+		}
+		return nil
+	}
+}
+
+// pageOf returns the page of results, collected and ordered in full.
+func (e *engine) pageOf(results []Result) []Result {
+	results = results[min(e.skip, len(results)):]
+	if e.maxResults > 0 && len(results) > e.maxResults {
+		results = results[:e.maxResults]
+	}
+	return results
+}
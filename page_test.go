@@ -0,0 +1,58 @@
+package jsonpath_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/njchilds90/go-jsonpath"
+)
+
+func TestResultPages(t *testing.T) {
+	tests := []struct {
+		opts []jsonpath.Option
+		want []string
+	}{
+		{[]jsonpath.Option{jsonpath.WithMaxResults(2)}, []string{"$.store.bicycle.price", "$.store.book[0].price"}},
+		{[]jsonpath.Option{jsonpath.WithResultOffset(2), jsonpath.WithMaxResults(2)}, []string{"$.store.book[1].price", "$.store.book[2].price"}},
+		{[]jsonpath.Option{jsonpath.WithResultOffset(4), jsonpath.WithMaxResults(2)}, []string{"$.store.book[3].price"}},
+		{[]jsonpath.Option{jsonpath.WithResultOffset(5)}, nil},
+		{[]jsonpath.Option{jsonpath.WithResultOffset(-1), jsonpath.WithMaxResults(-1)}, []string{"$.store.bicycle.price", "$.store.book[0].price", "$.store.book[1].price", "$.store.book[2].price", "$.store.book[3].price"}},
+		{[]jsonpath.Option{jsonpath.WithSort(jsonpath.ByValueDesc), jsonpath.WithResultOffset(1), jsonpath.WithMaxResults(2)}, []string{"$.store.bicycle.price", "$.store.book[1].price"}},
+		{[]jsonpath.Option{jsonpath.WithOrder(jsonpath.DocumentOrder), jsonpath.WithMaxResults(1)}, []string{"$.store.book[0].price"}},
+	}
+	for _, tt := range tests {
+		paths, err := jsonpath.Paths(sampleJSON, "$..price", tt.opts...)
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+			continue
+		}
+		if strings.Join(paths, " ") != strings.Join(tt.want, " ") {
+			t.Errorf("expected %v, got %v", tt.want, paths)
+		}
+	}
+
+	var stats jsonpath.Stats
+	n, err := jsonpath.Count(sampleJSON, "$..*", jsonpath.WithMaxResults(3), jsonpath.WithStats(&stats))
+	if err != nil || n != 3 {
+		t.Errorf("expected a page of 3, got %d (%v)", n, err)
+	}
+	var all jsonpath.Stats
+	if _, err := jsonpath.Count(sampleJSON, "$..*", jsonpath.WithStats(&all)); err != nil || stats.NodesVisited >= all.NodesVisited {
+		t.Errorf("expected evaluation to stop early, visited %d of %d (%v)", stats.NodesVisited, all.NodesVisited, err)
+	}
+
+	var titles []string
+	err = jsonpath.QueryFunc(context.Background(), sampleJSON, "$.store.book[*].title", func(r jsonpath.Result) error {
+		titles = append(titles, r.Value.(string))
+		return nil
+	}, jsonpath.WithResultOffset(3))
+	if err != nil || len(titles) != 1 || titles[0] != "The Lord of the Rings" {
+		t.Errorf("expected the last title, got %v (%v)", titles, err)
+	}
+
+	results, err := jsonpath.Query(sampleJSON, "$.store.book[?(@.price < 10)].title", jsonpath.WithResultOffset(1))
+	if err != nil || len(results) != 1 || results[0].Value != "Moby Dick" {
+		t.Errorf("expected the offset not to apply to filter operands, got %v (%v)", results, err)
+	}
+}
@@ -0,0 +1,75 @@
+package jsonpath
+
+// WithParentSelector enables the ^ selector, an extension found in
+// jsonpath-plus that steps from each node to its parent, so that a path can
+// select containers by what they contain. Paths using ^ fail to compile
+// without this option. A node matched more than once yields its parent once
+// per match; the root, or the node a relative query starts from, has no
+// parent. ^ may not appear in filter expressions.
+//
+// Example:
+//
+//	// the objects with a member mentioning rings, at any depth
+//	objs, err := jsonpath.Query(data, "$..[?(@ =~ /Ring/)]^", jsonpath.WithParentSelector())
+func WithParentSelector() Option {
+	return func(e *engine) {
+		e.allowParent = true
+	}
+}
+
+const parentGateMessage = "the parent selector ^ is an extension; enable it with WithParentSelector"
+
+// usesParent reports whether tokens, or their alternatives, use ^.
+func usesParent(tokens []token) bool {
+	for _, tok := range tokens {
+		if tok.kind == tokenParent {
+			return true
+		}
+		for _, alt := range tok.alts {
+			if usesParent(alt) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// anchor records the node evaluation of tokens starts from and its path,
+// from which ^ finds the parents of the nodes below it. Filter operands,
+// which share the evaluation state, never use ^ and so leave it in place.
+func (e *engine) anchor(tokens []token, node interface{}, path string) {
+	if usesParent(tokens) {
+		e.st.root, e.st.rootPath = node, path
+	}
+}
+
+// evalParent applies rest to the parent of the node at currentPath, found
+// by walking the path down from the node evaluation started from.
+func (e *engine) evalParent(rest []token, currentPath string, emit emitFunc) error {
+	segs, err := ParsePath(currentPath)
+	if err != nil {
+		return err
+	}
+	base, err := ParsePath(e.st.rootPath)
+	if err != nil {
+		return err
+	}
+	if len(segs) <= len(base) {
+		if e.tracer != nil {
+			e.traceReject(currentPath, "^", "no parent within the queried value")
+		}
+		return nil
+	}
+	node, path := e.st.root, e.st.rootPath
+	for _, s := range segs[len(base) : len(segs)-1] {
+		if s.Kind == SegmentIndex {
+			arr, _ := arrayOf(node)
+			node, path = arr.Index(s.Index), indexPath(path, s.Index)
+		} else {
+			obj, _ := objectOf(node)
+			node, _ = obj.Get(s.Name)
+			path = childPath(path, s.Name)
+		}
+	}
+	return e.evaluate(node, rest, path, emit)
+}
@@ -0,0 +1,73 @@
+package jsonpath_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/njchilds90/go-jsonpath"
+)
+
+func TestParentSelector(t *testing.T) {
+	tests := []struct {
+		path string
+		want []string
+	}{
+		{"$..[?(@ =~ /Ring/)]^", []string{"$.store.book[3]"}},
+		{"$..[?(@ =~ /Ring/)]^.author", []string{"$.store.book[3].author"}},
+		{"$.store.book[?(@.price > 20)]^^", []string{"$.store"}},
+		{"$.store.book[0].title^^^.bicycle", []string{"$.store.bicycle"}},
+		{"$.store.bicycle.*^", []string{"$.store.bicycle", "$.store.bicycle"}},
+		{"$^", nil},
+		{"$.store^^", nil},
+		{"$.nope^", nil},
+		{"$.nope | $.expensive^", []string{"$"}},
+	}
+	for _, tt := range tests {
+		results, err := jsonpath.Query(sampleJSON, tt.path, jsonpath.WithParentSelector())
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", tt.path, err)
+			continue
+		}
+		if len(results) != len(tt.want) {
+			t.Errorf("%s: expected %v, got %v", tt.path, tt.want, results)
+			continue
+		}
+		for i, r := range results {
+			if r.Path != tt.want[i] {
+				t.Errorf("%s: expected %s, got %s", tt.path, tt.want[i], r.Path)
+			}
+		}
+	}
+
+	n, err := jsonpath.Count(sampleJSON, "$..isbn^", jsonpath.WithParentSelector())
+	if err != nil || n != 2 {
+		t.Errorf("expected 2 books with an isbn, got %d (%v)", n, err)
+	}
+
+	book, err := jsonpath.QueryOne(sampleJSON, "$.store.book[2]")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cp, err := jsonpath.Compile("$.isbn^", jsonpath.WithParentSelector())
+	if err != nil {
+		t.Fatal(err)
+	}
+	results, err := cp.QueryValue(book.Value)
+	if err != nil || len(results) != 1 || results[0].Path != "$" {
+		t.Errorf("expected the book itself, got %v (%v)", results, err)
+	}
+
+	results, err = jsonpath.QueryReader(strings.NewReader(`[{"a":1},{"b":2}]`), "$[*].a^", jsonpath.WithParentSelector())
+	if err != nil || len(results) != 1 || results[0].Path != "$[0]" {
+		t.Errorf("expected $[0] from a reader, got %v (%v)", results, err)
+	}
+}
+
+func TestParentSelectorOptIn(t *testing.T) {
+	if _, err := jsonpath.Query(sampleJSON, "$.store.book^"); !jsonpath.IsPathError(err) {
+		t.Errorf("expected ^ to need WithParentSelector, got %v", err)
+	}
+	if _, err := jsonpath.Compile("$.store.book[?(@.author^)]", jsonpath.WithParentSelector()); !jsonpath.IsPathError(err) {
+		t.Errorf("expected ^ to be rejected in filters, got %v", err)
+	}
+}
@@ -0,0 +1,140 @@
+package jsonpath
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// readGlob returns the glob at the start of s, made of member name
+// characters, * and ?, and its length. n is 0 when s does not start with
+// a glob that has at least one * or ?.
+func readGlob(s string) (glob string, n int) {
+	wild := false
+	for n < len(s) {
+		c := s[n]
+		switch {
+		case c == '*' || c == '?':
+			wild = true
+		case !isAlphaNum(c) && c != '_' && c != '-' && c < 0x80:
+			return globOrNothing(s[:n], wild)
+		}
+		n++
+	}
+	return globOrNothing(s, wild)
+}
+
+func globOrNothing(s string, wild bool) (string, int) {
+	if !wild {
+		return "", 0
+	}
+	return s, len(s)
+}
+
+// globToken returns the pattern token for glob.
+func globToken(glob string) token {
+	var b strings.Builder
+	b.WriteByte('^')
+	for _, r := range glob {
+		switch r {
+		case '*':
+			b.WriteString(".*")
+		case '?':
+			b.WriteByte('.')
+		default:
+			b.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+	b.WriteByte('$')
+	pattern := b.String()
+	return token{kind: tokenPattern, key: glob, expr: &regexExpr{pattern: pattern, re: regexp.MustCompile("(?s)" + pattern)}}
+}
+
+// parsePatternBracket parses inner, the text of a bracket selector starting
+// with '/', as a regular expression with optional flags.
+func parsePatternBracket(inner string) (token, error) {
+	pattern, flags, n, ok := readRegex(inner)
+	if !ok {
+		return token{}, &Error{Code: ErrInvalidPath, Message: "unterminated regex", Offset: 1}
+	}
+	if n != len(inner) {
+		return token{}, &Error{Code: ErrInvalidPath, Message: fmt.Sprintf("unexpected %q after regex", inner[n:]), Offset: 1 + n}
+	}
+	re, err := compileRegex(pattern, flags)
+	if err != nil {
+		return token{}, &Error{Code: ErrInvalidPath, Message: fmt.Sprintf("invalid regex: %v", err), Offset: 2}
+	}
+	return token{kind: tokenPattern, key: inner, expr: &regexExpr{pattern: pattern, flags: flags, re: re}}, nil
+}
+
+// readRegex reads the regex literal /pattern/flags at the start of s and
+// returns its parts and length. ok is false if the pattern is not closed.
+func readRegex(s string) (pattern, flags string, n int, ok bool) {
+	i := 1
+	for i < len(s) && s[i] != '/' {
+		if s[i] == '\\' {
+			i++
+		}
+		i++
+	}
+	if i >= len(s) {
+		return "", "", 0, false
+	}
+	pattern = s[1:i]
+	i++
+	flagStart := i
+	for i < len(s) && s[i] >= 'a' && s[i] <= 'z' {
+		i++
+	}
+	return pattern, s[flagStart:i], i, true
+}
+
+// compileRegex compiles pattern with JavaScript-style flags, honouring the
+// ones Go's RE2 understands and ignoring the rest.
+func compileRegex(pattern, flags string) (*regexp.Regexp, error) {
+	prefix := ""
+	for _, f := range flags {
+		switch f {
+		case 'i', 'm', 's':
+			prefix += string(f)
+		}
+	}
+	if prefix != "" {
+		pattern = "(?" + prefix + ")" + pattern
+	}
+	return regexp.Compile(pattern)
+}
+
+// isRegexToken reports whether the pattern token tok is a regular
+// expression rather than a glob.
+func isRegexToken(tok token) bool {
+	return strings.HasPrefix(tok.key, "/")
+}
+
+// evalPattern applies a member name pattern, which selects the members of
+// an object whose names match, for documents with dynamic names such as
+// per-host or per-date keys:
+//
+//	$.metrics.cpu_*        glob: * matches any run of characters, ? one
+//	$[/^feature_/]         regular expression, with the flags of =~
+//
+// Globs are written in dot notation and may use the characters of member
+// names besides * and ?; regular expressions are written in brackets.
+// Names are matched exactly, even with WithCaseInsensitiveKeys; use the i
+// flag to ignore case. Regular expressions count towards WithRegexLimits.
+func (e *engine) evalPattern(node interface{}, tok token, rest []token, currentPath string, emit emitFunc) error {
+	obj, ok := objectOf(node)
+	if !ok {
+		return nil
+	}
+	re := tok.expr.(*regexExpr).re
+	return e.eachMember(obj, func(k string, v interface{}) error {
+		if !re.MatchString(k) {
+			if e.tracer != nil {
+				e.traceReject(e.childPath(currentPath, k), tok.String(), "name does not match")
+			}
+			return nil
+		}
+		return e.evaluate(v, rest, e.childPath(currentPath, k), emit)
+	})
+}
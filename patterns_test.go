@@ -0,0 +1,81 @@
+package jsonpath_test
+
+import (
+	"testing"
+
+	"github.com/njchilds90/go-jsonpath"
+)
+
+func TestMemberPatterns(t *testing.T) {
+	data := []byte(`{
+		"features": {"feature_a": 1, "feature_b": 2, "Feature_c": 3, "legacy": 4},
+		"metrics": {"cpu_0": 10, "cpu_1": 20, "cpu_total": 30, "mem": 40, "h2": {"cpu_x": 50}},
+		"odd": {"a.b": 1, "a b": 2}
+	}`)
+	tests := []struct {
+		path string
+		want []string
+	}{
+		{"$.features[/^feature_/]", []string{"$.features.feature_a", "$.features.feature_b"}},
+		{"$.features[/^feature_/i]", []string{"$.features.Feature_c", "$.features.feature_a", "$.features.feature_b"}},
+		{"$.metrics.cpu_*", []string{"$.metrics.cpu_0", "$.metrics.cpu_1", "$.metrics.cpu_total"}},
+		{"$.metrics.cpu_?", []string{"$.metrics.cpu_0", "$.metrics.cpu_1"}},
+		{"$..cpu_*", []string{"$.metrics.cpu_0", "$.metrics.cpu_1", "$.metrics.cpu_total", "$.metrics.h2.cpu_x"}},
+		{"$.*.h?", []string{"$.metrics.h2"}},
+		{"$.odd[/a.b/]", []string{"$.odd['a b']", "$.odd['a.b']"}},
+		{"$.odd[/a\\.b/]", []string{"$.odd['a.b']"}},
+		{"$.metrics.mem*", []string{"$.metrics.mem"}},
+		{"$.features.legacy[/x/]", nil},
+		{"$.metrics['cpu_*']", nil},
+	}
+	for _, tt := range tests {
+		results, err := jsonpath.Query(data, tt.path)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", tt.path, err)
+			continue
+		}
+		if len(results) != len(tt.want) {
+			t.Errorf("%s: expected %v, got %v", tt.path, tt.want, results)
+			continue
+		}
+		for i, r := range results {
+			if r.Path != tt.want[i] {
+				t.Errorf("%s: expected %s, got %s", tt.path, tt.want[i], r.Path)
+			}
+		}
+	}
+}
+
+func TestMemberPatternsCompile(t *testing.T) {
+	for _, path := range []string{"$[/unterminated]", "$[/a/1]", "$[/(/]"} {
+		if _, err := jsonpath.Compile(path); !jsonpath.IsPathError(err) {
+			t.Errorf("%s: expected a path error, got %v", path, err)
+		}
+	}
+
+	cp := jsonpath.MustCompile("$.a.cpu_*[/^x$/i]")
+	if plan := cp.Explain(); plan.Steps[2].Kind != "pattern" || plan.Steps[2].Selector != ".cpu_*" || plan.Steps[3].Selector != "[/^x$/i]" {
+		t.Errorf("unexpected plan %+v", plan.Steps)
+	}
+
+	_, err := jsonpath.Compile("$[/a+/][/b+/]", jsonpath.WithRegexLimits(jsonpath.RegexLimits{MaxPatterns: 1}))
+	if !jsonpath.IsLimitExceeded(err) {
+		t.Errorf("expected member patterns to count towards regex limits, got %v", err)
+	}
+	if _, err := jsonpath.Compile("$.a*.b*", jsonpath.WithRegexLimits(jsonpath.RegexLimits{MaxPatterns: 1})); err != nil {
+		t.Errorf("expected globs not to count, got %v", err)
+	}
+
+	for _, tt := range []struct {
+		target jsonpath.Target
+		want   string
+	}{
+		{jsonpath.TargetJQ, `.m | objects | to_entries[] | select(.key | test("^cpu_.*$")) | .value`},
+		{jsonpath.TargetPostgres, `$."m".keyvalue() ? (@.key like_regex "^cpu_.*$").value`},
+	} {
+		expr, err := jsonpath.Transpile("$.m.cpu_*", tt.target)
+		if err != nil || expr != tt.want {
+			t.Errorf("%v: expected %s, got %s (%v)", tt.target, tt.want, expr, err)
+		}
+	}
+}
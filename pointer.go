@@ -0,0 +1,166 @@
+package jsonpath
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+var (
+	pointerEscaper   = strings.NewReplacer("~", "~0", "/", "~1")
+	pointerUnescaper = strings.NewReplacer("~1", "/", "~0", "~")
+)
+
+// Pointer returns the RFC 6901 JSON Pointer of the match, such as
+// "/store/book/0/author" for $.store.book[0].author. The root is "".
+//
+// Example:
+//
+//	for _, r := range results {
+//	    patch = append(patch, map[string]interface{}{"op": "remove", "path": r.Pointer()})
+//	}
+func (r Result) Pointer() string {
+	var b strings.Builder
+	for _, seg := range r.Segments() {
+		b.WriteByte('/')
+		if seg.Kind == SegmentIndex {
+			b.WriteString(strconv.Itoa(seg.Index))
+		} else {
+			pointerEscaper.WriteString(&b, seg.Name)
+		}
+	}
+	return b.String()
+}
+
+// PathToPointer converts a singular JSONPath expression — one made only of
+// member names and non-negative indices — to an RFC 6901 JSON Pointer.
+// Paths that can select more than one node, or that use negative indices,
+// which a pointer cannot express, fail with ErrInvalidPath.
+//
+// Example:
+//
+//	ptr, err := jsonpath.PathToPointer("$.spec.containers[0].image")
+//	// ptr == "/spec/containers/0/image"
+func PathToPointer(path string) (string, error) {
+	tokens, err := tokenize(path)
+	if err != nil {
+		return "", err
+	}
+	var b strings.Builder
+	for _, tok := range tokens[1:] {
+		switch {
+		case tok.kind == tokenChild:
+			b.WriteByte('/')
+			pointerEscaper.WriteString(&b, tok.key)
+		case tok.kind == tokenIndex && tok.index >= 0:
+			b.WriteByte('/')
+			b.WriteString(strconv.Itoa(tok.index))
+		case tok.kind == tokenIndex:
+			return "", &Error{Code: ErrInvalidPath, Message: fmt.Sprintf("negative index %d has no JSON Pointer form", tok.index)}
+		default:
+			return "", &Error{Code: ErrInvalidPath, Message: fmt.Sprintf("path is not singular: %s selects more than one node", tok)}
+		}
+	}
+	return b.String(), nil
+}
+
+// PointerToPath converts an RFC 6901 JSON Pointer to a normalized JSONPath
+// expression. A pointer does not say whether a reference token is an array
+// index or a member name, so tokens made only of digits become indices
+// ("/items/0" is $.items[0]) and all others member names.
+//
+// Example:
+//
+//	path, err := jsonpath.PointerToPath(schemaErr.InstanceLocation)
+//	results, err := jsonpath.Query(data, path)
+func PointerToPath(ptr string) (string, error) {
+	if ptr == "" {
+		return "$", nil
+	}
+	if ptr[0] != '/' {
+		return "", &Error{Code: ErrInvalidPath, Message: fmt.Sprintf("JSON Pointer must be empty or start with '/': %q", ptr)}
+	}
+	path := "$"
+	for _, ref := range strings.Split(ptr[1:], "/") {
+		for i := 0; i < len(ref); i++ {
+			if ref[i] == '~' && (i+1 == len(ref) || (ref[i+1] != '0' && ref[i+1] != '1')) {
+				return "", &Error{Code: ErrInvalidPath, Message: fmt.Sprintf("invalid escape in JSON Pointer: %q", ptr)}
+			}
+		}
+		ref = pointerUnescaper.Replace(ref)
+
+		if isArrayIndex(ref) {
+			n, _ := strconv.Atoi(ref)
+			path = indexPath(path, n)
+			continue
+		}
+		path = childPath(path, ref)
+	}
+	return path, nil
+}
+
+// isArrayIndex reports whether s is an array index in RFC 6901 form: digits
+// without a leading zero.
+func isArrayIndex(s string) bool {
+	if s == "" || (len(s) > 1 && s[0] == '0') || len(s) > 9 {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// RelativePointer returns the Relative JSON Pointer that leads from the node
+// matched by r to the node matched by to: the number of levels to go up,
+// followed by the JSON Pointer down from there. For example, from
+// $.store.book[0].title to $.store.book[0].author it is "1/author", and from
+// $.store.book[0] to $.store it is "2".
+//
+// Example:
+//
+//	title, _ := jsonpath.First(data, "$.store.book[0].title")
+//	author, _ := jsonpath.First(data, "$.store.book[0].author")
+//	ref := title.RelativePointer(*author) // "1/author"
+func (r Result) RelativePointer(to Result) string {
+	return relativePointer(r.Pointer(), to.Pointer())
+}
+
+// RelativePointer is the form of Result.RelativePointer for singular paths,
+// such as the normalized paths of results. It fails with ErrInvalidPath if
+// either path is not singular; see PathToPointer.
+func RelativePointer(from, to string) (string, error) {
+	fp, err := PathToPointer(from)
+	if err != nil {
+		return "", err
+	}
+	tp, err := PathToPointer(to)
+	if err != nil {
+		return "", err
+	}
+	return relativePointer(fp, tp), nil
+}
+
+// relativePointer returns the Relative JSON Pointer between two JSON Pointers.
+func relativePointer(from, to string) string {
+	fs, ts := pointerTokens(from), pointerTokens(to)
+	common := 0
+	for common < len(fs) && common < len(ts) && fs[common] == ts[common] {
+		common++
+	}
+	rel := strconv.Itoa(len(fs) - common)
+	if common < len(ts) {
+		rel += "/" + strings.Join(ts[common:], "/")
+	}
+	return rel
+}
+
+// pointerTokens splits a JSON Pointer into its still escaped reference tokens.
+func pointerTokens(ptr string) []string {
+	if ptr == "" {
+		return nil
+	}
+	return strings.Split(ptr[1:], "/")
+}
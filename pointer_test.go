@@ -0,0 +1,164 @@
+package jsonpath_test
+
+import (
+	"testing"
+
+	"github.com/njchilds90/go-jsonpath"
+)
+
+func TestResultPointer(t *testing.T) {
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"$", ""},
+		{"$.store.book[0].author", "/store/book/0/author"},
+		{"$.a/b.c~d", "/a~1b/c~0d"},
+		{"$.key[x].y", "/key[x]/y"},
+		{"$.list[12][3]", "/list/12/3"},
+	}
+	for _, tt := range tests {
+		if got := (jsonpath.Result{Path: tt.path}).Pointer(); got != tt.want {
+			t.Errorf("%s: expected %q, got %q", tt.path, tt.want, got)
+		}
+	}
+}
+
+func TestResultPointerFromQuery(t *testing.T) {
+	data := []byte(`{"a/b": {"m~n": [1, {"x y": true}]}}`)
+	results, err := jsonpath.Query(data, "$..*")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []string{"/a~1b", "/a~1b/m~0n", "/a~1b/m~0n/0", "/a~1b/m~0n/1", "/a~1b/m~0n/1/x y"}
+	if len(results) != len(want) {
+		t.Fatalf("expected %d results, got %d", len(want), len(results))
+	}
+	for i, r := range results {
+		if got := r.Pointer(); got != want[i] {
+			t.Errorf("%s: expected %q, got %q", r.Path, want[i], got)
+		}
+	}
+}
+
+func TestPathToPointer(t *testing.T) {
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"$", ""},
+		{"$.spec.containers[0].image", "/spec/containers/0/image"},
+		{"$['a/b']['c~d']", "/a~1b/c~0d"},
+		{"$['']", "/"},
+	}
+	for _, tt := range tests {
+		got, err := jsonpath.PathToPointer(tt.path)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", tt.path, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("%s: expected %q, got %q", tt.path, tt.want, got)
+		}
+	}
+
+	for _, path := range []string{"$.items[*]", "$..name", "$.a[-1]", "$[0,1]", "$[0:2]", "$[?(@.x)]", "store"} {
+		if _, err := jsonpath.PathToPointer(path); !jsonpath.IsPathError(err) {
+			t.Errorf("%s: expected path error, got: %v", path, err)
+		}
+	}
+}
+
+func TestPointerToPath(t *testing.T) {
+	tests := []struct {
+		ptr  string
+		want string
+	}{
+		{"", "$"},
+		{"/", "$['']"},
+		{"/spec/containers/0/image", "$.spec.containers[0].image"},
+		{"/a~1b/c~0d", "$['a/b']['c~d']"},
+		{"/items/01", "$.items.01"},
+		{"/first name", "$['first name']"},
+		{"/it's", `$['it\'s']`},
+		{"/a]b", "$['a]b']"},
+	}
+	for _, tt := range tests {
+		got, err := jsonpath.PointerToPath(tt.ptr)
+		if err != nil {
+			t.Errorf("%q: unexpected error: %v", tt.ptr, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("%q: expected %q, got %q", tt.ptr, tt.want, got)
+		}
+	}
+
+	for _, ptr := range []string{"spec", "/a~2", "/a~"} {
+		if _, err := jsonpath.PointerToPath(ptr); !jsonpath.IsPathError(err) {
+			t.Errorf("%q: expected path error, got: %v", ptr, err)
+		}
+	}
+}
+
+func TestPointerRoundTrip(t *testing.T) {
+	results, err := jsonpath.Query(sampleJSON, "$.store..price")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, r := range results {
+		path, err := jsonpath.PointerToPath(r.Pointer())
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", r.Path, err)
+		}
+		again, err := jsonpath.Query(sampleJSON, path)
+		if err != nil || len(again) != 1 || again[0].Path != r.Path {
+			t.Errorf("%s: round trip through %q gave %v, %v", r.Path, path, again, err)
+		}
+	}
+}
+
+func TestRelativePointer(t *testing.T) {
+	tests := []struct {
+		from, to string
+		want     string
+	}{
+		{"$.store.book[0].title", "$.store.book[0].author", "1/author"},
+		{"$.store.book[0]", "$.store", "2"},
+		{"$.store", "$.store", "0"},
+		{"$", "$.store.bicycle", "0/store/bicycle"},
+		{"$.store.book[0].title", "$.store.book[1].title", "2/1/title"},
+		{"$.a['x/y']", "$.a.b", "1/b"},
+	}
+	for _, tt := range tests {
+		got, err := jsonpath.RelativePointer(tt.from, tt.to)
+		if err != nil {
+			t.Errorf("%s -> %s: unexpected error: %v", tt.from, tt.to, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("%s -> %s: expected %q, got %q", tt.from, tt.to, tt.want, got)
+		}
+	}
+
+	if _, err := jsonpath.RelativePointer("$.a[*]", "$.a"); !jsonpath.IsPathError(err) {
+		t.Errorf("expected path error, got: %v", err)
+	}
+}
+
+func TestResultRelativePointer(t *testing.T) {
+	titles, err := jsonpath.Query(sampleJSON, "$.store.book[*].title")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	bicycle, err := jsonpath.Query(sampleJSON, "$.store.bicycle.color")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := titles[2].RelativePointer(bicycle[0]); got != "3/bicycle/color" {
+		t.Errorf("expected %q, got %q", "3/bicycle/color", got)
+	}
+	if got := titles[2].RelativePointer(titles[2]); got != "0" {
+		t.Errorf("expected %q, got %q", "0", got)
+	}
+}
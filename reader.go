@@ -0,0 +1,241 @@
+package jsonpath
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+)
+
+// QueryReader executes a JSONPath expression against the JSON document read
+// from r. See QueryReaderFunc for how large top-level arrays are streamed.
+func QueryReader(r io.Reader, path string, opts ...Option) ([]Result, error) {
+	var results []Result
+	err := QueryReaderFunc(context.Background(), r, path, func(res Result) error {
+		results = append(results, res)
+		return nil
+	}, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// QueryReaderFunc executes a JSONPath expression against the JSON document
+// read from r, calling fn for each match.
+//
+// When the document is a top-level array and the path begins with an array
+// selector that can be decided one element at a time — $[*], $[n] and
+// $[n,m,...] with ascending non-negative indices, $[start:end] with
+// non-negative bounds and a positive step, or $[?(...)] — the elements are
+// decoded and evaluated one by one, so memory stays flat regardless of the
+// array's length, and reading stops once no later element can match.
+// Any other path or document is decoded in full first.
+//
+// ErrStop returned by fn ends the query without error. WithOffsets and
+// WithRawValues do not apply to reader input.
+//
+// Example:
+//
+//	f, _ := os.Open("events.json") // [{"id":1,...}, {"id":2,...}, ...]
+//	defer f.Close()
+//	err := jsonpath.QueryReaderFunc(ctx, f, "$[?(@.type == 'purchase')].id", func(r jsonpath.Result) error {
+//	    return process(r.Value)
+//	})
+func QueryReaderFunc(ctx context.Context, r io.Reader, path string, fn func(Result) error, opts ...Option) error {
+	if ctx == nil {
+		return &Error{Code: ErrInvalidInput, Message: "context must not be nil"}
+	}
+	if r == nil {
+		return &Error{Code: ErrInvalidInput, Message: "reader must not be nil"}
+	}
+	e := newEngine(ctx, opts)
+	cp, err := e.compile(path)
+	if err != nil {
+		return err
+	}
+
+	br := bufio.NewReader(r)
+	dec := json.NewDecoder(br)
+	if e.useNumber {
+		dec.UseNumber()
+	}
+	if len(cp.tokens) > 1 && streamable(cp.tokens[1]) && !usesParent(cp.tokens) && peekNonSpace(br) == '[' {
+		return e.streamArray(dec, cp.tokens[1], cp.tokens[2:], fn)
+	}
+
+	var root interface{}
+	if err := dec.Decode(&root); err != nil {
+		return &Error{Code: ErrInvalidJSON, Message: "failed to parse JSON", Cause: err}
+	}
+	if e.exactNumbers {
+		root = exactNumbers(root)
+	}
+	return e.stream(root, cp.tokens, fn)
+}
+
+// streamable reports whether tok selects array elements in ascending order
+// and can decide each element without knowing the array's length.
+func streamable(tok token) bool {
+	switch tok.kind {
+	case tokenWildcard, tokenFilter:
+		return true
+	case tokenIndex:
+		return tok.index >= 0
+	case tokenUnion:
+		if len(tok.indices) == 0 {
+			return false
+		}
+		for i, idx := range tok.indices {
+			if idx < 0 || (i > 0 && idx <= tok.indices[i-1]) {
+				return false
+			}
+		}
+		return true
+	case tokenSlice:
+		for _, p := range tok.slice {
+			if p != nil && *p < 0 {
+				return false
+			}
+		}
+		return tok.slice[2] == nil || *tok.slice[2] > 0
+	}
+	return false
+}
+
+// selects reports whether the streamable tok selects element i, and whether
+// no element after i can be selected.
+func (tok token) selects(i int) (selected, done bool) {
+	switch tok.kind {
+	case tokenIndex:
+		return i == tok.index, i >= tok.index
+	case tokenUnion:
+		for _, idx := range tok.indices {
+			if idx == i {
+				selected = true
+			}
+		}
+		return selected, i >= tok.indices[len(tok.indices)-1]
+	case tokenSlice:
+		start, step := 0, 1
+		if tok.slice[0] != nil {
+			start = *tok.slice[0]
+		}
+		if tok.slice[2] != nil {
+			step = *tok.slice[2]
+		}
+		end := tok.slice[1]
+		if end != nil && i >= *end-1 {
+			done = true
+		}
+		return i >= start && (end == nil || i < *end) && (i-start)%step == 0, done
+	}
+	return true, false
+}
+
+// streamArray evaluates sel and rest against the elements of the array whose
+// opening bracket is next in dec, decoding one element at a time.
+func (e *engine) streamArray(dec *json.Decoder, sel token, rest []token, fn func(Result) error) error {
+	if e.metrics != nil {
+		e.begin(append([]token{{kind: tokenRoot}, sel}, rest...))
+	}
+	stopped := false
+	next := fn
+	fn = func(r Result) error {
+		err := next(r)
+		stopped = err == ErrStop
+		return err
+	}
+	emit, collected := e.sink(fn), func() []Result { return nil }
+	if e.reorders() {
+		var add emitFunc
+		add, collected = e.gather()
+		emit = e.sink(add)
+	}
+	mark := len(e.st.violations)
+	err := e.visit("$", []interface{}(nil)) // the array being streamed
+	if err == nil {
+		err = e.eachElement(dec, func(i int, elem interface{}) (bool, error) {
+			selected, done := sel.selects(i)
+			if selected && sel.kind == tokenFilter {
+				e.st.stats.FiltersEvaluated++
+				ok, err := e.matchFilter(elem, sel.expr)
+				if err != nil {
+					return false, err
+				}
+				if e.logger != nil {
+					e.debug("jsonpath: filter", "path", indexPath("$", i), "filter", formatFilter(sel.expr), "matched", ok)
+				}
+				selected = ok
+			}
+			if selected {
+				if err := e.evaluate(elem, rest, indexPath("$", i), emit); err != nil {
+					return false, err
+				}
+			}
+			return done, nil
+		})
+	}
+	if err == nil || err == ErrStop {
+		err = nil
+		for _, r := range e.arrange(collected()) {
+			if err = fn(r); err != nil {
+				break
+			}
+		}
+	}
+	violations := e.violations(mark)
+	if err == nil || err == ErrStop {
+		err = violations
+	}
+	if stopped {
+		err = nil
+	}
+	e.report(err)
+	return err
+}
+
+// eachElement decodes the elements of the array next in dec and passes them
+// to fn until the array ends or fn reports it is done.
+func (e *engine) eachElement(dec *json.Decoder, fn func(int, interface{}) (bool, error)) error {
+	if _, err := dec.Token(); err != nil {
+		return &Error{Code: ErrInvalidJSON, Message: "failed to parse JSON", Cause: err}
+	}
+	for i := 0; dec.More(); i++ {
+		if err := e.ctx.Err(); err != nil {
+			return &Error{Code: ErrCancelled, Message: "context cancelled", Cause: err}
+		}
+		var elem interface{}
+		if err := dec.Decode(&elem); err != nil {
+			return &Error{Code: ErrInvalidJSON, Message: "failed to parse JSON", Cause: err}
+		}
+		if e.exactNumbers {
+			elem = exactNumbers(elem)
+		}
+		done, err := fn(i, elem)
+		if err != nil || done {
+			return err
+		}
+	}
+	if _, err := dec.Token(); err != nil {
+		return &Error{Code: ErrInvalidJSON, Message: "failed to parse JSON", Cause: err}
+	}
+	return nil
+}
+
+// peekNonSpace discards leading whitespace from br and returns the next
+// byte without consuming it, or 0 at the end of the input.
+func peekNonSpace(br *bufio.Reader) byte {
+	for {
+		b, err := br.Peek(1)
+		if err != nil {
+			return 0
+		}
+		switch b[0] {
+		case ' ', '\t', '\n', '\r':
+			br.Discard(1)
+		default:
+			return b[0]
+		}
+	}
+}
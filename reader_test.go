@@ -0,0 +1,150 @@
+package jsonpath_test
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/njchilds90/go-jsonpath"
+)
+
+const eventsJSON = `[
+	{"id": 1, "type": "view"},
+	{"id": 2, "type": "purchase", "items": [{"sku": "a"}]},
+	{"id": 3, "type": "view"},
+	{"id": 4, "type": "purchase", "items": [{"sku": "b"}, {"sku": "c"}]}
+]`
+
+func TestQueryReaderMatchesQuery(t *testing.T) {
+	paths := []string{
+		"$[*].id",
+		"$[1]",
+		"$[-1].id",
+		"$[0,2].id",
+		"$[2,0].id",
+		"$[1:3].id",
+		"$[::2].id",
+		"$[::-1].id",
+		"$[?(@.type == 'purchase')].items[*].sku",
+		"$..sku",
+		"$",
+	}
+	for _, doc := range []string{eventsJSON, string(sampleJSON)} {
+		for _, path := range paths {
+			want, err := jsonpath.Query([]byte(doc), path)
+			if err != nil {
+				t.Fatalf("%s: unexpected error: %v", path, err)
+			}
+			got, err := jsonpath.QueryReader(strings.NewReader(doc), path)
+			if err != nil {
+				t.Fatalf("%s: unexpected error: %v", path, err)
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("%s: expected %v, got %v", path, want, got)
+			}
+		}
+	}
+}
+
+// countingReader records how many bytes have been read through it.
+type countingReader struct {
+	r io.Reader
+	n int
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	if len(p) > 16 {
+		p = p[:16]
+	}
+	n, err := c.r.Read(p)
+	c.n += n
+	return n, err
+}
+
+func TestQueryReaderStopsEarly(t *testing.T) {
+	var buf bytes.Buffer
+	buf.WriteString("[")
+	for i := 0; i < 10000; i++ {
+		if i > 0 {
+			buf.WriteString(",")
+		}
+		buf.WriteString(`{"n":1}`)
+	}
+	buf.WriteString("]")
+
+	cr := &countingReader{r: &buf}
+	results, err := jsonpath.QueryReader(cr, "$[1].n")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(results) != 1 || results[0].Path != "$[1].n" {
+		t.Errorf("unexpected results: %v", results)
+	}
+	if cr.n > 8192 {
+		t.Errorf("expected reading to stop after element 1, read %d bytes", cr.n)
+	}
+}
+
+func TestQueryReaderFuncStop(t *testing.T) {
+	var ids []interface{}
+	err := jsonpath.QueryReaderFunc(context.Background(), strings.NewReader(eventsJSON), "$[*].id", func(r jsonpath.Result) error {
+		ids = append(ids, r.Value)
+		if len(ids) == 2 {
+			return jsonpath.ErrStop
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(ids) != 2 {
+		t.Errorf("expected 2 ids, got %v", ids)
+	}
+}
+
+func TestQueryReaderInvalid(t *testing.T) {
+	if _, err := jsonpath.QueryReader(strings.NewReader(`[{"id":1}, {oops}]`), "$[*].id"); !jsonpath.IsJSONError(err) {
+		t.Errorf("expected JSON error, got: %v", err)
+	}
+	if _, err := jsonpath.QueryReader(strings.NewReader(`{`), "$.a"); !jsonpath.IsJSONError(err) {
+		t.Errorf("expected JSON error, got: %v", err)
+	}
+	if _, err := jsonpath.QueryReader(strings.NewReader(`[]`), "bad"); !jsonpath.IsPathError(err) {
+		t.Errorf("expected path error, got: %v", err)
+	}
+}
+
+func TestQueryReaderSorted(t *testing.T) {
+	results, err := jsonpath.QueryReader(strings.NewReader(`[3, 1, 2]`), "$[*]", jsonpath.WithSort(jsonpath.ByValueDesc))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []interface{}
+	for _, r := range results {
+		got = append(got, r.Value)
+	}
+	if want := []interface{}{float64(3), float64(2), float64(1)}; !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %v from a streamed array, got %v", want, got)
+	}
+}
+
+func TestQueryReaderAllErrors(t *testing.T) {
+	strict := []jsonpath.Option{jsonpath.WithAllowMissingKeys(true), jsonpath.WithAllErrors()}
+	var values []interface{}
+	err := jsonpath.QueryReaderFunc(context.Background(), strings.NewReader(`[{"a":1},{},{"a":3}]`), "$[*].a", func(r jsonpath.Result) error {
+		values = append(values, r.Value)
+		return nil
+	}, strict...)
+	if len(values) != 2 {
+		t.Errorf("expected 2 values alongside the violation, got %v", values)
+	}
+	if !jsonpath.IsNotFound(err) || err.(interface{ Unwrap() []error }).Unwrap()[0].(*jsonpath.Error).Path != "$[1]" {
+		t.Errorf("expected a not-found violation at $[1], got %v", err)
+	}
+	if _, want := jsonpath.Query([]byte(`[{"a":1},{},{"a":3}]`), "$[*].a", strict...); want == nil || err == nil || err.Error() != want.Error() {
+		t.Errorf("expected the error Query returns, %v, got %v", want, err)
+	}
+}
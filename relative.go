@@ -0,0 +1,110 @@
+package jsonpath
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Query runs path, which is relative to the result and starts with '@',
+// against the result's value, so code can drill into a match without
+// running an absolute query from the root again. Result paths extend
+// r.Path and so stay valid in the document r came from. A json.RawMessage
+// value, as produced by WithRawValues, is decoded first; use
+// Document.QueryFrom to keep offsets and raw values.
+//
+// Example:
+//
+//	book, err := jsonpath.First(data, "$.store.book[?(@.isbn)]")
+//	authors, err := book.Query("@.authors[*].name")
+//	// authors[0].Path is book.Path + ".authors[0].name"
+func (r Result) Query(path string, opts ...Option) ([]Result, error) {
+	return r.QueryContext(context.Background(), path, opts...)
+}
+
+// QueryContext is Result.Query with context support.
+func (r Result) QueryContext(ctx context.Context, path string, opts ...Option) ([]Result, error) {
+	if ctx == nil {
+		return nil, &Error{Code: ErrInvalidInput, Message: "context must not be nil"}
+	}
+	e := newEngine(ctx, opts)
+	cp, err := e.compileRelative(path)
+	if err != nil {
+		return nil, err
+	}
+	node := r.Value
+	if raw, ok := node.(json.RawMessage); ok {
+		if node, err = e.unmarshal(raw); err != nil {
+			return nil, &Error{Code: ErrInvalidJSON, Message: "failed to parse raw result value", Cause: err}
+		}
+	}
+	return e.collectAt(plainRoot(node), cp.tokens[1:], resultPath(r))
+}
+
+// QueryFrom runs path, which is relative to r and starts with '@', against
+// the node of d at r.Path. Unlike Result.Query it finds the node in d, so
+// WithOffsets and WithRawValues apply to its results.
+//
+// Example:
+//
+//	sections, err := doc.Query("$..section[?(@.title == 'Usage')]")
+//	for _, s := range sections {
+//	    children, err := doc.QueryFrom(s, "@.children[*]")
+//	}
+func (d *Document) QueryFrom(r Result, path string, opts ...Option) ([]Result, error) {
+	return d.QueryFromContext(context.Background(), r, path, opts...)
+}
+
+// QueryFromContext is QueryFrom with context support.
+func (d *Document) QueryFromContext(ctx context.Context, r Result, path string, opts ...Option) ([]Result, error) {
+	if ctx == nil {
+		return nil, &Error{Code: ErrInvalidInput, Message: "context must not be nil"}
+	}
+	e := newEngine(ctx, opts)
+	cp, err := e.compileRelative(path)
+	if err != nil {
+		return nil, err
+	}
+	at := resultPath(r)
+	node, err := nodeAt(d.root, at)
+	if err != nil {
+		return nil, err
+	}
+	e.attach(d)
+	return e.collectAt(node, cp.tokens[1:], at)
+}
+
+// nodeAt returns the node of root at the normalized path at.
+func nodeAt(root interface{}, at string) (interface{}, error) {
+	tokens, err := tokenize(at)
+	if err != nil {
+		return nil, err
+	}
+	node := prefixNode{node: plainRoot(root), path: "$"}
+	for _, tok := range tokens[1:] {
+		if tok.kind != tokenChild && tok.kind != tokenIndex {
+			return nil, &Error{Code: ErrInvalidInput, Message: fmt.Sprintf("result path %s is not a normalized path", at)}
+		}
+		if node = stepInto(node, tok); node.missing {
+			return nil, &Error{Code: ErrInvalidInput, Message: fmt.Sprintf("result path %s is not in the document", at)}
+		}
+	}
+	return node.node, nil
+}
+
+// compileRelative compiles a path that starts with '@'.
+func (e *engine) compileRelative(path string) (*CompiledPath, error) {
+	if !strings.HasPrefix(path, "@") {
+		return nil, &Error{Code: ErrInvalidPath, Message: fmt.Sprintf("relative path must start with '@': %s", path)}
+	}
+	return e.compile("$" + path[1:])
+}
+
+// resultPath returns r.Path, or $ for a result without one.
+func resultPath(r Result) string {
+	if r.Path == "" {
+		return "$"
+	}
+	return r.Path
+}
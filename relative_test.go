@@ -0,0 +1,67 @@
+package jsonpath_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/njchilds90/go-jsonpath"
+)
+
+func TestResultQuery(t *testing.T) {
+	store, err := jsonpath.QueryOne(sampleJSON, "$.store")
+	if err != nil {
+		t.Fatal(err)
+	}
+	results, err := store.Query("@.book[?(@.price < 10)].title")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 2 || results[0].Path != "$.store.book[0].title" || results[0].Value != "Sayings of the Century" {
+		t.Errorf("unexpected results: %v", results)
+	}
+
+	raw, err := jsonpath.QueryOne(sampleJSON, "$.store.bicycle", jsonpath.WithRawValues())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := raw.Value.(json.RawMessage); !ok {
+		t.Fatalf("expected a raw value, got %T", raw.Value)
+	}
+	color, err := raw.Query("@.color")
+	if err != nil || len(color) != 1 || color[0].Value != "red" || color[0].Path != "$.store.bicycle.color" {
+		t.Errorf("unexpected results: %v, %v", color, err)
+	}
+
+	if _, err := store.Query("$.book"); !jsonpath.IsPathError(err) {
+		t.Errorf("expected a path error for an absolute path, got %v", err)
+	}
+}
+
+func TestDocumentQueryFrom(t *testing.T) {
+	doc, err := jsonpath.Parse(sampleJSON)
+	if err != nil {
+		t.Fatal(err)
+	}
+	books, err := doc.Query("$.store.book[?(@.isbn)]")
+	if err != nil || len(books) != 2 {
+		t.Fatalf("unexpected books: %v, %v", books, err)
+	}
+	isbn, err := doc.QueryFrom(books[1], "@.isbn", jsonpath.WithOffsets())
+	if err != nil || len(isbn) != 1 {
+		t.Fatalf("unexpected results: %v, %v", isbn, err)
+	}
+	if isbn[0].Path != "$.store.book[3].isbn" || string(sampleJSON[isbn[0].Start:isbn[0].End]) != `"0-395-19395-8"` {
+		t.Errorf("unexpected result: %+v", isbn[0])
+	}
+
+	if _, err := doc.QueryFrom(jsonpath.Result{Path: "$.nowhere"}, "@"); !isInputError(err) {
+		t.Errorf("expected ErrInvalidInput for a path outside the document, got %v", err)
+	}
+	if _, err := doc.QueryFrom(jsonpath.Result{Path: "$..book"}, "@"); !isInputError(err) {
+		t.Errorf("expected ErrInvalidInput for a non-normalized path, got %v", err)
+	}
+	all, err := doc.QueryFrom(jsonpath.Result{}, "@.store.bicycle.price")
+	if err != nil || len(all) != 1 || all[0].Value != 19.95 {
+		t.Errorf("expected a result without a path to mean the root, got %v, %v", all, err)
+	}
+}
@@ -0,0 +1,79 @@
+package jsonpath
+
+import (
+	"math/rand"
+	"sort"
+)
+
+// WithSample returns a uniform random sample of n of the matches, chosen by
+// reservoir sampling while they are found, so that at most n are held at a
+// time however many there are. The sample keeps the order in which the
+// matches were found, and the same seed picks the same sample from the same
+// document. It suits exploring huge documents, including arrays streamed by
+// QueryReader. Like WithSort, it makes streaming queries such as QueryFunc
+// deliver results once evaluation is done. Default is 0 (no sampling).
+//
+// Example:
+//
+//	// 100 representative events
+//	events, err := jsonpath.Query(data, "$.events[*]", jsonpath.WithSample(100, 42))
+func WithSample(n int, seed int64) Option {
+	return func(e *engine) {
+		e.sampleSize, e.seed = max(n, 0), seed
+	}
+}
+
+// gather returns an emitFunc that collects matches, or a sample of them
+// under WithSample, and a function that returns what it collected.
+func (e *engine) gather() (emitFunc, func() []Result) {
+	if e.sampleSize > 0 {
+		s := newReservoir[Result](e.sampleSize, e.seed)
+		return s.add, s.sample
+	}
+	var results []Result
+	if e.expected > 0 {
+		results = make([]Result, 0, e.expected)
+	}
+	add := func(r Result) error {
+		results = append(results, r)
+		return nil
+	}
+	return add, func() []Result { return results }
+}
+
+// reservoir samples matches with Algorithm R.
+type reservoir[T any] struct {
+	size  int
+	rng   *rand.Rand
+	seen  int
+	items []sampled[T]
+}
+
+type sampled[T any] struct {
+	seq int // position among all matches
+	v   T
+}
+
+func newReservoir[T any](size int, seed int64) *reservoir[T] {
+	return &reservoir[T]{size: size, rng: rand.New(rand.NewSource(seed))}
+}
+
+func (s *reservoir[T]) add(v T) error {
+	if len(s.items) < s.size {
+		s.items = append(s.items, sampled[T]{s.seen, v})
+	} else if j := s.rng.Intn(s.seen + 1); j < s.size {
+		s.items[j] = sampled[T]{s.seen, v}
+	}
+	s.seen++
+	return nil
+}
+
+// sample returns the sampled matches in the order they were found.
+func (s *reservoir[T]) sample() []T {
+	sort.Slice(s.items, func(i, j int) bool { return s.items[i].seq < s.items[j].seq })
+	values := make([]T, len(s.items))
+	for i, it := range s.items {
+		values[i] = it.v
+	}
+	return values
+}
@@ -0,0 +1,74 @@
+package jsonpath_test
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/njchilds90/go-jsonpath"
+)
+
+func TestSample(t *testing.T) {
+	var b strings.Builder
+	b.WriteString("[")
+	for i := 0; i < 1000; i++ {
+		if i > 0 {
+			b.WriteString(",")
+		}
+		fmt.Fprintf(&b, `{"id": %d}`, i)
+	}
+	b.WriteString("]")
+	data := []byte(b.String())
+
+	ids := func(opts ...jsonpath.Option) []interface{} {
+		t.Helper()
+		vals, err := jsonpath.Values(data, "$[*].id", opts...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return vals
+	}
+	sample := ids(jsonpath.WithSample(10, 1))
+	if len(sample) != 10 {
+		t.Fatalf("expected 10 samples, got %d", len(sample))
+	}
+	for i := 1; i < len(sample); i++ {
+		if sample[i].(float64) <= sample[i-1].(float64) {
+			t.Errorf("expected the sample in match order, got %v", sample)
+			break
+		}
+	}
+	if !reflect.DeepEqual(ids(jsonpath.WithSample(10, 1)), sample) {
+		t.Error("expected the same seed to pick the same sample")
+	}
+	if reflect.DeepEqual(ids(jsonpath.WithSample(10, 2)), sample) {
+		t.Error("expected another seed to pick another sample")
+	}
+	if sample[len(sample)-1].(float64) < 500 {
+		t.Errorf("expected the sample to span the matches, got %v", sample)
+	}
+	if got := ids(jsonpath.WithSample(2000, 1)); len(got) != 1000 {
+		t.Errorf("expected every match when the sample is larger, got %d", len(got))
+	}
+	if got := ids(jsonpath.WithSample(5, 1), jsonpath.WithSort(jsonpath.ByValueDesc), jsonpath.WithMaxResults(2)); len(got) != 2 || got[0].(float64) < got[1].(float64) {
+		t.Errorf("expected the top 2 of the sample, got %v", got)
+	}
+
+	n, err := jsonpath.Count(data, "$[*]", jsonpath.WithSample(7, 1))
+	if err != nil || n != 7 {
+		t.Errorf("expected a count of 7, got %d (%v)", n, err)
+	}
+
+	streamed, err := jsonpath.QueryReader(bytes.NewReader(data), "$[*].id", jsonpath.WithSample(10, 1))
+	if err != nil || len(streamed) != 10 {
+		t.Fatalf("expected 10 samples from a reader, got %v (%v)", streamed, err)
+	}
+	for i, r := range streamed {
+		if r.Value != sample[i] {
+			t.Errorf("expected a streamed sample like the decoded one, got %v", streamed)
+			break
+		}
+	}
+}
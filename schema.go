@@ -0,0 +1,607 @@
+package jsonpath
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// maxSchemaDepth bounds how deep CheckSchema follows $ref and composition
+// keywords, so that recursive schemas terminate.
+const maxSchemaDepth = 32
+
+// SchemaReport is the result of CheckSchema.
+type SchemaReport struct {
+	// Matchable reports whether some document conforming to the schema can
+	// have a match.
+	Matchable bool `json:"matchable"`
+	// Kinds lists the kinds of value the matches can have, in NodeKind order.
+	Kinds []NodeKind `json:"kinds"`
+	// Problems describes each selector that can match nothing in conforming
+	// documents, or that names a member the schema does not declare.
+	Problems []SchemaProblem `json:"problems,omitempty"`
+}
+
+// SchemaProblem is one problem CheckSchema found in a path.
+type SchemaProblem struct {
+	// Path is the path up to and including the selector with the problem.
+	Path string `json:"path"`
+	// Message describes the problem.
+	Message string `json:"message"`
+	// Suggestion proposes a fix, such as "did you mean $.user.email?", when
+	// a declared member has a similar name; otherwise it is empty.
+	Suggestion string `json:"suggestion,omitempty"`
+}
+
+// CheckSchema checks the path against schema, a JSON Schema, without any
+// document, and reports whether the path can match a conforming document,
+// the kinds of value its matches can have, and its problems. A member the
+// schema does not declare is a problem even when additionalProperties
+// allows it, so that typos such as $.user.emial are caught at deploy time
+// rather than by queries that silently match nothing.
+//
+// CheckSchema understands type, const, enum, properties, patternProperties,
+// additionalProperties, items, prefixItems, additionalItems, allOf, anyOf,
+// oneOf and $ref to definitions in the same schema; other references match
+// any value. It reads filters as if every candidate could pass them, and ^
+// as matching any value.
+//
+// Example:
+//
+//	report, err := cp.CheckSchema(userSchema)
+//	for _, p := range report.Problems {
+//	    log.Printf("%s: %s %s", p.Path, p.Message, p.Suggestion)
+//	}
+func (cp *CompiledPath) CheckSchema(schema []byte) (*SchemaReport, error) {
+	var root interface{}
+	if err := json.Unmarshal(schema, &root); err != nil {
+		return nil, &Error{Code: ErrInvalidJSON, Message: "failed to parse JSON Schema", Cause: err}
+	}
+	c := &schemaChecker{root: root}
+	kinds := c.check(cp.tokens, []interface{}{root})
+	report := &SchemaReport{Kinds: []NodeKind{}, Problems: c.problems}
+	for k := NodeNull; k <= NodeObject; k++ {
+		if kinds[k] {
+			report.Kinds = append(report.Kinds, k)
+		}
+	}
+	report.Matchable = len(report.Kinds) > 0
+	return report, nil
+}
+
+type schemaChecker struct {
+	root     interface{}
+	problems []SchemaProblem
+}
+
+func (c *schemaChecker) fail(tokens []token, format string, args ...interface{}) {
+	c.problems = append(c.problems, SchemaProblem{Path: formatTokens(tokens), Message: fmt.Sprintf(format, args...)})
+}
+
+// check returns the kinds of value tokens can match when applied to values
+// conforming to any of schemas.
+func (c *schemaChecker) check(tokens []token, schemas []interface{}) map[NodeKind]bool {
+	for i, tok := range tokens {
+		var next []interface{}
+		switch tok.kind {
+		case tokenRoot:
+			continue
+		case tokenAlternatives:
+			kinds := map[NodeKind]bool{}
+			for _, alt := range tok.alts {
+				for k := range c.check(alt, schemas) {
+					kinds[k] = true
+				}
+			}
+			return kinds
+		case tokenDefault:
+			kinds := c.check(tok.alts[0], schemas)
+			if kinds == nil {
+				kinds = map[NodeKind]bool{}
+			}
+			kinds[kindOf(tok.literal.value)] = true
+			return kinds
+		case tokenFunction:
+			if !c.allows(schemas, NodeArray, NodeObject, NodeString) {
+				c.fail(tokens[:i+1], "%s is never an array, object or string", formatTokens(tokens[:i]))
+				return nil
+			}
+			return map[NodeKind]bool{NodeNumber: true}
+		case tokenNames:
+			if !c.allows(schemas, NodeObject) {
+				c.fail(tokens[:i+1], "%s is never an object", formatTokens(tokens[:i]))
+				return nil
+			}
+			return map[NodeKind]bool{NodeString: true}
+		case tokenChild:
+			next = c.members(tokens[:i+1], schemas, []string{tok.key})
+		case tokenUnion:
+			if len(tok.indices) == 0 {
+				next = c.members(tokens[:i+1], schemas, tok.keys)
+				break
+			}
+			next = c.elements(tokens[:i+1], schemas, nil)
+		case tokenIndex:
+			idx := tok.index
+			next = c.elements(tokens[:i+1], schemas, &idx)
+		case tokenSlice:
+			next = c.elements(tokens[:i+1], schemas, nil)
+		case tokenWildcard, tokenFilter:
+			next = c.children(tokens[:i+1], schemas, true, true)
+		case tokenPattern:
+			next = c.children(tokens[:i+1], schemas, false, false)
+			next = append(next, c.matching(schemas, tok.expr.(*regexExpr).re)...)
+			if len(next) == 0 {
+				c.fail(tokens[:i+1], "no member the schema allows at %s matches %s", formatTokens(tokens[:i]), tok)
+			}
+		case tokenRecursive:
+			seen := map[uintptr]bool{}
+			for _, s := range schemas {
+				next = c.descendants(s, seen, next, 0)
+			}
+		case tokenParent:
+			next = []interface{}{true}
+		}
+		if len(next) == 0 {
+			return nil
+		}
+		schemas = next
+	}
+	kinds := map[NodeKind]bool{}
+	for _, s := range schemas {
+		for _, b := range c.branches(s, 0) {
+			for k := range schemaKinds(b) {
+				kinds[k] = true
+			}
+		}
+	}
+	return kinds
+}
+
+// members returns the schemas of the members named keys of values
+// conforming to schemas, recording a problem for each name that no schema
+// allows or declares.
+func (c *schemaChecker) members(tokens []token, schemas []interface{}, keys []string) []interface{} {
+	var out, objects []interface{}
+	for _, s := range schemas {
+		for _, b := range c.branches(s, 0) {
+			if schemaKinds(b)[NodeObject] {
+				objects = append(objects, b)
+			}
+		}
+	}
+	if len(objects) == 0 {
+		c.fail(tokens, "%s is never an object", formatTokens(tokens[:len(tokens)-1]))
+		return nil
+	}
+	for _, key := range keys {
+		declared, open, n := false, false, len(out)
+		var names []string
+		var loose []interface{} // undeclared members, used only if none is declared
+		for _, b := range objects {
+			m, ok := b.(map[string]interface{})
+			if !ok {
+				loose, open = append(loose, b), true
+				continue
+			}
+			props, _ := m["properties"].(map[string]interface{})
+			for name := range props {
+				names = append(names, name)
+			}
+			if p, ok := props[key]; ok {
+				out, declared = append(out, p), true
+				continue
+			}
+			if matched := c.patternMembers(m, key); len(matched) > 0 {
+				out, declared = append(out, matched...), true
+				continue
+			}
+			switch extra := m["additionalProperties"].(type) {
+			case nil:
+				loose, open = append(loose, true), open || (props == nil && m["patternProperties"] == nil)
+			case bool:
+				if extra {
+					loose, open = append(loose, true), true
+				}
+			default:
+				out, declared = append(out, extra), true
+			}
+		}
+		if !declared {
+			out = append(out, loose...)
+		}
+		parent := formatTokens(tokens[:len(tokens)-1])
+		switch {
+		case declared || open:
+			continue
+		case len(out) == n:
+			c.fail(tokens, "the schema allows no member %s at %s", quoteKey(key), parent)
+		default:
+			c.fail(tokens, "the schema does not declare member %s at %s", quoteKey(key), parent)
+		}
+		if best := closestName(key, names); best != "" {
+			c.problems[len(c.problems)-1].Suggestion = "did you mean " + parent + formatMember(best) + "?"
+		}
+	}
+	return out
+}
+
+// patternMembers returns the patternProperties schemas of m whose patterns
+// match key.
+func (c *schemaChecker) patternMembers(m map[string]interface{}, key string) []interface{} {
+	var out []interface{}
+	patterns, _ := m["patternProperties"].(map[string]interface{})
+	for pattern, p := range patterns {
+		if re, err := regexp.Compile(pattern); err == nil && re.MatchString(key) {
+			out = append(out, p)
+		}
+	}
+	return out
+}
+
+// matching returns the schemas of the declared members of schemas whose
+// names re matches.
+func (c *schemaChecker) matching(schemas []interface{}, re *regexp.Regexp) []interface{} {
+	var out []interface{}
+	for _, s := range schemas {
+		for _, b := range c.branches(s, 0) {
+			m, _ := b.(map[string]interface{})
+			props, _ := m["properties"].(map[string]interface{})
+			for name, p := range props {
+				if re.MatchString(name) {
+					out = append(out, p)
+				}
+			}
+		}
+	}
+	return out
+}
+
+// children returns the schemas of the members of object values conforming
+// to schemas, leaving out those declared by name unless declared is set,
+// and, when elements is set, of the elements of array values.
+func (c *schemaChecker) children(tokens []token, schemas []interface{}, declared, elements bool) []interface{} {
+	var out []interface{}
+	containers := false
+	for _, s := range schemas {
+		for _, b := range c.branches(s, 0) {
+			kinds := schemaKinds(b)
+			if kinds[NodeObject] {
+				containers = true
+				out = c.undeclaredMembers(b, declared, out)
+			}
+			if elements && kinds[NodeArray] {
+				containers = true
+				out = append(out, arrayItems(b, nil)...)
+			}
+		}
+	}
+	if !containers {
+		c.fail(tokens, "%s is never an object or array", formatTokens(tokens[:len(tokens)-1]))
+	}
+	return out
+}
+
+// undeclaredMembers appends to out the schemas of the members of b that are
+// not declared by name, and of the declared ones when declared is set.
+func (c *schemaChecker) undeclaredMembers(b interface{}, declared bool, out []interface{}) []interface{} {
+	m, ok := b.(map[string]interface{})
+	if !ok {
+		return append(out, b)
+	}
+	props, _ := m["properties"].(map[string]interface{})
+	patterns, _ := m["patternProperties"].(map[string]interface{})
+	if declared {
+		for _, p := range props {
+			out = append(out, p)
+		}
+	}
+	for _, p := range patterns {
+		out = append(out, p)
+	}
+	switch extra := m["additionalProperties"].(type) {
+	case nil:
+		if props == nil && patterns == nil {
+			out = append(out, true)
+		}
+	case bool:
+		if extra {
+			out = append(out, true)
+		}
+	default:
+		out = append(out, extra)
+	}
+	return out
+}
+
+// elements returns the schemas of the element at *idx, or of every element
+// when idx is nil, of array values conforming to schemas.
+func (c *schemaChecker) elements(tokens []token, schemas []interface{}, idx *int) []interface{} {
+	var out []interface{}
+	arrays := false
+	for _, s := range schemas {
+		for _, b := range c.branches(s, 0) {
+			if schemaKinds(b)[NodeArray] {
+				arrays = true
+				out = append(out, arrayItems(b, idx)...)
+			}
+		}
+	}
+	switch {
+	case !arrays:
+		c.fail(tokens, "%s is never an array", formatTokens(tokens[:len(tokens)-1]))
+	case len(out) == 0:
+		c.fail(tokens, "the schema allows no element %s at %s", tokens[len(tokens)-1], formatTokens(tokens[:len(tokens)-1]))
+	}
+	return out
+}
+
+// arrayItems returns the schemas of the element at *idx, or of every
+// element when idx is nil, of the array schema b, with prefixItems or a
+// list of items describing leading elements.
+func arrayItems(b interface{}, idx *int) []interface{} {
+	m, ok := b.(map[string]interface{})
+	if !ok {
+		return []interface{}{b}
+	}
+	tuple, _ := m["prefixItems"].([]interface{})
+	rest, hasRest := m["items"]
+	if list, ok := rest.([]interface{}); ok {
+		tuple = list
+		rest, hasRest = m["additionalItems"]
+	}
+	if !hasRest {
+		rest = true
+	}
+	if idx != nil && *idx >= 0 && *idx < len(tuple) {
+		return []interface{}{tuple[*idx]}
+	}
+	var out []interface{}
+	if idx == nil || *idx < 0 {
+		out = append(out, tuple...)
+	}
+	if allow, ok := rest.(bool); !ok || allow {
+		out = append(out, rest)
+	}
+	return out
+}
+
+// descendants appends to out s and the schemas of every value below values
+// conforming to s, each map schema once.
+func (c *schemaChecker) descendants(s interface{}, seen map[uintptr]bool, out []interface{}, depth int) []interface{} {
+	var id uintptr // 0 for true, matching any value
+	switch x := s.(type) {
+	case bool:
+		if !x {
+			return out
+		}
+	case map[string]interface{}:
+		id = reflect.ValueOf(x).Pointer()
+	}
+	if seen[id] {
+		return out
+	}
+	seen[id] = true
+	out = append(out, s)
+	if depth > maxSchemaDepth {
+		return out
+	}
+	for _, b := range c.branches(s, 0) {
+		kinds := schemaKinds(b)
+		var children []interface{}
+		if kinds[NodeObject] {
+			children = c.undeclaredMembers(b, true, children)
+		}
+		if kinds[NodeArray] {
+			children = append(children, arrayItems(b, nil)...)
+		}
+		for _, child := range children {
+			out = c.descendants(child, seen, out, depth+1)
+		}
+	}
+	return out
+}
+
+// allows reports whether a value conforming to one of schemas can have one
+// of kinds.
+func (c *schemaChecker) allows(schemas []interface{}, kinds ...NodeKind) bool {
+	for _, s := range schemas {
+		for _, b := range c.branches(s, 0) {
+			allowed := schemaKinds(b)
+			for _, k := range kinds {
+				if allowed[k] {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// branches returns the schemas a value conforming to s conforms to one of,
+// following $ref and splitting allOf, anyOf and oneOf. The branches of allOf
+// are read as alternatives too, which can only widen what a path matches;
+// those that describe no type are dropped in favour of those that do.
+func (c *schemaChecker) branches(s interface{}, depth int) []interface{} {
+	m, ok := s.(map[string]interface{})
+	if !ok || depth > maxSchemaDepth {
+		return []interface{}{s}
+	}
+	var out []interface{}
+	if ref, ok := m["$ref"].(string); ok {
+		out = append(out, c.branches(c.resolve(ref), depth+1)...)
+	}
+	for _, keyword := range []string{"anyOf", "oneOf"} {
+		list, _ := m[keyword].([]interface{})
+		for _, sub := range list {
+			out = append(out, c.branches(sub, depth+1)...)
+		}
+	}
+	list, _ := m["allOf"].([]interface{})
+	var typed, untyped []interface{}
+	for _, sub := range list {
+		for _, b := range c.branches(sub, depth+1) {
+			if describesType(b) {
+				typed = append(typed, b)
+			} else {
+				untyped = append(untyped, b)
+			}
+		}
+	}
+	if len(typed) > 0 {
+		out = append(out, typed...)
+	} else {
+		out = append(out, untyped...)
+	}
+	if len(out) == 0 || describesType(m) {
+		out = append(out, m)
+	}
+	return out
+}
+
+// resolve returns the schema ref refers to within the root schema, or true,
+// matching any value, for references it cannot follow.
+func (c *schemaChecker) resolve(ref string) interface{} {
+	if !strings.HasPrefix(ref, "#") {
+		return true
+	}
+	node := c.root
+	for _, tok := range pointerTokens(ref[1:]) {
+		tok = pointerUnescaper.Replace(tok)
+		switch x := node.(type) {
+		case map[string]interface{}:
+			node = x[tok]
+		case []interface{}:
+			i, err := strconv.Atoi(tok)
+			if err != nil || !isArrayIndex(tok) || i >= len(x) {
+				return true
+			}
+			node = x[i]
+		default:
+			return true
+		}
+	}
+	if node == nil {
+		return true
+	}
+	return node
+}
+
+// describesType reports whether b says anything about the type of the
+// values conforming to it.
+func describesType(b interface{}) bool {
+	m, ok := b.(map[string]interface{})
+	if !ok {
+		return true
+	}
+	for _, keyword := range []string{"type", "const", "enum", "properties", "patternProperties", "additionalProperties", "items", "prefixItems"} {
+		if _, ok := m[keyword]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+var schemaTypeKinds = map[string]NodeKind{
+	"null":    NodeNull,
+	"boolean": NodeBool,
+	"number":  NodeNumber,
+	"integer": NodeNumber,
+	"string":  NodeString,
+	"array":   NodeArray,
+	"object":  NodeObject,
+}
+
+// schemaKinds returns the kinds of value conforming to the branch b can
+// have. Without type, const or enum, an object or array is assumed from
+// keywords that describe one, and any kind otherwise.
+func schemaKinds(b interface{}) map[NodeKind]bool {
+	kinds := map[NodeKind]bool{}
+	m, ok := b.(map[string]interface{})
+	if !ok {
+		if b == true {
+			for k := NodeNull; k <= NodeObject; k++ {
+				kinds[k] = true
+			}
+		}
+		return kinds
+	}
+	switch t := m["type"].(type) {
+	case string:
+		kinds[schemaTypeKinds[t]] = true
+		return kinds
+	case []interface{}:
+		for _, name := range t {
+			if s, ok := name.(string); ok {
+				kinds[schemaTypeKinds[s]] = true
+			}
+		}
+		return kinds
+	}
+	if v, ok := m["const"]; ok {
+		kinds[kindOf(v)] = true
+		return kinds
+	}
+	if values, ok := m["enum"].([]interface{}); ok {
+		for _, v := range values {
+			kinds[kindOf(v)] = true
+		}
+		return kinds
+	}
+	for _, keyword := range []string{"properties", "patternProperties", "additionalProperties"} {
+		if _, ok := m[keyword]; ok {
+			kinds[NodeObject] = true
+		}
+	}
+	for _, keyword := range []string{"items", "prefixItems"} {
+		if _, ok := m[keyword]; ok {
+			kinds[NodeArray] = true
+		}
+	}
+	if len(kinds) == 0 {
+		return schemaKinds(true)
+	}
+	return kinds
+}
+
+// closestName returns the name in names most like name, if it is close
+// enough to be a likely typo, or "".
+func closestName(name string, names []string) string {
+	best, bestDist := "", len(name)/3+1
+	for _, n := range names {
+		if d := editDistance(strings.ToLower(name), strings.ToLower(n)); d < bestDist || (d == bestDist && n < best) {
+			best, bestDist = n, d
+		}
+	}
+	return best
+}
+
+// editDistance returns the Damerau–Levenshtein distance between a and b,
+// counting a swap of adjacent characters as one edit.
+func editDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	d := make([][]int, len(ra)+1)
+	for i := range d {
+		d[i] = make([]int, len(rb)+1)
+		d[i][0] = i
+	}
+	for j := range d[0] {
+		d[0][j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			d[i][j] = min(d[i-1][j]+1, d[i][j-1]+1, d[i-1][j-1]+cost)
+			if i > 1 && j > 1 && ra[i-1] == rb[j-2] && ra[i-2] == rb[j-1] {
+				d[i][j] = min(d[i][j], d[i-2][j-2]+1)
+			}
+		}
+	}
+	return d[len(ra)][len(rb)]
+}
@@ -0,0 +1,124 @@
+package jsonpath_test
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/njchilds90/go-jsonpath"
+)
+
+var userSchema = []byte(`{
+	"$schema": "https://json-schema.org/draft/2020-12/schema",
+	"type": "object",
+	"properties": {
+		"user": {"$ref": "#/$defs/user"},
+		"tags": {"type": "array", "items": {"type": "string"}},
+		"point": {"type": "array", "prefixItems": [{"type": "number"}, {"type": "number"}], "items": false},
+		"labels": {"type": "object", "additionalProperties": {"type": "string"}},
+		"metrics": {"type": "object", "patternProperties": {"^cpu_": {"type": "number"}}, "additionalProperties": false},
+		"status": {"enum": ["active", "disabled", null]},
+		"parent": {"$ref": "#"}
+	},
+	"$defs": {
+		"user": {
+			"type": "object",
+			"properties": {
+				"name": {"type": "string"},
+				"email": {"type": ["string", "null"]},
+				"age": {"type": "integer"},
+				"address": {
+					"allOf": [
+						{"$ref": "#/$defs/place"},
+						{"required": ["city"]}
+					]
+				}
+			},
+			"additionalProperties": false
+		},
+		"place": {"type": "object", "properties": {"city": {"type": "string"}, "zip": {"type": "string"}}}
+	}
+}`)
+
+func TestCheckSchema(t *testing.T) {
+	tests := []struct {
+		path      string
+		matchable bool
+		kinds     []jsonpath.NodeKind
+		problems  int
+	}{
+		{"$.user.name", true, []jsonpath.NodeKind{jsonpath.NodeString}, 0},
+		{"$.user.email", true, []jsonpath.NodeKind{jsonpath.NodeNull, jsonpath.NodeString}, 0},
+		{"$.user.address.city", true, []jsonpath.NodeKind{jsonpath.NodeString}, 0},
+		{"$.user.emial", false, nil, 1},
+		{"$.user.name.first", false, nil, 1},
+		{"$.tags[0]", true, []jsonpath.NodeKind{jsonpath.NodeString}, 0},
+		{"$.tags[?(@ == 'x')]", true, []jsonpath.NodeKind{jsonpath.NodeString}, 0},
+		{"$.point[1]", true, []jsonpath.NodeKind{jsonpath.NodeNumber}, 0},
+		{"$.point[2]", false, nil, 1},
+		{"$.labels.team", true, []jsonpath.NodeKind{jsonpath.NodeString}, 0},
+		{"$.labels.*", true, []jsonpath.NodeKind{jsonpath.NodeString}, 0},
+		{"$.metrics.cpu_user", true, []jsonpath.NodeKind{jsonpath.NodeNumber}, 0},
+		{"$.metrics.mem", false, nil, 1},
+		{"$.status", true, []jsonpath.NodeKind{jsonpath.NodeNull, jsonpath.NodeString}, 0},
+		{"$.parent.parent.user.age", true, []jsonpath.NodeKind{jsonpath.NodeNumber}, 0},
+		{"$..zip", true, []jsonpath.NodeKind{jsonpath.NodeString}, 0},
+		{"$.user.length()", true, []jsonpath.NodeKind{jsonpath.NodeNumber}, 0},
+		{"$.user~", true, []jsonpath.NodeKind{jsonpath.NodeString}, 0},
+		{"$.user.age.length()", false, nil, 1},
+		{"$.user.emial ?? 'none'", true, []jsonpath.NodeKind{jsonpath.NodeString}, 1},
+		{"$.user.emial | $.user.email", true, []jsonpath.NodeKind{jsonpath.NodeNull, jsonpath.NodeString}, 1},
+	}
+	for _, tt := range tests {
+		report, err := jsonpath.MustCompile(tt.path).CheckSchema(userSchema)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", tt.path, err)
+			continue
+		}
+		if report.Matchable != tt.matchable {
+			t.Errorf("%s: expected matchable %v, got %+v", tt.path, tt.matchable, report)
+		}
+		if len(report.Kinds) != len(tt.kinds) || (len(tt.kinds) > 0 && !reflect.DeepEqual(report.Kinds, tt.kinds)) {
+			t.Errorf("%s: expected kinds %v, got %v", tt.path, tt.kinds, report.Kinds)
+		}
+		if len(report.Problems) != tt.problems {
+			t.Errorf("%s: expected %d problems, got %+v", tt.path, tt.problems, report.Problems)
+		}
+	}
+}
+
+func TestCheckSchemaProblems(t *testing.T) {
+	report, err := jsonpath.MustCompile("$.user.emial").CheckSchema(userSchema)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []jsonpath.SchemaProblem{{
+		Path:       "$.user.emial",
+		Message:    "the schema allows no member 'emial' at $.user",
+		Suggestion: "did you mean $.user.email?",
+	}}
+	if !reflect.DeepEqual(report.Problems, want) {
+		t.Errorf("expected %+v, got %+v", want, report.Problems)
+	}
+
+	// undeclared members of an open object are problems, but can match
+	report, err = jsonpath.MustCompile("$.user.address.ctiy").CheckSchema(userSchema)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !report.Matchable || len(report.Problems) != 1 || report.Problems[0].Suggestion != "did you mean $.user.address.city?" {
+		t.Errorf("expected an undeclared member, got %+v", report)
+	}
+
+	out, err := json.Marshal(report)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `{"matchable":true,"kinds":["null","boolean","number","string","array","object"],"problems":[{"path":"$.user.address.ctiy","message":"the schema does not declare member 'ctiy' at $.user.address","suggestion":"did you mean $.user.address.city?"}]}`; string(out) != want {
+		t.Errorf("expected %s, got %s", want, out)
+	}
+
+	if _, err := jsonpath.MustCompile("$.a").CheckSchema([]byte(`{`)); !jsonpath.IsJSONError(err) {
+		t.Errorf("expected a JSON error, got %v", err)
+	}
+}
@@ -0,0 +1,153 @@
+package jsonpath
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// SegmentKind tells member names and array indices apart in a Segment.
+type SegmentKind int
+
+const (
+	// SegmentName is an object member name.
+	SegmentName SegmentKind = iota + 1
+	// SegmentIndex is an array index.
+	SegmentIndex
+)
+
+// Segment is one step of a singular path: a member name or an array index.
+type Segment struct {
+	Kind SegmentKind
+	// Name is the member name of a SegmentName.
+	Name string
+	// Index is the array index of a SegmentIndex; negative indices count
+	// from the end.
+	Index int
+}
+
+// NameSegment returns the segment selecting member name.
+func NameSegment(name string) Segment {
+	return Segment{Kind: SegmentName, Name: name}
+}
+
+// IndexSegment returns the segment selecting array index i.
+func IndexSegment(i int) Segment {
+	return Segment{Kind: SegmentIndex, Index: i}
+}
+
+// String returns the segment as a selector: ".name", "['odd name']" or "[2]".
+func (s Segment) String() string {
+	if s.Kind == SegmentIndex {
+		return "[" + strconv.Itoa(s.Index) + "]"
+	}
+	return formatMember(s.Name)
+}
+
+// ParsePath splits a singular path — one made only of member names and
+// indices — into its segments, so keys containing dots or brackets survive
+// intact. Paths that can select more than one node fail with ErrInvalidPath.
+//
+// Example:
+//
+//	segs, err := jsonpath.ParsePath("$['user.name'].emails[0]")
+//	// segs == [{Name "user.name"} {Name "emails"} {Index 0}]
+func ParsePath(path string) ([]Segment, error) {
+	tokens, err := tokenize(path)
+	if err != nil {
+		return nil, err
+	}
+	segs := make([]Segment, 0, len(tokens)-1)
+	for _, tok := range tokens[1:] {
+		switch tok.kind {
+		case tokenChild:
+			segs = append(segs, NameSegment(tok.key))
+		case tokenIndex:
+			segs = append(segs, IndexSegment(tok.index))
+		default:
+			return nil, &Error{Code: ErrInvalidPath, Message: fmt.Sprintf("path is not singular: %s selects more than one node", tok)}
+		}
+	}
+	return segs, nil
+}
+
+// FormatPath returns the path made of segs in dot notation, falling back to
+// brackets for member names that are not identifiers:
+// $.store.book[0]['first name'].
+func FormatPath(segs []Segment) string {
+	var b strings.Builder
+	b.WriteByte('$')
+	for _, s := range segs {
+		b.WriteString(s.String())
+	}
+	return b.String()
+}
+
+// FormatBracketPath returns the path made of segs in bracket notation:
+// $['store']['book'][0]['first name'].
+func FormatBracketPath(segs []Segment) string {
+	var b strings.Builder
+	b.WriteByte('$')
+	for _, s := range segs {
+		if s.Kind == SegmentIndex {
+			b.WriteString(s.String())
+			continue
+		}
+		b.WriteString("[" + quoteKey(s.Name) + "]")
+	}
+	return b.String()
+}
+
+// Segments returns the segments of the match's normalized path. Unlike
+// ParsePath it never fails, as Path always names a single node.
+//
+// Example:
+//
+//	for _, seg := range r.Segments() {
+//	    if seg.Kind == jsonpath.SegmentIndex {
+//	        row = seg.Index
+//	    }
+//	}
+func (r Result) Segments() []Segment {
+	var segs []Segment
+	p := r.Path
+	for i := 1; i < len(p); {
+		if p[i] == '[' {
+			if n := indexSelectorLen(p[i:]); n > 0 {
+				idx, _ := strconv.Atoi(p[i+1 : i+n-1])
+				segs = append(segs, IndexSegment(idx))
+				i += n
+				continue
+			}
+			// a member name that is not an identifier is quoted: ['a.b']
+			if i+1 < len(p) && (p[i+1] == '\'' || p[i+1] == '"') {
+				if name, n, ok := readQuoted(p[i+1:]); ok && i+1+n < len(p) && p[i+1+n] == ']' {
+					segs = append(segs, NameSegment(name))
+					i += n + 2
+					continue
+				}
+			}
+		}
+		// a member name runs up to the next '.' or array index
+		j := i + 1
+		for j < len(p) && p[j] != '.' && (p[j] != '[' || indexSelectorLen(p[j:]) == 0) {
+			j++
+		}
+		segs = append(segs, NameSegment(p[i+1:j]))
+		i = j
+	}
+	return segs
+}
+
+// indexSelectorLen returns the length of the "[n]" index at the start of s,
+// or 0 if s does not start with one.
+func indexSelectorLen(s string) int {
+	i := 1
+	for i < len(s) && s[i] >= '0' && s[i] <= '9' {
+		i++
+	}
+	if i == 1 || i >= len(s) || s[i] != ']' {
+		return 0
+	}
+	return i + 1
+}
@@ -0,0 +1,77 @@
+package jsonpath_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/njchilds90/go-jsonpath"
+)
+
+func TestParsePath(t *testing.T) {
+	segs, err := jsonpath.ParsePath("$['user.name'].emails[0]['a b'][-1]")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []jsonpath.Segment{
+		jsonpath.NameSegment("user.name"),
+		jsonpath.NameSegment("emails"),
+		jsonpath.IndexSegment(0),
+		jsonpath.NameSegment("a b"),
+		jsonpath.IndexSegment(-1),
+	}
+	if !reflect.DeepEqual(segs, want) {
+		t.Errorf("expected %v, got %v", want, segs)
+	}
+
+	if segs, err := jsonpath.ParsePath("$"); err != nil || len(segs) != 0 {
+		t.Errorf("expected no segments for the root, got %v %v", segs, err)
+	}
+	for _, path := range []string{"$.a[*]", "$..a", "$.a[0:2]", "$.a[?(@.b)]", "$.a[0,1]", "a.b"} {
+		if _, err := jsonpath.ParsePath(path); !jsonpath.IsPathError(err) {
+			t.Errorf("%s: expected path error, got %v", path, err)
+		}
+	}
+}
+
+func TestFormatPath(t *testing.T) {
+	segs := []jsonpath.Segment{
+		jsonpath.NameSegment("store"),
+		jsonpath.NameSegment("first name"),
+		jsonpath.IndexSegment(2),
+		jsonpath.NameSegment("it's"),
+	}
+	if got, want := jsonpath.FormatPath(segs), `$.store['first name'][2]['it\'s']`; got != want {
+		t.Errorf("expected %s, got %s", want, got)
+	}
+	if got, want := jsonpath.FormatBracketPath(segs), `$['store']['first name'][2]['it\'s']`; got != want {
+		t.Errorf("expected %s, got %s", want, got)
+	}
+	if got := jsonpath.FormatPath(nil); got != "$" {
+		t.Errorf("expected $, got %s", got)
+	}
+
+	segs = segs[:3]
+	back, err := jsonpath.ParsePath(jsonpath.FormatBracketPath(segs))
+	if err != nil || !reflect.DeepEqual(back, segs) {
+		t.Errorf("expected a round trip, got %v %v", back, err)
+	}
+}
+
+func TestResultSegments(t *testing.T) {
+	results, err := jsonpath.Query(sampleJSON, "$.store.book[2].author")
+	if err != nil || len(results) != 1 {
+		t.Fatalf("unexpected query result: %v %v", results, err)
+	}
+	want := []jsonpath.Segment{
+		jsonpath.NameSegment("store"),
+		jsonpath.NameSegment("book"),
+		jsonpath.IndexSegment(2),
+		jsonpath.NameSegment("author"),
+	}
+	if got := results[0].Segments(); !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+	if got := (jsonpath.Result{Path: "$"}).Segments(); len(got) != 0 {
+		t.Errorf("expected no segments for the root, got %v", got)
+	}
+}
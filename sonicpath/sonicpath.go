@@ -0,0 +1,200 @@
+// Package sonicpath evaluates compiled JSONPath expressions against the lazy
+// AST of github.com/bytedance/sonic, so that only the branches a path
+// touches are parsed. For wide documents from which a handful of fields are
+// extracted this avoids decoding almost all of the input.
+//
+// It lives in its own module so that the jsonpath package itself keeps no
+// dependencies.
+//
+// Example:
+//
+//	cp := jsonpath.MustCompile("$.items[?(@.price < 10)].sku")
+//	results, err := sonicpath.Query(cp, body)
+package sonicpath
+
+import (
+	"sort"
+
+	"github.com/bytedance/sonic/ast"
+	"github.com/njchilds90/go-jsonpath"
+)
+
+// Query evaluates cp against JSON text, parsing lazily with sonic. Results
+// are the same as cp.Query would return for the same text: matched objects
+// and arrays are fully decoded to map[string]interface{} and []interface{},
+// numbers are float64.
+func Query(cp *jsonpath.CompiledPath, data string, opts ...jsonpath.Option) ([]jsonpath.Result, error) {
+	root := ast.NewRaw(data)
+	return QueryNode(cp, &root, opts...)
+}
+
+// QueryNode evaluates cp against a sonic AST node, loading only the children
+// the path visits. Lazy sonic nodes are modified as they load, so n must not
+// be used by other goroutines during the query.
+func QueryNode(cp *jsonpath.CompiledPath, n *ast.Node, opts ...jsonpath.Option) ([]jsonpath.Result, error) {
+	a := &adapter{}
+	results, err := cp.QueryValue(a.wrap(n), opts...)
+	if err == nil {
+		err = a.err
+	}
+	if err != nil {
+		return nil, err
+	}
+	for i := range results {
+		if results[i].Value, err = a.plain(results[i].Value); err != nil {
+			return nil, err
+		}
+	}
+	return results, nil
+}
+
+// adapter wraps sonic nodes for the evaluator and records the first parse
+// error, which the jsonpath.Object and jsonpath.Array methods cannot return.
+type adapter struct {
+	err error
+}
+
+func (a *adapter) fail(err error) {
+	if a.err == nil {
+		a.err = &jsonpath.Error{Code: jsonpath.ErrInvalidJSON, Message: "failed to parse JSON", Cause: err}
+	}
+}
+
+// wrap returns containers as jsonpath.Object or jsonpath.Array and scalars
+// as the values encoding/json produces.
+func (a *adapter) wrap(n *ast.Node) interface{} {
+	var (
+		v   interface{}
+		err error
+	)
+	switch t := n.TypeSafe(); t {
+	case ast.V_OBJECT, ast.V_ARRAY:
+		// Nodes handed out by a lazy parent can move as it loads further
+		// children, so each container gets a detached raw node of its own.
+		raw, err := n.Raw()
+		if err != nil {
+			a.fail(err)
+			return nil
+		}
+		c := ast.NewRaw(raw)
+		if t == ast.V_OBJECT {
+			return &object{a: a, n: &c}
+		}
+		return &array{a: a, n: &c}
+	case ast.V_STRING:
+		v, err = n.String()
+	case ast.V_NUMBER:
+		v, err = n.Float64()
+	case ast.V_TRUE:
+		return true
+	case ast.V_FALSE:
+		return false
+	case ast.V_NULL:
+		return nil
+	default:
+		v, err = n.Interface()
+	}
+	if err != nil {
+		a.fail(err)
+		return nil
+	}
+	return v
+}
+
+// plain decodes a wrapped container in full.
+func (a *adapter) plain(v interface{}) (interface{}, error) {
+	var n *ast.Node
+	switch c := v.(type) {
+	case *object:
+		n = c.n
+	case *array:
+		n = c.n
+	default:
+		return v, nil
+	}
+	out, err := n.Interface()
+	if err != nil {
+		return nil, &jsonpath.Error{Code: jsonpath.ErrInvalidJSON, Message: "failed to parse JSON", Cause: err}
+	}
+	return out, nil
+}
+
+// object is a sonic object node viewed as a jsonpath.Object.
+type object struct {
+	a    *adapter
+	n    *ast.Node
+	keys []string
+}
+
+func (o *object) Get(key string) (interface{}, bool) {
+	c := o.n.Get(key)
+	if !c.Exists() {
+		if err := c.Check(); err != nil && err != ast.ErrNotExist {
+			o.a.fail(err)
+		}
+		return nil, false
+	}
+	return o.a.wrap(c), true
+}
+
+// Keys returns the member names sorted, as the jsonpath package visits the
+// members of decoded objects.
+func (o *object) Keys() []string {
+	if o.keys == nil {
+		it, err := o.n.Properties()
+		if err != nil {
+			o.a.fail(err)
+			return nil
+		}
+		o.keys = []string{}
+		var p ast.Pair
+		for it.Next(&p) {
+			o.keys = append(o.keys, p.Key)
+		}
+		if err := o.n.Check(); err != nil {
+			o.a.fail(err)
+		}
+		sort.Strings(o.keys)
+	}
+	return o.keys
+}
+
+// array is a sonic array node viewed as a jsonpath.Array. Its elements are
+// located on first use.
+type array struct {
+	a     *adapter
+	n     *ast.Node
+	elems []*ast.Node
+}
+
+func (l *array) load() {
+	if l.elems != nil {
+		return
+	}
+	l.elems = []*ast.Node{}
+	it, err := l.n.Values()
+	if err != nil {
+		l.a.fail(err)
+		return
+	}
+	for {
+		var c ast.Node
+		if !it.Next(&c) {
+			break
+		}
+		l.elems = append(l.elems, &c)
+	}
+	if err := l.n.Check(); err != nil {
+		l.a.fail(err)
+	}
+}
+
+func (l *array) Len() int {
+	l.load()
+	return len(l.elems)
+}
+
+func (l *array) Index(i int) interface{} {
+	l.load()
+	return l.a.wrap(l.elems[i])
+}
@@ -0,0 +1,73 @@
+package sonicpath_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/njchilds90/go-jsonpath"
+	"github.com/njchilds90/go-jsonpath/sonicpath"
+)
+
+const sampleJSON = `{
+	"store": {
+		"book": [
+			{"category": "reference", "author": "Nigel Rees", "title": "Sayings of the Century", "price": 8.95},
+			{"category": "fiction", "author": "Evelyn Waugh", "title": "Sword of Honour", "price": 12.99},
+			{"category": "fiction", "author": "Herman Melville", "title": "Moby Dick", "isbn": "0-553-21311-3", "price": 8.99},
+			{"category": "fiction", "author": "J. R. R. Tolkien", "title": "The Lord of the Rings", "isbn": "0-395-19395-8", "price": 22.99}
+		],
+		"bicycle": {"color": "red", "price": 19.95}
+	},
+	"expensive": 10
+}`
+
+func TestQueryMatchesJSONPath(t *testing.T) {
+	paths := []string{
+		"$.store.book[*].author",
+		"$..author",
+		"$.store.*",
+		"$.store..price",
+		"$..book[2]",
+		"$..book[-1:]",
+		"$..book[0,1]",
+		"$..book[:2]",
+		"$..book[?(@.isbn)]",
+		"$..book[?(@.price < 10)].title",
+		"$..*",
+		"$.store.bicycle",
+		"$.missing",
+	}
+	for _, path := range paths {
+		t.Run(path, func(t *testing.T) {
+			cp := jsonpath.MustCompile(path)
+			want, err := cp.Query([]byte(sampleJSON))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			got, err := sonicpath.Query(cp, sampleJSON)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("expected %v, got %v", want, got)
+			}
+		})
+	}
+}
+
+func TestQueryIgnoresUntouchedBranches(t *testing.T) {
+	// sonic only checks the structure of members it skips, so the bad
+	// escape in "other" is never decoded by a path that does not visit it
+	data := `{"wanted": {"id": 7}, "other": {"x": ["\q"]}}`
+	results, err := sonicpath.Query(jsonpath.MustCompile("$.wanted.id"), data)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(results) != 1 || results[0].Value != 7.0 {
+		t.Errorf("unexpected results: %v", results)
+	}
+
+	if _, err := sonicpath.Query(jsonpath.MustCompile("$.other.x[*]"), data); !jsonpath.IsJSONError(err) {
+		t.Errorf("expected JSON error for a visited malformed branch, got: %v", err)
+	}
+}
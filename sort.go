@@ -0,0 +1,137 @@
+package jsonpath
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+)
+
+// SortOrder selects how WithSort and SortResults order results.
+type SortOrder int
+
+const (
+	// ByValueAsc orders results by value, smallest first. Values of different
+	// JSON types order as null, booleans, numbers, strings, then arrays and
+	// objects; numbers compare numerically and strings bytewise.
+	ByValueAsc SortOrder = iota + 1
+	// ByValueDesc is ByValueAsc reversed.
+	ByValueDesc
+	// ByPath orders results by normalized path, comparing array indices
+	// numerically, so $.a[2] comes before $.a[10].
+	ByPath
+)
+
+// WithSort orders the results of a query. Streaming queries such as
+// QueryFunc collect every match before the first callback when it is set.
+//
+// Example:
+//
+//	top, err := jsonpath.Query(data, "$.items[*].price", jsonpath.WithSort(jsonpath.ByValueDesc))
+//	if len(top) > 10 {
+//	    top = top[:10]
+//	}
+func WithSort(order SortOrder) Option {
+	return func(e *engine) {
+		e.sortOrder = order
+	}
+}
+
+// SortResults orders results in place. The sort is stable, so results that
+// compare equal keep their order.
+func SortResults(results []Result, order SortOrder) {
+	switch order {
+	case ByValueAsc:
+		sort.SliceStable(results, func(i, j int) bool {
+			return compareJSON(decodedValue(results[i].Value), decodedValue(results[j].Value)) < 0
+		})
+	case ByValueDesc:
+		sort.SliceStable(results, func(i, j int) bool {
+			return compareJSON(decodedValue(results[i].Value), decodedValue(results[j].Value)) > 0
+		})
+	case ByPath:
+		sort.SliceStable(results, func(i, j int) bool {
+			return comparePaths(results[i].Segments(), results[j].Segments()) < 0
+		})
+	}
+}
+
+// decodedValue decodes json.RawMessage values, as produced by WithRawValues,
+// so raw results are handled like decoded ones.
+func decodedValue(v interface{}) interface{} {
+	if raw, ok := v.(json.RawMessage); ok {
+		var decoded interface{}
+		if json.Unmarshal(raw, &decoded) == nil {
+			return decoded
+		}
+	}
+	return v
+}
+
+// typeRank gives the position of v's JSON type in the sort order.
+func typeRank(v interface{}) int {
+	switch v.(type) {
+	case nil:
+		return 0
+	case bool:
+		return 1
+	case string:
+		return 3
+	}
+	if _, ok := toFloat64(v); ok {
+		return 2
+	}
+	return 4
+}
+
+// compareJSON returns -1, 0 or 1 as a sorts before, with or after b.
+func compareJSON(a, b interface{}) int {
+	ra, rb := typeRank(a), typeRank(b)
+	if ra != rb {
+		return compareInts(ra, rb)
+	}
+	switch ra {
+	case 1:
+		ba, bb := a.(bool), b.(bool)
+		if ba == bb {
+			return 0
+		}
+		if !ba {
+			return -1
+		}
+		return 1
+	case 2:
+		fa, _ := toFloat64(a)
+		fb, _ := toFloat64(b)
+		return compareNumbers(a, b, fa, fb)
+	case 3:
+		return strings.Compare(a.(string), b.(string))
+	}
+	return 0
+}
+
+func comparePaths(a, b []Segment) int {
+	for i := 0; i < len(a) && i < len(b); i++ {
+		sa, sb := a[i], b[i]
+		if sa.Kind != sb.Kind {
+			return compareInts(int(sa.Kind), int(sb.Kind))
+		}
+		if sa.Kind == SegmentIndex {
+			if c := compareInts(sa.Index, sb.Index); c != 0 {
+				return c
+			}
+		} else if c := strings.Compare(sa.Name, sb.Name); c != 0 {
+			return c
+		}
+	}
+	return compareInts(len(a), len(b))
+}
+
+func compareInts(a, b int) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,88 @@
+package jsonpath_test
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/njchilds90/go-jsonpath"
+)
+
+func TestWithSort(t *testing.T) {
+	vals, err := jsonpath.Values(sampleJSON, "$..price", jsonpath.WithSort(jsonpath.ByValueDesc))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := []interface{}{22.99, 19.95, 12.99, 8.99, 8.95}; !reflect.DeepEqual(vals, want) {
+		t.Errorf("expected %v, got %v", want, vals)
+	}
+
+	first, err := jsonpath.First(sampleJSON, "$.store.book[*].title", jsonpath.WithSort(jsonpath.ByValueAsc))
+	if err != nil || first == nil || first.Value != "Moby Dick" {
+		t.Errorf("expected the smallest title first, got %v %v", first, err)
+	}
+
+	var streamed []interface{}
+	err = jsonpath.QueryFunc(context.Background(), sampleJSON, "$..price", func(r jsonpath.Result) error {
+		streamed = append(streamed, r.Value)
+		if len(streamed) == 2 {
+			return jsonpath.ErrStop
+		}
+		return nil
+	}, jsonpath.WithSort(jsonpath.ByValueAsc))
+	if err != nil || !reflect.DeepEqual(streamed, []interface{}{8.95, 8.99}) {
+		t.Errorf("unexpected streamed order: %v %v", streamed, err)
+	}
+
+	raw, err := jsonpath.Values(sampleJSON, "$..price", jsonpath.WithRawValues(), jsonpath.WithSort(jsonpath.ByValueAsc))
+	if err != nil || len(raw) != 5 || string(raw[4].(json.RawMessage)) != "22.99" {
+		t.Errorf("unexpected raw order: %v %v", raw, err)
+	}
+
+	if n, err := jsonpath.Count(sampleJSON, "$..price", jsonpath.WithSort(jsonpath.ByPath)); err != nil || n != 5 {
+		t.Errorf("expected 5, got %d %v", n, err)
+	}
+}
+
+func TestWithSortFilterOperands(t *testing.T) {
+	data := []byte(`[{"v":[1,5],"n":"a"},{"v":[3,1],"n":"b"}]`)
+	for _, order := range []jsonpath.SortOrder{jsonpath.ByValueAsc, jsonpath.ByValueDesc} {
+		vals, err := jsonpath.Values(data, "$[?(@.v[*] == 1)].n", jsonpath.WithSort(order))
+		if err != nil || !reflect.DeepEqual(vals, []interface{}{"a"}) {
+			t.Errorf("expected sorting to leave the filter alone, got %v %v", vals, err)
+		}
+	}
+}
+
+func TestSortResults(t *testing.T) {
+	data := []byte(`[3, "b", null, {"x": 1}, true, 10, "a", false, [1], 2.5]`)
+	results, err := jsonpath.Query(data, "$[*]")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	jsonpath.SortResults(results, jsonpath.ByValueAsc)
+	var got []interface{}
+	for _, r := range results {
+		got = append(got, r.Value)
+	}
+	want := []interface{}{nil, false, true, 2.5, 3.0, 10.0, "a", "b", map[string]interface{}{"x": 1.0}, []interface{}{1.0}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+
+	jsonpath.SortResults(results, jsonpath.ByPath)
+	var paths []string
+	for _, r := range results {
+		paths = append(paths, r.Path)
+	}
+	if paths[2] != "$[2]" || paths[9] != "$[9]" {
+		t.Errorf("expected numeric index order, got %v", paths)
+	}
+
+	results = []jsonpath.Result{{Path: "$.b[10]"}, {Path: "$.a"}, {Path: "$.b[2]"}, {Path: "$.b"}}
+	jsonpath.SortResults(results, jsonpath.ByPath)
+	if results[0].Path != "$.a" || results[1].Path != "$.b" || results[2].Path != "$.b[2]" || results[3].Path != "$.b[10]" {
+		t.Errorf("unexpected path order: %v", results)
+	}
+}
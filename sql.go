@@ -0,0 +1,66 @@
+package jsonpath
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+var _ sql.Scanner = (*Column)(nil)
+
+// Column is an sql.Scanner that runs a compiled path against a JSON or JSONB
+// column as rows are scanned, so the column never needs to be held or
+// decoded separately.
+//
+// Example:
+//
+//	email := jsonpath.Column{Path: jsonpath.MustCompile("$.contact.email")}
+//	for rows.Next() {
+//	    var id int64
+//	    if err := rows.Scan(&id, &email); err != nil {
+//	        return err
+//	    }
+//	    if email.Valid && len(email.Results) > 0 {
+//	        notify(id, email.Results[0].Value)
+//	    }
+//	}
+type Column struct {
+	// Path is the path run against each scanned value. It must be set.
+	Path *CompiledPath
+	// Options are passed to the query, e.g. WithLenientJSON or WithOffsets.
+	Options []Option
+
+	// Results holds the matches in the last scanned value.
+	Results []Result
+	// Valid is false when the last scanned value was SQL NULL.
+	Valid bool
+}
+
+// Scan implements sql.Scanner. It accepts the []byte and string values
+// drivers return for JSON, JSONB and text columns; NULL sets Valid to false
+// and clears Results. The input is copied, so results stay valid after the
+// driver reuses its buffers.
+func (c *Column) Scan(src interface{}) error {
+	c.Results, c.Valid = nil, false
+	if c.Path == nil {
+		return &Error{Code: ErrInvalidInput, Message: "Column.Path must not be nil"}
+	}
+
+	var data []byte
+	switch v := src.(type) {
+	case nil:
+		return nil
+	case []byte:
+		data = append([]byte(nil), v...)
+	case string:
+		data = []byte(v)
+	default:
+		return &Error{Code: ErrInvalidInput, Message: fmt.Sprintf("cannot scan %T into Column", src)}
+	}
+
+	results, err := c.Path.Query(data, c.Options...)
+	if err != nil {
+		return err
+	}
+	c.Results, c.Valid = results, true
+	return nil
+}
@@ -0,0 +1,71 @@
+package jsonpath_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/njchilds90/go-jsonpath"
+)
+
+func TestColumnScan(t *testing.T) {
+	col := jsonpath.Column{Path: jsonpath.MustCompile("$.contact.email")}
+
+	buf := []byte(`{"contact": {"email": "ann@example.com"}}`)
+	if err := col.Scan(buf); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	copy(buf, "XXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXX") // drivers reuse their buffers
+	if !col.Valid || len(col.Results) != 1 || col.Results[0].Value != "ann@example.com" {
+		t.Errorf("unexpected scan state: valid=%v results=%v", col.Valid, col.Results)
+	}
+
+	if err := col.Scan(`{"contact": {}}`); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !col.Valid || len(col.Results) != 0 {
+		t.Errorf("expected no matches, got valid=%v results=%v", col.Valid, col.Results)
+	}
+
+	if err := col.Scan(nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if col.Valid || col.Results != nil {
+		t.Errorf("expected NULL state, got valid=%v results=%v", col.Valid, col.Results)
+	}
+}
+
+func TestColumnScanOptions(t *testing.T) {
+	col := jsonpath.Column{
+		Path:    jsonpath.MustCompile("$.tags[*]"),
+		Options: []jsonpath.Option{jsonpath.WithLenientJSON(), jsonpath.WithRawValues()},
+	}
+	buf := []byte(`{tags: ["a", "b",]}`)
+	if err := col.Scan(buf); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	copy(buf, "XXXXXXXXXXXXXXXXXXX")
+	if len(col.Results) != 2 || string(col.Results[1].Value.(json.RawMessage)) != `"b"` {
+		t.Errorf("unexpected results: %v", col.Results)
+	}
+}
+
+func TestColumnScanErrors(t *testing.T) {
+	col := jsonpath.Column{Path: jsonpath.MustCompile("$.a")}
+	if err := col.Scan(42); !isInputError(err) {
+		t.Errorf("expected input error for int64, got: %v", err)
+	}
+	if err := col.Scan([]byte(`{bad`)); !jsonpath.IsJSONError(err) {
+		t.Errorf("expected JSON error, got: %v", err)
+	}
+	if col.Valid {
+		t.Error("expected Valid to be false after a failed scan")
+	}
+	if err := (&jsonpath.Column{}).Scan([]byte(`{}`)); !isInputError(err) {
+		t.Errorf("expected input error for nil Path, got: %v", err)
+	}
+}
+
+func isInputError(err error) bool {
+	e, ok := err.(*jsonpath.Error)
+	return ok && e.Code == jsonpath.ErrInvalidInput
+}
@@ -0,0 +1,70 @@
+package jsonpath
+
+import "time"
+
+// Stats reports the work done by one query. Pass a pointer with WithStats to
+// have it filled in when the query finishes, whether or not it succeeded.
+type Stats struct {
+	// NodesVisited counts node visits, including those made while resolving
+	// filter operands. It is the quantity limited by WithMaxNodes.
+	NodesVisited int `json:"nodesVisited"`
+	// FiltersEvaluated counts filter expression evaluations, one per candidate element.
+	FiltersEvaluated int `json:"filtersEvaluated"`
+	// MaxDepth is the deepest document nesting level visited; the root is depth 0.
+	MaxDepth int `json:"maxDepth"`
+	// Matches counts the results produced.
+	Matches int `json:"matches"`
+	// Duration is the wall time spent evaluating.
+	Duration time.Duration `json:"duration"`
+}
+
+// WithStats records execution statistics for the query into s.
+//
+// Example:
+//
+//	var stats jsonpath.Stats
+//	results, err := jsonpath.Query(data, "$..price", jsonpath.WithStats(&stats))
+//	metrics.Observe(stats.NodesVisited)
+func WithStats(s *Stats) Option {
+	return func(e *engine) {
+		e.stats = s
+	}
+}
+
+// pathDepth returns the number of segments in a normalized path.
+func pathDepth(path string) int {
+	depth := 0
+	var quote byte
+	for i := 1; i < len(path); i++ {
+		c := path[i]
+		switch {
+		case quote != 0:
+			if c == '\\' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"':
+			quote = c
+		case c == '.' || c == '[':
+			depth++
+		}
+	}
+	return depth
+}
+
+// report writes the collected statistics to the WithStats destination and
+// reports the end of the query, which returns err, to WithMetrics.
+func (e *engine) report(err error) {
+	e.finish(err)
+	e.recordStats()
+}
+
+// recordStats writes the collected statistics to the WithStats destination.
+func (e *engine) recordStats() {
+	if e.stats == nil {
+		return
+	}
+	*e.stats = e.st.stats
+	e.stats.Duration = time.Since(e.st.started)
+}
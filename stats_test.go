@@ -0,0 +1,50 @@
+package jsonpath_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/njchilds90/go-jsonpath"
+)
+
+func TestWithStats(t *testing.T) {
+	var stats jsonpath.Stats
+	results, err := jsonpath.Query(sampleJSON, "$.store.book[?(@.price < 10)].title", jsonpath.WithStats(&stats))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.Matches != len(results) || stats.Matches != 2 {
+		t.Errorf("expected 2 matches, got %d", stats.Matches)
+	}
+	if stats.FiltersEvaluated != 4 {
+		t.Errorf("expected one filter evaluation per book, got %d", stats.FiltersEvaluated)
+	}
+	if stats.MaxDepth != 4 {
+		t.Errorf("expected max depth 4 ($.store.book[i].title), got %d", stats.MaxDepth)
+	}
+	if stats.NodesVisited == 0 || stats.Duration <= 0 {
+		t.Errorf("expected visits and duration to be recorded: %+v", stats)
+	}
+}
+
+func TestWithStatsOnStreamAndError(t *testing.T) {
+	var stats jsonpath.Stats
+	err := jsonpath.QueryFunc(context.Background(), sampleJSON, "$..price", func(jsonpath.Result) error {
+		return nil
+	}, jsonpath.WithStats(&stats))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.Matches != 5 {
+		t.Errorf("expected 5 matches, got %d", stats.Matches)
+	}
+
+	stats = jsonpath.Stats{}
+	_, err = jsonpath.Query(sampleJSON, "$..price", jsonpath.WithMaxNodes(3), jsonpath.WithStats(&stats))
+	if !jsonpath.IsBudgetExceeded(err) {
+		t.Fatalf("expected budget error, got: %v", err)
+	}
+	if stats.NodesVisited != 4 {
+		t.Errorf("expected stats up to the failing visit, got %+v", stats)
+	}
+}
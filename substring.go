@@ -0,0 +1,60 @@
+package jsonpath
+
+// WithStringSlices makes slice selectors apply to strings as well as
+// arrays, matching the substring of the characters they select, as several
+// other implementations do: $.id[0:8] matches the first eight characters of
+// id. Slices count characters, not bytes. A substring is not a node, so its
+// Result has the path of the sliced string, and its Substring field locates
+// the characters selected. Without this option, slices of strings match
+// nothing, as RFC 9535 specifies.
+//
+// Example:
+//
+//	days, err := jsonpath.Values(data, "$.events[*].timestamp[0:10]", jsonpath.WithStringSlices())
+func WithStringSlices() Option {
+	return func(e *engine) {
+		e.stringSlices = true
+	}
+}
+
+// Substring locates the characters a string slice selected: Len characters
+// of the string, from position Start, Step characters apart. Positions count
+// characters, not bytes, and a negative Step selects them backwards.
+type Substring struct {
+	Start int `json:"start"`
+	Step  int `json:"step"`
+	Len   int `json:"len"`
+}
+
+// evalSubstring applies slice to the characters of str.
+func (e *engine) evalSubstring(str string, slice [3]*int, rest []token, currentPath string, emit emitFunc) error {
+	runes := []rune(str)
+	var sub []rune
+	loc := Substring{Step: 1}
+	if slice[2] != nil {
+		loc.Step = *slice[2]
+	}
+	err := eachSliceIndex(slice, len(runes), func(i int) error {
+		if len(sub) == 0 {
+			loc.Start = i
+		}
+		sub = append(sub, runes[i])
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	loc.Len = len(sub)
+	return e.evaluate(string(sub), rest, currentPath, func(r Result) error {
+		if r.Path == currentPath {
+			if r.Substring == nil {
+				r.Substring = &loc
+			} else {
+				// a slice of the substring is a substring of str
+				inner := *r.Substring
+				r.Substring = &Substring{Start: loc.Start + inner.Start*loc.Step, Step: loc.Step * inner.Step, Len: inner.Len}
+			}
+		}
+		return emit(r)
+	})
+}
@@ -0,0 +1,62 @@
+package jsonpath_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/njchilds90/go-jsonpath"
+)
+
+func TestStringSlices(t *testing.T) {
+	data := []byte(`{"id": "9f8e7d6c-5b4a", "at": "2026-03-01T10:00:00Z", "name": "café au lait", "tags": ["alpha", "beta"]}`)
+	tests := []struct {
+		path string
+		want []interface{}
+	}{
+		{"$.id[0:8]", []interface{}{"9f8e7d6c"}},
+		{"$.at[:10]", []interface{}{"2026-03-01"}},
+		{"$.id[-4:]", []interface{}{"5b4a"}},
+		{"$.name[::-1]", []interface{}{"tial ua éfac"}},
+		{"$.name[2:4]", []interface{}{"fé"}},
+		{"$.id[20:30]", []interface{}{""}},
+		{"$.tags[*][0:1]", []interface{}{"a", "b"}},
+		{"$.tags[0:1]", []interface{}{"alpha"}},
+	}
+	for _, tt := range tests {
+		results, err := jsonpath.Query(data, tt.path, jsonpath.WithStringSlices())
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", tt.path, err)
+			continue
+		}
+		if len(results) != len(tt.want) {
+			t.Errorf("%s: expected %v, got %v", tt.path, tt.want, results)
+			continue
+		}
+		for i, r := range results {
+			if r.Value != tt.want[i] {
+				t.Errorf("%s: expected %q, got %q", tt.path, tt.want[i], r.Value)
+			}
+		}
+	}
+
+	r, err := jsonpath.QueryOne(data, "$.tags[1][1:3]", jsonpath.WithStringSlices())
+	if err != nil || r.Path != "$.tags[1]" || r.Substring == nil || *r.Substring != (jsonpath.Substring{Start: 1, Step: 1, Len: 2}) {
+		t.Fatalf("expected the string's path and the substring apart, got %+v (%v)", r, err)
+	}
+	if segs := r.Segments(); len(segs) != 2 || segs[1] != jsonpath.IndexSegment(1) || r.Pointer() != "/tags/1" {
+		t.Errorf("expected the segments and pointer of the string, got %v %s", segs, r.Pointer())
+	}
+	if out, err := json.Marshal(r); err != nil || string(out) != `{"path":"$.tags[1]","substring":{"start":1,"step":1,"len":2},"value":"et"}` {
+		t.Errorf("unexpected JSON %s (%v)", out, err)
+	}
+
+	// slices of substrings compose
+	r, err = jsonpath.QueryOne(data, "$.name[::-1][1:6:2]", jsonpath.WithStringSlices())
+	if err != nil || r.Value != "ilu" || *r.Substring != (jsonpath.Substring{Start: 10, Step: -2, Len: 3}) {
+		t.Errorf("expected characters 10, 8 and 6 of name, got %+v (%v)", r, err)
+	}
+	results, err := jsonpath.Query(data, "$.id[0:8]")
+	if err != nil || len(results) != 0 {
+		t.Errorf("expected no match without WithStringSlices, got %v (%v)", results, err)
+	}
+}
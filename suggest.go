@@ -0,0 +1,154 @@
+package jsonpath
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Suggest returns completions of partial, a path being typed, against the
+// document: every member name or index of the nodes the complete part of
+// partial selects that starts with the trailing fragment. After a complete
+// selector such as [0], every member and index is a completion. Each
+// completion is the whole path, in the order wildcards visit the members,
+// without duplicates:
+//
+//	$.store.bo      → $.store.book
+//	$.store.        → $.store.bicycle, $.store.book
+//	$.store.book[   → $.store.book[0], $.store.book[1], ...
+//	$.store.book[0] → $.store.book[0].author, $.store.book[0].category, ...
+//	$.store['bi     → $.store['bicycle']
+//	$..au           → $..author
+//
+// It powers interactive query builders, and lets agents recover from a path
+// that matched nothing by asking what exists instead.
+//
+// Example:
+//
+//	next, err := jsonpath.Suggest(data, "$.store.bo")
+//	// next == []string{"$.store.book"}
+func Suggest(data []byte, partial string, opts ...Option) ([]string, error) {
+	e := newEngine(context.Background(), opts)
+	doc, err := e.parse(data)
+	if err != nil {
+		return nil, err
+	}
+	prefix, frag, mode := splitPartial(strings.TrimSpace(partial))
+	if prefix == "" {
+		prefix = "$"
+	}
+	cp, err := e.compile(prefix)
+	if err != nil {
+		return nil, err
+	}
+	s := &suggester{seen: map[string]bool{}, prefix: prefix, frag: frag, mode: mode}
+	err = e.evaluate(plainRoot(doc.root), cp.tokens, "$", func(r Result) error {
+		if mode == suggestDescendant {
+			return e.descendantNames(r.Value, 0, s)
+		}
+		s.names(r.Value)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.out, nil
+}
+
+// suggestMode is the kind of selector a partial path ends in.
+type suggestMode int
+
+const (
+	suggestNext       suggestMode = iota // a complete path: any member or index
+	suggestMember                        // .name
+	suggestBracket                       // [' or [ followed by digits
+	suggestDescendant                    // ..name
+)
+
+// splitPartial splits partial into the complete path before its last
+// selector, the fragment typed of that selector so far, and its kind.
+func splitPartial(partial string) (prefix, frag string, mode suggestMode) {
+	if i := strings.LastIndexAny(partial, `'"`); i > 0 && partial[i-1] == '[' && !strings.ContainsAny(partial[i+1:], `'"]`) {
+		return partial[:i-1], partial[i+1:], suggestBracket
+	}
+	j := len(partial)
+	for j > 0 && (isAlphaNum(partial[j-1]) || partial[j-1] == '_' || partial[j-1] == '-') {
+		j--
+	}
+	switch {
+	case j >= 2 && partial[j-2:j] == "..":
+		return partial[:j-2], partial[j:], suggestDescendant
+	case j >= 1 && partial[j-1] == '.':
+		return partial[:j-1], partial[j:], suggestMember
+	case j >= 1 && partial[j-1] == '[':
+		return partial[:j-1], partial[j:], suggestBracket
+	}
+	return partial, "", suggestNext
+}
+
+// suggester collects completions without duplicates.
+type suggester struct {
+	seen   map[string]bool
+	out    []string
+	prefix string
+	frag   string
+	mode   suggestMode
+}
+
+func (s *suggester) add(sel string) {
+	if p := s.prefix + sel; !s.seen[p] {
+		s.seen[p] = true
+		s.out = append(s.out, p)
+	}
+}
+
+// names adds the completions naming the members or elements of node.
+func (s *suggester) names(node interface{}) {
+	if obj, ok := objectOf(node); ok {
+		for _, k := range obj.Keys() {
+			if !strings.HasPrefix(k, s.frag) {
+				continue
+			}
+			switch s.mode {
+			case suggestBracket:
+				s.add("[" + quoteKey(k) + "]")
+			case suggestDescendant:
+				s.add(".." + strings.TrimPrefix(formatMember(k), "."))
+			default:
+				s.add(formatMember(k))
+			}
+		}
+		return
+	}
+	if arr, ok := arrayOf(node); ok && (s.mode == suggestNext || s.mode == suggestBracket) {
+		for i := 0; i < arr.Len(); i++ {
+			if idx := strconv.Itoa(i); strings.HasPrefix(idx, s.frag) {
+				s.add("[" + idx + "]")
+			}
+		}
+	}
+}
+
+// descendantNames adds the member names of node and of every node below it.
+func (e *engine) descendantNames(node interface{}, depth int, s *suggester) error {
+	if e.maxDepth > 0 && depth > e.maxDepth {
+		return &Error{Code: ErrMaxDepthExceeded, Message: fmt.Sprintf("max depth %d exceeded", e.maxDepth)}
+	}
+	s.names(node)
+	if obj, ok := objectOf(node); ok {
+		for _, k := range obj.Keys() {
+			v, _ := obj.Get(k)
+			if err := e.descendantNames(v, depth+1, s); err != nil {
+				return err
+			}
+		}
+	} else if arr, ok := arrayOf(node); ok {
+		for i := 0; i < arr.Len(); i++ {
+			if err := e.descendantNames(arr.Index(i), depth+1, s); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
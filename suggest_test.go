@@ -0,0 +1,44 @@
+package jsonpath_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/njchilds90/go-jsonpath"
+)
+
+func TestSuggest(t *testing.T) {
+	tests := []struct {
+		partial string
+		want    []string
+	}{
+		{"$.store.bo", []string{"$.store.book"}},
+		{"$.store.", []string{"$.store.bicycle", "$.store.book"}},
+		{"$.store.book", []string{"$.store.book"}},
+		{"$.store.book[", []string{"$.store.book[0]", "$.store.book[1]", "$.store.book[2]", "$.store.book[3]"}},
+		{"$.store.bicycle", []string{"$.store.bicycle"}},
+		{"$.store.book[0]", []string{"$.store.book[0].author", "$.store.book[0].category", "$.store.book[0].price", "$.store.book[0].title"}},
+		{"$.store.book[3", []string{"$.store.book[3]"}},
+		{"$.store['bi", []string{"$.store['bicycle']"}},
+		{"$.store.book[*].i", []string{"$.store.book[*].isbn"}},
+		{"$..pr", []string{"$..price"}},
+		{"", []string{"$.expensive", "$.store"}},
+		{"$.store.x", nil},
+	}
+	for _, tt := range tests {
+		got, err := jsonpath.Suggest(sampleJSON, tt.partial)
+		if err != nil {
+			t.Fatalf("%q: %v", tt.partial, err)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%q: expected %v, got %v", tt.partial, tt.want, got)
+		}
+	}
+
+	if _, err := jsonpath.Suggest(sampleJSON, "$.store[?(@.p"); err == nil {
+		t.Error("expected an error for an unfinished filter")
+	}
+	if _, err := jsonpath.Suggest(sampleJSON, "$..", jsonpath.WithMaxDepth(2)); err == nil {
+		t.Error("expected the depth limit to apply")
+	}
+}
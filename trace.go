@@ -0,0 +1,120 @@
+package jsonpath
+
+import (
+	"fmt"
+	"strings"
+)
+
+// TraceAction says what a TraceStep records.
+type TraceAction string
+
+const (
+	// TraceApply records a selector applied to a node.
+	TraceApply TraceAction = "apply"
+	// TraceReject records a candidate node, or a node a selector could not
+	// be applied to, dropped for the step's Reason.
+	TraceReject TraceAction = "reject"
+	// TraceMatch records a node that became a result.
+	TraceMatch TraceAction = "match"
+)
+
+// TraceStep is one step of the evaluation recorded by Trace.
+type TraceStep struct {
+	Action TraceAction `json:"action"`
+	// Path is the normalized path of the node the step concerns.
+	Path string `json:"path"`
+	// Selector is the selector applied or that rejected the node, in path
+	// syntax such as ".book" or "[?(@.price < 10)]". It is empty for matches.
+	Selector string `json:"selector,omitempty"`
+	// Reason explains a rejection, such as "filter is false",
+	// "type mismatch: expected object, got array" or "key 'isbn' not found".
+	Reason string `json:"reason,omitempty"`
+}
+
+// TraceReport is the record Trace returns: the results of the query and the
+// steps that led to them, in evaluation order.
+type TraceReport struct {
+	Path    string      `json:"path"`
+	Steps   []TraceStep `json:"steps"`
+	Results []Result    `json:"results"`
+}
+
+// String renders the steps one per line, indented by the depth of the node,
+// for reading in a terminal or a test failure.
+func (t *TraceReport) String() string {
+	var b strings.Builder
+	for _, s := range t.Steps {
+		b.WriteString(strings.Repeat("  ", pathDepth(s.Path)))
+		switch s.Action {
+		case TraceApply:
+			fmt.Fprintf(&b, "%s: apply %s\n", s.Path, s.Selector)
+		case TraceReject:
+			fmt.Fprintf(&b, "%s: rejected by %s: %s\n", s.Path, s.Selector, s.Reason)
+		case TraceMatch:
+			fmt.Fprintf(&b, "%s: match\n", s.Path)
+		}
+	}
+	return b.String()
+}
+
+// Trace evaluates path against data like Query and records, step by step,
+// which selectors were applied to which nodes and why candidates were
+// rejected: a filter that was false, a missing key, an index out of bounds
+// or a node of the wrong type. It is meant for authoring and debugging
+// paths; recording every step makes it slower than Query. The report is
+// returned with the steps recorded so far even when the query fails.
+//
+// Example:
+//
+//	report, err := jsonpath.Trace(data, "$.items[?(@.price < 10 && @.stock > 0)].name")
+//	fmt.Print(report)
+//	// $.items[1]: rejected by [?(@.price < 10 && @.stock > 0)]: filter is false
+func Trace(data []byte, path string, opts ...Option) (*TraceReport, error) {
+	report := &TraceReport{Path: path}
+	opts = append(opts[:len(opts):len(opts)], func(e *engine) {
+		e.tracer = func(s TraceStep) {
+			report.Steps = append(report.Steps, s)
+		}
+	})
+	results, err := Query(data, path, opts...)
+	report.Results = results
+	return report, err
+}
+
+// traceReject records that the node at path was rejected by selector.
+func (e *engine) traceReject(path, selector, format string, args ...interface{}) {
+	e.tracer(TraceStep{Action: TraceReject, Path: path, Selector: selector, Reason: fmt.Sprintf(format, args...)})
+}
+
+// traceMismatch records that selector, which expects want, cannot be applied
+// to node.
+func (e *engine) traceMismatch(path, selector, want string, node interface{}) {
+	e.traceReject(path, selector, "type mismatch: expected %s, got %s", want, kindOf(node))
+}
+
+// traceApply records that tok is applied to the node at path, and that the
+// node is rejected when the selector cannot apply to its type.
+func (e *engine) traceApply(path string, tok token, node interface{}) {
+	selector := tok.String()
+	e.tracer(TraceStep{Action: TraceApply, Path: path, Selector: selector})
+	_, isObject := objectOf(node)
+	_, isArray := arrayOf(node)
+	switch {
+	case tok.kind == tokenChild, tok.kind == tokenPattern, tok.kind == tokenUnion && len(tok.indices) == 0:
+		if !isObject {
+			e.traceMismatch(path, selector, "object", node)
+		}
+	case tok.kind == tokenSlice && e.stringSlices:
+		if _, isString := node.(string); !isArray && !isString {
+			e.traceMismatch(path, selector, "array or string", node)
+		}
+	case tok.kind == tokenIndex, tok.kind == tokenSlice, tok.kind == tokenUnion:
+		if !isArray {
+			e.traceMismatch(path, selector, "array", node)
+		}
+	case tok.kind == tokenWildcard, tok.kind == tokenFilter:
+		if !isObject && !isArray {
+			e.traceMismatch(path, selector, "object or array", node)
+		}
+	}
+}
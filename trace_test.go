@@ -0,0 +1,95 @@
+package jsonpath_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/njchilds90/go-jsonpath"
+)
+
+func TestTrace(t *testing.T) {
+	report, err := jsonpath.Trace(sampleJSON, "$.store.book[?(@.price < 9)].isbn")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(report.Results) != 1 {
+		t.Fatalf("expected one result, got %v", report.Results)
+	}
+	want := []jsonpath.TraceStep{
+		{Action: jsonpath.TraceApply, Path: "$", Selector: ".store"},
+		{Action: jsonpath.TraceApply, Path: "$.store", Selector: ".book"},
+		{Action: jsonpath.TraceApply, Path: "$.store.book", Selector: "[?(@.price < 9)]"},
+		{Action: jsonpath.TraceApply, Path: "$.store.book[0]", Selector: ".isbn"},
+		{Action: jsonpath.TraceReject, Path: "$.store.book[0]", Selector: ".isbn", Reason: "key 'isbn' not found"},
+		{Action: jsonpath.TraceReject, Path: "$.store.book[1]", Selector: "[?(@.price < 9)]", Reason: "filter is false"},
+		{Action: jsonpath.TraceApply, Path: "$.store.book[2]", Selector: ".isbn"},
+		{Action: jsonpath.TraceMatch, Path: "$.store.book[2].isbn"},
+		{Action: jsonpath.TraceReject, Path: "$.store.book[3]", Selector: "[?(@.price < 9)]", Reason: "filter is false"},
+	}
+	if len(report.Steps) != len(want) {
+		t.Fatalf("expected %d steps, got:\n%s", len(want), report)
+	}
+	for i := range want {
+		if report.Steps[i] != want[i] {
+			t.Errorf("step %d: expected %+v, got %+v", i, want[i], report.Steps[i])
+		}
+	}
+	if !strings.Contains(report.String(), "      $.store.book[1]: rejected by [?(@.price < 9)]: filter is false\n") {
+		t.Errorf("unexpected rendering:\n%s", report)
+	}
+}
+
+func TestTraceRejections(t *testing.T) {
+	tests := []struct {
+		path   string
+		reason string
+	}{
+		{"$.store.book.title", "type mismatch: expected object, got array"},
+		{"$.store.book[7]", "index 7 out of bounds (length 4)"},
+		{"$.store.bicycle[0]", "type mismatch: expected array, got object"},
+		{"$.store.book[0:2]", ""},
+		{"$.store.bicycle.color[*]", "type mismatch: expected object or array, got string"},
+		{"$.store.bicycle['color','size']", "key 'size' not found"},
+		{"$.store.book[0,9]", "index 9 out of bounds (length 4)"},
+	}
+	for _, tt := range tests {
+		report, err := jsonpath.Trace(sampleJSON, tt.path)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", tt.path, err)
+		}
+		var reasons []string
+		for _, s := range report.Steps {
+			if s.Action == jsonpath.TraceReject {
+				reasons = append(reasons, s.Reason)
+			}
+		}
+		if tt.reason == "" {
+			if len(reasons) != 0 {
+				t.Errorf("%s: expected no rejections, got %q", tt.path, reasons)
+			}
+		} else if len(reasons) != 1 || reasons[0] != tt.reason {
+			t.Errorf("%s: expected rejection %q, got %q", tt.path, tt.reason, reasons)
+		}
+	}
+
+	report, err := jsonpath.Trace(sampleJSON, "$..price", jsonpath.WithMaxNodes(3))
+	if !jsonpath.IsBudgetExceeded(err) || report == nil || len(report.Steps) == 0 {
+		t.Fatalf("expected a partial report and a budget error, got %v and %v", report, err)
+	}
+}
+
+func TestTraceMatches(t *testing.T) {
+	report, err := jsonpath.Trace(sampleJSON, "$.store.book[1,2].author")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var matches []string
+	for _, s := range report.Steps {
+		if s.Action == jsonpath.TraceMatch {
+			matches = append(matches, s.Path)
+		}
+	}
+	if len(matches) != 2 || matches[0] != "$.store.book[1].author" || matches[1] != "$.store.book[2].author" || len(report.Results) != 2 {
+		t.Errorf("expected two matches, got %v and %v", matches, report.Results)
+	}
+}
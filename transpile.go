@@ -0,0 +1,614 @@
+package jsonpath
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Target is a query language that Transpile can translate paths to.
+type Target int
+
+const (
+	// TargetPostgres is the SQL/JSON path language of PostgreSQL's
+	// jsonb_path_query, jsonb_path_exists and the @? and @@ operators.
+	TargetPostgres Target = iota + 1
+	// TargetMySQL is the path syntax of MySQL's JSON_EXTRACT and related
+	// functions, for singular paths.
+	TargetMySQL
+	// TargetSQLite is the path syntax of SQLite's json_extract and ->>
+	// operator, for singular paths.
+	TargetSQLite
+	// TargetJQ is a jq filter.
+	TargetJQ
+)
+
+var targetNames = map[Target]string{
+	TargetPostgres: "PostgreSQL",
+	TargetMySQL:    "MySQL",
+	TargetSQLite:   "SQLite",
+	TargetJQ:       "jq",
+}
+
+// String returns the name of the target.
+func (t Target) String() string {
+	if name, ok := targetNames[t]; ok {
+		return name
+	}
+	return "Target(" + strconv.Itoa(int(t)) + ")"
+}
+
+// Transpile translates a JSONPath expression to the path language of target,
+// so that a path validated with this package can be pushed down to a
+// database or tool. Constructs the target cannot express are all reported
+// in a single ErrUnsupported error.
+//
+// For TargetPostgres the result is an SQL/JSON path. Wildcards become .**{1},
+// which selects the members of objects and the elements of arrays alike,
+// and unions of member names become a .keyvalue() filter. Paths that use
+// recursive descent are emitted in strict mode, where .** does not return
+// duplicates; all others use the default lax mode. Comparisons follow
+// PostgreSQL's rules, so values of different types never compare equal.
+// Lax mode unwraps arrays where a member is accessed and treats other values
+// as one-element arrays where an index is applied, so the expression can
+// match more than the path does here: $.a.b also selects the b members of
+// objects in an array a, and $.a[0] selects a itself when a is an object.
+// Prefix the result with "strict " to stop that, at the cost of making
+// missing members errors, which jsonb_path_query's silent argument hides.
+//
+// TargetMySQL and TargetSQLite take singular paths only — member names and
+// indices — since JSON_EXTRACT and json_extract return a single value.
+// Negative indices become [last-N] for MySQL (8.0.2 and later) and [#-N] for
+// SQLite (3.31 and later).
+//
+// For TargetJQ the result is a jq filter producing the matched values in
+// turn. Member and index selectors that follow a wildcard, slice, filter or
+// descendant step skip values that lack them, as the query would; on the
+// leading singular part of a path a missing member yields null, as usual in
+// jq. Paths in filters use optional access, so that elements of another type
+// fail the filter instead of aborting jq. Regular expressions are passed to
+// test() and may only use the i flag.
+//
+// Example:
+//
+//	expr, err := jsonpath.Transpile("$.items[?(@.price < 10)].sku", jsonpath.TargetPostgres)
+//	// expr == `$."items".**{1} ? (@."price" < 10)."sku"`
+//	rows, err := db.Query(`SELECT jsonb_path_query(doc, $1, '{}', true) FROM orders`, expr)
+func Transpile(path string, target Target) (string, error) {
+	tokens, err := tokenize(path)
+	if err != nil {
+		return "", err
+	}
+	if len(tokens) > 1 && tokens[1].kind == tokenAlternatives {
+		return "", &Error{Code: ErrUnsupported, Message: fmt.Sprintf("cannot transpile %s to %s: alternatives", path, target)}
+	}
+	if len(tokens) > 1 && tokens[1].kind == tokenDefault {
+		return "", &Error{Code: ErrUnsupported, Message: fmt.Sprintf("cannot transpile %s to %s: default values", path, target)}
+	}
+	t := &transpiler{target: target}
+	var out string
+	switch target {
+	case TargetPostgres:
+		out = t.postgresPath("$", tokens[1:])
+		if hasDescendant(tokens) {
+			out = "strict " + out
+		}
+	case TargetMySQL, TargetSQLite:
+		out = t.sqlPath(tokens[1:])
+	case TargetJQ:
+		out = t.jqPath(tokens[1:], false)
+	default:
+		return "", &Error{Code: ErrInvalidInput, Message: fmt.Sprintf("unknown transpile target %s", target)}
+	}
+	if len(t.unsupported) > 0 {
+		return "", &Error{Code: ErrUnsupported, Message: fmt.Sprintf("cannot transpile %s to %s: %s", path, target, strings.Join(t.unsupported, "; "))}
+	}
+	return out, nil
+}
+
+// transpiler collects the constructs of a path that its target lacks, so
+// they can be reported together.
+type transpiler struct {
+	target      Target
+	unsupported []string
+}
+
+func (t *transpiler) fail(format string, args ...interface{}) {
+	t.unsupported = append(t.unsupported, fmt.Sprintf(format, args...))
+}
+
+// hasDescendant reports whether tokens, or the paths in their filters, use
+// recursive descent.
+func hasDescendant(tokens []token) bool {
+	for _, tok := range tokens {
+		if tok.kind == tokenRecursive || (tok.kind == tokenFilter && filterHasDescendant(tok.expr)) {
+			return true
+		}
+	}
+	return false
+}
+
+func filterHasDescendant(expr filterExpr) bool {
+	switch x := expr.(type) {
+	case *logicalExpr:
+		return filterHasDescendant(x.left) || filterHasDescendant(x.right)
+	case *compareExpr:
+		return operandHasDescendant(x.left) || operandHasDescendant(x.right)
+	case *regexExpr:
+		return operandHasDescendant(x.left)
+	case *existsExpr:
+		return operandHasDescendant(x.operand)
+	}
+	return false
+}
+
+func operandHasDescendant(op operand) bool {
+	switch x := op.(type) {
+	case *pathOperand:
+		return hasDescendant(x.tokens)
+	case *callOperand:
+		for _, a := range x.args {
+			if operandHasDescendant(a) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// jsonString returns s as a JSON string literal, which is also a valid
+// string literal in the SQL/JSON path language and in jq.
+func jsonString(s string) string {
+	var b strings.Builder
+	enc := json.NewEncoder(&b)
+	enc.SetEscapeHTML(false)
+	enc.Encode(s)
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+// jsonLiteral returns a filter literal in JSON syntax.
+func jsonLiteral(v interface{}) string {
+	switch x := v.(type) {
+	case string:
+		return jsonString(x)
+	case float64:
+		return strconv.FormatFloat(x, 'g', -1, 64)
+	case bool:
+		return strconv.FormatBool(x)
+	}
+	return "null"
+}
+
+// --- PostgreSQL ---
+
+// postgresPath translates tokens to SQL/JSON path steps after root, which
+// is "$" or "@".
+func (t *transpiler) postgresPath(root string, tokens []token) string {
+	var b strings.Builder
+	b.WriteString(root)
+	for _, tok := range tokens {
+		switch tok.kind {
+		case tokenChild:
+			b.WriteString("." + jsonString(tok.key))
+		case tokenRecursive:
+			b.WriteString(".**")
+		case tokenWildcard:
+			b.WriteString(".**{1}")
+		case tokenIndex:
+			b.WriteString("[" + postgresIndex(tok.index) + "]")
+		case tokenSlice:
+			b.WriteString(t.postgresSlice(tok))
+		case tokenUnion:
+			if len(tok.keys) > 0 {
+				conds := make([]string, len(tok.keys))
+				for i, k := range tok.keys {
+					conds[i] = "@.key == " + jsonString(k)
+				}
+				b.WriteString(".keyvalue() ? (" + strings.Join(conds, " || ") + ").value")
+				continue
+			}
+			idx := make([]string, len(tok.indices))
+			for i, n := range tok.indices {
+				idx[i] = postgresIndex(n)
+			}
+			b.WriteString("[" + strings.Join(idx, ", ") + "]")
+		case tokenFilter:
+			b.WriteString(".**{1} ? (" + t.postgresFilter(tok.expr, false) + ")")
+		case tokenFunction:
+			t.fail("function %s, which SQL/JSON path only has for arrays", tok)
+		case tokenParent:
+			t.fail("parent selector ^")
+		case tokenNames:
+			b.WriteString(".keyvalue().key")
+		case tokenPattern:
+			b.WriteString(".keyvalue() ? (" + postgresRegex("@.key", tok.expr.(*regexExpr)) + ").value")
+		}
+	}
+	return b.String()
+}
+
+// postgresIndex writes negative indices relative to the last element.
+func postgresIndex(n int) string {
+	switch {
+	case n >= 0:
+		return strconv.Itoa(n)
+	case n == -1:
+		return "last"
+	}
+	return "last-" + strconv.Itoa(-n-1)
+}
+
+func (t *transpiler) postgresSlice(tok token) string {
+	start, end, step := tok.slice[0], tok.slice[1], tok.slice[2]
+	if step != nil && *step != 1 {
+		t.fail("slice step %d", *step)
+		return ""
+	}
+	from, to := "0", "last"
+	if start != nil {
+		from = postgresIndex(*start)
+	}
+	if end != nil {
+		switch {
+		case *end > 0:
+			to = strconv.Itoa(*end - 1)
+		case *end < 0:
+			to = "last-" + strconv.Itoa(-*end)
+		default:
+			t.fail("slice %s, which is always empty", tok)
+			return ""
+		}
+	}
+	return "[" + from + " to " + to + "]"
+}
+
+// postgresFilter translates a filter expression. Nested logical expressions
+// are parenthesized when nested is set.
+func (t *transpiler) postgresFilter(expr filterExpr, nested bool) string {
+	switch x := expr.(type) {
+	case *logicalExpr:
+		s := t.postgresFilter(x.left, true) + " " + x.op + " " + t.postgresFilter(x.right, true)
+		if nested {
+			s = "(" + s + ")"
+		}
+		return s
+	case *compareExpr:
+		return t.postgresOperand(x.left) + " " + x.op + " " + t.postgresOperand(x.right)
+	case *regexExpr:
+		return postgresRegex(t.postgresOperand(x.left), x)
+	case *existsExpr:
+		return t.postgresOperand(x.operand) + " != null"
+	}
+	return ""
+}
+
+// postgresRegex matches the SQL/JSON path expression left against x.
+func postgresRegex(left string, x *regexExpr) string {
+	s := left + " like_regex " + jsonString(x.pattern)
+	if flags := regexFlags(x.flags, "ims"); flags != "" {
+		s += " flag " + jsonString(flags)
+	}
+	return s
+}
+
+func (t *transpiler) postgresOperand(op operand) string {
+	switch x := op.(type) {
+	case *pathOperand:
+		return t.postgresPath("@", x.tokens[1:])
+	case *literalOperand:
+		return jsonLiteral(x.value)
+	case *callOperand:
+		if x.name == "type" && len(x.args) == 1 {
+			return t.postgresOperand(x.args[0]) + ".type()"
+		}
+		t.fail("function %s()", x.name)
+	case *paramOperand:
+		t.fail("unbound parameter :%s", x.name)
+	}
+	return ""
+}
+
+// regexFlags keeps the regex flags the target understands, in the order
+// given, and drops the rest as the evaluator does.
+func regexFlags(flags, supported string) string {
+	var b strings.Builder
+	for _, f := range flags {
+		if strings.ContainsRune(supported, f) {
+			b.WriteRune(f)
+		}
+	}
+	return b.String()
+}
+
+// --- MySQL and SQLite ---
+
+// sqlPath translates a singular path to MySQL or SQLite syntax, which differ
+// only in how they count from the end of an array.
+func (t *transpiler) sqlPath(tokens []token) string {
+	var b strings.Builder
+	b.WriteString("$")
+	for _, tok := range tokens {
+		switch {
+		case tok.kind == tokenChild && t.target == TargetMySQL:
+			b.WriteString("." + jsonString(tok.key))
+		case tok.kind == tokenChild && strings.ContainsAny(tok.key, `"\`):
+			t.fail("member name %q, which contains a quote or backslash", tok.key)
+		case tok.kind == tokenChild:
+			b.WriteString(`."` + tok.key + `"`)
+		case tok.kind == tokenIndex && tok.index >= 0:
+			b.WriteString("[" + strconv.Itoa(tok.index) + "]")
+		case tok.kind == tokenIndex && t.target == TargetMySQL:
+			b.WriteString("[" + postgresIndex(tok.index) + "]")
+		case tok.kind == tokenIndex:
+			b.WriteString("[#" + strconv.Itoa(tok.index) + "]")
+		case tok.kind == tokenFunction:
+			t.fail("function %s", tok)
+		case tok.kind == tokenParent:
+			t.fail("parent selector ^")
+		case tok.kind == tokenNames:
+			t.fail("member names selector ~")
+		case tok.kind == tokenPattern:
+			t.fail("member name pattern %s, which can select more than one value", tok)
+		default:
+			t.fail("%s selector %s, which can select more than one value", tok.kind, tok)
+		}
+	}
+	return b.String()
+}
+
+// --- jq ---
+
+// jqPipe builds a jq pipeline, appending postfix terms such as .key and
+// [0] to the current stage.
+type jqPipe struct {
+	stages []string
+	cur    string
+}
+
+// postfix appends a term starting with '.' or '['.
+func (p *jqPipe) postfix(term string) {
+	if p.cur == ".." {
+		p.pipe("")
+	}
+	if p.cur == "" && term[0] == '[' {
+		term = "." + term
+	}
+	p.cur += term
+}
+
+// pipe starts a new stage.
+func (p *jqPipe) pipe(stage string) {
+	if p.cur != "" {
+		p.stages = append(p.stages, p.cur)
+	}
+	p.cur = stage
+}
+
+func (p *jqPipe) String() string {
+	stages := p.stages
+	if p.cur != "" {
+		stages = append(stages, p.cur)
+	}
+	if len(stages) == 0 {
+		return "."
+	}
+	return strings.Join(stages, " | ")
+}
+
+// jqPath translates tokens to a jq filter relative to the input. Once a
+// step can select several values, later members and indices are selected
+// so that values lacking them produce nothing rather than null. When
+// optional is set, as for filter operands, the leading members and indices
+// are optional too, so that values of another type produce nothing rather
+// than an error.
+func (t *transpiler) jqPath(tokens []token, optional bool) string {
+	var p jqPipe
+	multi := false
+	suffix := ""
+	if optional {
+		suffix = "?"
+	}
+	for _, tok := range tokens {
+		switch tok.kind {
+		case tokenChild:
+			member := jqMember(tok.key)
+			if multi {
+				p.pipe("objects")
+				p.pipe("select(has(" + jsonString(tok.key) + "))")
+				p.pipe("")
+			} else {
+				member += suffix
+			}
+			p.postfix(member)
+		case tokenRecursive:
+			p.pipe("..")
+			multi = true
+		case tokenWildcard:
+			p.postfix("[]?")
+			multi = true
+		case tokenIndex:
+			if multi {
+				p.pipe("arrays")
+				p.pipe(jqElement(tok.index))
+			} else {
+				p.postfix("[" + strconv.Itoa(tok.index) + "]" + suffix)
+			}
+		case tokenSlice:
+			p.pipe("arrays")
+			p.pipe(t.jqSlice(tok))
+			multi = true
+		case tokenUnion:
+			parts := make([]string, 0, len(tok.keys)+len(tok.indices))
+			if len(tok.keys) > 0 {
+				p.pipe("objects")
+				for _, k := range tok.keys {
+					parts = append(parts, "select(has("+jsonString(k)+"))"+jqMember(k))
+				}
+			} else {
+				p.pipe("arrays")
+				for _, n := range tok.indices {
+					parts = append(parts, jqElement(n))
+				}
+			}
+			p.pipe("(" + strings.Join(parts, ", ") + ")")
+			multi = true
+		case tokenFilter:
+			p.postfix("[]?")
+			p.pipe("select(" + t.jqFilter(tok.expr, false) + ")")
+			multi = true
+		case tokenFunction:
+			// jq's length also measures numbers and null
+			p.pipe(`select(type == "array" or type == "object" or type == "string")`)
+			p.pipe("length")
+		case tokenParent:
+			t.fail("parent selector ^")
+		case tokenNames:
+			p.pipe("objects")
+			p.pipe("keys[]")
+		case tokenPattern:
+			p.pipe("objects")
+			p.pipe("to_entries[]")
+			p.pipe("select(.key | " + t.jqTest(tok.expr.(*regexExpr)) + ")")
+			p.pipe(".value")
+			multi = true
+		}
+	}
+	return p.String()
+}
+
+// jqMember returns the postfix term selecting member key.
+func jqMember(key string) string {
+	if isJQIdentifier(key) {
+		return "." + key
+	}
+	return "[" + jsonString(key) + "]"
+}
+
+func isJQIdentifier(s string) bool {
+	if s == "" || (s[0] >= '0' && s[0] <= '9') {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		if c := s[i]; !isAlphaNum(c) && c != '_' {
+			return false
+		}
+	}
+	return true
+}
+
+// jqElement selects element n of an array, producing nothing when it is out
+// of range.
+func jqElement(n int) string {
+	if n == -1 {
+		return ".[-1:][]"
+	}
+	return ".[" + strconv.Itoa(n) + ":" + strconv.Itoa(n+1) + "][]"
+}
+
+func (t *transpiler) jqSlice(tok token) string {
+	var bounds [2]string
+	for i, b := range tok.slice[:2] {
+		if b != nil {
+			bounds[i] = strconv.Itoa(*b)
+		}
+	}
+	s := ".[" + bounds[0] + ":" + bounds[1] + "]"
+	if s == ".[:]" {
+		s = "."
+	}
+	switch step := tok.slice[2]; {
+	case step == nil || *step == 1:
+		if s == "." {
+			return ".[]"
+		}
+		return s + "[]"
+	case *step > 1:
+		every := "to_entries[] | select(.key % " + strconv.Itoa(*step) + " == 0).value"
+		if s == "." {
+			return every
+		}
+		return s + " | " + every
+	default:
+		t.fail("slice step %d", *step)
+		return s
+	}
+}
+
+// jqFilter translates a filter expression to a jq condition. Nested logical
+// expressions are parenthesized when nested is set.
+func (t *transpiler) jqFilter(expr filterExpr, nested bool) string {
+	var s string
+	switch x := expr.(type) {
+	case *logicalExpr:
+		op := "and"
+		if x.op == "||" {
+			op = "or"
+		}
+		s = t.jqFilter(x.left, true) + " " + op + " " + t.jqFilter(x.right, true)
+	case *compareExpr:
+		// a missing operand fails the comparison, where jq would compare null
+		var terms []string
+		if !isNullLiteral(x.left) && !isNullLiteral(x.right) {
+			for _, op := range []operand{x.left, x.right} {
+				if _, ok := op.(*pathOperand); ok {
+					terms = append(terms, t.jqOperand(op)+" != null")
+				}
+			}
+		}
+		cmp := t.jqOperand(x.left) + " " + x.op + " " + t.jqOperand(x.right)
+		if len(terms) == 0 {
+			return cmp
+		}
+		s = strings.Join(append(terms, cmp), " and ")
+	case *regexExpr:
+		s = t.jqOperand(x.left) + " | type == \"string\" and " + t.jqTest(x)
+	case *existsExpr:
+		return t.jqOperand(x.operand) + " != null"
+	}
+	if nested {
+		s = "(" + s + ")"
+	}
+	return s
+}
+
+// jqTest returns the jq call testing its input against x.
+func (t *transpiler) jqTest(x *regexExpr) string {
+	if flags := strings.Trim(x.flags, "i"); flags != "" {
+		t.fail("regex flags %q", flags)
+	}
+	s := "test(" + jsonString(x.pattern)
+	if strings.Contains(x.flags, "i") {
+		s += "; \"i\""
+	}
+	return s + ")"
+}
+
+func (t *transpiler) jqOperand(op operand) string {
+	switch x := op.(type) {
+	case *pathOperand:
+		s := t.jqPath(x.tokens[1:], true)
+		for _, tok := range x.tokens[1:] {
+			if tok.kind != tokenChild && tok.kind != tokenIndex {
+				// like the evaluator, compare the first match only
+				return "first(" + s + ")"
+			}
+		}
+		return s
+	case *literalOperand:
+		return jsonLiteral(x.value)
+	case *callOperand:
+		if x.name == "type" && len(x.args) == 1 {
+			return "(" + t.jqOperand(x.args[0]) + " | type)"
+		}
+		t.fail("function %s()", x.name)
+	case *paramOperand:
+		t.fail("unbound parameter :%s", x.name)
+	}
+	return ""
+}
+
+func isNullLiteral(op operand) bool {
+	lit, ok := op.(*literalOperand)
+	return ok && lit.value == nil
+}
@@ -0,0 +1,161 @@
+package jsonpath_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/njchilds90/go-jsonpath"
+)
+
+func TestTranspilePostgres(t *testing.T) {
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"$", "$"},
+		{"$.store.book[0].title", `$."store"."book"[0]."title"`},
+		{"$['first name']", `$."first name"`},
+		{"$.items[*].id", `$."items".**{1}."id"`},
+		{"$.items[-1]", `$."items"[last]`},
+		{"$.items[-3]", `$."items"[last-2]`},
+		{"$.items[0,2,-1]", `$."items"[0, 2, last]`},
+		{"$.items[1:3]", `$."items"[1 to 2]`},
+		{"$.items[:2]", `$."items"[0 to 1]`},
+		{"$.items[-2:]", `$."items"[last-1 to last]`},
+		{"$.items[:-1]", `$."items"[0 to last-1]`},
+		{"$['a','b']", `$.keyvalue() ? (@.key == "a" || @.key == "b").value`},
+		{"$..author", `strict $.**."author"`},
+		{"$..*", `strict $.**.**{1}`},
+		{"$.items[?(@.price < 10)].sku", `$."items".**{1} ? (@."price" < 10)."sku"`},
+		{"$[?(@.isbn)]", `$.**{1} ? (@."isbn" != null)`},
+		{"$[?(@.a == 'x' && (@.b > 1 || @.c == true))]", `$.**{1} ? (@."a" == "x" && (@."b" > 1 || @."c" == true))`},
+		{"$[?(@.name =~ /^ab/i)]", `$.**{1} ? (@."name" like_regex "^ab" flag "i")`},
+		{"$[?(@.tag == null)]", `$.**{1} ? (@."tag" == null)`},
+	}
+	for _, tt := range tests {
+		got, err := jsonpath.Transpile(tt.path, jsonpath.TargetPostgres)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", tt.path, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("%s:\n  expected %s\n       got %s", tt.path, tt.want, got)
+		}
+	}
+}
+
+func TestTranspilePostgresLax(t *testing.T) {
+	// PostgreSQL's lax mode unwraps a, yielding 1, where the path matches nothing
+	data := []byte(`{"a": [{"b": 1}]}`)
+	if results, err := jsonpath.Query(data, "$.a.b"); err != nil || len(results) != 0 {
+		t.Fatalf("expected no matches, got %v %v", results, err)
+	}
+	if got, err := jsonpath.Transpile("$.a.b", jsonpath.TargetPostgres); err != nil || got != `$."a"."b"` {
+		t.Errorf("expected a lax mode expression, got %s %v", got, err)
+	}
+}
+
+func TestTranspileUnsupported(t *testing.T) {
+	_, err := jsonpath.Transpile("$.items[0:10:2][?(isActive(@))]", jsonpath.TargetPostgres)
+	if !jsonpath.IsUnsupported(err) {
+		t.Fatalf("expected unsupported error, got: %v", err)
+	}
+	for _, want := range []string{"slice step 2", "function isActive()"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("expected %q in error: %v", want, err)
+		}
+	}
+
+	if _, err := jsonpath.Transpile("$.a[", jsonpath.TargetPostgres); !jsonpath.IsPathError(err) {
+		t.Errorf("expected path error, got: %v", err)
+	}
+	if _, err := jsonpath.Transpile("$.a", jsonpath.Target(99)); err == nil {
+		t.Error("expected error for unknown target")
+	}
+}
+
+func TestTranspileSQL(t *testing.T) {
+	tests := []struct {
+		path   string
+		mysql  string
+		sqlite string
+	}{
+		{"$", "$", "$"},
+		{"$.store.book[0].title", `$."store"."book"[0]."title"`, `$."store"."book"[0]."title"`},
+		{"$['a.b'][2]", `$."a.b"[2]`, `$."a.b"[2]`},
+		{"$.items[-1]", `$."items"[last]`, `$."items"[#-1]`},
+		{"$.items[-2].id", `$."items"[last-1]."id"`, `$."items"[#-2]."id"`},
+	}
+	for _, tt := range tests {
+		for target, want := range map[jsonpath.Target]string{jsonpath.TargetMySQL: tt.mysql, jsonpath.TargetSQLite: tt.sqlite} {
+			got, err := jsonpath.Transpile(tt.path, target)
+			if err != nil {
+				t.Errorf("%s (%s): unexpected error: %v", tt.path, target, err)
+				continue
+			}
+			if got != want {
+				t.Errorf("%s (%s): expected %s, got %s", tt.path, target, want, got)
+			}
+		}
+	}
+
+	for _, path := range []string{"$.items[*]", "$..id", "$.items[0:2]", "$.items[0,1]", "$[?(@.id)]"} {
+		for _, target := range []jsonpath.Target{jsonpath.TargetMySQL, jsonpath.TargetSQLite} {
+			if _, err := jsonpath.Transpile(path, target); !jsonpath.IsUnsupported(err) {
+				t.Errorf("%s (%s): expected unsupported error, got: %v", path, target, err)
+			}
+		}
+	}
+
+	// MySQL escapes quotes in member names; SQLite cannot
+	if got, err := jsonpath.Transpile(`$['say "hi"']`, jsonpath.TargetMySQL); err != nil || got != `$."say \"hi\""` {
+		t.Errorf("expected escaped MySQL member, got %s (%v)", got, err)
+	}
+	if _, err := jsonpath.Transpile(`$['say "hi"']`, jsonpath.TargetSQLite); !jsonpath.IsUnsupported(err) {
+		t.Errorf("expected unsupported error for SQLite, got: %v", err)
+	}
+}
+
+func TestTranspileJQ(t *testing.T) {
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"$", "."},
+		{"$.store.book[0].title", ".store.book[0].title"},
+		{"$['first name'][-1]", `.["first name"][-1]`},
+		{"$.store.*", ".store[]?"},
+		{"$.store.book[*].author", `.store.book[]? | objects | select(has("author")) | .author`},
+		{"$..author", `.. | objects | select(has("author")) | .author`},
+		{"$..*", ".. | .[]?"},
+		{"$..[0]", ".. | arrays | .[0:1][]"},
+		{"$.items[-1:]", ".items | arrays | .[-1:][]"},
+		{"$.items[::2]", ".items | arrays | to_entries[] | select(.key % 2 == 0).value"},
+		{"$.items[:]", ".items | arrays | .[]"},
+		{"$.items[0,-1]", ".items | arrays | (.[0:1][], .[-1:][])"},
+		{"$['a','b']", `objects | (select(has("a")).a, select(has("b")).b)`},
+		{"$.items[?(@.isbn)]", ".items[]? | select(.isbn? != null)"},
+		{"$.items[?(@.price < 10)].sku", `.items[]? | select(.price? != null and .price? < 10) | objects | select(has("sku")) | .sku`},
+		// optional operands skip elements of other types, such as [1, "s", {"tags": "x"}]
+		{"$[?(@.tags[0] == 'go')].n", `.[]? | select(.tags?[0]? != null and .tags?[0]? == "go") | objects | select(has("n")) | .n`},
+		{"$[?(@.a == null || @.b == 'x')]", `.[]? | select(.a? == null or (.b? != null and .b? == "x"))`},
+		{"$[?(@.name =~ /^ab/i)]", `.[]? | select(.name? | type == "string" and test("^ab"; "i"))`},
+		{"$[?(@..tag == 'go')]", `.[]? | select(first(.. | objects | select(has("tag")) | .tag) != null and first(.. | objects | select(has("tag")) | .tag) == "go")`},
+	}
+	for _, tt := range tests {
+		got, err := jsonpath.Transpile(tt.path, jsonpath.TargetJQ)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", tt.path, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("%s:\n  expected %s\n       got %s", tt.path, tt.want, got)
+		}
+	}
+
+	for _, path := range []string{"$.items[::-1]", "$[?(@.name =~ /x/m)]", "$[?(valid(@))]"} {
+		if _, err := jsonpath.Transpile(path, jsonpath.TargetJQ); !jsonpath.IsUnsupported(err) {
+			t.Errorf("%s: expected unsupported error, got: %v", path, err)
+		}
+	}
+}
@@ -0,0 +1,99 @@
+package jsonpath
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+)
+
+// As runs a query and converts every matched value to T. Values that already
+// have type T are used as they are; others, including json.RawMessage values
+// from WithRawValues, are decoded into T through encoding/json, so T may be a
+// struct, a map, a slice or any scalar type json accepts. A value that cannot
+// be converted fails the whole call with ErrTypeMismatch.
+//
+// Example:
+//
+//	titles, err := jsonpath.As[string](data, "$.store.book[*].title")
+//	books, err := jsonpath.As[Book](data, "$.store.book[?(@.price < 10)]")
+func As[T any](data []byte, path string, opts ...Option) ([]T, error) {
+	results, err := Query(data, path, opts...)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]T, len(results))
+	for i, r := range results {
+		if out[i], err = convert[T](r); err != nil {
+			return nil, err
+		}
+	}
+	return out, nil
+}
+
+// FirstAs is As for the first match only. ok is false when nothing matched.
+//
+// Example:
+//
+//	name, ok, err := jsonpath.FirstAs[string](data, "$.user.name")
+func FirstAs[T any](data []byte, path string, opts ...Option) (v T, ok bool, err error) {
+	var first *Result
+	err = QueryFunc(context.Background(), data, path, func(r Result) error {
+		first = &r
+		return ErrStop
+	}, opts...)
+	if err != nil || first == nil {
+		return v, false, err
+	}
+	if v, err = convert[T](*first); err != nil {
+		return v, false, err
+	}
+	return v, true, nil
+}
+
+// Unmarshal decodes the single value matched by path into dst, which must be
+// a non-nil pointer, as json.Unmarshal would. It fails as QueryOne does when
+// the path does not match exactly one node, and with ErrTypeMismatch when the
+// match does not fit dst.
+//
+// Example:
+//
+//	var addr Address
+//	err := jsonpath.Unmarshal(data, "$.customers[?(@.id == 42)].address", &addr)
+func Unmarshal(data []byte, path string, dst interface{}, opts ...Option) error {
+	if rv := reflect.ValueOf(dst); rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return &Error{Code: ErrInvalidInput, Message: fmt.Sprintf("Unmarshal needs a non-nil pointer, got %T", dst)}
+	}
+	match, err := QueryOne(data, path, opts...)
+	if err != nil {
+		return err
+	}
+	if err := decodeValue(match.Value, dst); err != nil {
+		return &Error{Code: ErrTypeMismatch, Message: fmt.Sprintf("cannot unmarshal %s into %T", match.Path, dst), Cause: err}
+	}
+	return nil
+}
+
+// convert returns r.Value as a T.
+func convert[T any](r Result) (T, error) {
+	if v, ok := r.Value.(T); ok {
+		return v, nil
+	}
+	var v T
+	if err := decodeValue(r.Value, &v); err != nil {
+		return v, &Error{Code: ErrTypeMismatch, Message: fmt.Sprintf("cannot convert %s to %T", r.Path, v), Cause: err}
+	}
+	return v, nil
+}
+
+// decodeValue decodes a matched value into dst through encoding/json.
+func decodeValue(value, dst interface{}) error {
+	raw, ok := value.(json.RawMessage)
+	if !ok {
+		var err error
+		if raw, err = json.Marshal(value); err != nil {
+			return err
+		}
+	}
+	return json.Unmarshal(raw, dst)
+}
@@ -0,0 +1,106 @@
+package jsonpath_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/njchilds90/go-jsonpath"
+)
+
+type book struct {
+	Category string  `json:"category"`
+	Author   string  `json:"author"`
+	Title    string  `json:"title"`
+	Price    float64 `json:"price"`
+}
+
+func TestAs(t *testing.T) {
+	titles, err := jsonpath.As[string](sampleJSON, "$.store.book[*].title")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(titles) != 4 || titles[2] != "Moby Dick" {
+		t.Errorf("unexpected titles: %v", titles)
+	}
+
+	books, err := jsonpath.As[book](sampleJSON, "$.store.book[?(@.price < 10)]")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(books) != 2 || books[1].Title != "Moby Dick" || books[1].Price != 8.99 {
+		t.Errorf("unexpected books: %+v", books)
+	}
+
+	prices, err := jsonpath.As[int](sampleJSON, "$.store.bicycle.price", jsonpath.WithRawValues())
+	if !isTypeMismatch(err) {
+		t.Errorf("expected a type mismatch for 19.95 as int, got %v %v", prices, err)
+	}
+
+	raw, err := jsonpath.As[map[string]interface{}](sampleJSON, "$.store.bicycle", jsonpath.WithRawValues())
+	if err != nil || raw[0]["color"] != "red" {
+		t.Errorf("unexpected raw decode: %v %v", raw, err)
+	}
+
+	if _, err := jsonpath.As[string](sampleJSON, "$.store.book[*].price"); !isTypeMismatch(err) {
+		t.Errorf("expected type mismatch, got %v", err)
+	}
+	if _, err := jsonpath.As[string](sampleJSON, "store"); !jsonpath.IsPathError(err) {
+		t.Errorf("expected path error, got %v", err)
+	}
+}
+
+func TestFirstAs(t *testing.T) {
+	price, ok, err := jsonpath.FirstAs[float64](sampleJSON, "$.store.book[*].price")
+	if err != nil || !ok || price != 8.95 {
+		t.Errorf("expected 8.95, got %v %v %v", price, ok, err)
+	}
+
+	n, ok, err := jsonpath.FirstAs[json.Number](sampleJSON, "$.store.book[0].price")
+	if err != nil || !ok || n != "8.95" {
+		t.Errorf("expected json.Number 8.95, got %q %v %v", n, ok, err)
+	}
+
+	s, ok, err := jsonpath.FirstAs[string](sampleJSON, "$.store.missing")
+	if err != nil || ok || s != "" {
+		t.Errorf("expected no match, got %q %v %v", s, ok, err)
+	}
+}
+
+func isTypeMismatch(err error) bool {
+	e, ok := err.(*jsonpath.Error)
+	return ok && e.Code == jsonpath.ErrTypeMismatch
+}
+
+func TestUnmarshal(t *testing.T) {
+	var b book
+	if err := jsonpath.Unmarshal(sampleJSON, "$.store.book[?(@.isbn == '0-553-21311-3')]", &b); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if b.Title != "Moby Dick" || b.Author != "Herman Melville" {
+		t.Errorf("unexpected book: %+v", b)
+	}
+
+	var color string
+	if err := jsonpath.Unmarshal(sampleJSON, "$.store.bicycle.color", &color, jsonpath.WithRawValues()); err != nil || color != "red" {
+		t.Errorf("expected red, got %q %v", color, err)
+	}
+
+	tests := []struct {
+		name string
+		path string
+		dst  interface{}
+		ok   func(error) bool
+	}{
+		{"no match", "$.store.missing", &b, jsonpath.IsNoMatch},
+		{"many matches", "$.store.book[*]", &b, jsonpath.IsMultipleMatches},
+		{"wrong type", "$.store.book[0].price", &color, isTypeMismatch},
+		{"not a pointer", "$.store.bicycle.color", color, isInputError},
+		{"nil pointer", "$.store.bicycle.color", (*string)(nil), isInputError},
+		{"bad path", "store", &b, jsonpath.IsPathError},
+	}
+	for _, tt := range tests {
+		if err := jsonpath.Unmarshal(sampleJSON, tt.path, tt.dst); !tt.ok(err) {
+			t.Errorf("%s: unexpected error: %v", tt.name, err)
+		}
+	}
+}
@@ -0,0 +1,38 @@
+package jsonpath
+
+import "slices"
+
+// WithTypeFilter keeps only the matches whose value is of one of kinds,
+// dropping the rest before they are counted, sorted or passed to hooks. It
+// cleans up fields of mixed type, such as prices that are sometimes "N/A".
+// To test types inside a filter instead, use the built-in type() function,
+// which returns the name of NodeKind.String: [?type(@.price) == 'number'].
+//
+// Example:
+//
+//	prices, err := jsonpath.Query(data, "$..price", jsonpath.WithTypeFilter(jsonpath.NodeNumber))
+func WithTypeFilter(kinds ...NodeKind) Option {
+	return func(e *engine) {
+		e.kinds = kinds
+	}
+}
+
+// typeFilter wraps fn to drop the results WithTypeFilter excludes.
+func (e *engine) typeFilter(fn emitFunc) emitFunc {
+	return func(r Result) error {
+		if !slices.Contains(e.kinds, kindOf(r.Value)) {
+			return nil
+		}
+		return fn(r)
+	}
+}
+
+// typeOf implements the built-in type() filter function: the name of the
+// JSON type of arg, such as "number", or nothing when arg matches nothing.
+func (e *engine) typeOf(node interface{}, arg operand) (interface{}, bool, error) {
+	v, found, err := e.resolveOperand(node, arg)
+	if err != nil || !found {
+		return nil, false, err
+	}
+	return kindOf(v).String(), true, nil
+}
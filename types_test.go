@@ -0,0 +1,77 @@
+package jsonpath_test
+
+import (
+	"testing"
+
+	"github.com/njchilds90/go-jsonpath"
+)
+
+var mixedJSON = []byte(`{"items": [
+	{"sku": "a", "price": 10},
+	{"sku": "b", "price": "N/A"},
+	{"sku": "c", "price": null},
+	{"sku": "d", "price": 2.5},
+	{"sku": "e"}
+]}`)
+
+func TestTypeFilter(t *testing.T) {
+	results, err := jsonpath.Query(mixedJSON, "$..price", jsonpath.WithTypeFilter(jsonpath.NodeNumber))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(results) != 2 || results[0].Value != float64(10) || results[1].Value != 2.5 {
+		t.Fatalf("expected the two numeric prices, got %v", results)
+	}
+
+	var stats jsonpath.Stats
+	n, err := jsonpath.Count(mixedJSON, "$..price", jsonpath.WithTypeFilter(jsonpath.NodeString, jsonpath.NodeNull), jsonpath.WithStats(&stats))
+	if err != nil || n != 2 || stats.Matches != 2 {
+		t.Errorf("expected 2 matches counted, got %d and %d (%v)", n, stats.Matches, err)
+	}
+
+	results, err = jsonpath.Query(mixedJSON, "$.items[*]", jsonpath.WithTypeFilter(jsonpath.NodeArray))
+	if err != nil || len(results) != 0 {
+		t.Errorf("expected no arrays, got %v (%v)", results, err)
+	}
+
+	// filter operands are not subject to the type filter
+	results, err = jsonpath.Query(sampleJSON, "$.store.book[?(@.price < 10)]", jsonpath.WithTypeFilter(jsonpath.NodeObject))
+	if err != nil || len(results) != 2 {
+		t.Errorf("expected the 2 cheap books, got %v (%v)", results, err)
+	}
+}
+
+func TestTypeFunction(t *testing.T) {
+	tests := []struct {
+		path string
+		want []interface{}
+	}{
+		{"$.items[?type(@.price) == 'number'].sku", []interface{}{"a", "d"}},
+		{"$.items[?(type(@.price) == 'string')].sku", []interface{}{"b"}},
+		{"$.items[?type(@.price)=='null'].sku", []interface{}{"c"}},
+		{"$.items[?type(@.price) != 'number'].sku", []interface{}{"b", "c"}},
+		{"$.items[?type(@) == 'object'].sku", []interface{}{"a", "b", "c", "d", "e"}},
+		{"$.items[?type(@.price) == 'array'].sku", nil},
+	}
+	for _, tt := range tests {
+		results, err := jsonpath.Query(mixedJSON, tt.path)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", tt.path, err)
+			continue
+		}
+		if len(results) != len(tt.want) {
+			t.Errorf("%s: expected %v, got %v", tt.path, tt.want, results)
+			continue
+		}
+		for i, r := range results {
+			if r.Value != tt.want[i] {
+				t.Errorf("%s: expected %v, got %v", tt.path, tt.want[i], r.Value)
+			}
+		}
+	}
+
+	expr, err := jsonpath.Transpile("$.items[?type(@.price) == 'number'].sku", jsonpath.TargetJQ)
+	if err != nil || expr != `.items[]? | select((.price? | type) == "number") | objects | select(has("sku")) | .sku` {
+		t.Errorf("unexpected jq translation %s (%v)", expr, err)
+	}
+}
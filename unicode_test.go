@@ -0,0 +1,89 @@
+package jsonpath_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/njchilds90/go-jsonpath"
+)
+
+// TestUnicodeMemberNames checks that member names with escapes, non-ASCII
+// and astral characters can be selected in every spelling the parser
+// accepts, and that the path of each result selects the same value again.
+func TestUnicodeMemberNames(t *testing.T) {
+	tests := []struct {
+		key       string
+		selectors []string
+		path      string
+	}{
+		{"café", []string{`$.café`, `$['café']`, `$['caf\u00e9']`, `$["caf\u00E9"]`}, `$['café']`},
+		{"ключ", []string{`$.ключ`, `$['ключ']`, `$['\u043a\u043b\u044e\u0447']`}, `$['ключ']`},
+		{"🚀", []string{`$.🚀`, `$['🚀']`, `$['\ud83d\ude80']`, `$["\uD83D\uDE80"]`}, `$['🚀']`},
+		{"a🚀b", []string{`$.a🚀b`, `$['a\ud83d\ude80b']`}, `$['a🚀b']`},
+		{"日本語", []string{`$.日本語`, `$['\u65e5\u672c\u8a9e']`}, `$['日本語']`},
+		{"tab\there", []string{`$['tab\there']`, `$['tab\u0009here']`}, `$['tab\there']`},
+		{"line\nbreak", []string{`$['line\nbreak']`, `$["line\u000abreak"]`}, `$['line\nbreak']`},
+		{"nul\x00", []string{`$['nul\u0000']`}, `$['nul\u0000']`},
+		{`back\slash`, []string{`$['back\\slash']`, `$['back\u005cslash']`}, `$['back\\slash']`},
+		{"it's", []string{`$['it\'s']`, `$["it's"]`, `$['it\u0027s']`}, `$['it\'s']`},
+		{"a]b", []string{`$['a]b']`, `$['a\u005db']`}, `$['a]b']`},
+		{"A", []string{`$.A`, `$['A']`}, `$.A`},
+	}
+	for _, tt := range tests {
+		doc, err := json.Marshal(map[string]interface{}{tt.key: 1, "other": 2})
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, sel := range tt.selectors {
+			results, err := jsonpath.Query(doc, sel)
+			if err != nil {
+				t.Errorf("%s: unexpected error: %v", sel, err)
+				continue
+			}
+			if len(results) != 1 || results[0].Value != float64(1) {
+				t.Errorf("%s: expected to select %q, got %v", sel, tt.key, results)
+				continue
+			}
+			if results[0].Path != tt.path {
+				t.Errorf("%s: expected path %s, got %s", sel, tt.path, results[0].Path)
+			}
+		}
+
+		again, err := jsonpath.Query(doc, tt.path)
+		if err != nil || len(again) != 1 || again[0].Value != float64(1) {
+			t.Errorf("%s: expected the result path to select %q again, got %v (%v)", tt.path, tt.key, again, err)
+			continue
+		}
+		segs := again[0].Segments()
+		if len(segs) != 1 || segs[0].Name != tt.key || jsonpath.FormatPath(segs) != tt.path {
+			t.Errorf("%s: expected one segment %q, got %v", tt.path, tt.key, segs)
+		}
+		if path, err := jsonpath.PointerToPath(again[0].Pointer()); err != nil || path != tt.path {
+			t.Errorf("%s: pointer %q converts back to %s (%v)", tt.path, again[0].Pointer(), path, err)
+		}
+	}
+}
+
+func TestUnicodeFilterStrings(t *testing.T) {
+	data := []byte(`[{"name":"café","id":1},{"name":"🚀","id":2},{"name":"a\nb","id":3}]`)
+	tests := []struct {
+		path string
+		want float64
+	}{
+		{`$[?(@.name == 'café')].id`, 1},
+		{`$[?(@.name == 'caf\u00e9')].id`, 1},
+		{`$[?(@.name == "🚀")].id`, 2},
+		{`$[?(@.name == '\ud83d\ude80')].id`, 2},
+		{`$[?(@.name == 'a\nb')].id`, 3},
+	}
+	for _, tt := range tests {
+		results, err := jsonpath.Query(data, tt.path)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", tt.path, err)
+			continue
+		}
+		if len(results) != 1 || results[0].Value != tt.want {
+			t.Errorf("%s: expected %v, got %v", tt.path, tt.want, results)
+		}
+	}
+}
@@ -0,0 +1,35 @@
+package jsonpath
+
+import "fmt"
+
+// WithUniqueNodes returns each node once, dropping matches whose normalized
+// path was already returned, such as the second [0] of $[0,0,1] or a node
+// reached both by a union and by a descendant segment. The first match of a
+// node is kept, in its place. RFC 9535 keeps duplicates, so this is off by
+// default.
+//
+// Example:
+//
+//	results, err := jsonpath.Query(data, "$..[?(@.id)]..[?(@.id)]", jsonpath.WithUniqueNodes())
+func WithUniqueNodes() Option {
+	return func(e *engine) {
+		e.unique = true
+	}
+}
+
+// dedupe wraps fn to drop the matches whose path, and substring, it has
+// already seen.
+func (e *engine) dedupe(fn emitFunc) emitFunc {
+	seen := map[string]bool{}
+	return func(r Result) error {
+		key := r.Path
+		if r.Substring != nil {
+			key += fmt.Sprint(*r.Substring)
+		}
+		if seen[key] {
+			return nil
+		}
+		seen[key] = true
+		return fn(r)
+	}
+}
@@ -0,0 +1,42 @@
+package jsonpath_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/njchilds90/go-jsonpath"
+)
+
+func TestUniqueNodes(t *testing.T) {
+	tests := []struct {
+		path string
+		want []string
+	}{
+		{"$.store.book[0,0,1].title", []string{"$.store.book[0].title", "$.store.book[1].title"}},
+		{"$.store.book[-1,3]", []string{"$.store.book[3]"}},
+		{"$.store['book','book'][0]", []string{"$.store.book[0]"}},
+		{"$..*..price", []string{"$.store.bicycle.price", "$.store.book[0].price", "$.store.book[1].price", "$.store.book[2].price", "$.store.book[3].price"}},
+	}
+	for _, tt := range tests {
+		paths, err := jsonpath.Paths(sampleJSON, tt.path, jsonpath.WithUniqueNodes())
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", tt.path, err)
+			continue
+		}
+		if strings.Join(paths, " ") != strings.Join(tt.want, " ") {
+			t.Errorf("%s: expected %v, got %v", tt.path, tt.want, paths)
+		}
+	}
+
+	if paths, _ := jsonpath.Paths(sampleJSON, "$.store.book[0,0]"); len(paths) != 2 {
+		t.Errorf("expected duplicates by default, got %v", paths)
+	}
+	n, err := jsonpath.Count(sampleJSON, "$..*..price", jsonpath.WithUniqueNodes())
+	if err != nil || n != 5 {
+		t.Errorf("expected 5 unique prices counted, got %d (%v)", n, err)
+	}
+	paths, err := jsonpath.Paths(sampleJSON, "$.store.book[0,0,1,1,2]", jsonpath.WithUniqueNodes(), jsonpath.WithMaxResults(2))
+	if err != nil || len(paths) != 2 || paths[1] != "$.store.book[1]" {
+		t.Errorf("expected pages of unique nodes, got %v (%v)", paths, err)
+	}
+}
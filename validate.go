@@ -0,0 +1,43 @@
+package jsonpath
+
+import "context"
+
+// Diagnostic is one problem Validate found in a path.
+type Diagnostic struct {
+	// Offset is the byte offset in the path of the problem, as in Error.Offset.
+	Offset int `json:"offset"`
+	// Code is a short, stable name for the kind of problem: empty-path,
+	// missing-root, trailing-dot, invalid-member, unexpected-character,
+	// unclosed-bracket, invalid-bracket, invalid-filter, empty-alternative,
+	// unknown-function, function-not-last, names-not-last, invalid-default or
+	// parent-selector.
+	Code string `json:"code"`
+	// Message describes the problem, as the error from Compile would.
+	Message string `json:"message"`
+	// Suggestion proposes a fix, such as "did you mean $['my key']?", when
+	// one is likely; otherwise it is empty.
+	Suggestion string `json:"suggestion,omitempty"`
+
+	err *Error
+}
+
+// Validate checks the syntax of path without running it and returns every
+// problem found, not just the first, in the order they occur. After a
+// problem it resumes at the next selector. A path without diagnostics
+// compiles with the same options. Options enabling extensions, such as
+// WithParentSelector, allow their syntax; without them it is reported.
+// Validate suits checking user-supplied paths when configuration is loaded,
+// where actionable messages matter.
+//
+// Example:
+//
+//	for _, d := range jsonpath.Validate("$.my key[?(@.a = 1)]") {
+//	    fmt.Printf("%d %s: %s\n", d.Offset, d.Code, d.Suggestion)
+//	}
+//	// 4 unexpected-character: did you mean $['my key'][?(@.a = 1)]?
+//	// 15 invalid-filter: use == to compare values
+func Validate(path string, opts ...Option) []Diagnostic {
+	s := &pathScanner{all: true, gateParent: !newEngine(context.Background(), opts).allowParent}
+	s.scan(path, 0)
+	return s.diags
+}
@@ -0,0 +1,67 @@
+package jsonpath_test
+
+import (
+	"testing"
+
+	"github.com/njchilds90/go-jsonpath"
+)
+
+func TestValidate(t *testing.T) {
+	type diag struct {
+		offset           int
+		code, suggestion string
+	}
+	tests := []struct {
+		path string
+		want []diag
+	}{
+		{"$.store.book[0].title", nil},
+		{"", []diag{{0, "empty-path", ""}}},
+		{"  ", []diag{{0, "empty-path", ""}}},
+		{"store.book", []diag{{0, "missing-root", "did you mean $.store.book?"}}},
+		{"@.a", []diag{{0, "missing-root", "did you mean $.a?"}}},
+		{"$.a.", []diag{{3, "trailing-dot", "remove the trailing '.'"}}},
+		{"$.my key.items[0", []diag{
+			{4, "unexpected-character", "did you mean $['my key'].items[0?"},
+			{14, "unclosed-bracket", "close the bracket with ']'"},
+		}},
+		{"$.#a[x:y].b", []diag{
+			{2, "invalid-member", "did you mean $['#a'][x:y].b?"},
+			{5, "invalid-bracket", ""},
+		}},
+		{"$.a[?(@.b = 1)]", []diag{{10, "invalid-filter", "use == to compare values"}}},
+		{"$..[?(@.b)]^.c^", []diag{
+			{11, "parent-selector", "enable ^ with WithParentSelector"},
+			{14, "parent-selector", "enable ^ with WithParentSelector"},
+		}},
+		{"$.a |  | $.b[", []diag{
+			{5, "empty-alternative", "remove the extra '|'"},
+			{12, "unclosed-bracket", "close the bracket with ']'"},
+		}},
+	}
+	for _, tt := range tests {
+		got := jsonpath.Validate(tt.path)
+		if len(got) != len(tt.want) {
+			t.Errorf("%q: expected %d diagnostics, got %+v", tt.path, len(tt.want), got)
+			continue
+		}
+		for i, d := range got {
+			w := tt.want[i]
+			if d.Offset != w.offset || d.Code != w.code || d.Suggestion != w.suggestion || d.Message == "" {
+				t.Errorf("%q: expected %+v, got %+v", tt.path, w, d)
+			}
+		}
+		if _, err := jsonpath.Compile(tt.path); (err == nil) != (len(got) == 0) {
+			t.Errorf("%q: Compile and Validate disagree: %v, %+v", tt.path, err, got)
+		}
+	}
+}
+
+func TestValidateOptions(t *testing.T) {
+	if d := jsonpath.Validate("$..[?(@.b)]^.c^", jsonpath.WithParentSelector()); len(d) != 0 {
+		t.Errorf("expected ^ to validate with WithParentSelector, got %+v", d)
+	}
+	if _, err := jsonpath.Compile("$..[?(@.b)]^.c^", jsonpath.WithParentSelector()); err != nil {
+		t.Errorf("expected the path to compile with WithParentSelector, got %v", err)
+	}
+}
@@ -0,0 +1,97 @@
+package jsonpath
+
+import (
+	"context"
+	"sync"
+)
+
+// WatchEvent reports that a match of a watched path appeared (ChangeAdded),
+// disappeared (ChangeRemoved) or changed value (ChangeModified) between two
+// document versions. Path, Old and New describe the match as in Change.
+type WatchEvent struct {
+	// Query is the watched path.
+	Query *CompiledPath `json:"query"`
+	Change
+}
+
+// Watcher detects changes in the matches of compiled paths across successive
+// versions of a document, such as a polled configuration or state file. It is
+// safe for concurrent use; versions are compared in the order Update is
+// called.
+//
+// Example:
+//
+//	w := jsonpath.NewWatcher(jsonpath.MustCompile("$.features[?(@.enabled)].name"))
+//	for range ticker.C {
+//	    events, err := w.Update(fetchConfig())
+//	    for _, ev := range events {
+//	        log.Printf("%s %s: %v → %v", ev.Kind, ev.Path, ev.Old, ev.New)
+//	    }
+//	}
+type Watcher struct {
+	paths []*CompiledPath
+
+	mu   sync.Mutex
+	last [][]Result // matches of each path in the previous version
+}
+
+// NewWatcher returns a Watcher for paths. Until the first Update there are
+// no previous matches, so every match of the first version is added.
+func NewWatcher(paths ...*CompiledPath) *Watcher {
+	return &Watcher{paths: paths, last: make([][]Result, len(paths))}
+}
+
+// Update evaluates every watched path against data, the next version of the
+// document, and returns how their matches differ from the previous version:
+// for each path in turn, first the matches of data in order, then the
+// matches that disappeared. A match whose value is deeply equal is not
+// reported. If data cannot be queried the previous version is kept.
+func (w *Watcher) Update(data []byte, opts ...Option) ([]WatchEvent, error) {
+	e := newEngine(context.Background(), opts)
+	doc, err := e.parse(data)
+	if err != nil {
+		return nil, err
+	}
+	e.attach(doc)
+	current := make([][]Result, len(w.paths))
+	for i, cp := range w.paths {
+		if current[i], err = e.collect(doc.root, cp.tokens); err != nil {
+			return nil, err
+		}
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	var events []WatchEvent
+	for i, cp := range w.paths {
+		previous := make(map[string]interface{}, len(w.last[i]))
+		for _, r := range w.last[i] {
+			previous[r.Path] = r.Value
+		}
+		for _, r := range current[i] {
+			old, ok := previous[r.Path]
+			delete(previous, r.Path)
+			switch {
+			case !ok:
+				events = append(events, WatchEvent{Query: cp, Change: Change{Path: r.Path, Kind: ChangeAdded, New: r.Value}})
+			case !valuesEqual(old, r.Value):
+				events = append(events, WatchEvent{Query: cp, Change: Change{Path: r.Path, Kind: ChangeModified, Old: old, New: r.Value}})
+			}
+		}
+		for _, r := range w.last[i] {
+			if _, ok := previous[r.Path]; ok {
+				events = append(events, WatchEvent{Query: cp, Change: Change{Path: r.Path, Kind: ChangeRemoved, Old: r.Value}})
+			}
+		}
+	}
+	w.last = current
+	return events, nil
+}
+
+// valuesEqual reports whether a and b are the same JSON value, as Diff
+// compares them.
+func valuesEqual(a, b interface{}) bool {
+	d := &differ{}
+	d.diff(decodedValue(a), decodedValue(b), "$", 0)
+	return len(d.changes) == 0
+}
@@ -0,0 +1,63 @@
+package jsonpath_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/njchilds90/go-jsonpath"
+)
+
+func TestWatcher(t *testing.T) {
+	names := jsonpath.MustCompile("$.features[?(@.enabled)].name")
+	limit := jsonpath.MustCompile("$.limits")
+	w := jsonpath.NewWatcher(names, limit)
+
+	events, err := w.Update([]byte(`{"features": [{"name": "a", "enabled": true}, {"name": "b"}], "limits": {"rps": 10}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 2 || events[0].Kind != jsonpath.ChangeAdded || events[0].Path != "$.features[0].name" || events[1].Query != limit {
+		t.Errorf("expected every first match to be added, got %v", events)
+	}
+
+	events, err = w.Update([]byte(`{"features": [{"name": "a", "enabled": true}, {"name": "b"}], "limits": {"rps": 10.0}}`))
+	if err != nil || len(events) != 0 {
+		t.Errorf("expected no events for an equal version, got %v, %v", events, err)
+	}
+
+	events, err = w.Update([]byte(`{"features": [{"name": "a"}, {"name": "b", "enabled": true}], "limits": {"rps": 20}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []struct {
+		path string
+		kind jsonpath.ChangeKind
+	}{
+		{"$.features[1].name", jsonpath.ChangeAdded},
+		{"$.features[0].name", jsonpath.ChangeRemoved},
+		{"$.limits", jsonpath.ChangeModified},
+	}
+	if len(events) != len(want) {
+		t.Fatalf("expected %d events, got %v", len(want), events)
+	}
+	for i, ev := range events {
+		if ev.Path != want[i].path || ev.Kind != want[i].kind {
+			t.Errorf("event %d: expected %s %s, got %s %s", i, want[i].kind, want[i].path, ev.Kind, ev.Path)
+		}
+	}
+	if events[1].Old != "a" || events[2].New.(map[string]interface{})["rps"] != 20.0 {
+		t.Errorf("unexpected values: %v", events)
+	}
+	b, err := json.Marshal(events[0])
+	if err != nil || string(b) != `{"query":"$.features[?(@.enabled)].name","path":"$.features[1].name","kind":"added","old":null,"new":"b"}` {
+		t.Errorf("unexpected JSON: %s, %v", b, err)
+	}
+
+	if _, err := w.Update([]byte(`{`)); !jsonpath.IsJSONError(err) {
+		t.Errorf("expected ErrInvalidJSON, got %v", err)
+	}
+	events, err = w.Update([]byte(`{"features": [{"name": "a"}, {"name": "b", "enabled": true}], "limits": {"rps": 20}}`))
+	if err != nil || len(events) != 0 {
+		t.Errorf("expected a failed update to keep the previous version, got %v, %v", events, err)
+	}
+}